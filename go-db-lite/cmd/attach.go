@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/ansi"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/engine"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/mvcc"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+var (
+	// attached maps the lower case names given by ATTACH to database file names in the
+	// operating directory. Attachments belong to the session and survive USE.
+	attached = map[string]string{}
+
+	// attachedStores are the stores of attached databases by file path, opened on first use
+	attachedStores = map[string]*mvcc.Store{}
+)
+
+// attachedDatabaseName returns the name of the database attached under the given name, or
+// the name itself when nothing is attached under it
+func attachedDatabaseName(name string) string {
+	if file, ok := attached[strings.ToLower(name)]; ok {
+		return strings.TrimSuffix(file, ".db")
+	}
+	return name
+}
+
+// attachDatabases makes the attached databases, as last committed, readable from a query on
+// db, along with db itself under the name of the database in use
+func attachDatabases(db *types.Database) error {
+	db.Attached = map[string]*types.Database{strings.ToLower(currentDatabase()): db}
+	for name, file := range attached {
+		path := config.HomeDir + "/" + file
+		s := attachedStores[path]
+		if store != nil && store.Path() == path {
+			s = store
+		}
+		if s == nil {
+			var err error
+			if s, err = mvcc.Open(path, config.BusyTimeout); err != nil {
+				return fmt.Errorf("attached database %s: %v", name, err)
+			}
+			attachedStores[path] = s
+		}
+		s.LockTimeout = config.BusyTimeout
+		t, err := s.Begin(mvcc.ReadCommitted)
+		if err != nil {
+			return fmt.Errorf("attached database %s: %v", name, err)
+		}
+		other, err := t.Database()
+		t.Rollback()
+		if err != nil {
+			return fmt.Errorf("attached database %s: %v", name, err)
+		}
+		db.Attached[name] = other
+	}
+	return nil
+}
+
+// executeAttachCommand runs ATTACH and DETACH. Attached databases can be read, not
+// changed; USE switches to one to change it.
+func executeAttachCommand(buffer []byte) {
+	stmt, err := parser.ParseAttach(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid attach command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: ATTACH [DATABASE] 'file.db' AS name or DETACH [DATABASE] name" + ansi.Reset)
+		return
+	}
+	key := strings.ToLower(stmt.Alias)
+	if stmt.Detach {
+		file, ok := attached[key]
+		if !ok {
+			printError(ansi.BoldText+ansi.Red+"Error detaching database: database not attached:"+ansi.Reset, stmt.Alias)
+			return
+		}
+		delete(attached, key)
+		delete(attachedStores, config.HomeDir+"/"+file)
+		fmt.Println(ansi.RegText+ansi.Green+"Database detached:"+ansi.Reset, stmt.Alias)
+		return
+	}
+	if key == engine.InformationSchema {
+		printError(ansi.BoldText+ansi.Red+"Error attaching database: the name is reserved:"+ansi.Reset, stmt.Alias)
+		return
+	}
+	if _, ok := attached[key]; ok {
+		printError(ansi.BoldText+ansi.Red+"Error attaching database: a database is already attached as"+ansi.Reset, stmt.Alias)
+		return
+	}
+	file := stmt.File
+	if !strings.HasSuffix(file, ".db") {
+		file += ".db"
+	}
+	if strings.ContainsAny(file, `/\`) {
+		printError(ansi.BoldText+ansi.Red+"Error attaching database: the file must be in the operating directory:"+ansi.Reset, stmt.File)
+		return
+	}
+	if _, err := os.Stat(config.HomeDir + "/" + file); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error attaching database:"+ansi.Reset, err)
+		return
+	}
+	attached[key] = file
+	fmt.Println(ansi.RegText+ansi.Green+"Database attached:"+ansi.Reset, file, "as", stmt.Alias)
+}
+
+// attachedNames returns the names databases are attached as, sorted, given their file name
+func attachedNames(file string) []string {
+	var names []string
+	for name, f := range attached {
+		if f == file {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
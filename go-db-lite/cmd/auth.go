@@ -0,0 +1,578 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/ansi"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/auth"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/engine"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/lock"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+	"golang.org/x/term"
+)
+
+// CatalogFileName is the system catalog of users, roles and grants in the operating directory
+const CatalogFileName = ".catalog"
+
+// PasswordEnv holds the password to log in with, instead of prompting for it
+const PasswordEnv = "GO_DB_LITE_PASSWORD"
+
+// sessionUser is the user the session logged in as
+var sessionUser = auth.Root
+
+func catalogPath() string {
+	return config.HomeDir + "/" + CatalogFileName
+}
+
+// initCatalog creates the system catalog if the operating directory has none yet
+func initCatalog() error {
+	if _, err := os.Stat(catalogPath()); !os.IsNotExist(err) {
+		return err
+	}
+	return auth.NewCatalog().Save(catalogPath())
+}
+
+// loadCatalog reads the system catalog, which other sessions may have changed
+func loadCatalog() (*auth.Catalog, error) {
+	if _, err := os.Stat(catalogPath()); os.IsNotExist(err) {
+		return auth.NewCatalog(), nil
+	}
+	fileLock := lock.OpenFile(catalogPath())
+	if err := fileLock.Lock(lock.Shared, config.BusyTimeout); err != nil {
+		return nil, err
+	}
+	defer fileLock.Unlock(lock.Shared)
+	return auth.Load(catalogPath())
+}
+
+// updateCatalog changes the system catalog, holding it locked against other processes
+func updateCatalog(fn func(c *auth.Catalog) error) error {
+	if err := initCatalog(); err != nil {
+		return err
+	}
+	fileLock := lock.OpenFile(catalogPath())
+	if err := fileLock.Lock(lock.Exclusive, config.BusyTimeout); err != nil {
+		return err
+	}
+	defer fileLock.Unlock(lock.Exclusive)
+	c, err := auth.Load(catalogPath())
+	if err != nil {
+		return err
+	}
+	if err := fn(c); err != nil {
+		return err
+	}
+	return c.Save(catalogPath())
+}
+
+// login authenticates the session user, prompting for a password when one is needed
+func login(name string) error {
+	c, err := loadCatalog()
+	if err != nil {
+		return err
+	}
+	password := ""
+	if c.NeedsPassword(name) {
+		if password, err = readPassword(name); err != nil {
+			return err
+		}
+	}
+	if err := c.Authenticate(name, password); err != nil {
+		return err
+	}
+	if u := c.FindUser(name); u != nil {
+		sessionUser = u.Name
+	}
+	return nil
+}
+
+// readPassword takes the password from the environment, the terminal without echo, or a
+// line of standard input
+func readPassword(name string) (string, error) {
+	if password, ok := os.LookupEnv(PasswordEnv); ok {
+		return password, nil
+	}
+	fmt.Print("Password for " + name + ": ")
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		password, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		return string(password), err
+	}
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// currentDatabase returns the name of the database in use
+func currentDatabase() string {
+	return strings.TrimSuffix(config.DBFileName, ".db")
+}
+
+// requirement is a privilege a statement needs
+type requirement struct {
+	privilege auth.Privilege
+	on        auth.Object
+	columns   []string // the privilege on these columns is enough, if any
+}
+
+// authorize checks the session user's privileges before a statement runs. Statements that
+// do not parse are let through, for their handler to report the syntax error.
+func authorize(command types.CommandType, buffer []byte) error {
+	if strings.EqualFold(sessionUser, auth.Root) {
+		return nil
+	}
+	input := string(buffer)
+	db := currentDatabase()
+	if cmd, ok := command.(types.CoreCommand); ok && cmd.Command() == types.CmdUse {
+		target, err := databaseName(input[len("use"):])
+		if err != nil {
+			return nil
+		}
+		c, err := loadCatalog()
+		if err != nil {
+			return err
+		}
+		if !c.AllowedAny(sessionUser, target) {
+			return fmt.Errorf("user %s has no privileges on database %s", sessionUser, target)
+		}
+		return nil
+	}
+	reqs := requirements(command, input, db)
+	if len(reqs) == 0 {
+		return nil
+	}
+	c, err := loadCatalog()
+	if err != nil {
+		return err
+	}
+	for _, r := range reqs {
+		if !c.Allowed(sessionUser, r.privilege, r.on, r.columns) {
+			on := r.on.String()
+			if r.on.Table != "" && len(r.columns) > 0 {
+				on += "(" + strings.Join(r.columns, ", ") + ")"
+			}
+			return fmt.Errorf("user %s lacks the %s privilege on %s", sessionUser, r.privilege, on)
+		}
+	}
+	return nil
+}
+
+// authorizeSQL checks the privileges of a statement the session runs indirectly, such as
+// one in the body of a procedure
+func authorizeSQL(sql string) error {
+	command, err := parser.ParseCommand(&types.InputBuffer{Buffer: []byte(sql)})
+	if err != nil {
+		return err
+	}
+	return authorize(command, []byte(sql))
+}
+
+// requirements returns the privileges a statement needs
+func requirements(command types.CommandType, input, db string) []requirement {
+	onDatabase := auth.Object{Database: db}
+	onTable := func(table string) auth.Object {
+		return auth.Object{Database: db, Table: table}
+	}
+	single := func(p auth.Privilege, on auth.Object) []requirement {
+		return []requirement{{privilege: p, on: on}}
+	}
+	switch cmd := command.(type) {
+	case types.CoreCommand:
+		switch cmd.Command() {
+		case types.CmdSelect:
+			if stmt, err := parser.ParseSelect(input); err == nil {
+				return selectRequirements(stmt, onTable)
+			}
+		case types.CmdInsert:
+			if stmt, err := parser.ParseInsert(input); err == nil {
+				return []requirement{{privilege: auth.Insert, on: onTable(stmt.Table), columns: stmt.Columns}}
+			}
+		case types.CmdUpdate:
+			if stmt, err := parser.ParseUpdate(input); err == nil {
+				var columns []string
+				for _, a := range stmt.Set {
+					columns = append(columns, a.Column)
+				}
+				return []requirement{{privilege: auth.Update, on: onTable(stmt.Table), columns: columns}}
+			}
+		case types.CmdDelete:
+			if stmt, err := parser.ParseDelete(input); err == nil {
+				return single(auth.Delete, onTable(stmt.Table))
+			}
+		case types.CmdImport:
+			if stmt, err := parser.ParseImport(input); err == nil {
+				return single(auth.Insert, onTable(stmt.Table))
+			}
+		case types.CmdTruncate:
+			if stmt, err := parser.ParseTruncate(input); err == nil {
+				return single(auth.Delete, onTable(stmt.Table))
+			}
+		case types.CmdRefresh:
+			return single(auth.View, onDatabase)
+		case types.CmdCall:
+			return single(auth.Procedure, onDatabase)
+		case types.CmdComment:
+			if stmt, err := parser.ParseComment(input); err == nil {
+				return single(auth.Alter, onTable(stmt.Table))
+			}
+		case types.CmdPragma:
+			// reading settings needs no privilege, like the other descriptions of the schema
+			if stmt, err := parser.ParsePragma(input); err == nil && stmt.Set {
+				if stmt.Table != "" {
+					return single(auth.Alter, onTable(stmt.Table))
+				}
+				return single(auth.Alter, onDatabase)
+			}
+		}
+	case types.CreateCommand:
+		switch cmd.Command() {
+		case types.CmdCreateDatabase:
+			name, err := databaseName(input[len("create database"):])
+			if err != nil {
+				return nil
+			}
+			return single(auth.Create, auth.Object{Database: name})
+		case types.CmdCreateTable, types.CmdCreateSequence:
+			return single(auth.Create, onDatabase)
+		case types.CmdCreateIndex, types.CmdCreateUniqueIndex:
+			return single(auth.Index, onDatabase)
+		case types.CmdCreateView, types.CmdCreateOrReplaceView, types.CmdCreateMaterializedView:
+			// a view lends its readers the privileges of its creator on what it reads
+			if stmt, err := parser.ParseCreateView(input); err == nil {
+				return append(single(auth.View, onDatabase), selectRequirements(stmt.Query, onTable)...)
+			}
+			return single(auth.View, onDatabase)
+		case types.CmdCreateProcedure, types.CmdCreateOrReplaceProcedure:
+			return single(auth.Procedure, onDatabase)
+		case types.CmdCreateTrigger:
+			// the statements of a trigger are not checked against the privileges of the
+			// user whose change fires it, so creating one takes ALTER on its table, and its
+			// handler checks the body against the privileges of its creator
+			if stmt, err := parser.ParseCreateTrigger(input); err == nil {
+				return single(auth.Alter, onTable(stmt.Table))
+			}
+			return single(auth.Alter, onDatabase)
+		}
+	case types.DropCommand:
+		switch cmd.Command() {
+		case types.CmdDropTable:
+			if stmt, err := parser.ParseDrop(input, "TABLE"); err == nil {
+				return single(auth.Drop, onTable(stmt.Name))
+			}
+		case types.CmdDropDatabase:
+			if stmt, err := parser.ParseDrop(input, "DATABASE"); err == nil {
+				return single(auth.Drop, auth.Object{Database: stmt.Name})
+			}
+		case types.CmdDropIndex:
+			return single(auth.Index, onDatabase)
+		case types.CmdDropTrigger:
+			return single(auth.Alter, onDatabase)
+		case types.CmdDropView, types.CmdDropMaterializedView, types.CmdDropProcedure, types.CmdDropSequence:
+			return single(auth.Drop, onDatabase)
+		}
+	case types.AlterCommand:
+		switch cmd.Command() {
+		case types.CmdAlterTable:
+			if stmt, err := parser.ParseAlterTable(input); err == nil {
+				return single(auth.Alter, onTable(stmt.Table))
+			}
+		case types.CmdAlterIndex, types.CmdAlterView, types.CmdAlterProcedure:
+			return single(auth.Alter, onDatabase)
+		}
+	case types.LockCommand:
+		if stmt, err := parser.ParseLock(input); err == nil {
+			if stmt.Database {
+				return single(auth.Select, onDatabase)
+			}
+			var reqs []requirement
+			for _, table := range stmt.Tables {
+				reqs = append(reqs, requirement{privilege: auth.Select, on: onTable(table)})
+			}
+			return reqs
+		}
+	}
+	return nil
+}
+
+// selectRequirements returns the privileges a SELECT needs: SELECT on the columns it reads
+// of a single table or view, or on the whole of each one it joins. The tables of an
+// attached database are checked against the privileges on that database.
+func selectRequirements(stmt *parser.SelectStmt, onTable func(string) auth.Object) []requirement {
+	if stmt.From == nil {
+		return nil
+	}
+	on := func(from *parser.TableName) auth.Object {
+		object := onTable(from.Name)
+		if from.Schema != "" {
+			object.Database = attachedDatabaseName(from.Schema)
+		}
+		return object
+	}
+	// information_schema only describes the schema, which every user can see
+	catalog := func(from *parser.TableName) bool {
+		return strings.EqualFold(from.Schema, engine.InformationSchema)
+	}
+	if len(stmt.Joins) == 0 {
+		if catalog(stmt.From) {
+			return nil
+		}
+		return []requirement{{privilege: auth.Select, on: on(stmt.From), columns: selectedColumns(stmt)}}
+	}
+	var reqs []requirement
+	tables := []*parser.TableName{stmt.From}
+	for _, join := range stmt.Joins {
+		tables = append(tables, join.Table)
+	}
+	for _, from := range tables {
+		if !catalog(from) {
+			reqs = append(reqs, requirement{privilege: auth.Select, on: on(from)})
+		}
+	}
+	return reqs
+}
+
+// selectedColumns returns the columns a SELECT reads, or nil when it reads every column
+func selectedColumns(stmt *parser.SelectStmt) []string {
+	var columns []string
+	collect := func(e parser.Expr) {
+		parser.Walk(e, func(e parser.Expr) bool {
+			if ref, ok := e.(*parser.ColumnRef); ok {
+				columns = append(columns, ref.Name)
+			}
+			return true
+		})
+	}
+	for _, item := range stmt.Columns {
+		if item.Star {
+			return nil
+		}
+		collect(item.Expr)
+	}
+	collect(stmt.Where)
+	for _, e := range stmt.GroupBy {
+		collect(e)
+	}
+	collect(stmt.Having)
+	for _, item := range stmt.OrderBy {
+		collect(item.Expr)
+	}
+	return columns
+}
+
+// executeUserCommand runs CREATE USER, CREATE ROLE, ALTER USER, DROP USER and DROP ROLE.
+// Only the superuser manages users, though every user may change their own password.
+func executeUserCommand(buffer []byte) {
+	stmt, err := parser.ParseUser(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid user command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: CREATE USER name [WITH PASSWORD 'password'] | CREATE ROLE name | ALTER USER name PASSWORD 'password' | DROP USER | ROLE [IF EXISTS] name" + ansi.Reset)
+		return
+	}
+	if !strings.EqualFold(sessionUser, auth.Root) && !(stmt.Action == "ALTER" && strings.EqualFold(stmt.Name, sessionUser)) {
+		printError(ansi.BoldText+ansi.Red+"Permission denied:"+ansi.Reset, "only "+auth.Root+" can manage users and roles")
+		return
+	}
+	kind := "User"
+	if stmt.Role {
+		kind = "Role"
+	}
+	password := ""
+	if stmt.Password != nil {
+		password = *stmt.Password
+	}
+	skipped := false
+	err = updateCatalog(func(c *auth.Catalog) error {
+		switch stmt.Action {
+		case "CREATE":
+			return c.CreateUser(stmt.Name, password, stmt.Role)
+		case "ALTER":
+			return c.SetPassword(stmt.Name, password)
+		}
+		if u := c.FindUser(stmt.Name); stmt.IfExists && (u == nil || u.Role != stmt.Role) {
+			skipped = true
+			return nil
+		}
+		return c.DropUser(stmt.Name, stmt.Role)
+	})
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error updating the system catalog:"+ansi.Reset, err)
+		return
+	}
+	switch {
+	case skipped:
+		fmt.Println(ansi.RegText+ansi.Yellow+kind+" does not exist, skipping:"+ansi.Reset, stmt.Name)
+	case stmt.Action == "CREATE":
+		fmt.Println(ansi.RegText+ansi.Green+kind+" created:"+ansi.Reset, stmt.Name)
+	case stmt.Action == "ALTER":
+		fmt.Println(ansi.RegText+ansi.Green+"Password changed:"+ansi.Reset, stmt.Name)
+	default:
+		fmt.Println(ansi.RegText+ansi.Green+kind+" dropped:"+ansi.Reset, stmt.Name)
+	}
+}
+
+// executeGrantCommand runs GRANT and REVOKE. Privileges may be granted or revoked by the
+// superuser and by those holding them WITH GRANT OPTION; roles only by the superuser.
+func executeGrantCommand(buffer []byte) {
+	stmt, err := parser.ParseGrant(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid grant command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: GRANT privilege [(column, ...)], ... ON DATABASE name | *.* | db.* | [TABLE] [db.]table TO name, ... [WITH GRANT OPTION] | GRANT ROLE role TO name, ..." + ansi.Reset)
+		return
+	}
+	verb := "Granted"
+	if stmt.Revoke {
+		verb = "Revoked"
+	}
+	if stmt.Role != "" {
+		if !strings.EqualFold(sessionUser, auth.Root) {
+			printError(ansi.BoldText+ansi.Red+"Permission denied:"+ansi.Reset, "only "+auth.Root+" can grant and revoke roles")
+			return
+		}
+		err := updateCatalog(func(c *auth.Catalog) error {
+			for _, grantee := range stmt.Grantees {
+				var err error
+				if stmt.Revoke {
+					err = c.RevokeRole(stmt.Role, grantee)
+				} else {
+					err = c.GrantRole(stmt.Role, grantee)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			printError(ansi.BoldText+ansi.Red+"Error updating the system catalog:"+ansi.Reset, err)
+			return
+		}
+		fmt.Println(ansi.RegText+ansi.Green+verb+" role "+stmt.Role+":"+ansi.Reset, strings.Join(stmt.Grantees, ", "))
+		return
+	}
+
+	grants, err := grantsOf(stmt)
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid grant command:"+ansi.Reset, err)
+		return
+	}
+	removed, denied := 0, ""
+	err = updateCatalog(func(c *auth.Catalog) error {
+		for _, g := range grants {
+			if !c.CanGrant(sessionUser, g.Privilege, g.On) {
+				denied = fmt.Sprintf("user %s cannot grant %s on %s", sessionUser, g.Privilege, g.On)
+				return errPermission
+			}
+		}
+		for _, grantee := range stmt.Grantees {
+			for _, g := range grants {
+				if stmt.Revoke {
+					removed += c.RevokePrivilege(grantee, g.Privilege, g.On)
+					continue
+				}
+				g.Grantee = grantee
+				if err := c.GrantPrivilege(g); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errPermission) {
+		printError(ansi.BoldText+ansi.Red+"Permission denied:"+ansi.Reset, denied)
+		return
+	}
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error updating the system catalog:"+ansi.Reset, err)
+		return
+	}
+	if stmt.Revoke && removed == 0 {
+		fmt.Println(ansi.RegText+ansi.Yellow+"No matching privileges were granted to:"+ansi.Reset, strings.Join(stmt.Grantees, ", "))
+		return
+	}
+	fmt.Println(ansi.RegText+ansi.Green+verb+":"+ansi.Reset, describeGrants(grants), "for", strings.Join(stmt.Grantees, ", "))
+}
+
+// errPermission stops a catalog update the session user is not allowed to make
+var errPermission = errors.New("permission denied")
+
+// grantsOf returns the grants of a GRANT or REVOKE statement, one per privilege and column
+func grantsOf(stmt *parser.GrantStmt) ([]auth.Grant, error) {
+	on := auth.Object{Database: stmt.Database, Table: stmt.Table}
+	if on.Database == "" {
+		on.Database = currentDatabase()
+	}
+	var grants []auth.Grant
+	for _, priv := range stmt.Privileges {
+		p, err := auth.ParsePrivilege(priv.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(priv.Columns) == 0 {
+			grants = append(grants, auth.Grant{Privilege: p, On: on, GrantOption: stmt.GrantOption})
+			continue
+		}
+		if on.Table == "" {
+			return nil, fmt.Errorf("column privileges need a table")
+		}
+		for _, column := range priv.Columns {
+			colOn := on
+			colOn.Column = column
+			grants = append(grants, auth.Grant{Privilege: p, On: colOn, GrantOption: stmt.GrantOption})
+		}
+	}
+	return grants, nil
+}
+
+// describeGrants formats grants as "SELECT ON shop.items, UPDATE ON shop.items(price)"
+func describeGrants(grants []auth.Grant) string {
+	parts := make([]string, len(grants))
+	for i, g := range grants {
+		parts[i] = string(g.Privilege) + " ON " + g.On.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// executeShowGrantsCommand lists the roles and privileges of a user. Users may list their
+// own; the superuser may list anyone's.
+func executeShowGrantsCommand(buffer []byte) {
+	stmt, err := parser.ParseShowGrants(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid show grants command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: SHOW GRANTS [FOR name]" + ansi.Reset)
+		return
+	}
+	name := stmt.User
+	if name == "" {
+		name = sessionUser
+	}
+	if !strings.EqualFold(name, sessionUser) && !strings.EqualFold(sessionUser, auth.Root) {
+		printError(ansi.BoldText+ansi.Red+"Permission denied:"+ansi.Reset, "only "+auth.Root+" can list the grants of other users")
+		return
+	}
+	c, err := loadCatalog()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading the system catalog:"+ansi.Reset, err)
+		return
+	}
+	u := c.FindUser(name)
+	if u == nil {
+		printError(ansi.BoldText+ansi.Red+"User or role not found:"+ansi.Reset, name)
+		return
+	}
+	fmt.Println(ansi.RegText + ansi.Green + "Grants for " + u.Name + ":" + ansi.Reset)
+	if strings.EqualFold(u.Name, auth.Root) {
+		fmt.Println("  " + auth.Grant{Grantee: u.Name, Privilege: auth.All, On: auth.Object{Database: "*"}, GrantOption: true}.String())
+	}
+	for _, role := range u.Roles {
+		fmt.Println("  GRANT ROLE " + role + " TO " + u.Name)
+	}
+	for _, g := range c.GrantsOf(u.Name) {
+		fmt.Println("  " + g.String())
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/ansi"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// interactive is set when statements are typed at the prompt, rather than run from -e, -f
+// or standard input that is not a terminal
+var interactive = true
+
+// statementFailed is set when the running statement prints an error
+var statementFailed bool
+
+// printError prints an error to standard error and marks the running statement as failed
+func printError(a ...interface{}) {
+	statementFailed = true
+	fmt.Fprintln(os.Stderr, a...)
+}
+
+// readScript returns the statements to run in batch mode: those given with -e, those of the
+// file given with -f, or else standard input
+func readScript(statements, file string) (string, error) {
+	switch {
+	case statements != "" && file != "":
+		return "", fmt.Errorf("-e and -f cannot both be given")
+	case statements != "":
+		return statements, nil
+	case file != "":
+		script, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("error reading script: %v", err)
+		}
+		return string(script), nil
+	}
+	script, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", fmt.Errorf("error reading standard input: %v", err)
+	}
+	return string(script), nil
+}
+
+// runBatch runs the statements of a script one after another, as if typed at the prompt, and
+// returns the exit status: 0 when every statement succeeded, 1 otherwise. With bail it
+// stops at the first statement that fails; EXIT stops it too.
+func runBatch(script string, bail bool) int {
+	status := 0
+	for _, statement := range parser.SplitScript(script) {
+		statementFailed = false
+		inputBuffer := &types.InputBuffer{Buffer: []byte(statement)}
+		command, err := parser.ParseCommand(inputBuffer)
+		if err != nil {
+			printError(ansi.BoldText+ansi.Red+"Error parsing command:"+ansi.Reset, err)
+		} else if c, ok := command.(types.CoreCommand); ok && c.Command() == types.CmdExit {
+			break
+		} else {
+			executeCommand(command, inputBuffer)
+		}
+		if statementFailed {
+			status = 1
+			if bail {
+				break
+			}
+		}
+	}
+	if tx != nil {
+		fmt.Fprintln(os.Stderr, ansi.RegText+ansi.Yellow+"The open transaction was rolled back"+ansi.Reset)
+		tx.Rollback()
+		tx = nil
+	}
+	return status
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/auth"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// Test that a script defining a procedure and a trigger over several lines creates them
+// without running the statements of their bodies
+func TestRunBatchRoutines(t *testing.T) {
+	interactive = false
+	config = types.NewConfig(t.TempDir(), false, "test.db")
+	if err := types.NewDatabase().WriteToFile(config.GetDBFilePath()); err != nil {
+		t.Fatal(err)
+	}
+	script := `create table log (msg varchar)
+create table accounts (id int)
+
+create procedure note (msg varchar)
+begin
+  insert into log values (msg);
+end;
+
+create trigger audit after insert on accounts
+for each row
+begin
+  insert into log values ('inserted');
+end;
+`
+	if status := runBatch(script, true); status != 0 {
+		t.Fatalf("the script failed with status %d", status)
+	}
+	db := types.NewDatabase()
+	if err := db.ReadFromFile(config.GetDBFilePath()); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Procedures) != 1 || len(db.Triggers) != 1 {
+		t.Errorf("the script created %d procedures and %d triggers, want 1 and 1", len(db.Procedures), len(db.Triggers))
+	}
+	for _, table := range db.Tables {
+		if table.GetName() == "log" && len(table.Rows) != 0 {
+			t.Errorf("the script inserted %d rows into log, want none", len(table.Rows))
+		}
+	}
+}
+
+// Test that CREATE DATABASE and USE name the database the same way, with or without a
+// semicolon, so that a script goes on in the database it created
+func TestCreateAndUseDatabase(t *testing.T) {
+	interactive = false
+	config = types.NewConfig(t.TempDir(), false, "test.db")
+	if err := types.NewDatabase().WriteToFile(config.GetDBFilePath()); err != nil {
+		t.Fatal(err)
+	}
+	if status := runBatch("create database other;\nuse other;\ncreate table t (id int);", true); status != 0 {
+		t.Fatalf("the script failed with status %d", status)
+	}
+	if config.DBFileName != "other.db" {
+		t.Errorf("the script went on in %s, want other.db", config.DBFileName)
+	}
+	db := types.NewDatabase()
+	if err := db.ReadFromFile(config.HomeDir + "/other.db"); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Tables) != 1 {
+		t.Errorf("other.db has %d tables, want 1", len(db.Tables))
+	}
+	if db.Metadata[types.MetadataOwner] != sessionUser || db.Metadata[types.MetadataCreated] == "" {
+		t.Errorf("other.db was created with metadata %v", db.Metadata)
+	}
+	for _, name := range []string{"", "a b", "../other", "'x'"} {
+		if _, err := databaseName(name); err == nil {
+			t.Errorf("databaseName(%q) accepted an invalid name", name)
+		}
+	}
+}
+
+// Test that a user cannot create a trigger, which runs for whoever changes its table, whose
+// body deletes from a table the user may not delete from, directly or through a procedure
+func TestCreateTriggerChecksBody(t *testing.T) {
+	interactive = false
+	config = types.NewConfig(t.TempDir(), false, "test.db")
+	if err := types.NewDatabase().WriteToFile(config.GetDBFilePath()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { sessionUser = auth.Root }()
+	setup := `create table a (id int);
+create table secret (id int);
+insert into secret values (1);
+create procedure wipe () begin delete from secret; end;
+create user bob;
+grant alter, insert on a to bob;
+grant procedure on database test to bob;`
+	if status := runBatch(setup, true); status != 0 {
+		t.Fatalf("the setup failed with status %d", status)
+	}
+	sessionUser = "bob"
+	for _, body := range []string{"delete from secret;", "call wipe();"} {
+		script := "create trigger t after insert on a for each row begin " + body + " end;"
+		if status := runBatch(script, true); status == 0 {
+			t.Errorf("bob created a trigger running %s without DELETE on secret", body)
+		}
+	}
+	if status := runBatch("insert into a values (1);", true); status != 0 {
+		t.Fatalf("bob's insert failed with status %d", status)
+	}
+	db := types.NewDatabase()
+	if err := db.ReadFromFile(config.GetDBFilePath()); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Triggers) != 0 {
+		t.Errorf("the database has %d triggers, want none", len(db.Triggers))
+	}
+	for _, table := range db.Tables {
+		if table.GetName() == "secret" && len(table.Rows) != 1 {
+			t.Errorf("secret has %d rows, want 1", len(table.Rows))
+		}
+	}
+}
+
+// Test that a script drops a database without asking for confirmation, and goes on with the
+// statements after
+func TestDropDatabaseInBatch(t *testing.T) {
+	interactive = false
+	config = types.NewConfig(t.TempDir(), false, "test.db")
+	if err := types.NewDatabase().WriteToFile(config.GetDBFilePath()); err != nil {
+		t.Fatal(err)
+	}
+	if status := runBatch("create database other;\ndrop database other;\ncreate table t (id int);", true); status != 0 {
+		t.Fatalf("the script failed with status %d", status)
+	}
+	if _, err := os.Stat(config.HomeDir + "/other.db"); !os.IsNotExist(err) {
+		t.Errorf("other.db remains after DROP DATABASE: %v", err)
+	}
+	db := types.NewDatabase()
+	if err := db.ReadFromFile(config.GetDBFilePath()); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Tables) != 1 {
+		t.Errorf("test.db has %d tables, want 1", len(db.Tables))
+	}
+}
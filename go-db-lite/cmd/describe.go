@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/ansi"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/engine"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// executeShowCommand runs SHOW TABLES, DESCRIBE, SHOW CREATE TABLE and SHOW INDEXES, which
+// describe the tables of the database in use
+func executeShowCommand(buffer []byte) {
+	stmt, err := parser.ParseShow(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid show command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: SHOW TABLES, DESCRIBE table_name, SHOW CREATE TABLE table_name or SHOW INDEXES FROM table_name" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during show:"+ansi.Reset, err)
+		return
+	}
+	if stmt.Object == "TABLES" {
+		result := &types.ResultSet{Columns: []string{"table", "type", "columns", "rows", "comment"}}
+		for _, t := range db.Tables {
+			kind := "BASE TABLE"
+			if db.FindView(t.GetName()) != nil {
+				kind = "MATERIALIZED VIEW"
+			}
+			result.Rows = append(result.Rows, []interface{}{t.GetName(), kind, int64(len(t.Columns)), int64(len(t.Rows)), t.Metadata[types.MetadataComment]})
+		}
+		result.Print()
+		return
+	}
+	t := engine.FindTable(db, stmt.Table)
+	if t == nil {
+		if v := db.FindView(stmt.Table); v != nil && stmt.Object == "CREATE TABLE" {
+			result := &types.ResultSet{Columns: []string{"table", "create statement"}, Rows: [][]interface{}{{v.Name, v.String() + ";"}}}
+			result.Print()
+			return
+		}
+		printError(ansi.BoldText+ansi.Red+"Table not found:"+ansi.Reset, stmt.Table)
+		return
+	}
+	switch stmt.Object {
+	case "DESCRIBE":
+		fmt.Println(ansi.RegText+ansi.Green+"Table:"+ansi.Reset, t.GetName())
+		t.PrintTableMetadata()
+		for _, tr := range db.TableTriggers(t.GetName()) {
+			fmt.Printf("TRIGGER %s %s %s\n", tr.Name, tr.Timing, tr.Event)
+		}
+	case "CREATE TABLE":
+		ddl := t.CreateTableStatement()
+		// a materialized view is created from its query, not from its columns
+		if v := db.FindView(t.GetName()); v != nil {
+			ddl = v.String()
+		}
+		result := &types.ResultSet{Columns: []string{"table", "create statement"}, Rows: [][]interface{}{{t.GetName(), ddl + ";"}}}
+		result.Print()
+	case "INDEXES":
+		result := &types.ResultSet{Columns: []string{"index", "table", "column", "position", "unique", "primary"}}
+		for i, column := range t.PrimaryKey {
+			result.Rows = append(result.Rows, []interface{}{t.PrimaryKeyIndexName(), t.GetName(), t.Columns[column].GetName(), int64(i + 1), "YES", "YES"})
+		}
+		result.Print()
+	default:
+		printError(ansi.BoldText+ansi.Red+"Unrecognized show command:"+ansi.Reset, strings.ToLower(stmt.Object))
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test that the DDL SHOW CREATE TABLE prints for a table creates the same table again
+func TestCreateTableStatement(t *testing.T) {
+	body := "id INT GENERATED ALWAYS AS IDENTITY, account VARCHAR(20) NOT NULL DEFAULT 'x', " +
+		"kind ENUM('a','b''c'), amount DECIMAL(10,2) CHECK (amount > 0), " +
+		"PRIMARY KEY (id), CONSTRAINT fk_owner FOREIGN KEY (account) REFERENCES owners (name) ON DELETE CASCADE"
+	build := func(body string) string {
+		table, err := parseTableDefinition("ledger", body)
+		if err != nil {
+			t.Fatalf("parse %q: %v", body, err)
+		}
+		return table.CreateTableStatement()
+	}
+	ddl := build(body)
+	want := "CREATE TABLE ledger (id INT NOT NULL GENERATED ALWAYS AS IDENTITY, account VARCHAR(20) NOT NULL DEFAULT 'x', " +
+		"kind ENUM('a','b''c'), amount DECIMAL(10,2), PRIMARY KEY (id), " +
+		"CONSTRAINT fk_owner FOREIGN KEY (account) REFERENCES owners (name) ON DELETE CASCADE, CONSTRAINT chk_ledger_1 CHECK (amount > 0))"
+	if ddl != want {
+		t.Fatalf("got  %s\nwant %s", ddl, want)
+	}
+	again := build(strings.TrimSuffix(strings.TrimPrefix(ddl, "CREATE TABLE ledger ("), ")"))
+	if again != ddl {
+		t.Errorf("the regenerated DDL creates a different table:\n%s\n%s", again, ddl)
+	}
+}
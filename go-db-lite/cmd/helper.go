@@ -13,7 +13,21 @@ func printHelp() {
 	fmt.Println(ansi.RegBg + ansi.Blue + ansi.BoldText + ansi.Magenta + "Commands:" + ansi.Reset)
 
 	// select
-	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "select <column_name / *> from <table_name>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "select <column_name / *> from [<database>.]<table_name> [[inner | left | right | cross] join [<database>.]<table_name> on condition]" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   └── " + ansi.BoldText + ansi.White + "information_schema.<tables | columns | indexes | views | constraints | routines | triggers> describe the database" + ansi.Reset)
+
+	// databases
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "Database commands" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "show databases" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "show tables" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "describe <table_name>  (columns, nullability, defaults, keys and indexes)" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "show create table <table_name>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "show indexes from <table_name>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "comment on <table <table_name> | column <table_name>.<column_name>> is '<text>' | null" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "pragma [[<table_name>.]<key> [= <value> | null]]  (settings kept with the database)" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "use <database_name>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "attach [database] '<file.db>' as <name>  (read-only; query its tables as <name>.<table_name>)" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   └── " + ansi.BoldText + ansi.White + "detach [database] <name>" + ansi.Reset)
 
 	// insert
 	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "insert into <table_name> (column_name, ...) values (value, ...)" + ansi.Reset)
@@ -24,28 +38,84 @@ func printHelp() {
 	// delete
 	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "delete from <table_name> where condition" + ansi.Reset)
 
+	// import
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "import into <table_name> from '<file.csv>'" + ansi.Reset)
+
+	// views
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "show views" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "refresh materialized view <view_name>" + ansi.Reset)
+
+	// procedures
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "Procedure commands" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "call <procedure_name>([arg, ...])" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "show procedures" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   └── " + ansi.BoldText + ansi.White + "body statements: declare <name> <type> [default expr] | set <name> = expr | select ... [into <name>, ...] | insert | update | delete | call | return [expr]" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │       " + ansi.BoldText + ansi.White + "if cond then ... [elseif cond then ...] [else ...] end if | while cond do ... end while | for <row> in (select ...) do ... end for" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │       " + ansi.BoldText + ansi.White + "signal sqlstate '<state>' [set message_text = expr]" + ansi.Reset)
+
+	// triggers
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "Trigger commands" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "show triggers" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   └── " + ansi.BoldText + ansi.White + "trigger bodies read old.<column_name> and new.<column_name>; before insert and before update triggers may set new.<column_name> = expr" + ansi.Reset)
+
+	// truncate
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "truncate table <table_name>" + ansi.Reset)
+
+	// transactions
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "Transaction commands" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "begin | start transaction [isolation level read committed | repeatable read | snapshot]" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "commit" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "rollback [to savepoint <savepoint_name>]" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "savepoint <savepoint_name>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   └── " + ansi.BoldText + ansi.White + "release savepoint <savepoint_name>" + ansi.Reset)
+
+	// users and privileges
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "User commands" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "create user <user_name> [with password '<password>'] | create role <role_name>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "alter user <user_name> [with] password '<password>'" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "drop user | role [if exists] <name>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "grant <privilege> [(column_name, ...)], ... | all on database <db> | *.* | <db>.* | [table] <table_name> to <name>, ... [with grant option]" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "revoke <privilege> [(column_name, ...)], ... | all on ... from <name>, ..." + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "grant role <role_name> to <name>, ... | revoke role <role_name> from <name>, ..." + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   └── " + ansi.BoldText + ansi.White + "show grants [for <name>]" + ansi.Reset)
+
+	// locks
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "Lock commands" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "lock table <table_name>, ... [in share | exclusive mode]" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "lock database [<database_name>] [in share | exclusive mode]" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   └── " + ansi.BoldText + ansi.White + "unlock tables" + ansi.Reset)
+
 	// create
 	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "CREATE commands" + ansi.Reset)
 	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "create database <database_name>" + ansi.Reset)
 	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "create table <table_name> (column_name column_type, ...)" + ansi.Reset)
 	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "create index <index_name> on <table_name> (column_name, ...)" + ansi.Reset)
 	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "create unique index <index_name> on <table_name> (column_name, ...)" + ansi.Reset)
-	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "create view <view_name> as select <select_statement>" + ansi.Reset)
-	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   └── " + ansi.BoldText + ansi.White + "create procedure <procedure_name> (param_name param_type, ...)" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "create [or replace] view <view_name> [(column_name, ...)] as select <select_statement>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "create materialized view <view_name> [(column_name, ...)] as select <select_statement>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "create [or replace] procedure <procedure_name> (param_name param_type, ...) begin <statement>; ... end" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "create sequence <sequence_name> [start with n] [increment by n]" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   └── " + ansi.BoldText + ansi.White + "create trigger <trigger_name> before | after insert | update | delete on <table_name> for each row begin <statement>; ... end" + ansi.Reset)
 
 	// drop
 	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "DROP commands" + ansi.Reset)
-	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "drop database <database_name>" + ansi.Reset)
-	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "drop table <table_name>" + ansi.Reset)
-	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "drop index <index_name>" + ansi.Reset)
-	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ── " + ansi.BoldText + ansi.White + "drop view <view_name>" + ansi.Reset)
-	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   └── " + ansi.BoldText + ansi.White + "drop procedure <procedure_name>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "drop database [if exists] <database_name>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "drop table [if exists] <table_name> [cascade | restrict]" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "drop index [if exists] <index_name> [on <table_name>]" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "drop view [if exists] <view_name> [cascade | restrict]" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "drop materialized view [if exists] <view_name> [cascade | restrict]" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "drop procedure [if exists] <procedure_name>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "drop sequence [if exists] <sequence_name>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   └── " + ansi.BoldText + ansi.White + "drop trigger [if exists] <trigger_name>" + ansi.Reset)
 
 	// alter
 	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "ALTER commands" + ansi.Reset)
 	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "alter table <table_name> add column <column_name> <column_type>" + ansi.Reset)
 	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "alter table <table_name> drop column <column_name>" + ansi.Reset)
-	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   └── " + ansi.BoldText + ansi.White + "alter table <table_name> rename column <old_column_name> <new_column_name>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "alter table <table_name> rename column <old_column_name> to <new_column_name>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "alter table <table_name> rename to <new_table_name>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   ├── " + ansi.BoldText + ansi.White + "alter table <table_name> alter column <column_name> type <column_type>" + ansi.Reset)
+	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  │   └── " + ansi.BoldText + ansi.White + "alter procedure <procedure_name> rename to <new_procedure_name>" + ansi.Reset)
 
 	// grant
 	fmt.Println(ansi.RegBg + ansi.Black + ansi.BoldText + ansi.Yellow + "  ├── " + ansi.BoldText + ansi.White + "GRANT commands" + ansi.Reset)
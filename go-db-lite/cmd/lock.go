@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/ansi"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/lock"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+)
+
+// executeLockCommand locks tables or the database in use. Inside a transaction the locks
+// are held until it ends, otherwise until UNLOCK TABLES.
+func executeLockCommand(buffer []byte) {
+	stmt, err := parser.ParseLock(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid lock command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: LOCK TABLE name [, ...] | LOCK DATABASE [name] [IN SHARE | EXCLUSIVE MODE]" + ansi.Reset)
+		return
+	}
+	mode, err := lock.ParseMode(stmt.Mode)
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid lock command:"+ansi.Reset, err)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during lock:"+ansi.Reset, err)
+		return
+	}
+	resources := []lock.Resource{lock.Database}
+	if stmt.Database {
+		if stmt.Name != "" && !strings.EqualFold(stmt.Name+".db", config.DBFileName) {
+			printError(ansi.BoldText+ansi.Red+"Error locking database:"+ansi.Reset, "only the database in use can be locked: "+stmt.Name)
+			return
+		}
+	} else {
+		resources = resources[:0]
+		for _, name := range stmt.Tables {
+			if db.FindTable(name) == nil {
+				printError(ansi.BoldText+ansi.Red+"Error locking table:"+ansi.Reset, "table not found: "+name)
+				return
+			}
+			resources = append(resources, lock.Table(name))
+		}
+	}
+	for _, res := range resources {
+		if tx != nil {
+			err = tx.Lock(res, mode)
+		} else {
+			err = store.Locks().Lock(session, 0, res, mode, store.LockTimeout)
+		}
+		if err != nil {
+			printError(ansi.BoldText+ansi.Red+"Error acquiring lock:"+ansi.Reset, err)
+			return
+		}
+	}
+	until := "until UNLOCK TABLES"
+	if tx != nil {
+		until = "until the transaction ends"
+	}
+	if stmt.Database {
+		fmt.Println(ansi.RegText+ansi.Green+"Database locked in "+mode.String()+" mode"+ansi.Reset, "("+until+")")
+	} else {
+		fmt.Println(ansi.RegText+ansi.Green+"Locked in "+mode.String()+" mode:"+ansi.Reset, strings.Join(stmt.Tables, ", "), "("+until+")")
+	}
+}
+
+// executeUnlockCommand releases the locks the session took outside of transactions
+func executeUnlockCommand(buffer []byte) {
+	if _, err := parser.ParseLock(string(buffer)); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid unlock command:"+ansi.Reset, err)
+		return
+	}
+	if store != nil {
+		store.Locks().Unlock(session, 0)
+	}
+	fmt.Println(ansi.RegText + ansi.Green + "Session locks released" + ansi.Reset)
+	if tx != nil {
+		fmt.Println(ansi.RegText + ansi.Yellow + "Locks taken in the open transaction are held until it ends" + ansi.Reset)
+	}
+}
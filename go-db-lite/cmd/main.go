@@ -5,10 +5,13 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/chaitanyasharma/DBs/go-db-lite/internal/ansi"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/auth"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/engine"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/lock"
 	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
 	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
 	"golang.org/x/term"
@@ -23,6 +26,9 @@ const DefaultHomeDirName = "data"
 const HistoryFileName = ".history"
 const cursorOffset = 6
 
+// stdin is shared by the prompt and by commands asking for confirmation
+var stdin = bufio.NewReader(os.Stdin)
+
 // create select insert delete update alter
 
 func main() {
@@ -45,12 +51,19 @@ func main() {
 
 	inMemoryFlag := flag.Bool("in-memory", false, "use in-memory database")
 	operatingDirFlag := flag.String("dir", "./"+DefaultHomeDirName, "the directory where the database files will be stored")
+	strictFlag := flag.Bool("strict", true, "reject values that do not fit their column; with -strict=false over-length strings are truncated")
+	userFlag := flag.String("user", auth.Root, "the user to log in as; the password is prompted for or taken from $"+PasswordEnv)
+	busyTimeoutFlag := flag.Duration("busy-timeout", 5*time.Second, "how long to wait for a database locked by another process, e.g. 500ms or 10s; 0 fails at once")
+	timeZoneFlag := flag.String("tz", "", "the session time zone used for TIMESTAMP values, e.g. UTC, Europe/Berlin or +05:30 (default local)")
+	execFlag := flag.String("e", "", "run the given statements and exit, e.g. -e \"select * from t\"")
+	scriptFlag := flag.String("f", "", "run the statements of a script file and exit")
+	bailFlag := flag.Bool("bail", false, "in batch mode, stop at the first statement that fails")
 
 	flag.Parse()
 
 	// confirm that inMemoryFlag and operatingDirFlag are not both set
 	if *inMemoryFlag && *operatingDirFlag != DefaultHomeDirName {
-		fmt.Println(ansi.BoldText + ansi.Red + "Error: both in-memory and operating directory flags cannot be set" + ansi.Reset)
+		printError(ansi.BoldText + ansi.Red + "Error: both in-memory and operating directory flags cannot be set" + ansi.Reset)
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
@@ -68,21 +81,38 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *timeZoneFlag != "" {
+		loc, err := types.LoadLocation(*timeZoneFlag)
+		if err != nil {
+			printError(ansi.BoldText+ansi.Red+"Error: invalid time zone:"+ansi.Reset, err)
+			os.Exit(1)
+		}
+		types.SetSessionLocation(loc)
+	}
+
 	// set config values
 	config = types.NewConfig(*operatingDirFlag, *inMemoryFlag, dbFileName)
-	fmt.Println(ansi.BoldHighIntensityText + ansi.Green + "Mini SQL DB starting...\n" + ansi.Reset)
-	fmt.Println(ansi.RegText + ansi.Magenta + "Type 'exit' to quit" + ansi.Reset)
+	config.StrictMode = *strictFlag
+	config.BusyTimeout = *busyTimeoutFlag
+	// statements from -e, -f or a pipe run in batch mode, without the prompt and its messages
+	interactive = *execFlag == "" && *scriptFlag == "" && term.IsTerminal(int(os.Stdin.Fd()))
+	if interactive {
+		fmt.Println(ansi.BoldHighIntensityText + ansi.Green + "Mini SQL DB starting...\n" + ansi.Reset)
+		fmt.Println(ansi.RegText + ansi.Magenta + "Type 'exit' to quit" + ansi.Reset)
+	}
 	if *inMemoryFlag {
 		config.InMemory = true
 		config.DBFileName = InMemoryDBName + ".db"
-		fmt.Println("Using in-memory database")
+		if interactive {
+			fmt.Println("Using in-memory database")
+		}
 	} else {
 		if *operatingDirFlag != "./"+DefaultHomeDirName {
 			config.HomeDir = *operatingDirFlag
 		} else {
 			currentDir, err := os.Getwd()
 			if err != nil {
-				fmt.Println(ansi.BoldText+ansi.Red+"Error getting current directory:"+ansi.Reset, err)
+				printError(ansi.BoldText+ansi.Red+"Error getting current directory:"+ansi.Reset, err)
 				os.Exit(1)
 			}
 			config.HomeDir = currentDir + "/" + DefaultHomeDirName
@@ -90,27 +120,51 @@ func main() {
 		// if the folder does not exist, create it
 		if _, err := os.Stat(config.HomeDir); os.IsNotExist(err) {
 			if err := os.MkdirAll(config.HomeDir, 0755); err != nil {
-				fmt.Println(ansi.BoldText+ansi.Red+"Error creating directory:"+ansi.Reset, err)
+				printError(ansi.BoldText+ansi.Red+"Error creating directory:"+ansi.Reset, err)
 				os.Exit(1)
 			}
 		}
-		fmt.Printf("The directory: %s has been assumed as the operating directory\n", config.HomeDir)
-		if _, err := os.Stat(config.GetDBFilePath()); os.IsNotExist(err) {
-			newDB := types.NewDatabase()
-			if err := newDB.WriteToFile(config.GetDBFilePath()); err != nil {
-				fmt.Printf("Error initializing database file: %v\n", err)
-				os.Exit(1)
-			}
+		if interactive {
+			fmt.Printf("The directory: %s has been assumed as the operating directory\n", config.HomeDir)
+		}
+	}
+
+	if !config.InMemory {
+		if err := initCatalog(); err != nil {
+			printError(ansi.BoldText+ansi.Red+"Error creating the system catalog:"+ansi.Reset, err)
+			os.Exit(1)
+		}
+	}
+	if err := login(*userFlag); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error logging in:"+ansi.Reset, err)
+		os.Exit(1)
+	}
+	// the database is created once the session user, who owns it, is known
+	if _, err := os.Stat(config.GetDBFilePath()); !config.InMemory && os.IsNotExist(err) {
+		if err := createDatabaseFile(config.GetDBFilePath()); err != nil {
+			printError("Error initializing database file:", err)
+			os.Exit(1)
+		}
+		if interactive {
 			fmt.Printf("%s file has been created and initialized\n", config.DBFileName)
 		}
 	}
 
+	if !interactive {
+		script, err := readScript(*execFlag, *scriptFlag)
+		if err != nil {
+			printError(ansi.BoldText+ansi.Red+"Error:"+ansi.Reset, err)
+			os.Exit(1)
+		}
+		os.Exit(runBatch(script, *bailFlag))
+	}
+
 	// Create or open .history file
 	historyFile := config.HomeDir + "/" + HistoryFileName
 	if _, err := os.Stat(historyFile); os.IsNotExist(err) {
 		_, err = os.Create(historyFile)
 		if err != nil {
-			fmt.Println(ansi.BoldText+ansi.Red+"Error creating history file:"+ansi.Reset, err)
+			printError(ansi.BoldText+ansi.Red+"Error creating history file:"+ansi.Reset, err)
 			os.Exit(1)
 		}
 	}
@@ -118,7 +172,7 @@ func main() {
 	// Read history file into memory
 	historyBytes, err := os.ReadFile(historyFile)
 	if err != nil {
-		fmt.Println(ansi.BoldText+ansi.Red+"Error reading history file:"+ansi.Reset, err)
+		printError(ansi.BoldText+ansi.Red+"Error reading history file:"+ansi.Reset, err)
 		os.Exit(1)
 	}
 	history := strings.Split(string(historyBytes), "\n")
@@ -127,11 +181,11 @@ func main() {
 	// Open the history file for appending
 	historyFileHandle, err := os.OpenFile(historyFile, os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
-		fmt.Println(ansi.BoldText+ansi.Red+"Error opening history file:"+ansi.Reset, err)
+		printError(ansi.BoldText+ansi.Red+"Error opening history file:"+ansi.Reset, err)
 		os.Exit(1)
 	}
 	defer historyFileHandle.Close()
-	reader := bufio.NewReader(os.Stdin)
+	reader := stdin
 	for {
 		fmt.Print(ansi.RegText + ansi.Cyan + "db" + ansi.Reset + ansi.BoldHighIntensityText + ansi.Yellow + " > " + ansi.Reset)
 
@@ -237,7 +291,7 @@ func main() {
 		// Add the command to history file and memory only if it's not empty
 		if input != "" {
 			if _, err := historyFileHandle.WriteString(input + "\n"); err != nil {
-				fmt.Println(ansi.BoldText+ansi.Red+"Error writing to history file:"+ansi.Reset, err)
+				printError(ansi.BoldText+ansi.Red+"Error writing to history file:"+ansi.Reset, err)
 			}
 			history = append(history, input)
 			historyIndex = len(history)
@@ -247,13 +301,13 @@ func main() {
 
 			command, err := parser.ParseCommand(inputBuffer)
 			if err != nil {
-				fmt.Println(ansi.BoldText+ansi.Red+"Error parsing command:"+ansi.Reset, err)
+				printError(ansi.BoldText+ansi.Red+"Error parsing command:"+ansi.Reset, err)
 				continue
 			}
 
 			executeCommand(command, inputBuffer)
 		} else {
-			fmt.Println(ansi.BoldText + ansi.Red + "Please enter a valid command" + ansi.Reset)
+			printError(ansi.BoldText + ansi.Red + "Please enter a valid command" + ansi.Reset)
 			continue
 		}
 	}
@@ -261,6 +315,9 @@ func main() {
 
 func Exit(exitCode int) {
 	fmt.Print("\r\n")
+	if tx != nil {
+		fmt.Print(ansi.RegText + ansi.Yellow + "The open transaction was rolled back" + ansi.Reset + "\r\n")
+	}
 	if exitCode == 0 {
 		fmt.Println(ansi.BoldHighIntensityText + ansi.Green + "Until Next Time 👋" + ansi.Reset)
 	} else {
@@ -273,92 +330,119 @@ func Exit(exitCode int) {
 
 // Simple execution function
 func executeCommand(command types.CommandType, inputBuffer *types.InputBuffer) {
+	defer finishStatement()
+	if err := authorize(command, inputBuffer.Buffer); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Permission denied:"+ansi.Reset, err)
+		return
+	}
 	var tlc string
 	switch cmd := command.(type) {
 	case types.CreateCommand:
 		tlc = "Create"
 		switch cmd.Command() {
 		case types.CmdCreateDatabase:
-			dbName := strings.TrimSpace(string(inputBuffer.Buffer[len("create database"):]))
-			if _, err := os.Stat(config.HomeDir + "/" + dbName + ".db"); os.IsNotExist(err) && dbName != "" {
-				newDB := types.NewDatabase()
-				if err := newDB.WriteToFile(config.HomeDir + "/" + dbName + ".db"); err != nil {
-					fmt.Println(ansi.BoldText+ansi.Red+"Error creating database file:"+ansi.Reset, err)
+			dbName, err := databaseName(string(inputBuffer.Buffer[len("create database"):]))
+			if err != nil {
+				printError(ansi.BoldText+ansi.Red+"Invalid database name:"+ansi.Reset, err)
+				return
+			}
+			if _, err := os.Stat(config.HomeDir + "/" + dbName + ".db"); os.IsNotExist(err) {
+				if err := createDatabaseFile(config.HomeDir + "/" + dbName + ".db"); err != nil {
+					printError(ansi.BoldText+ansi.Red+"Error creating database file:"+ansi.Reset, err)
 					return
 				}
 				fmt.Println(ansi.RegText+ansi.Green+"Database file created successfully:"+ansi.Reset, dbName+".db")
-			} else if dbName == "" {
-				fmt.Println(ansi.BoldText + ansi.Red + "Database name cannot be empty" + ansi.Reset)
 			} else {
-				fmt.Println(ansi.BoldText+ansi.Red+"Database file already exists:"+ansi.Reset, dbName+".db")
+				printError(ansi.BoldText+ansi.Red+"Database file already exists:"+ansi.Reset, dbName+".db")
 			}
 		case types.CmdCreateTable:
 			tableName, columnsDef := parseTableCommand(inputBuffer.Buffer)
-			columns := parseColumns(columnsDef)
-			if columns != nil {
-				createTable(tableName, columns)
-			} else {
-				fmt.Println(ansi.BoldText + ansi.Red + "Error parsing columns for table" + ansi.Reset)
+			table, err := parseTableDefinition(tableName, columnsDef)
+			if err != nil {
+				printError(ansi.BoldText+ansi.Red+"Error parsing columns for table:"+ansi.Reset, err)
+				return
 			}
-			//
+			createTable(tableName, table)
 		case types.CmdCreateIndex:
 			notImplemented(tlc, cmd.CommandName())
 		case types.CmdCreateUniqueIndex:
 			notImplemented(tlc, cmd.CommandName())
-		case types.CmdCreateView:
-			notImplemented(tlc, cmd.CommandName())
-		case types.CmdCreateProcedure:
-			notImplemented(tlc, cmd.CommandName())
+		case types.CmdCreateView, types.CmdCreateOrReplaceView, types.CmdCreateMaterializedView:
+			executeCreateViewCommand(inputBuffer.Buffer)
+		case types.CmdCreateProcedure, types.CmdCreateOrReplaceProcedure:
+			executeCreateProcedureCommand(inputBuffer.Buffer)
+		case types.CmdCreateTrigger:
+			executeCreateTriggerCommand(inputBuffer.Buffer)
+		case types.CmdCreateSequence:
+			executeCreateSequenceCommand(inputBuffer.Buffer)
+		case types.CmdCreateUser, types.CmdCreateRole:
+			executeUserCommand(inputBuffer.Buffer)
 		case types.CmdCreateUnknown:
 			notImplemented(tlc, cmd.CommandName())
 		default:
-			fmt.Println(ansi.BoldText+ansi.Red+"Unknown Create Command:"+ansi.Reset, cmd.CommandName())
+			printError(ansi.BoldText+ansi.Red+"Unknown Create Command:"+ansi.Reset, cmd.CommandName())
 		}
 	case types.DropCommand:
 		tlc = "Drop"
 		switch cmd.Command() {
 		case types.CmdDropTable:
-			notImplemented(tlc, cmd.CommandName())
+			executeDropTableCommand(inputBuffer.Buffer)
 		case types.CmdDropIndex:
-			notImplemented(tlc, cmd.CommandName())
+			executeDropIndexCommand(inputBuffer.Buffer)
 		case types.CmdDropView:
-			notImplemented(tlc, cmd.CommandName())
+			executeDropViewCommand(inputBuffer.Buffer, "VIEW")
+		case types.CmdDropMaterializedView:
+			executeDropViewCommand(inputBuffer.Buffer, "MATERIALIZED VIEW")
 		case types.CmdDropProcedure:
-			notImplemented(tlc, cmd.CommandName())
+			executeDropProcedureCommand(inputBuffer.Buffer)
+		case types.CmdDropTrigger:
+			executeDropTriggerCommand(inputBuffer.Buffer)
+		case types.CmdDropSequence:
+			executeDropSequenceCommand(inputBuffer.Buffer)
+		case types.CmdDropDatabase:
+			executeDropDatabaseCommand(inputBuffer.Buffer)
+		case types.CmdDropUser, types.CmdDropRole:
+			executeUserCommand(inputBuffer.Buffer)
 		default:
-			fmt.Println(ansi.BoldText+ansi.Red+"Unknown Drop Command:"+ansi.Reset, cmd.CommandName())
+			printError(ansi.BoldText+ansi.Red+"Unknown Drop Command:"+ansi.Reset, cmd.CommandName())
 		}
 	case types.AlterCommand:
 		tlc = "Alter"
 		switch cmd.Command() {
 		case types.CmdAlterTable:
-			notImplemented(tlc, cmd.CommandName())
+			executeAlterTableCommand(inputBuffer.Buffer)
 		case types.CmdAlterIndex:
 			notImplemented(tlc, cmd.CommandName())
 		case types.CmdAlterView:
 			notImplemented(tlc, cmd.CommandName())
 		case types.CmdAlterProcedure:
-			notImplemented(tlc, cmd.CommandName())
+			executeAlterProcedureCommand(inputBuffer.Buffer)
+		case types.CmdAlterUser:
+			executeUserCommand(inputBuffer.Buffer)
 		default:
-			fmt.Println(ansi.BoldText+ansi.Red+"Unknown Alter Command:"+ansi.Reset, cmd.CommandName())
+			printError(ansi.BoldText+ansi.Red+"Unknown Alter Command:"+ansi.Reset, cmd.CommandName())
 		}
 	case types.GrantCommand:
 		tlc = "Grant"
 		switch cmd.Command() {
-		case types.CmdGrant:
-			notImplemented(tlc, cmd.CommandName())
-		case types.CmdRevoke:
-			notImplemented(tlc, cmd.CommandName())
+		case types.CmdGrantUnknown:
+			printError(ansi.BoldText+ansi.Red+"Unknown Grant Command:"+ansi.Reset, cmd.CommandName())
 		default:
-			fmt.Println(ansi.BoldText+ansi.Red+"Unknown Grant Command:"+ansi.Reset, cmd.CommandName())
+			executeGrantCommand(inputBuffer.Buffer)
+		}
+	case types.RevokeCommand:
+		tlc = "Revoke"
+		switch cmd.Command() {
+		case types.CmdRevokeUnknown:
+			printError(ansi.BoldText+ansi.Red+"Unknown Revoke Command:"+ansi.Reset, cmd.CommandName())
+		default:
+			executeGrantCommand(inputBuffer.Buffer)
 		}
 	case types.LockCommand:
 		tlc = "Lock"
 		switch cmd.Command() {
-		case types.CmdLockDatabase:
-			notImplemented(tlc, cmd.CommandName())
-		case types.CmdLockTable:
-			notImplemented(tlc, cmd.CommandName())
+		case types.CmdLockDatabase, types.CmdLockTable:
+			executeLockCommand(inputBuffer.Buffer)
 		case types.CmdLockIndex:
 			notImplemented(tlc, cmd.CommandName())
 		case types.CmdLockView:
@@ -366,53 +450,60 @@ func executeCommand(command types.CommandType, inputBuffer *types.InputBuffer) {
 		case types.CmdLockProcedure:
 			notImplemented(tlc, cmd.CommandName())
 		default:
-			fmt.Println(ansi.BoldText+ansi.Red+"Unknown Lock Command:"+ansi.Reset, cmd.CommandName())
+			printError(ansi.BoldText+ansi.Red+"Unknown Lock Command:"+ansi.Reset, cmd.CommandName())
 		}
 	case types.CoreCommand:
 		switch cmd.Command() {
 		case types.CmdExit:
 			Exit(0)
 		case types.CmdSelect:
-			query := strings.TrimSpace(string(inputBuffer.Buffer))
-			if strings.HasPrefix(strings.ToLower(query), "select * from ") {
-				tableName := strings.TrimSpace(query[len("select * from "):])
-				if tableName == "" {
-					fmt.Println(ansi.BoldText + ansi.Red + "Table name cannot be empty" + ansi.Reset)
-				} else {
-					// executeSelectAllCommand(tableName)
-					db := types.NewDatabase()
-					if err := db.ReadFromFile(config.GetDBFilePath()); err != nil {
-						fmt.Println(ansi.BoldText+ansi.Red+"Error reading database file during select:"+ansi.Reset, err)
-						return
-					}
-					for _, table := range db.Tables {
-						if strings.EqualFold(strings.TrimRight(string(table.Name[:]), "\x00"), tableName) {
-							table.PrintTable()
-						}
-					}
+			stmt, err := parser.ParseSelect(string(inputBuffer.Buffer))
+			if err != nil {
+				printError(ansi.BoldText+ansi.Red+"Error parsing select:"+ansi.Reset, err)
+				return
+			}
+			db, err := openDatabase()
+			if err != nil {
+				printError(ansi.BoldText+ansi.Red+"Error reading database file during select:"+ansi.Reset, err)
+				return
+			}
+			result, err := engine.Select(db, stmt)
+			if err != nil {
+				printError(ansi.BoldText+ansi.Red+"Error executing select:"+ansi.Reset, err)
+				return
+			}
+			// NEXTVAL must not hand out the same value twice
+			if db.SequencesChanged() {
+				if err := saveDatabase(db); err != nil {
+					printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+					return
 				}
-			} else {
-				fmt.Println(ansi.BoldText + ansi.Red + "Invalid select command. Only 'SELECT * FROM <table_name>' is supported." + ansi.Reset)
 			}
+			result.Print()
 		case types.CmdInsert:
 			executeInsertCommand(inputBuffer.Buffer)
 		case types.CmdUpdate:
-			notImplemented("", cmd.CommandName())
+			executeUpdateCommand(inputBuffer.Buffer)
 		case types.CmdDelete:
-			notImplemented("", cmd.CommandName())
+			executeDeleteCommand(inputBuffer.Buffer)
 		case types.CmdShowDatabases:
 			// list all the databases in the operating directory
 			printDatabases(config.HomeDir, config.DBFileName)
 		case types.CmdUse:
+			if tx != nil {
+				printError(ansi.BoldText + ansi.Red + "Cannot switch databases inside a transaction; COMMIT or ROLLBACK first" + ansi.Reset)
+				return
+			}
 			// see if it matches the format "use <database_name>"
-			dbFileName := strings.TrimSpace(string(inputBuffer.Buffer[len("use"):])) + ".db"
-			if len(dbFileName) == 0 {
-				fmt.Println(ansi.BoldText + ansi.Red + "Database name cannot be empty" + ansi.Reset)
+			name, err := databaseName(string(inputBuffer.Buffer[len("use"):]))
+			if err != nil {
+				printError(ansi.BoldText+ansi.Red+"Invalid database name:"+ansi.Reset, err)
 				return
 			}
+			dbFileName := name + ".db"
 			dbPath := config.HomeDir + "/" + dbFileName
 			if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-				fmt.Println(ansi.BoldText+ansi.Red+"Database file does not exist:"+ansi.Reset, dbPath)
+				printError(ansi.BoldText+ansi.Red+"Database file does not exist:"+ansi.Reset, dbPath)
 				fmt.Println(ansi.RegText + ansi.Green + "Available databases:" + ansi.Reset)
 				printDatabases(config.HomeDir, config.DBFileName)
 				return
@@ -421,101 +512,162 @@ func executeCommand(command types.CommandType, inputBuffer *types.InputBuffer) {
 			fmt.Println(ansi.RegText+ansi.Green+"Using database:"+ansi.Reset, dbFileName)
 		case types.CmdHelp:
 			printHelp()
+		case types.CmdImport:
+			executeImportCommand(inputBuffer.Buffer)
+		case types.CmdTruncate:
+			executeTruncateCommand(inputBuffer.Buffer)
+		case types.CmdBegin, types.CmdCommit, types.CmdRollback, types.CmdSavepoint, types.CmdRelease:
+			executeTransactionCommand(inputBuffer.Buffer)
+		case types.CmdUnlock:
+			executeUnlockCommand(inputBuffer.Buffer)
+		case types.CmdShowGrants:
+			executeShowGrantsCommand(inputBuffer.Buffer)
+		case types.CmdShowViews:
+			executeShowViewsCommand()
+		case types.CmdRefresh:
+			executeRefreshCommand(inputBuffer.Buffer)
+		case types.CmdCall:
+			executeCallCommand(inputBuffer.Buffer)
+		case types.CmdShowProcedures:
+			executeShowProceduresCommand()
+		case types.CmdShowTriggers:
+			executeShowTriggersCommand()
+		case types.CmdAttach, types.CmdDetach:
+			executeAttachCommand(inputBuffer.Buffer)
+		case types.CmdShowTables, types.CmdDescribe, types.CmdShowCreate, types.CmdShowIndexes:
+			executeShowCommand(inputBuffer.Buffer)
+		case types.CmdComment:
+			executeCommentCommand(inputBuffer.Buffer)
+		case types.CmdPragma:
+			executePragmaCommand(inputBuffer.Buffer)
 		default:
-			fmt.Println(ansi.BoldText+ansi.Red+"Unrecognized Core Command:"+ansi.Reset, cmd.CommandName())
+			printError(ansi.BoldText+ansi.Red+"Unrecognized Core Command:"+ansi.Reset, cmd.CommandName())
 		}
 	case types.UnknownCommand:
-		fmt.Println(ansi.RegText + ansi.Red + "Unknown Command" + ansi.Reset)
+		printError(ansi.RegText + ansi.Red + "Unknown Command" + ansi.Reset)
 	default:
-		fmt.Println(ansi.RegText + ansi.Red + "Unrecognized Command" + ansi.Reset)
+		printError(ansi.RegText + ansi.Red + "Unrecognized Command" + ansi.Reset)
 	}
 }
 
 // parseTableCommand parses the table name and columns definition from the input buffer
 func parseTableCommand(buffer []byte) (string, string) {
-	command := string(buffer)
+	command := strings.TrimSuffix(strings.TrimSpace(string(buffer)), ";")
 	parts := strings.SplitN(command, "(", 2)
 	if len(parts) < 2 {
 		return "", ""
 	}
 	tableName := strings.TrimSpace(parts[0][len("create table"):])
 	columnsDef := strings.TrimSpace(parts[1])
+	// drop the parenthesis closing the column list
+	if end := strings.LastIndex(columnsDef, ")"); end != -1 {
+		columnsDef = columnsDef[:end]
+	}
 	return tableName, columnsDef
 }
 
-// parseColumns parses the columns definition and returns a slice of Column
-func parseColumns(columnsDef string) []types.Column {
-	columns := []types.Column{}
-	columnDefs := strings.Split(columnsDef, ",")
-	for _, columnDef := range columnDefs {
-		parts := strings.Fields(strings.TrimSpace(columnDef))
-		if len(parts) < 2 {
-			return nil
-		}
-		columnName := parts[0]
-		dataType := types.GetDataTypeFromString(parts[1])
-		nullable := true
-		if len(parts) > 2 && strings.ToLower(parts[2]) == "not" && strings.ToLower(parts[3]) == "null" {
-			nullable = false
-		}
-		var nameBytes [64]byte
-		copy(nameBytes[:], columnName)
-		columns = append(columns, types.Column{Name: nameBytes, DataType: dataType, Nullable: nullable})
+// parseTableDefinition parses the columns and table constraints of a CREATE TABLE statement
+func parseTableDefinition(tableName string, columnsDef string) (types.Table, error) {
+	defs, constraints, err := parser.ParseColumnDefs(columnsDef)
+	if err != nil {
+		return types.Table{}, err
 	}
-	return columns
+	return engine.NewTable(tableName, defs, constraints)
 }
 
 // createTable creates a new table and adds it to the database
-func createTable(tableName string, columns []types.Column) {
-	var nameBytes [64]byte
-	copy(nameBytes[:], strings.ToLower(tableName))
-	for i := range columns {
-		copy(columns[i].Name[:], strings.ToLower(string(columns[i].Name[:])))
+func createTable(tableName string, table types.Table) {
+	copy(table.Name[:], strings.ToLower(tableName))
+	for i := range table.Columns {
+		copy(table.Columns[i].Name[:], strings.ToLower(string(table.Columns[i].Name[:])))
 	}
-	table := types.Table{Name: nameBytes, Columns: columns}
 
-	// File-based database logic
-	dbFileName := config.GetDBFilePath()
-	debugPrint("dbFileName:", dbFileName)
-	db := types.NewDatabase()
-	if err := db.ReadFromFile(dbFileName); err != nil {
-		fmt.Println(ansi.BoldText+ansi.Red+"Error reading database file during create table:"+ansi.Reset, err)
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during create table:"+ansi.Reset, err)
 		return
 	}
 	// Check if the table already exists
 	for _, t := range db.Tables {
 		if strings.EqualFold(strings.TrimRight(string(t.Name[:]), "\x00"), tableName) {
-			fmt.Println(ansi.BoldText+ansi.Red+"Table already exists:"+ansi.Reset, tableName)
+			printError(ansi.BoldText+ansi.Red+"Table already exists:"+ansi.Reset, tableName)
 			return
 		}
 	}
+	if db.FindView(tableName) != nil {
+		printError(ansi.BoldText+ansi.Red+"A view with this name already exists:"+ansi.Reset, tableName)
+		return
+	}
+
+	if err := engine.ValidateSchema(db, &table, config.StrictMode); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error creating table:"+ansi.Reset, err)
+		return
+	}
+	if err := db.ValidateForeignKeys(&table); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error creating table:"+ansi.Reset, err)
+		return
+	}
 
 	// Add the new table to the database
 	db.AddTable(table)
 	// Write the updated database back to the file
-	if err := db.WriteToFile(dbFileName); err != nil {
-		fmt.Println(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
 		return
 	}
 
 	fmt.Println(ansi.RegText+ansi.Green+"Table created and added to database file:"+ansi.Reset, tableName)
-	db.PrintDatabase()
+	if interactive {
+		db.PrintDatabase()
+	}
+}
+
+// createDatabaseFile writes a new, empty database, recording when it was created and that
+// the session user owns it
+func createDatabaseFile(path string) error {
+	db := types.NewDatabase()
+	db.Metadata = map[string]string{
+		types.MetadataCreated: time.Now().UTC().Format(time.RFC3339),
+		types.MetadataOwner:   sessionUser,
+	}
+	return db.WriteToFile(path)
+}
+
+// databaseName returns the database named after USE or CREATE DATABASE: an identifier,
+// which may carry the .db extension of its file, optionally followed by a semicolon
+func databaseName(text string) (string, error) {
+	text = strings.TrimSuffix(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), ";")), ".db")
+	if text == "" {
+		return "", fmt.Errorf("the name cannot be empty")
+	}
+	name, err := parser.ParseIdentifier(text)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", text, err)
+	}
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("%s: the database must be in the operating directory", text)
+	}
+	return name, nil
 }
 
 // printDatabases prints out all the databases in the operating directory
 func printDatabases(homeDir string, currentDB string) {
 	files, err := os.ReadDir(homeDir)
 	if err != nil {
-		fmt.Println(ansi.BoldText+ansi.Red+"Error reading database files during print:"+ansi.Reset, err)
+		printError(ansi.BoldText+ansi.Red+"Error reading database files during print:"+ansi.Reset, err)
 		return
 	}
 	fmt.Println(ansi.RegText + ansi.Green + "Databases:" + ansi.Reset)
 	for _, file := range files {
 		if strings.HasSuffix(file.Name(), ".db") {
+			note := ""
+			if names := attachedNames(file.Name()); len(names) > 0 {
+				note = " (attached as " + strings.Join(names, ", ") + ")"
+			}
 			if file.Name() == currentDB {
-				fmt.Println(ansi.RegText + ansi.Green + " " + ansi.Reset + ansi.HighIntensityText + ansi.Magenta + "*" + ansi.Reset + " " + ansi.RegText + ansi.Green + file.Name() + ansi.Reset)
+				fmt.Println(ansi.RegText + ansi.Green + " " + ansi.Reset + ansi.HighIntensityText + ansi.Magenta + "*" + ansi.Reset + " " + ansi.RegText + ansi.Green + file.Name() + ansi.Reset + note)
 			} else {
-				fmt.Println(ansi.RegText + ansi.Green + "   " + file.Name() + ansi.Reset)
+				fmt.Println(ansi.RegText + ansi.Green + "   " + file.Name() + ansi.Reset + note)
 			}
 		}
 	}
@@ -523,158 +675,341 @@ func printDatabases(homeDir string, currentDB string) {
 
 func executeInsertCommand(buffer []byte) {
 	query := string(buffer)
-	// parts := strings.SplitN(query, "values", 2) // we have to make it case insensitive
-	parts := strings.SplitN(strings.ToLower(query), "values", 2)
-	// fmt.Println("query:", query)
-	// fmt.Println("parts:", parts)
 	debugPrint("query:", query)
-	debugPrint("parts:", parts)
-	if len(parts) != 2 {
-		fmt.Println(ansi.BoldText + ansi.Red + "Invalid insert command. Use: INSERT INTO table_name (column1, column2, ...) VALUES (value1, value2, ...)" + ansi.Reset)
+	stmt, err := parser.ParseInsert(query)
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid insert command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: INSERT INTO table_name (column1, column2, ...) VALUES (value1, value2, ...)" + ansi.Reset)
 		return
 	}
 
-	// Parse table name and columns
-	tableInfo := strings.TrimSpace(parts[0][len("insert into"):])
-	tableNameEnd := strings.Index(tableInfo, "(")
-	if tableNameEnd == -1 {
-		fmt.Println(ansi.BoldText + ansi.Red + "Invalid insert command. Table name or columns not specified." + ansi.Reset)
+	// Read the database
+	debugPrint("config.GetDBFilePath():", config.GetDBFilePath())
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during insert:"+ansi.Reset, err)
 		return
 	}
-	tableName := strings.TrimSpace(tableInfo[:tableNameEnd])
-	columns := strings.TrimSpace(tableInfo[tableNameEnd+1 : len(tableInfo)-1])
-	columnNames := strings.Split(columns, ",")
-	for i, col := range columnNames {
-		columnNames[i] = strings.TrimSpace(col)
+	count, err := engine.Insert(db, stmt, config.StrictMode)
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error inserting rows:"+ansi.Reset, err)
+		return
 	}
 
-	// Parse values
-	values := strings.TrimSpace(parts[1])
-	values = strings.TrimPrefix(values, "(")
-	values = strings.TrimSuffix(values, ")")
-	valueStrings := strings.Split(values, ",")
-	for i, val := range valueStrings {
-		valueStrings[i] = strings.TrimSpace(val)
+	// Write the updated database back to the file
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
 	}
 
-	if len(columnNames) != len(valueStrings) {
-		fmt.Println(ansi.BoldText + ansi.Red + "Number of columns does not match number of values." + ansi.Reset)
-		return
+	if count == 1 {
+		fmt.Println(ansi.RegText + ansi.Green + "Row inserted successfully." + ansi.Reset)
+	} else {
+		fmt.Printf(ansi.RegText+ansi.Green+"%d rows inserted successfully.\n"+ansi.Reset, count)
 	}
+}
 
-	// Read the database
-	// fmt.Println("config.GetDBFilePath():", config.GetDBFilePath())
-	debugPrint("config.GetDBFilePath():", config.GetDBFilePath())
-	db := types.NewDatabase()
-	if err := db.ReadFromFile(config.GetDBFilePath()); err != nil {
-		fmt.Println(ansi.BoldText+ansi.Red+"Error reading database file during insert:"+ansi.Reset, err)
-		return
-	}
-	// fmt.Println("db:", db)
-	debugPrint("db:", db)
-	// Find the table
-	var table *types.Table
-	for i := range db.Tables {
-		// fmt.Println("db.Tables[i].Name[:]:", string(db.Tables[i].Name[:]))
-		// fmt.Println("tableName:", tableName)
-		debugPrint("db.Tables[i].Name[:]:", string(db.Tables[i].Name[:]))
-		debugPrint("tableName:", tableName)
-		if strings.EqualFold(strings.TrimRight(string(db.Tables[i].Name[:]), "\x00"), tableName) {
-			table = &db.Tables[i]
-			break
-		}
+func executeUpdateCommand(buffer []byte) {
+	stmt, err := parser.ParseUpdate(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid update command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: UPDATE table_name SET column1 = value1, ... [WHERE condition]" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during update:"+ansi.Reset, err)
+		return
 	}
+	count, err := engine.Update(db, stmt, config.StrictMode)
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error updating rows:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	fmt.Println(ansi.RegText + ansi.Green + rowCount(count) + " updated." + ansi.Reset)
+}
 
-	if table == nil {
-		fmt.Println(ansi.BoldText+ansi.Red+"Table not found:"+ansi.Reset, tableName)
+func executeDeleteCommand(buffer []byte) {
+	stmt, err := parser.ParseDelete(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid delete command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: DELETE FROM table_name [WHERE condition]" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during delete:"+ansi.Reset, err)
 		return
 	}
+	count, err := engine.Delete(db, stmt)
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error deleting rows:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	fmt.Println(ansi.RegText + ansi.Green + rowCount(count) + " deleted." + ansi.Reset)
+}
 
-	// Validate column names
-	tableColumns := table.GetColumnNames()
-	// fmt.Println("tableColumns:", tableColumns)
-	debugPrint("tableColumns:", tableColumns)
-	for _, col := range columnNames {
-		// fmt.Println("col:", col)
-		debugPrint("col:", col)
-		if !containsCaseInsensitive(tableColumns, strings.TrimSpace(col)) {
-			fmt.Printf(ansi.BoldText+ansi.Red+"Column %s not found in table %s\n"+ansi.Reset, col, tableName)
-			return
-		}
+func executeImportCommand(buffer []byte) {
+	stmt, err := parser.ParseImport(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid import command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: IMPORT INTO table_name FROM 'file.csv'" + ansi.Reset)
+		return
+	}
+	file, err := os.Open(stmt.File)
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error opening import file:"+ansi.Reset, err)
+		return
+	}
+	defer file.Close()
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during import:"+ansi.Reset, err)
+		return
+	}
+	count, err := engine.ImportCSV(db, stmt.Table, file, config.StrictMode)
+	if err != nil {
+		// nothing is written, so a failed import leaves the table unchanged
+		printError(ansi.BoldText+ansi.Red+"Error importing rows:"+ansi.Reset, err)
+		return
 	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	fmt.Println(ansi.RegText + ansi.Green + rowCount(count) + " imported." + ansi.Reset)
+}
 
-	// Prepare the row data
-	rowData := make([]interface{}, len(table.Columns))
-	for i, col := range table.Columns {
-		colName := strings.TrimSpace(string(col.Name[:]))
-		valueIndex := indexOfCaseInsensitive(columnNames, colName)
-		if valueIndex == -1 {
-			if !col.Nullable {
-				fmt.Printf(ansi.BoldText+ansi.Red+"Non-nullable column %s is missing a value\n"+ansi.Reset, colName)
-				return
-			}
-			rowData[i] = nil
-		} else {
-			value := valueStrings[valueIndex]
-			convertedValue, err := convertValue(value, col.DataType)
-			if err != nil {
-				fmt.Printf(ansi.BoldText+ansi.Red+"Error converting value for column %s: %v\n"+ansi.Reset, colName, err)
-				return
-			}
-			rowData[i] = convertedValue
-		}
+func executeCreateSequenceCommand(buffer []byte) {
+	stmt, err := parser.ParseCreateSequence(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid create sequence command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: CREATE SEQUENCE name [START WITH n] [INCREMENT BY n]" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during create sequence:"+ansi.Reset, err)
+		return
 	}
+	if err := db.CreateSequence(types.Sequence{Name: stmt.Name, Start: stmt.Start, Increment: stmt.Increment}); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error creating sequence:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	fmt.Println(ansi.RegText+ansi.Green+"Sequence created:"+ansi.Reset, strings.ToLower(stmt.Name))
+}
 
-	// Add the row to the table
-	if err := table.AddRow(rowData); err != nil {
-		fmt.Printf(ansi.BoldText+ansi.Red+"Error adding row: %v\n"+ansi.Reset, err)
+func executeDropSequenceCommand(buffer []byte) {
+	stmt, err := parser.ParseDropSequence(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid drop sequence command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: DROP SEQUENCE [IF EXISTS] name" + ansi.Reset)
 		return
 	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during drop sequence:"+ansi.Reset, err)
+		return
+	}
+	if db.FindSequence(stmt.Name) == nil && stmt.IfExists {
+		fmt.Println(ansi.RegText+ansi.Yellow+"Sequence does not exist, skipping:"+ansi.Reset, stmt.Name)
+		return
+	}
+	if err := db.DropSequence(stmt.Name); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error dropping sequence:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	fmt.Println(ansi.RegText+ansi.Green+"Sequence dropped:"+ansi.Reset, stmt.Name)
+}
 
-	// Write the updated database back to the file
-	if err := db.WriteToFile(config.GetDBFilePath()); err != nil {
-		fmt.Println(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+func executeAlterTableCommand(buffer []byte) {
+	stmt, err := parser.ParseAlterTable(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid alter table command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: ALTER TABLE name ADD COLUMN def | DROP COLUMN col | RENAME COLUMN col TO new | RENAME TO new | ALTER COLUMN col TYPE type" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during alter table:"+ansi.Reset, err)
 		return
 	}
+	if err := engine.AlterTable(db, stmt, config.StrictMode); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error altering table:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	fmt.Println(ansi.RegText+ansi.Green+"Table altered:"+ansi.Reset, stmt.Table)
+}
 
-	fmt.Println(ansi.RegText + ansi.Green + "Row inserted successfully." + ansi.Reset)
+func executeDropTableCommand(buffer []byte) {
+	stmt, err := parser.ParseDrop(string(buffer), "TABLE")
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid drop table command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: DROP TABLE [IF EXISTS] name [CASCADE | RESTRICT]" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during drop table:"+ansi.Reset, err)
+		return
+	}
+	if db.FindTable(stmt.Name) == nil && stmt.IfExists {
+		fmt.Println(ansi.RegText+ansi.Yellow+"Table does not exist, skipping:"+ansi.Reset, stmt.Name)
+		return
+	}
+	var views []string
+	if stmt.Cascade && db.FindTable(stmt.Name) != nil {
+		views = db.DropDependentViews(stmt.Name)
+	}
+	if err := db.DropTable(stmt.Name); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error dropping table:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	fmt.Println(ansi.RegText+ansi.Green+"Table dropped:"+ansi.Reset, stmt.Name)
+	if len(views) > 0 {
+		fmt.Println(ansi.RegText+ansi.Green+"Views dropped:"+ansi.Reset, strings.Join(views, ", "))
+	}
 }
 
-func containsCaseInsensitive(slice []string, item string) bool {
-	for _, s := range slice {
-		if strings.EqualFold(s, item) {
-			return true
+// executeDropIndexCommand drops a named index. The only indexes are the ones backing
+// primary keys, which go with their table, so this reports what the index belongs to.
+func executeDropIndexCommand(buffer []byte) {
+	stmt, err := parser.ParseDrop(string(buffer), "INDEX")
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid drop index command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: DROP INDEX [IF EXISTS] name [ON table]" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during drop index:"+ansi.Reset, err)
+		return
+	}
+	table := db.FindIndex(stmt.Name)
+	if table != nil && stmt.Table != "" && !strings.EqualFold(table.GetName(), stmt.Table) {
+		table = nil
+	}
+	if table == nil {
+		if stmt.IfExists {
+			fmt.Println(ansi.RegText+ansi.Yellow+"Index does not exist, skipping:"+ansi.Reset, stmt.Name)
+			return
 		}
+		printError(ansi.BoldText+ansi.Red+"Error dropping index:"+ansi.Reset, "index not found: "+stmt.Name)
+		return
 	}
-	return false
+	printError(ansi.BoldText+ansi.Red+"Error dropping index:"+ansi.Reset, fmt.Sprintf("index %s backs the %s of table %s and is dropped with the table", stmt.Name, table.PrimaryKeyString(), table.GetName()))
 }
 
-func indexOfCaseInsensitive(slice []string, item string) int {
-	for i, s := range slice {
-		if strings.EqualFold(s, item) {
-			return i
+// executeDropDatabaseCommand removes a database file from the operating directory. At the
+// prompt the user confirms by typing its name; a script, whose input is not the user's, is
+// not asked. The database in use cannot be dropped.
+func executeDropDatabaseCommand(buffer []byte) {
+	stmt, err := parser.ParseDrop(string(buffer), "DATABASE")
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid drop database command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: DROP DATABASE [IF EXISTS] name" + ansi.Reset)
+		return
+	}
+	dbFileName := stmt.Name + ".db"
+	dbPath := config.HomeDir + "/" + dbFileName
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		if stmt.IfExists {
+			fmt.Println(ansi.RegText+ansi.Yellow+"Database does not exist, skipping:"+ansi.Reset, stmt.Name)
+			return
 		}
+		printError(ansi.BoldText+ansi.Red+"Database file does not exist:"+ansi.Reset, dbPath)
+		return
+	}
+	if dbFileName == config.DBFileName {
+		printError(ansi.BoldText+ansi.Red+"Cannot drop the database in use:"+ansi.Reset, stmt.Name)
+		return
+	}
+	if interactive {
+		fmt.Print(ansi.BoldText + ansi.Yellow + "This permanently deletes " + dbPath + ". Type the database name to confirm: " + ansi.Reset)
+		answer, err := stdin.ReadString('\n')
+		if err != nil || strings.TrimSpace(answer) != stmt.Name {
+			fmt.Println(ansi.RegText + ansi.Yellow + "Drop database cancelled" + ansi.Reset)
+			return
+		}
+	}
+	// another process using the database holds a lock on its file
+	fileLock := lock.OpenFile(dbPath)
+	if err := fileLock.Lock(lock.Exclusive, config.BusyTimeout); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error deleting database file:"+ansi.Reset, err)
+		return
+	}
+	err = os.Remove(dbPath)
+	if err == nil {
+		os.Remove(dbPath + lock.Suffix)
+	}
+	fileLock.Unlock(lock.Exclusive)
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error deleting database file:"+ansi.Reset, err)
+		return
 	}
-	return -1
+	fmt.Println(ansi.RegText+ansi.Green+"Database dropped:"+ansi.Reset, stmt.Name)
 }
 
-func convertValue(value string, dataType types.DataType) (interface{}, error) {
-	if value == "" {
-		return nil, nil
+func executeTruncateCommand(buffer []byte) {
+	stmt, err := parser.ParseTruncate(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid truncate command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: TRUNCATE [TABLE] name" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during truncate:"+ansi.Reset, err)
+		return
+	}
+	table := db.FindTable(stmt.Table)
+	if table == nil {
+		printError(ansi.BoldText+ansi.Red+"Error truncating table:"+ansi.Reset, "table not found: "+stmt.Table)
+		return
+	}
+	removed := len(table.Rows)
+	engine.UseValidator(db, config.StrictMode)
+	if err := db.TruncateTable(table); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error truncating table:"+ansi.Reset, err)
+		return
 	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	fmt.Println(ansi.RegText+ansi.Green+"Table truncated:"+ansi.Reset, stmt.Table, "("+rowCount(removed)+" removed)")
+}
 
-	switch dataType {
-	case types.SQL_TYPE_INT, types.SQL_TYPE_DOUBLE, types.SQL_TYPE_DECIMAL, types.SQL_TYPE_DATE, types.SQL_TYPE_DATETIME, types.SQL_TYPE_TIMESTAMP, types.SQL_TYPE_BLOB:
-		return strconv.Atoi(value)
-	case types.SQL_TYPE_VARCHAR, types.SQL_TYPE_CHAR, types.SQL_TYPE_TINYTEXT, types.SQL_TYPE_TEXT, types.SQL_TYPE_MEDIUMTEXT, types.SQL_TYPE_LONGTEXT:
-		return strings.Trim(value, "'\""), nil
-	case types.SQL_TYPE_BOOL:
-		return strconv.ParseBool(value)
-	case types.SQL_TYPE_FLOAT:
-		return strconv.ParseFloat(value, 32)
-	default:
-		return nil, fmt.Errorf("unsupported data type")
+// rowCount formats a number of rows, e.g. "1 row" or "3 rows"
+func rowCount(n int) string {
+	if n == 1 {
+		return "1 row"
 	}
+	return fmt.Sprintf("%d rows", n)
 }
 
 func debugPrint(a ...any) {
@@ -686,7 +1021,7 @@ func debugPrint(a ...any) {
 func notImplemented(prefix string, commandName string) {
 	commandName = prefix + " " + commandName
 	fmt.Println(ansi.BoldText + ansi.Yellow + "Executing the " + commandName + " functionality ..." + ansi.Reset)
-	fmt.Println(ansi.BoldText + ansi.Red + "Not Implemented Yet!" + ansi.Reset)
-	fmt.Println(ansi.BoldText + ansi.Red + "Come back another day or contribute to the project at " + ansi.RegText + ansi.Cyan + "https://github.com/chaitanyasharma/DBs" + ansi.Reset + ansi.BoldText + ansi.Red + "!" + ansi.Reset)
-	fmt.Println(ansi.BoldText + ansi.Red + "Thank you!" + ansi.Reset)
+	printError(ansi.BoldText + ansi.Red + "Not Implemented Yet!" + ansi.Reset)
+	printError(ansi.BoldText + ansi.Red + "Come back another day or contribute to the project at " + ansi.RegText + ansi.Cyan + "https://github.com/chaitanyasharma/DBs" + ansi.Reset + ansi.BoldText + ansi.Red + "!" + ansi.Reset)
+	printError(ansi.BoldText + ansi.Red + "Thank you!" + ansi.Reset)
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/ansi"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/engine"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// executeCommentCommand runs COMMENT ON TABLE and COMMENT ON COLUMN, which keep the comment
+// in the metadata of the table
+func executeCommentCommand(buffer []byte) {
+	stmt, err := parser.ParseComment(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid comment command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: COMMENT ON TABLE table_name IS 'text' or COMMENT ON COLUMN table_name.column_name IS 'text' (IS NULL removes it)" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during comment:"+ansi.Reset, err)
+		return
+	}
+	t := engine.FindTable(db, stmt.Table)
+	if t == nil {
+		printError(ansi.BoldText+ansi.Red+"Table not found:"+ansi.Reset, stmt.Table)
+		return
+	}
+	key, on := types.MetadataComment, "table "+t.GetName()
+	if stmt.Column != "" {
+		index := t.ColumnIndex(stmt.Column)
+		if index == -1 {
+			printError(ansi.BoldText+ansi.Red+"Column not found:"+ansi.Reset, stmt.Column)
+			return
+		}
+		key, on = types.ColumnCommentKey(t.Columns[index].GetName()), "column "+t.GetName()+"."+t.Columns[index].GetName()
+	}
+	if err := types.SetMetadata(&t.Metadata, key, stmt.Text); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error setting comment:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	if stmt.Text == "" {
+		fmt.Println(ansi.RegText+ansi.Green+"Comment removed from"+ansi.Reset, on)
+	} else {
+		fmt.Println(ansi.RegText+ansi.Green+"Comment set on"+ansi.Reset, on)
+	}
+}
+
+// executePragmaCommand reads and writes the settings of the database and its tables, kept in
+// their metadata. PRAGMA alone lists them all.
+func executePragmaCommand(buffer []byte) {
+	stmt, err := parser.ParsePragma(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid pragma command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: PRAGMA, PRAGMA [table_name.]key or PRAGMA [table_name.]key = value (= NULL removes it)" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during pragma:"+ansi.Reset, err)
+		return
+	}
+	if stmt.Key == "" {
+		result := &types.ResultSet{Columns: []string{"table", "key", "value"}}
+		for _, key := range types.MetadataKeys(db.Metadata) {
+			result.Rows = append(result.Rows, []interface{}{nil, key, db.Metadata[key]})
+		}
+		for _, t := range db.Tables {
+			for _, key := range types.MetadataKeys(t.Metadata) {
+				result.Rows = append(result.Rows, []interface{}{t.GetName(), key, t.Metadata[key]})
+			}
+		}
+		result.Print()
+		return
+	}
+	metadata, name := &db.Metadata, stmt.Key
+	if stmt.Table != "" {
+		t := engine.FindTable(db, stmt.Table)
+		if t == nil {
+			printError(ansi.BoldText+ansi.Red+"Table not found:"+ansi.Reset, stmt.Table)
+			return
+		}
+		metadata, name = &t.Metadata, t.GetName()+"."+stmt.Key
+	}
+	if !stmt.Set {
+		var value interface{}
+		if v, ok := (*metadata)[stmt.Key]; ok {
+			value = v
+		}
+		result := &types.ResultSet{Columns: []string{"key", "value"}, Rows: [][]interface{}{{name, value}}}
+		result.Print()
+		return
+	}
+	if stmt.Table == "" && (stmt.Key == types.MetadataCreated || stmt.Key == types.MetadataOwner) {
+		printError(ansi.BoldText+ansi.Red+"Error setting pragma: it is set when the database is created:"+ansi.Reset, stmt.Key)
+		return
+	}
+	if err := types.SetMetadata(metadata, stmt.Key, stmt.Value); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error setting pragma:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	if stmt.Value == "" {
+		fmt.Println(ansi.RegText+ansi.Green+"Pragma removed:"+ansi.Reset, name)
+	} else {
+		fmt.Println(ansi.RegText+ansi.Green+"Pragma set:"+ansi.Reset, name, "=", stmt.Value)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/ansi"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/engine"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// executeCreateProcedureCommand runs CREATE PROCEDURE and CREATE OR REPLACE PROCEDURE
+func executeCreateProcedureCommand(buffer []byte) {
+	stmt, err := parser.ParseCreateProcedure(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid create procedure command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: CREATE [OR REPLACE] PROCEDURE name ([param type, ...]) BEGIN statement; ... END" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during create procedure:"+ansi.Reset, err)
+		return
+	}
+	replaced := db.FindProcedure(stmt.Name) != nil
+	if err := engine.CreateProcedure(db, stmt); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error creating procedure:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	if replaced {
+		fmt.Println(ansi.RegText+ansi.Green+"Procedure replaced:"+ansi.Reset, stmt.Name)
+		return
+	}
+	fmt.Println(ansi.RegText+ansi.Green+"Procedure created:"+ansi.Reset, stmt.Name)
+}
+
+// executeDropProcedureCommand runs DROP PROCEDURE
+func executeDropProcedureCommand(buffer []byte) {
+	stmt, err := parser.ParseDrop(string(buffer), "PROCEDURE")
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid drop procedure command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: DROP PROCEDURE [IF EXISTS] name" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during drop procedure:"+ansi.Reset, err)
+		return
+	}
+	if db.FindProcedure(stmt.Name) == nil && stmt.IfExists {
+		fmt.Println(ansi.RegText+ansi.Yellow+"Procedure does not exist, skipping:"+ansi.Reset, stmt.Name)
+		return
+	}
+	if err := db.DropProcedure(stmt.Name); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error dropping procedure:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	fmt.Println(ansi.RegText+ansi.Green+"Procedure dropped:"+ansi.Reset, stmt.Name)
+}
+
+// executeAlterProcedureCommand runs ALTER PROCEDURE ... RENAME TO
+func executeAlterProcedureCommand(buffer []byte) {
+	stmt, err := parser.ParseAlterProcedure(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid alter procedure command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: ALTER PROCEDURE name RENAME TO new_name" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during alter procedure:"+ansi.Reset, err)
+		return
+	}
+	if err := db.RenameProcedure(stmt.Name, stmt.NewName); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error altering procedure:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	fmt.Println(ansi.RegText+ansi.Green+"Procedure renamed:"+ansi.Reset, stmt.Name, "to", stmt.NewName)
+}
+
+// executeCallCommand runs CALL. The statements of the procedure are checked against the
+// privileges of the session user as they run, and a call that fails changes nothing.
+func executeCallCommand(buffer []byte) {
+	stmt, err := parser.ParseCall(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid call command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: CALL name([arg, ...])" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during call:"+ansi.Reset, err)
+		return
+	}
+	result, err := engine.CallProcedure(db, stmt, engine.CallOptions{Strict: config.StrictMode, Authorize: authorizeSQL})
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error calling procedure:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	for _, rs := range result.Results {
+		rs.Print()
+	}
+	fmt.Println(ansi.RegText+ansi.Green+"Procedure called:"+ansi.Reset, stmt.Name, "("+rowCount(result.Changed)+" changed)")
+	if result.Returned {
+		fmt.Println(ansi.RegText+ansi.Green+"Returned:"+ansi.Reset, types.FormatValue(result.Value))
+	}
+}
+
+// executeShowProceduresCommand lists the procedures of the current database
+func executeShowProceduresCommand() {
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during show procedures:"+ansi.Reset, err)
+		return
+	}
+	result := &types.ResultSet{Columns: []string{"procedure", "parameters", "body"}}
+	for _, p := range db.Procedures {
+		result.Rows = append(result.Rows, []interface{}{p.Name, p.Signature(), p.Body})
+	}
+	result.Print()
+}
+
+// executeCreateTriggerCommand runs CREATE TRIGGER
+func executeCreateTriggerCommand(buffer []byte) {
+	stmt, err := parser.ParseCreateTrigger(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid create trigger command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: CREATE TRIGGER name BEFORE|AFTER INSERT|UPDATE|DELETE ON table FOR EACH ROW BEGIN statement; ... END" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during create trigger:"+ansi.Reset, err)
+		return
+	}
+	// a trigger runs for whoever changes its table, so its creator must be allowed to run
+	// every statement of its body
+	for _, sql := range engine.BodyStatements(db, stmt.Body) {
+		if err := authorizeSQL(sql); err != nil {
+			printError(ansi.BoldText+ansi.Red+"Error creating trigger:"+ansi.Reset, err)
+			return
+		}
+	}
+	if err := engine.CreateTrigger(db, stmt); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error creating trigger:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	fmt.Println(ansi.RegText+ansi.Green+"Trigger created:"+ansi.Reset, stmt.Name, "("+stmt.Timing, stmt.Event, "ON", stmt.Table+")")
+}
+
+// executeDropTriggerCommand runs DROP TRIGGER
+func executeDropTriggerCommand(buffer []byte) {
+	stmt, err := parser.ParseDrop(string(buffer), "TRIGGER")
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid drop trigger command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: DROP TRIGGER [IF EXISTS] name" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during drop trigger:"+ansi.Reset, err)
+		return
+	}
+	if db.FindTrigger(stmt.Name) == nil && stmt.IfExists {
+		fmt.Println(ansi.RegText+ansi.Yellow+"Trigger does not exist, skipping:"+ansi.Reset, stmt.Name)
+		return
+	}
+	if err := db.DropTrigger(stmt.Name); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error dropping trigger:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	fmt.Println(ansi.RegText+ansi.Green+"Trigger dropped:"+ansi.Reset, stmt.Name)
+}
+
+// executeShowTriggersCommand lists the triggers of the current database
+func executeShowTriggersCommand() {
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during show triggers:"+ansi.Reset, err)
+		return
+	}
+	result := &types.ResultSet{Columns: []string{"trigger", "timing", "event", "table", "body"}}
+	for _, tr := range db.Triggers {
+		result.Rows = append(result.Rows, []interface{}{tr.Name, tr.Timing, tr.Event, tr.Table, tr.Body})
+	}
+	result.Print()
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/ansi"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/lock"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/mvcc"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+var (
+	store *mvcc.Store // versions of the database in use, opened on first use
+	tx    *mvcc.Tx    // the open transaction of the session, or nil in autocommit mode
+
+	// statementTx runs a statement outside of a transaction; it commits when the statement
+	// saves its changes and rolls back otherwise
+	statementTx *mvcc.Tx
+
+	// session owns the locks taken by LOCK outside of transactions; the transactions of
+	// the session take their locks on its behalf, so they are never blocked by them
+	session lock.Owner
+)
+
+// currentStore returns the store of the database in use
+func currentStore() (*mvcc.Store, error) {
+	if store == nil || store.Path() != config.GetDBFilePath() {
+		s, err := mvcc.Open(config.GetDBFilePath(), config.BusyTimeout)
+		if err != nil {
+			return nil, err
+		}
+		if store != nil {
+			store.Locks().Unlock(session, 0)
+		}
+		store, session = s, s.Locks().NewOwner()
+	}
+	store.LockTimeout = config.BusyTimeout
+	return store, nil
+}
+
+// openDatabase returns the database a statement works on: the state inside the open
+// transaction, or else the database as last committed. The attached databases can be read
+// from it.
+func openDatabase() (*types.Database, error) {
+	if tx != nil {
+		db, err := tx.Database()
+		if err != nil {
+			return nil, err
+		}
+		return db, attachDatabases(db)
+	}
+	s, err := currentStore()
+	if err != nil {
+		return nil, err
+	}
+	if statementTx, err = s.BeginFor(session, mvcc.ReadCommitted); err != nil {
+		return nil, err
+	}
+	db, err := statementTx.Database()
+	if err != nil {
+		return nil, err
+	}
+	return db, attachDatabases(db)
+}
+
+// saveDatabase keeps the changes of a statement: in the open transaction, or else by
+// committing them straight away
+func saveDatabase(db *types.Database) error {
+	if tx != nil {
+		return tx.Apply(db)
+	}
+	defer func() { statementTx = nil }()
+	if err := statementTx.Apply(db); err != nil {
+		statementTx.Rollback()
+		return err
+	}
+	return statementTx.Commit()
+}
+
+// finishStatement rolls back the changes of a statement that did not save them
+func finishStatement() {
+	if statementTx != nil {
+		statementTx.Rollback()
+		statementTx = nil
+	}
+}
+
+func executeTransactionCommand(buffer []byte) {
+	stmt, err := parser.ParseTransaction(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid transaction command:"+ansi.Reset, err)
+		return
+	}
+	if stmt.Action == "BEGIN" {
+		if tx != nil {
+			printError(ansi.BoldText + ansi.Red + "A transaction is already in progress" + ansi.Reset)
+			return
+		}
+		level := mvcc.Snapshot
+		if stmt.Isolation != "" {
+			if level, err = mvcc.ParseIsolationLevel(stmt.Isolation); err != nil {
+				printError(ansi.BoldText+ansi.Red+"Error beginning transaction:"+ansi.Reset, err)
+				return
+			}
+		}
+		s, err := currentStore()
+		if err == nil {
+			tx, err = s.BeginFor(session, level)
+		}
+		if err != nil {
+			printError(ansi.BoldText+ansi.Red+"Error beginning transaction:"+ansi.Reset, err)
+			return
+		}
+		fmt.Println(ansi.RegText+ansi.Green+"Transaction started:"+ansi.Reset, level)
+		return
+	}
+	if tx == nil {
+		printError(ansi.BoldText + ansi.Red + "No transaction is in progress" + ansi.Reset)
+		return
+	}
+	switch stmt.Action {
+	case "COMMIT":
+		err := tx.Commit()
+		tx = nil
+		if err != nil {
+			printError(ansi.BoldText+ansi.Red+"Error committing transaction, it was rolled back:"+ansi.Reset, err)
+			return
+		}
+		fmt.Println(ansi.RegText + ansi.Green + "Transaction committed" + ansi.Reset)
+	case "ROLLBACK":
+		tx.Rollback()
+		tx = nil
+		fmt.Println(ansi.RegText + ansi.Green + "Transaction rolled back" + ansi.Reset)
+	case "SAVEPOINT":
+		if err := tx.Savepoint(stmt.Savepoint); err != nil {
+			printError(ansi.BoldText+ansi.Red+"Error setting savepoint:"+ansi.Reset, err)
+			return
+		}
+		fmt.Println(ansi.RegText+ansi.Green+"Savepoint set:"+ansi.Reset, stmt.Savepoint)
+	case "ROLLBACK TO":
+		if err := tx.RollbackTo(stmt.Savepoint); err != nil {
+			printError(ansi.BoldText+ansi.Red+"Error rolling back to savepoint:"+ansi.Reset, err)
+			return
+		}
+		fmt.Println(ansi.RegText+ansi.Green+"Rolled back to savepoint:"+ansi.Reset, stmt.Savepoint)
+	case "RELEASE":
+		if err := tx.Release(stmt.Savepoint); err != nil {
+			printError(ansi.BoldText+ansi.Red+"Error releasing savepoint:"+ansi.Reset, err)
+			return
+		}
+		fmt.Println(ansi.RegText+ansi.Green+"Savepoint released:"+ansi.Reset, stmt.Savepoint)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/ansi"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/engine"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// executeCreateViewCommand runs CREATE VIEW, CREATE OR REPLACE VIEW and CREATE MATERIALIZED VIEW
+func executeCreateViewCommand(buffer []byte) {
+	stmt, err := parser.ParseCreateView(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid create view command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: CREATE [OR REPLACE | MATERIALIZED] VIEW name [(column, ...)] AS SELECT ..." + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during create view:"+ansi.Reset, err)
+		return
+	}
+	replaced := db.FindView(stmt.Name) != nil
+	if err := engine.CreateView(db, stmt); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error creating view:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	if stmt.Materialized {
+		refresh := "refreshed incrementally"
+		if !db.FindView(stmt.Name).Incremental {
+			refresh = "refreshed by REFRESH MATERIALIZED VIEW, as " + engine.ManualRefreshReason(db, stmt.Query)
+		}
+		fmt.Println(ansi.RegText+ansi.Green+"Materialized view created:"+ansi.Reset, stmt.Name, "("+refresh+")")
+		return
+	}
+	if replaced {
+		fmt.Println(ansi.RegText+ansi.Green+"View replaced:"+ansi.Reset, stmt.Name)
+		return
+	}
+	fmt.Println(ansi.RegText+ansi.Green+"View created:"+ansi.Reset, stmt.Name)
+}
+
+// executeDropViewCommand runs DROP VIEW and DROP MATERIALIZED VIEW, the object being "VIEW"
+// or "MATERIALIZED VIEW". Views read by other views are dropped only with CASCADE, which
+// drops those views too.
+func executeDropViewCommand(buffer []byte, object string) {
+	stmt, err := parser.ParseDrop(string(buffer), object)
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid drop view command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: DROP " + object + " [IF EXISTS] name [CASCADE | RESTRICT]" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during drop view:"+ansi.Reset, err)
+		return
+	}
+	if db.FindView(stmt.Name) == nil && stmt.IfExists {
+		fmt.Println(ansi.RegText+ansi.Yellow+"View does not exist, skipping:"+ansi.Reset, stmt.Name)
+		return
+	}
+	if v := db.FindView(stmt.Name); v != nil && v.Materialized != (object == "MATERIALIZED VIEW") {
+		if v.Materialized {
+			printError(ansi.BoldText+ansi.Red+"Error dropping view:"+ansi.Reset, stmt.Name, "is a materialized view; use DROP MATERIALIZED VIEW")
+		} else {
+			printError(ansi.BoldText+ansi.Red+"Error dropping view:"+ansi.Reset, stmt.Name, "is not a materialized view; use DROP VIEW")
+		}
+		return
+	}
+	dropped, err := db.DropView(stmt.Name, stmt.Cascade)
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error dropping view:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	if object == "MATERIALIZED VIEW" {
+		fmt.Println(ansi.RegText+ansi.Green+"Materialized view dropped:"+ansi.Reset, strings.Join(dropped, ", "))
+		return
+	}
+	fmt.Println(ansi.RegText+ansi.Green+"View dropped:"+ansi.Reset, strings.Join(dropped, ", "))
+}
+
+// executeRefreshCommand runs REFRESH MATERIALIZED VIEW
+func executeRefreshCommand(buffer []byte) {
+	stmt, err := parser.ParseRefresh(string(buffer))
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Invalid refresh command:"+ansi.Reset, err)
+		printError(ansi.BoldText + ansi.Red + "Use: REFRESH MATERIALIZED VIEW name" + ansi.Reset)
+		return
+	}
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during refresh:"+ansi.Reset, err)
+		return
+	}
+	rows, err := engine.RefreshMaterializedView(db, stmt.View)
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error refreshing materialized view:"+ansi.Reset, err)
+		return
+	}
+	if err := saveDatabase(db); err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error writing to database file:"+ansi.Reset, err)
+		return
+	}
+	fmt.Println(ansi.RegText+ansi.Green+"Materialized view refreshed:"+ansi.Reset, stmt.View, "("+rowCount(rows)+")")
+}
+
+// executeShowViewsCommand lists the views of the current database with their queries
+func executeShowViewsCommand() {
+	db, err := openDatabase()
+	if err != nil {
+		printError(ansi.BoldText+ansi.Red+"Error reading database file during show views:"+ansi.Reset, err)
+		return
+	}
+	result := &types.ResultSet{Columns: []string{"view", "type", "refresh", "columns", "query"}}
+	for _, v := range db.Views {
+		kind, refresh := "VIEW", ""
+		if v.Materialized {
+			kind, refresh = "MATERIALIZED VIEW", "MANUAL"
+			if v.Incremental {
+				refresh = "INCREMENTAL"
+			}
+		}
+		result.Rows = append(result.Rows, []interface{}{v.Name, kind, refresh, strings.Join(v.Columns, ", "), v.Query})
+	}
+	result.Print()
+}
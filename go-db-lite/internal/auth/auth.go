@@ -0,0 +1,363 @@
+// Package auth keeps the users and roles of a server and the privileges granted to them.
+// They live in a system catalog shared by every database of the operating directory.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Root is the superuser. It always exists, holds every privilege and starts without a password.
+const Root = "root"
+
+// ErrAccessDenied is returned when a login fails, whatever the reason
+var ErrAccessDenied = errors.New("access denied")
+
+// Privilege is a kind of statement a user may be allowed to run
+type Privilege string
+
+const (
+	Select    Privilege = "SELECT"
+	Insert    Privilege = "INSERT"
+	Update    Privilege = "UPDATE"
+	Delete    Privilege = "DELETE"
+	Create    Privilege = "CREATE"
+	Drop      Privilege = "DROP"
+	Alter     Privilege = "ALTER"
+	Index     Privilege = "INDEX"
+	View      Privilege = "VIEW"
+	Procedure Privilege = "PROCEDURE"
+	All       Privilege = "ALL"
+)
+
+// ParsePrivilege parses a privilege name, ignoring case
+func ParsePrivilege(s string) (Privilege, error) {
+	p := Privilege(strings.ToUpper(s))
+	switch p {
+	case Select, Insert, Update, Delete, Create, Drop, Alter, Index, View, Procedure, All:
+		return p, nil
+	case "ALL PRIVILEGES":
+		return All, nil
+	}
+	return "", fmt.Errorf("unknown privilege %s", s)
+}
+
+// Object is what a privilege is granted on: every database, a database, a table or a column
+type Object struct {
+	Database string // * for every database
+	Table    string // empty for the whole database
+	Column   string // empty for the whole table
+}
+
+func (o Object) String() string {
+	switch {
+	case o.Database == "*":
+		return "*.*"
+	case o.Table == "":
+		return o.Database + ".*"
+	case o.Column == "":
+		return o.Database + "." + o.Table
+	}
+	return o.Database + "." + o.Table + "(" + o.Column + ")"
+}
+
+// covers reports whether a privilege on o extends to the other object
+func (o Object) covers(other Object) bool {
+	return (o.Database == "*" || strings.EqualFold(o.Database, other.Database)) &&
+		(o.Table == "" || strings.EqualFold(o.Table, other.Table)) &&
+		(o.Column == "" || strings.EqualFold(o.Column, other.Column))
+}
+
+func (o Object) equal(other Object) bool {
+	return strings.EqualFold(o.Database, other.Database) && strings.EqualFold(o.Table, other.Table) &&
+		strings.EqualFold(o.Column, other.Column)
+}
+
+// User is a user that can log in, or a role holding privileges for the users granted it
+type User struct {
+	Name     string
+	Role     bool
+	Password string   // password hash, empty when logging in needs no password
+	Roles    []string // roles granted to the user
+}
+
+// Grant is a privilege held by a user or role
+type Grant struct {
+	Grantee     string
+	Privilege   Privilege
+	On          Object
+	GrantOption bool // the grantee may grant the privilege to others
+}
+
+func (g Grant) String() string {
+	s := fmt.Sprintf("GRANT %s ON %s TO %s", g.Privilege, g.On, g.Grantee)
+	if g.GrantOption {
+		s += " WITH GRANT OPTION"
+	}
+	return s
+}
+
+// Catalog holds the users, roles and grants
+type Catalog struct {
+	Users  []User
+	Grants []Grant
+}
+
+// NewCatalog creates a catalog holding only the superuser
+func NewCatalog() *Catalog {
+	return &Catalog{Users: []User{{Name: Root}}}
+}
+
+// FindUser returns the user or role with the given name, ignoring case, or nil
+func (c *Catalog) FindUser(name string) *User {
+	for i := range c.Users {
+		if strings.EqualFold(c.Users[i].Name, name) {
+			return &c.Users[i]
+		}
+	}
+	return nil
+}
+
+func kind(role bool) string {
+	if role {
+		return "role"
+	}
+	return "user"
+}
+
+// CreateUser adds a user or role. An empty password lets the user log in without one.
+func (c *Catalog) CreateUser(name, password string, role bool) error {
+	if c.FindUser(name) != nil {
+		return fmt.Errorf("user or role already exists: %s", name)
+	}
+	u := User{Name: name, Role: role}
+	if password != "" {
+		hash, err := HashPassword(password)
+		if err != nil {
+			return err
+		}
+		u.Password = hash
+	}
+	c.Users = append(c.Users, u)
+	return nil
+}
+
+// SetPassword changes the password of a user; an empty password removes it
+func (c *Catalog) SetPassword(name, password string) error {
+	u := c.FindUser(name)
+	if u == nil || u.Role {
+		return fmt.Errorf("user not found: %s", name)
+	}
+	if password == "" {
+		u.Password = ""
+		return nil
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	u.Password = hash
+	return nil
+}
+
+// DropUser removes a user or role along with its grants and memberships
+func (c *Catalog) DropUser(name string, role bool) error {
+	u := c.FindUser(name)
+	if u == nil || u.Role != role {
+		return fmt.Errorf("%s not found: %s", kind(role), name)
+	}
+	if strings.EqualFold(name, Root) {
+		return fmt.Errorf("the superuser %s cannot be dropped", Root)
+	}
+	name = u.Name
+	users := c.Users[:0]
+	for _, other := range c.Users {
+		if other.Name == name {
+			continue
+		}
+		other.Roles = removeName(other.Roles, name)
+		users = append(users, other)
+	}
+	c.Users = users
+	grants := c.Grants[:0]
+	for _, g := range c.Grants {
+		if !strings.EqualFold(g.Grantee, name) {
+			grants = append(grants, g)
+		}
+	}
+	c.Grants = grants
+	return nil
+}
+
+func removeName(names []string, name string) []string {
+	kept := names[:0]
+	for _, n := range names {
+		if !strings.EqualFold(n, name) {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+// NeedsPassword reports whether logging in as the user takes a password
+func (c *Catalog) NeedsPassword(name string) bool {
+	u := c.FindUser(name)
+	return u == nil || u.Password != ""
+}
+
+// Authenticate checks the password of a user. Roles cannot log in.
+func (c *Catalog) Authenticate(name, password string) error {
+	u := c.FindUser(name)
+	if u == nil || u.Role || (u.Password != "" && !CheckPassword(u.Password, password)) {
+		return fmt.Errorf("%w for user %s", ErrAccessDenied, name)
+	}
+	return nil
+}
+
+// GrantRole makes a user or role a member of a role
+func (c *Catalog) GrantRole(role, to string) error {
+	r := c.FindUser(role)
+	if r == nil || !r.Role {
+		return fmt.Errorf("role not found: %s", role)
+	}
+	u := c.FindUser(to)
+	if u == nil {
+		return fmt.Errorf("user or role not found: %s", to)
+	}
+	if c.principals(r.Name)[strings.ToLower(u.Name)] {
+		return fmt.Errorf("granting role %s to %s would make it a member of itself", r.Name, u.Name)
+	}
+	u.Roles = append(removeName(u.Roles, r.Name), r.Name)
+	return nil
+}
+
+// RevokeRole removes a user or role from a role
+func (c *Catalog) RevokeRole(role, from string) error {
+	u := c.FindUser(from)
+	if u == nil {
+		return fmt.Errorf("user or role not found: %s", from)
+	}
+	before := len(u.Roles)
+	if u.Roles = removeName(u.Roles, role); len(u.Roles) == before {
+		return fmt.Errorf("role %s is not granted to %s", role, from)
+	}
+	return nil
+}
+
+// GrantPrivilege records a grant. Granting a privilege held already only adds the grant option.
+func (c *Catalog) GrantPrivilege(g Grant) error {
+	u := c.FindUser(g.Grantee)
+	if u == nil {
+		return fmt.Errorf("user or role not found: %s", g.Grantee)
+	}
+	g.Grantee = u.Name
+	for i := range c.Grants {
+		if c.Grants[i].Grantee == u.Name && c.Grants[i].Privilege == g.Privilege && c.Grants[i].On.equal(g.On) {
+			c.Grants[i].GrantOption = c.Grants[i].GrantOption || g.GrantOption
+			return nil
+		}
+	}
+	c.Grants = append(c.Grants, g)
+	return nil
+}
+
+// RevokePrivilege removes the grants of a privilege on exactly the given object and returns
+// how many were removed. Revoking ALL removes every privilege on the object.
+func (c *Catalog) RevokePrivilege(grantee string, p Privilege, on Object) int {
+	removed := 0
+	grants := c.Grants[:0]
+	for _, g := range c.Grants {
+		if strings.EqualFold(g.Grantee, grantee) && (p == All || g.Privilege == p) && g.On.equal(on) {
+			removed++
+			continue
+		}
+		grants = append(grants, g)
+	}
+	c.Grants = grants
+	return removed
+}
+
+// GrantsOf returns the grants made to a user or role directly, not through its roles
+func (c *Catalog) GrantsOf(name string) []Grant {
+	var grants []Grant
+	for _, g := range c.Grants {
+		if strings.EqualFold(g.Grantee, name) {
+			grants = append(grants, g)
+		}
+	}
+	return grants
+}
+
+// principals returns the lower case names of a user and every role it holds, directly or
+// through other roles
+func (c *Catalog) principals(name string) map[string]bool {
+	names := make(map[string]bool)
+	var add func(name string)
+	add = func(name string) {
+		if names[strings.ToLower(name)] {
+			return
+		}
+		names[strings.ToLower(name)] = true
+		if u := c.FindUser(name); u != nil {
+			for _, r := range u.Roles {
+				add(r)
+			}
+		}
+	}
+	add(name)
+	return names
+}
+
+// holds reports whether a user holds a privilege on an object, optionally with grant option
+func (c *Catalog) holds(user string, p Privilege, on Object, grantOption bool) bool {
+	if strings.EqualFold(user, Root) {
+		return true
+	}
+	names := c.principals(user)
+	for _, g := range c.Grants {
+		if names[strings.ToLower(g.Grantee)] && (g.Privilege == p || g.Privilege == All) &&
+			g.On.covers(on) && (g.GrantOption || !grantOption) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed reports whether a user may use a privilege on an object. When columns are given,
+// privileges granted on those columns alone are enough.
+func (c *Catalog) Allowed(user string, p Privilege, on Object, columns []string) bool {
+	if c.holds(user, p, on, false) {
+		return true
+	}
+	if on.Table == "" || len(columns) == 0 {
+		return false
+	}
+	for _, column := range columns {
+		on.Column = column
+		if !c.holds(user, p, on, false) {
+			return false
+		}
+	}
+	return true
+}
+
+// AllowedAny reports whether a user holds any privilege in a database
+func (c *Catalog) AllowedAny(user, database string) bool {
+	if strings.EqualFold(user, Root) {
+		return true
+	}
+	names := c.principals(user)
+	for _, g := range c.Grants {
+		if names[strings.ToLower(g.Grantee)] && (g.On.Database == "*" || strings.EqualFold(g.On.Database, database)) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanGrant reports whether a user may grant or revoke a privilege on an object: the
+// superuser may, as may those granted the privilege WITH GRANT OPTION
+func (c *Catalog) CanGrant(user string, p Privilege, on Object) bool {
+	return c.holds(user, p, on, true)
+}
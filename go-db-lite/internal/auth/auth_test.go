@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// Test that privileges reach users through roles and column grants, that revoking takes
+// them away, and that the catalog survives a round trip through its file
+func TestPrivileges(t *testing.T) {
+	c := NewCatalog()
+	if err := c.CreateUser("bob", "secret", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CreateUser("analyst", "", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.GrantRole("analyst", "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.GrantRole("analyst", "analyst"); err == nil {
+		t.Error("a role was granted to itself")
+	}
+	items := Object{Database: "shop", Table: "items"}
+	c.GrantPrivilege(Grant{Grantee: "analyst", Privilege: Select, On: Object{Database: "shop"}})
+	c.GrantPrivilege(Grant{Grantee: "bob", Privilege: Update, On: Object{Database: "shop", Table: "items", Column: "price"}})
+
+	checks := []struct {
+		p       Privilege
+		on      Object
+		columns []string
+		want    bool
+	}{
+		{Select, items, nil, true},
+		{Select, Object{Database: "other", Table: "items"}, nil, false},
+		{Insert, items, nil, false},
+		{Update, items, []string{"price"}, true},
+		{Update, items, []string{"price", "name"}, false},
+		{Update, items, nil, false},
+	}
+	path := filepath.Join(t.TempDir(), ".catalog")
+	if err := c.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cat := range []*Catalog{c, loaded} {
+		for _, check := range checks {
+			if got := cat.Allowed("bob", check.p, check.on, check.columns); got != check.want {
+				t.Errorf("bob %s on %s %v: got %v, want %v", check.p, check.on, check.columns, got, check.want)
+			}
+		}
+	}
+	if err := loaded.Authenticate("bob", "wrong"); !errors.Is(err, ErrAccessDenied) {
+		t.Errorf("wrong password: got %v, want ErrAccessDenied", err)
+	}
+	if err := loaded.Authenticate("bob", "secret"); err != nil {
+		t.Errorf("right password: %v", err)
+	}
+
+	if n := c.RevokePrivilege("analyst", All, Object{Database: "shop"}); n != 1 {
+		t.Errorf("revoked %d grants, want 1", n)
+	}
+	if c.Allowed("bob", Select, items, nil) {
+		t.Error("SELECT still allowed after REVOKE")
+	}
+	if !c.Allowed(Root, Drop, items, nil) {
+		t.Error("the superuser was refused")
+	}
+}
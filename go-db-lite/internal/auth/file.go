@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// catalogMagic starts every catalog file
+const catalogMagic uint32 = 0x41555448 // "AUTH"
+
+// Load reads a catalog file. A missing file holds only the superuser.
+func Load(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewCatalog(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening catalog file: %v", err)
+	}
+	c := &Catalog{}
+	if err := c.decode(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("error reading catalog file: %v", err)
+	}
+	if c.FindUser(Root) == nil {
+		c.Users = append([]User{{Name: Root}}, c.Users...)
+	}
+	return c, nil
+}
+
+// Save writes the catalog to a file
+func (c *Catalog) Save(path string) error {
+	var buf bytes.Buffer
+	if err := c.encode(&buf); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("error writing catalog file: %v", err)
+	}
+	return nil
+}
+
+func (c *Catalog) encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	put := func(v interface{}) { binary.Write(bw, binary.LittleEndian, v) }
+	str := func(s string) {
+		put(uint32(len(s)))
+		bw.WriteString(s)
+	}
+	put(catalogMagic)
+	put(uint32(len(c.Users)))
+	for _, u := range c.Users {
+		str(u.Name)
+		put(u.Role)
+		str(u.Password)
+		put(uint32(len(u.Roles)))
+		for _, r := range u.Roles {
+			str(r)
+		}
+	}
+	put(uint32(len(c.Grants)))
+	for _, g := range c.Grants {
+		str(g.Grantee)
+		str(string(g.Privilege))
+		str(g.On.Database)
+		str(g.On.Table)
+		str(g.On.Column)
+		put(g.GrantOption)
+	}
+	return bw.Flush()
+}
+
+func (c *Catalog) decode(r io.Reader) error {
+	var err error
+	get := func(v interface{}) {
+		if err == nil {
+			err = binary.Read(r, binary.LittleEndian, v)
+		}
+	}
+	str := func() string {
+		var n uint32
+		if get(&n); err != nil {
+			return ""
+		}
+		b := make([]byte, n)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return ""
+		}
+		return string(b)
+	}
+	var magic, count uint32
+	if get(&magic); err == nil && magic != catalogMagic {
+		return fmt.Errorf("not a catalog file")
+	}
+	get(&count)
+	for i := uint32(0); i < count && err == nil; i++ {
+		u := User{Name: str()}
+		get(&u.Role)
+		u.Password = str()
+		var roles uint32
+		get(&roles)
+		for j := uint32(0); j < roles && err == nil; j++ {
+			u.Roles = append(u.Roles, str())
+		}
+		c.Users = append(c.Users, u)
+	}
+	get(&count)
+	for i := uint32(0); i < count && err == nil; i++ {
+		g := Grant{Grantee: str(), Privilege: Privilege(str())}
+		g.On = Object{Database: str(), Table: str(), Column: str()}
+		get(&g.GrantOption)
+		c.Grants = append(c.Grants, g)
+	}
+	return err
+}
@@ -0,0 +1,226 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// aggregator accumulates the values of one aggregate call over a group of rows
+type aggregator interface {
+	add(value interface{}) error
+	result() (interface{}, error)
+}
+
+func isAggregate(name string) bool {
+	switch name {
+	case "COUNT", "SUM", "AVG", "MIN", "MAX":
+		return true
+	}
+	return false
+}
+
+// findAggregates returns the aggregate calls contained in the expressions, outermost first
+func findAggregates(exprs ...parser.Expr) []*parser.FuncCall {
+	var calls []*parser.FuncCall
+	for _, expr := range exprs {
+		parser.Walk(expr, func(e parser.Expr) bool {
+			if call, ok := e.(*parser.FuncCall); ok && isAggregate(call.Name) {
+				calls = append(calls, call)
+				return false
+			}
+			return true
+		})
+	}
+	return calls
+}
+
+func newAggregator(call *parser.FuncCall) (aggregator, error) {
+	if call.Star && call.Name != "COUNT" {
+		return nil, fmt.Errorf("%s(*) is not supported", call.Name)
+	}
+	if !call.Star && len(call.Args) != 1 {
+		return nil, fmt.Errorf("aggregate function %s takes exactly one argument", call.Name)
+	}
+	var agg aggregator
+	switch call.Name {
+	case "COUNT":
+		agg = &countAggregator{star: call.Star}
+	case "SUM":
+		agg = &sumAggregator{}
+	case "AVG":
+		agg = &avgAggregator{}
+	case "MIN":
+		agg = &extremeAggregator{sign: -1}
+	case "MAX":
+		agg = &extremeAggregator{sign: 1}
+	}
+	if call.Distinct {
+		agg = &distinctAggregator{inner: agg, seen: map[string]bool{}}
+	}
+	return agg, nil
+}
+
+type countAggregator struct {
+	star  bool
+	count int64
+}
+
+func (a *countAggregator) add(value interface{}) error {
+	if a.star || value != nil {
+		a.count++
+	}
+	return nil
+}
+
+func (a *countAggregator) result() (interface{}, error) {
+	return a.count, nil
+}
+
+// sumAggregator sums exactly: integers stay integers (promoting to decimal on overflow), decimals stay decimals
+type sumAggregator struct {
+	sum interface{}
+}
+
+func (a *sumAggregator) add(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	if a.sum == nil {
+		n, _, err := toNumber(value)
+		if err != nil {
+			return err
+		}
+		a.sum = n
+		return nil
+	}
+	sum, err := arithmetic("+", a.sum, value)
+	if err != nil {
+		return err
+	}
+	a.sum = sum
+	return nil
+}
+
+func (a *sumAggregator) result() (interface{}, error) {
+	return a.sum, nil
+}
+
+// avgAggregator averages exactly for integers and decimals, adding DivisionScaleIncrement digits of scale
+type avgAggregator struct {
+	sum   sumAggregator
+	count int64
+}
+
+func (a *avgAggregator) add(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	a.count++
+	return a.sum.add(value)
+}
+
+func (a *avgAggregator) result() (interface{}, error) {
+	if a.count == 0 {
+		return nil, nil
+	}
+	switch sum := a.sum.sum.(type) {
+	case float64:
+		return sum / float64(a.count), nil
+	case int64:
+		return types.NewDecimal(sum, 0).Div(types.NewDecimal(a.count, 0))
+	case types.Decimal:
+		return sum.Div(types.NewDecimal(a.count, 0))
+	}
+	return nil, fmt.Errorf("cannot average %v", a.sum.sum)
+}
+
+// extremeAggregator implements MIN (sign -1) and MAX (sign 1)
+type extremeAggregator struct {
+	sign int
+	best interface{}
+}
+
+func (a *extremeAggregator) add(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	if a.best == nil {
+		a.best = value
+		return nil
+	}
+	c, err := compareValues(value, a.best)
+	if err != nil {
+		return err
+	}
+	if c*a.sign > 0 {
+		a.best = value
+	}
+	return nil
+}
+
+func (a *extremeAggregator) result() (interface{}, error) {
+	return a.best, nil
+}
+
+// distinctAggregator forwards each distinct non-null value once
+type distinctAggregator struct {
+	inner aggregator
+	seen  map[string]bool
+}
+
+func (a *distinctAggregator) add(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	key := groupKey([]interface{}{value})
+	if a.seen[key] {
+		return nil
+	}
+	a.seen[key] = true
+	return a.inner.add(value)
+}
+
+func (a *distinctAggregator) result() (interface{}, error) {
+	return a.inner.result()
+}
+
+// groupKey builds a map key that is equal for rows with equal values (numbers compare by value)
+func groupKey(values []interface{}) string {
+	key := make([]byte, 0, 16*len(values))
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+			key = append(key, 'N')
+		case string:
+			key = append(key, 'S')
+			key = append(key, v...)
+		default:
+			if n, kind, err := toNumber(v); err == nil {
+				// normalise numbers so that 1, 1.0 and 1.00 group together
+				if kind == kindFloat {
+					key = append(key, 'F')
+					key = append(key, types.FormatValue(n)...)
+				} else {
+					d := asDecimal(n)
+					key = append(key, 'D')
+					key = append(key, normalizedDecimal(d)...)
+				}
+			} else {
+				key = append(key, 'V')
+				key = append(key, types.FormatValue(v)...)
+			}
+		}
+		key = append(key, 0)
+	}
+	return string(key)
+}
+
+// normalizedDecimal formats a decimal without trailing fractional zeros
+func normalizedDecimal(d types.Decimal) string {
+	for d.Scale() > 0 && d.Truncate(d.Scale()-1).Cmp(d) == 0 {
+		d = d.Truncate(d.Scale() - 1)
+	}
+	return d.String()
+}
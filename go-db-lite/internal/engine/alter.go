@@ -0,0 +1,248 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// AlterTable executes an ALTER TABLE statement. Rows are rewritten for the new columns and
+// checked against every constraint of the table; on error the database must be discarded.
+// In strict mode values that do not fit an altered column type are rejected rather than truncated.
+func AlterTable(db *types.Database, stmt *parser.AlterTableStmt, strict bool) error {
+	table := FindTable(db, stmt.Table)
+	if table == nil {
+		return fmt.Errorf("table not found: %s", stmt.Table)
+	}
+	if err := db.CheckWritable(table); err != nil {
+		return err
+	}
+	attachValidator(db, strict)
+
+	if stmt.Action == "ADD COLUMN" {
+		return addColumn(db, table, stmt.Column, strict)
+	}
+	if stmt.Action == "RENAME TO" {
+		return db.RenameTable(table, strings.ToLower(stmt.NewName))
+	}
+	column := table.ColumnIndex(stmt.Name)
+	if column == -1 {
+		return fmt.Errorf("column %s not found in table %s", stmt.Name, stmt.Table)
+	}
+	switch stmt.Action {
+	case "DROP COLUMN":
+		return dropColumn(db, table, column)
+	case "RENAME COLUMN":
+		return renameColumn(db, table, column, strings.ToLower(stmt.NewName))
+	case "ALTER COLUMN TYPE":
+		return alterColumnType(db, table, column, stmt.Type, strict)
+	}
+	return fmt.Errorf("unsupported ALTER TABLE action %s", stmt.Action)
+}
+
+// addColumn appends a column to a table. Existing rows take the default of the column, or
+// the next values of the table counter for an identity column.
+func addColumn(db *types.Database, table *types.Table, def parser.ColumnDef, strict bool) error {
+	def.Name = strings.ToLower(def.Name)
+	if table.ColumnIndex(def.Name) != -1 {
+		return fmt.Errorf("column %s already exists in table %s", def.Name, table.GetName())
+	}
+	column, err := ColumnFromDef(def)
+	if err != nil {
+		return err
+	}
+	if column.Identity != types.IdentityNone && table.IdentityColumn() != -1 {
+		return fmt.Errorf("table %s already has an identity column", table.GetName())
+	}
+	if !column.Nullable && column.Default == "" && column.Identity == types.IdentityNone && len(table.Rows) > 0 {
+		return fmt.Errorf("cannot add NOT NULL column %s without a DEFAULT to table %s, which has rows", def.Name, table.GetName())
+	}
+	if def.PrimaryKey && len(table.PrimaryKey) > 0 {
+		return fmt.Errorf("table %s already has a %s", table.GetName(), table.PrimaryKeyString())
+	}
+
+	// defaults are evaluated for every row, so that a NEXTVAL default numbers them
+	columns := append(append([]types.Column(nil), table.Columns...), column)
+	shadow := *table
+	shadow.Columns = columns
+	err = table.RewriteRows(columns, func(values []interface{}) ([]interface{}, error) {
+		if column.Identity != types.IdentityNone {
+			table.AutoIncrement++
+			return append(values, table.AutoIncrement), nil
+		}
+		value, err := db.DefaultValue(&shadow, len(columns)-1)
+		if err != nil {
+			return nil, err
+		}
+		return append(values, value), nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := addColumnConstraints(table, def); err != nil {
+		return err
+	}
+	if err := ValidateSchema(db, table, strict); err != nil {
+		return err
+	}
+	if err := db.ValidateForeignKeys(table); err != nil {
+		return err
+	}
+	return db.ValidateTable(table)
+}
+
+// dropColumn removes a column from a table. Columns used by a key or a CHECK constraint
+// must have the constraint removed first, and those of a table read by views the views.
+func dropColumn(db *types.Database, table *types.Table, column int) error {
+	name := table.Columns[column].GetName()
+	if err := db.CheckNotReadByViews(table, "drop column "+name+" of table "+table.GetName()); err != nil {
+		return err
+	}
+	for _, check := range table.Checks {
+		uses, err := usesColumn(check.Expr, name)
+		if err != nil {
+			return err
+		}
+		if uses {
+			return fmt.Errorf("cannot drop column %s, it is used by CHECK constraint %s", name, check.Name)
+		}
+	}
+	return table.DropColumn(column)
+}
+
+// renameColumn renames a column and every reference to it in CHECK constraints and foreign keys
+func renameColumn(db *types.Database, table *types.Table, column int, name string) error {
+	old := table.Columns[column].GetName()
+	if err := db.RenameColumn(table, column, name); err != nil {
+		return err
+	}
+	for i := range table.Checks {
+		expr, err := parser.ParseExpr(table.Checks[i].Expr)
+		if err != nil {
+			return err
+		}
+		parser.Walk(expr, func(e parser.Expr) bool {
+			if ref, ok := e.(*parser.ColumnRef); ok && strings.EqualFold(ref.Name, old) {
+				ref.Name = name
+			}
+			return true
+		})
+		table.Checks[i].Expr = ExprText(expr)
+	}
+	return nil
+}
+
+// alterColumnType changes the type of a column, converting the value of every row to it.
+// The columns of foreign keys must keep matching types, so their type cannot change, nor
+// can those of a table read by views. The DEFAULT of the column must convert to the new
+// type, and the CHECK constraints using it must still evaluate.
+func alterColumnType(db *types.Database, table *types.Table, column int, spec parser.TypeSpec, strict bool) error {
+	col := table.Columns[column]
+	name := col.GetName()
+	if err := db.CheckNotReadByViews(table, "change the type of column "+name+" of table "+table.GetName()); err != nil {
+		return err
+	}
+	for _, fk := range table.ForeignKeys {
+		for _, c := range fk.Columns {
+			if c == column {
+				return fmt.Errorf("cannot change the type of column %s, it is part of foreign key %s", name, fk.Name)
+			}
+		}
+	}
+	if refs := db.ReferencedBy(table, column); len(refs) > 0 {
+		return fmt.Errorf("cannot change the type of column %s, it is referenced by foreign key %s", name, refs[0])
+	}
+	if err := setColumnType(&col, spec); err != nil {
+		return err
+	}
+	if col.Identity != types.IdentityNone && col.DataType != types.SQL_TYPE_INT && col.DataType != types.SQL_TYPE_BIGINT {
+		return fmt.Errorf("column %s: only INT and BIGINT columns can be %s", name, col.Identity)
+	}
+
+	columns := append([]types.Column(nil), table.Columns...)
+	columns[column] = col
+	if err := checkColumnType(db, table, columns, column, strict); err != nil {
+		return err
+	}
+	err := table.RewriteRows(columns, func(values []interface{}) ([]interface{}, error) {
+		value, err := ConvertValue(values[column], col, strict)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %s of column %s to %s: %v", types.FormatValue(values[column]), name, col.TypeString(), err)
+		}
+		values[column] = value
+		return values, nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := ValidateSchema(db, table, strict); err != nil {
+		return err
+	}
+	return db.ValidateTable(table)
+}
+
+// checkColumnType checks the DEFAULT of a column about to take a new type, given in columns,
+// and evaluates the CHECK constraints using the column on a value of the new type, so that
+// a constraint comparing it with a value of the old type is found before any row is changed
+func checkColumnType(db *types.Database, table *types.Table, columns []types.Column, column int, strict bool) error {
+	col := columns[column]
+	name := col.GetName()
+	trial := *table
+	trial.Columns = columns
+	v := &rowValidator{db: db, strict: strict, exprs: make(map[string]parser.Expr)}
+	if col.Default != "" {
+		if _, err := v.DefaultValue(&trial, column); err != nil {
+			return fmt.Errorf("cannot change the type of column %s to %s: %v", name, col.TypeString(), err)
+		}
+	}
+	values := make([]interface{}, len(columns))
+	values[column] = sampleValue(col)
+	for i := range table.Checks {
+		uses, err := usesColumn(table.Checks[i].Expr, name)
+		if err != nil {
+			return err
+		}
+		if !uses {
+			continue
+		}
+		if _, err := v.Check(&trial, &table.Checks[i], values); err != nil {
+			return fmt.Errorf("cannot change the type of column %s to %s, CHECK constraint %s does not hold for it: %v",
+				name, col.TypeString(), table.Checks[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// sampleValue returns a value of the type of a column, for evaluating expressions using it:
+// the empty string for text, so that it does not pass for a number, or else the first of
+// a few values that converts to the type
+func sampleValue(col types.Column) interface{} {
+	candidates := []string{"", "0", "1970-01-01", "00:00:00", "2000", "false"}
+	if len(col.Labels) > 0 {
+		candidates = append([]string{col.Labels[0]}, candidates...)
+	}
+	for _, text := range candidates {
+		if value, err := ConvertValue(text, col, false); err == nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// usesColumn reports whether a stored SQL expression refers to the named column
+func usesColumn(sql string, name string) (bool, error) {
+	expr, err := parser.ParseExpr(sql)
+	if err != nil {
+		return false, err
+	}
+	uses := false
+	parser.Walk(expr, func(e parser.Expr) bool {
+		if ref, ok := e.(*parser.ColumnRef); ok && strings.EqualFold(ref.Name, name) {
+			uses = true
+		}
+		return !uses
+	})
+	return uses, nil
+}
@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+)
+
+// Test that ALTER TABLE rewrites existing rows, keeps CHECK constraints in step with renamed
+// columns and changes the type of a column only where its DEFAULT and CHECK constraints allow
+func TestAlterTable(t *testing.T) {
+	db := newTestDatabase(t)
+	alter := func(query string) error {
+		stmt, err := parser.ParseAlterTable(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return AlterTable(db, stmt, true)
+	}
+	FindTable(db, "ledger").AddCheck("", "amount <> 1")
+
+	if err := alter("ALTER TABLE ledger ADD COLUMN note VARCHAR(5) DEFAULT 'n/a'"); err != nil {
+		t.Fatal(err)
+	}
+	if err := alter("ALTER TABLE ledger ADD COLUMN required INT NOT NULL"); err == nil {
+		t.Error("adding a NOT NULL column without a default to a non-empty table should fail")
+	}
+	if err := alter("ALTER TABLE ledger DROP COLUMN amount"); err == nil {
+		t.Error("dropping a column used by a CHECK constraint should fail")
+	}
+	if err := alter("ALTER TABLE ledger RENAME COLUMN amount TO total"); err != nil {
+		t.Fatal(err)
+	}
+	if check := FindTable(db, "ledger").Checks[0].Expr; check != "total <> 1" {
+		t.Errorf("CHECK after rename = %q, want %q", check, "total <> 1")
+	}
+	if err := alter("ALTER TABLE ledger ALTER COLUMN account TYPE INT"); err == nil {
+		t.Error("changing the type of a column whose values do not convert should fail")
+	}
+	if err := alter("ALTER TABLE ledger ALTER COLUMN total TYPE VARCHAR(10)"); err == nil || !strings.Contains(err.Error(), "CHECK") {
+		t.Errorf("changing the type of a column its CHECK constraint compares with a number to VARCHAR: got %v, want a CHECK error", err)
+	}
+	if err := alter("ALTER TABLE ledger ALTER COLUMN note TYPE INT"); err == nil || !strings.Contains(err.Error(), "DEFAULT") {
+		t.Errorf("changing the type of a column whose DEFAULT does not convert: got %v, want a DEFAULT error", err)
+	}
+	if err := alter("ALTER TABLE ledger ALTER COLUMN total TYPE DECIMAL(8,2)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := alter("ALTER TABLE ledger DROP COLUMN account"); err != nil {
+		t.Fatal(err)
+	}
+	if err := alter("ALTER TABLE ledger RENAME TO book"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := runQuery(t, db, "SELECT * FROM book")
+	want := "[[0.10 n/a] [0.20 n/a] [19.99 n/a] [NULL n/a]]"
+	if fmt.Sprint(got) != want {
+		t.Errorf("rows after ALTER TABLE = %v, want %s", got, want)
+	}
+}
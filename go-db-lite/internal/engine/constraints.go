@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// rowValidator evaluates the DEFAULT and CHECK expressions of table schemas for a Database
+type rowValidator struct {
+	db     *types.Database
+	strict bool
+	exprs  map[string]parser.Expr // parsed expressions by SQL text
+}
+
+// UseValidator attaches the expression evaluator to the database, so that writes through it
+// fill in column defaults, enforce CHECK constraints and run triggers. In strict mode
+// over-length default strings are rejected rather than truncated.
+func UseValidator(db *types.Database, strict bool) {
+	db.Validator = &rowValidator{db: db, strict: strict, exprs: make(map[string]parser.Expr)}
+	db.Maintainer = &viewMaintainer{db: db, queries: make(map[string]*parser.SelectStmt)}
+	db.Executor = &triggerExecutor{db: db, strict: strict, bodies: make(map[string][]parser.Statement)}
+}
+
+// attachValidator attaches a validator to the database unless it already has one
+func attachValidator(db *types.Database, strict bool) {
+	if db.Validator == nil {
+		UseValidator(db, strict)
+	}
+}
+
+func (v *rowValidator) parse(sql string) (parser.Expr, error) {
+	if expr, ok := v.exprs[sql]; ok {
+		return expr, nil
+	}
+	expr, err := parser.ParseExpr(sql)
+	if err != nil {
+		return nil, err
+	}
+	v.exprs[sql] = expr
+	return expr, nil
+}
+
+func (v *rowValidator) DefaultValue(t *types.Table, column int) (interface{}, error) {
+	col := t.Columns[column]
+	expr, err := v.parse(col.Default)
+	if err != nil {
+		return nil, err
+	}
+	value, err := Eval(expr, &Scope{db: v.db})
+	if err == nil {
+		value, err = ConvertValue(value, col, v.strict)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid DEFAULT for column %s: %v", col.GetName(), err)
+	}
+	return value, nil
+}
+
+func (v *rowValidator) Check(t *types.Table, check *types.CheckConstraint, values []interface{}) (bool, error) {
+	expr, err := v.parse(check.Expr)
+	if err != nil {
+		return false, err
+	}
+	value, err := Eval(expr, tableScope(t).WithValues(values))
+	if err != nil {
+		return false, err
+	}
+	ok, err := truthy(value)
+	if err != nil {
+		return false, err
+	}
+	return ok != false, nil // unknown passes
+}
+
+// ValidateSchema checks the DEFAULT and CHECK expressions of a new table of the database:
+// every default must evaluate without referring to columns and convert to its column type,
+// and every CHECK condition may only refer to columns of the table. Sequences used by
+// defaults are not advanced.
+func ValidateSchema(db *types.Database, t *types.Table, strict bool) error {
+	trial := *db
+	trial.Sequences = append([]types.Sequence(nil), db.Sequences...)
+	v := &rowValidator{db: &trial, strict: strict, exprs: make(map[string]parser.Expr)}
+	for i := range t.Columns {
+		if t.Columns[i].Default == "" {
+			continue
+		}
+		if _, err := v.DefaultValue(t, i); err != nil {
+			return err
+		}
+	}
+	nulls := make([]interface{}, len(t.Columns))
+	for i := range t.Checks {
+		if _, err := v.Check(t, &t.Checks[i], nulls); err != nil {
+			return fmt.Errorf("CHECK constraint %s: %v", t.Checks[i].Name, err)
+		}
+	}
+	return nil
+}
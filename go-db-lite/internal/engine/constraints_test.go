@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+)
+
+// Test that defaults, NOT NULL and CHECK constraints apply alike to INSERT, UPDATE and CSV import
+func TestConstraints(t *testing.T) {
+	db := newTestDatabase(t)
+	ledger := FindTable(db, "ledger")
+	ledger.Columns[0].Default = "'cash'"
+	ledger.AddCheck("positive", "amount > 0")
+	if err := ValidateSchema(db, ledger, true); err != nil {
+		t.Fatal(err)
+	}
+
+	insert := func(query string) error {
+		stmt, err := parser.ParseInsert(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Insert(db, stmt, true)
+		return err
+	}
+	if err := insert("INSERT INTO ledger (amount) VALUES (5)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := insert("INSERT INTO ledger (amount) VALUES (-5)"); err == nil {
+		t.Error("INSERT violating the CHECK constraint should fail")
+	}
+	if err := insert("INSERT INTO ledger (account) VALUES (NULL)"); err == nil {
+		t.Error("INSERT of NULL into a NOT NULL column should fail")
+	}
+
+	update, err := parser.ParseUpdate("UPDATE ledger SET amount = amount - 1 WHERE account = 'a'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Update(db, update, true); err == nil {
+		t.Error("UPDATE violating the CHECK constraint should fail")
+	}
+
+	if _, err := ImportCSV(db, "ledger", strings.NewReader("amount\n7\n\\N\n"), true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ImportCSV(db, "ledger", strings.NewReader("account,amount\nc,-1\n"), true); err == nil {
+		t.Error("import violating the CHECK constraint should fail")
+	}
+
+	got := runQuery(t, db, "SELECT account, amount FROM ledger WHERE account = 'cash' ORDER BY amount")
+	want := [][]string{{"cash", "NULL"}, {"cash", "5.00"}, {"cash", "7.00"}}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i][0] != want[i][0] || got[i][1] != want[i][1] {
+			t.Errorf("row %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,442 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+func init() {
+	for name, fn := range map[string]scalarFunc{
+		"NOW":               fnNow,
+		"CURRENT_TIMESTAMP": fnNow,
+		"LOCALTIMESTAMP":    fnLocalTimestamp,
+		"CURRENT_DATE":      fnCurrentDate,
+		"CURDATE":           fnCurrentDate,
+		"CURRENT_TIME":      fnCurrentTime,
+		"CURTIME":           fnCurrentTime,
+		"DATE":              temporalCast(types.SQL_TYPE_DATE),
+		"DATETIME":          temporalCast(types.SQL_TYPE_DATETIME),
+		"TIMESTAMP":         temporalCast(types.SQL_TYPE_TIMESTAMP),
+		"TIME":              fnTime,
+		"DATE_ADD":          fnDateAdd,
+		"DATE_SUB":          fnDateSub,
+		"DATE_TRUNC":        fnDateTrunc,
+		"EXTRACT":           fnExtract,
+		"STRFTIME":          fnStrftime,
+	} {
+		scalarFunctions[name] = fn
+	}
+}
+
+// evalInterval evaluates INTERVAL n unit
+func evalInterval(value interface{}, unit string) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	n, kind, err := toNumber(value)
+	if err != nil {
+		return nil, err
+	}
+	if kind != kindInt {
+		return nil, fmt.Errorf("INTERVAL expects a whole number of %s", strings.ToLower(unit))
+	}
+	return types.NewInterval(n.(int64), unit)
+}
+
+// temporalArithmetic handles date/time +/- INTERVAL; ok is false if neither operand is temporal
+func temporalArithmetic(op string, a, b interface{}) (interface{}, bool, error) {
+	if interval, ok := a.(types.Interval); ok && op == "+" {
+		// INTERVAL n unit + date
+		a, b = b, interval
+	}
+	interval, isInterval := b.(types.Interval)
+	if isInterval && (op == "+" || op == "-") {
+		if op == "-" {
+			interval = interval.Negate()
+		}
+		switch v := a.(type) {
+		case types.DateTime:
+			return v.AddInterval(interval), true, nil
+		case types.TimeOfDay:
+			if interval.Months != 0 {
+				return nil, true, fmt.Errorf("cannot add a month or year INTERVAL to a TIME")
+			}
+			return types.TimeOfDay(v.Duration() + time.Duration(interval.Micros)*time.Microsecond), true, nil
+		}
+	}
+	for _, v := range []interface{}{a, b} {
+		switch v.(type) {
+		case types.Interval:
+			return nil, true, fmt.Errorf("an INTERVAL can only be added to or subtracted from a date or time")
+		case types.DateTime, types.TimeOfDay:
+			return nil, true, fmt.Errorf("operator %s is not supported for dates and times; use INTERVAL arithmetic", op)
+		}
+	}
+	return nil, false, nil
+}
+
+// compareTemporal compares a date/time value with another value; strings are parsed as the same kind.
+// A DATE compared with a date-time is taken as midnight of its day. ok is false if neither
+// operand is temporal.
+func compareTemporal(a, b interface{}) (int, bool, error) {
+	switch x := a.(type) {
+	case types.DateTime:
+		kind := x.Kind()
+		if other, ok := b.(types.DateTime); ok && kind == types.SQL_TYPE_DATE {
+			kind = other.Kind()
+		}
+		x, err := toDateTime(x, kind)
+		if err != nil {
+			return 0, true, err
+		}
+		y, err := toDateTime(b, kind)
+		if err != nil {
+			return 0, true, err
+		}
+		return x.Compare(y), true, nil
+	case types.TimeOfDay:
+		y, err := toTimeOfDay(b)
+		if err != nil {
+			return 0, true, err
+		}
+		switch {
+		case x < y:
+			return -1, true, nil
+		case x > y:
+			return 1, true, nil
+		}
+		return 0, true, nil
+	}
+	switch b.(type) {
+	case types.DateTime, types.TimeOfDay:
+		c, ok, err := compareTemporal(b, a)
+		return -c, ok, err
+	}
+	return 0, false, nil
+}
+
+// toDateTime converts a value to a date/time of the given kind
+func toDateTime(value interface{}, kind types.DataType) (types.DateTime, error) {
+	switch v := value.(type) {
+	case types.DateTime:
+		if v.Kind() == kind {
+			return v, nil
+		}
+		if kind == types.SQL_TYPE_TIMESTAMP || v.Kind() == types.SQL_TYPE_TIMESTAMP {
+			// convert through the session wall clock
+			return types.NewDateTime(time.Date(v.Wall().Year(), v.Wall().Month(), v.Wall().Day(), v.Wall().Hour(),
+				v.Wall().Minute(), v.Wall().Second(), v.Wall().Nanosecond(), types.SessionLocation()), kind), nil
+		}
+		return types.NewDateTime(v.Wall(), kind), nil
+	case string:
+		if kind == types.SQL_TYPE_DATE {
+			// a date may be compared with a date-time string; keep the day
+			if dt, err := types.ParseDateTime(v, types.SQL_TYPE_DATE); err == nil {
+				return dt, nil
+			}
+			dt, err := types.ParseDateTime(v, types.SQL_TYPE_DATETIME)
+			if err != nil {
+				return types.DateTime{}, err
+			}
+			return types.NewDateTime(dt.Wall(), types.SQL_TYPE_DATETIME), nil
+		}
+		return types.ParseDateTime(v, kind)
+	}
+	return types.DateTime{}, fmt.Errorf("cannot use %s as a %s", QuoteValue(value), kind.GetDataTypeString())
+}
+
+// toTimeOfDay converts a value to a TIME
+func toTimeOfDay(value interface{}) (types.TimeOfDay, error) {
+	switch v := value.(type) {
+	case types.TimeOfDay:
+		return v, nil
+	case types.DateTime:
+		wall := v.Wall()
+		return types.TimeOfDay(wall.Sub(wall.Truncate(24 * time.Hour))), nil
+	case string:
+		return types.ParseTimeOfDay(v)
+	}
+	return 0, fmt.Errorf("cannot use %s as a TIME", QuoteValue(value))
+}
+
+// toAnyDateTime converts a value to a date/time, guessing the kind of strings from their shape
+func toAnyDateTime(value interface{}) (types.DateTime, error) {
+	switch v := value.(type) {
+	case types.DateTime:
+		return v, nil
+	case string:
+		if strings.EqualFold(v, "now") {
+			return types.NewDateTime(time.Now(), types.SQL_TYPE_TIMESTAMP), nil
+		}
+		if dt, err := types.ParseDateTime(v, types.SQL_TYPE_DATE); err == nil {
+			return dt, nil
+		}
+		return types.ParseDateTime(v, types.SQL_TYPE_DATETIME)
+	}
+	return types.DateTime{}, fmt.Errorf("cannot use %s as a date", QuoteValue(value))
+}
+
+func fnNow(args []interface{}) (interface{}, error) {
+	if err := checkArgs("NOW", args, 0, 0); err != nil {
+		return nil, err
+	}
+	return types.NewDateTime(time.Now(), types.SQL_TYPE_TIMESTAMP), nil
+}
+
+func fnLocalTimestamp(args []interface{}) (interface{}, error) {
+	if err := checkArgs("LOCALTIMESTAMP", args, 0, 0); err != nil {
+		return nil, err
+	}
+	return types.NewDateTime(time.Now().In(types.SessionLocation()), types.SQL_TYPE_DATETIME), nil
+}
+
+func fnCurrentDate(args []interface{}) (interface{}, error) {
+	if err := checkArgs("CURRENT_DATE", args, 0, 0); err != nil {
+		return nil, err
+	}
+	return types.NewDateTime(time.Now().In(types.SessionLocation()), types.SQL_TYPE_DATE), nil
+}
+
+func fnCurrentTime(args []interface{}) (interface{}, error) {
+	if err := checkArgs("CURRENT_TIME", args, 0, 0); err != nil {
+		return nil, err
+	}
+	now := time.Now().In(types.SessionLocation())
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return types.TimeOfDay(now.Sub(midnight).Truncate(time.Second)), nil
+}
+
+// temporalCast implements DATE(x), DATETIME(x) and TIMESTAMP(x)
+func temporalCast(kind types.DataType) scalarFunc {
+	return func(args []interface{}) (interface{}, error) {
+		if err := checkArgs(kind.GetDataTypeString(), args, 1, 1); err != nil || args[0] == nil {
+			return nil, err
+		}
+		if s, ok := args[0].(string); ok && kind == types.SQL_TYPE_DATE {
+			// DATE('2024-01-31 10:00') keeps the day
+			dt, err := toAnyDateTime(s)
+			if err != nil {
+				return nil, err
+			}
+			return types.NewDateTime(dt.Wall(), kind), nil
+		}
+		return toDateTime(args[0], kind)
+	}
+}
+
+func fnTime(args []interface{}) (interface{}, error) {
+	if err := checkArgs("TIME", args, 1, 1); err != nil || args[0] == nil {
+		return nil, err
+	}
+	if s, ok := args[0].(string); ok {
+		if dt, err := toAnyDateTime(s); err == nil {
+			return toTimeOfDay(dt)
+		}
+	}
+	return toTimeOfDay(args[0])
+}
+
+func fnDateAdd(args []interface{}) (interface{}, error) {
+	return dateAdd("DATE_ADD", args, false)
+}
+
+func fnDateSub(args []interface{}) (interface{}, error) {
+	return dateAdd("DATE_SUB", args, true)
+}
+
+// dateAdd implements DATE_ADD(date, INTERVAL n unit) and DATE_SUB
+func dateAdd(name string, args []interface{}, subtract bool) (interface{}, error) {
+	if err := checkArgs(name, args, 2, 2); err != nil {
+		return nil, err
+	}
+	if args[0] == nil || args[1] == nil {
+		return nil, nil
+	}
+	interval, ok := args[1].(types.Interval)
+	if !ok {
+		// DATE_ADD(date, n) adds days
+		n, kind, err := toNumber(args[1])
+		if err != nil || kind != kindInt {
+			return nil, fmt.Errorf("%s expects an INTERVAL", name)
+		}
+		interval, _ = types.NewInterval(n.(int64), "DAY")
+	}
+	if subtract {
+		interval = interval.Negate()
+	}
+	value := args[0]
+	if s, ok := value.(string); ok {
+		dt, err := toAnyDateTime(s)
+		if err != nil {
+			return nil, err
+		}
+		value = dt
+	}
+	result, ok, err := temporalArithmetic("+", value, interval)
+	if !ok {
+		return nil, fmt.Errorf("%s expects a date or time, got %s", name, QuoteValue(value))
+	}
+	return result, err
+}
+
+// fnDateTrunc implements DATE_TRUNC('unit', date): year, quarter, month, week (starting Monday), day, hour, minute, second
+func fnDateTrunc(args []interface{}) (interface{}, error) {
+	if err := checkArgs("DATE_TRUNC", args, 2, 2); err != nil {
+		return nil, err
+	}
+	if args[0] == nil || args[1] == nil {
+		return nil, nil
+	}
+	dt, err := toAnyDateTime(args[1])
+	if err != nil {
+		return nil, err
+	}
+	wall := dt.Wall()
+	year, month, day := wall.Date()
+	var truncated time.Time
+	switch unit := strings.ToUpper(valueToString(args[0])); unit {
+	case "YEAR":
+		truncated = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	case "QUARTER":
+		truncated = time.Date(year, (month-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+	case "MONTH":
+		truncated = time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	case "WEEK":
+		offset := (int(wall.Weekday()) + 6) % 7 // days since Monday
+		truncated = time.Date(year, month, day-offset, 0, 0, 0, 0, time.UTC)
+	case "DAY":
+		truncated = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	case "HOUR":
+		truncated = wall.Truncate(time.Hour)
+	case "MINUTE":
+		truncated = wall.Truncate(time.Minute)
+	case "SECOND":
+		truncated = wall.Truncate(time.Second)
+	default:
+		return nil, fmt.Errorf("DATE_TRUNC: unknown unit %s", unit)
+	}
+	return dt.WithWall(truncated), nil
+}
+
+// fnExtract implements EXTRACT(field FROM value)
+func fnExtract(args []interface{}) (interface{}, error) {
+	if err := checkArgs("EXTRACT", args, 2, 2); err != nil || args[1] == nil {
+		return nil, err
+	}
+	field := strings.ToUpper(valueToString(args[0]))
+
+	if tod, ok := args[1].(types.TimeOfDay); ok {
+		d := tod.Duration()
+		switch field {
+		case "HOUR":
+			return int64(d / time.Hour), nil
+		case "MINUTE":
+			return int64(d % time.Hour / time.Minute), nil
+		case "SECOND":
+			return int64(d % time.Minute / time.Second), nil
+		case "MICROSECOND":
+			return int64(d % time.Second / time.Microsecond), nil
+		case "EPOCH":
+			return int64(d / time.Second), nil
+		}
+		return nil, fmt.Errorf("EXTRACT: field %s is not valid for TIME", field)
+	}
+
+	dt, err := toAnyDateTime(args[1])
+	if err != nil {
+		return nil, err
+	}
+	wall := dt.Wall()
+	switch field {
+	case "YEAR":
+		return int64(wall.Year()), nil
+	case "QUARTER":
+		return int64((wall.Month()-1)/3 + 1), nil
+	case "MONTH":
+		return int64(wall.Month()), nil
+	case "WEEK":
+		_, week := wall.ISOWeek()
+		return int64(week), nil
+	case "DAY":
+		return int64(wall.Day()), nil
+	case "DOW":
+		return int64(wall.Weekday()), nil
+	case "DOY":
+		return int64(wall.YearDay()), nil
+	case "HOUR":
+		return int64(wall.Hour()), nil
+	case "MINUTE":
+		return int64(wall.Minute()), nil
+	case "SECOND":
+		return int64(wall.Second()), nil
+	case "MICROSECOND":
+		return int64(wall.Nanosecond() / 1000), nil
+	case "EPOCH":
+		if dt.Kind() == types.SQL_TYPE_TIMESTAMP {
+			return dt.UnixMicro() / 1e6, nil
+		}
+		return wall.Unix(), nil
+	}
+	return nil, fmt.Errorf("EXTRACT: unknown field %s", field)
+}
+
+// fnStrftime implements STRFTIME(format, value) with the SQLite conversions
+// %Y %m %d %H %M %S %f %j %w %W %s %z and %%
+func fnStrftime(args []interface{}) (interface{}, error) {
+	if err := checkArgs("STRFTIME", args, 2, 2); err != nil {
+		return nil, err
+	}
+	if args[0] == nil || args[1] == nil {
+		return nil, nil
+	}
+	dt, err := toAnyDateTime(args[1])
+	if err != nil {
+		return nil, err
+	}
+	t := dt.Time()
+	format := valueToString(args[0])
+	var sb strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			sb.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch format[i] {
+		case 'Y':
+			fmt.Fprintf(&sb, "%04d", t.Year())
+		case 'm':
+			fmt.Fprintf(&sb, "%02d", int(t.Month()))
+		case 'd':
+			fmt.Fprintf(&sb, "%02d", t.Day())
+		case 'H':
+			fmt.Fprintf(&sb, "%02d", t.Hour())
+		case 'M':
+			fmt.Fprintf(&sb, "%02d", t.Minute())
+		case 'S':
+			fmt.Fprintf(&sb, "%02d", t.Second())
+		case 'f':
+			fmt.Fprintf(&sb, "%02d.%03d", t.Second(), t.Nanosecond()/1e6)
+		case 'j':
+			fmt.Fprintf(&sb, "%03d", t.YearDay())
+		case 'w':
+			sb.WriteString(strconv.Itoa(int(t.Weekday())))
+		case 'W':
+			_, week := t.ISOWeek()
+			fmt.Fprintf(&sb, "%02d", week)
+		case 's':
+			sb.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'z':
+			sb.WriteString(t.Format("-07:00"))
+		case '%':
+			sb.WriteByte('%')
+		default:
+			return nil, fmt.Errorf("STRFTIME: unknown conversion %%%c", format[i])
+		}
+	}
+	return sb.String(), nil
+}
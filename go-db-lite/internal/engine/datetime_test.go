@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// Test that a DATE compared with a DATETIME or TIMESTAMP is taken as midnight of its day,
+// whichever side of the comparison it is on
+func TestCompareDates(t *testing.T) {
+	loc, err := types.LoadLocation("+05:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	types.SetSessionLocation(loc)
+	defer types.SetSessionLocation(time.Local)
+	db := newTestDatabase(t)
+
+	d, dt := "date('2024-01-31')", "datetime('2024-01-31 10:00')"
+	query := "select " + d + " = " + dt + ", " + dt + " = " + d + ", " +
+		d + " < " + dt + ", " + dt + " > " + d + ", " +
+		d + " >= " + dt + ", " + dt + " <= " + d
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"false", "false", "true", "true", "false", "false"}})
+
+	midnight, ts := "datetime('2024-01-31 00:00')", "timestamp('2024-01-31 00:00:00+05:30')"
+	query = "select " + d + " = " + midnight + ", " + midnight + " = " + d + ", " +
+		d + " = " + ts + ", " + ts + " = " + d + ", " + ts + " = " + midnight
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"true", "true", "true", "true", "true"}})
+}
+
+// Test EXTRACT, DATE_TRUNC and STRFTIME, and that TIMESTAMP values are shown and taken
+// apart in the session time zone
+func TestDateFunctions(t *testing.T) {
+	loc, err := types.LoadLocation("+05:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	types.SetSessionLocation(loc)
+	defer types.SetSessionLocation(time.Local)
+	db := newTestDatabase(t)
+
+	query := "select extract(year from date '2024-05-02'), extract(quarter from date '2024-05-02'), " +
+		"extract(dow from date '2024-05-02'), extract(doy from date '2024-05-02'), " +
+		"extract(minute from time '10:15:30'), extract(epoch from datetime('1970-01-02 00:00'))"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"2024", "2", "4", "123", "15", "86400"}})
+
+	query = "select date_trunc('week', '2024-05-02 13:45'), date_trunc('quarter', date '2024-05-02'), " +
+		"date_trunc('hour', datetime('2024-05-02 13:45:10'))"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"2024-04-29 00:00:00", "2024-04-01", "2024-05-02 13:00:00"}})
+
+	query = "select strftime('%Y/%m/%d %j %H:%M:%S %%', '2024-02-03 04:05:06')"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"2024/02/03 034 04:05:06 %"}})
+
+	ts := "timestamp('2024-01-31 23:30:00+00:00')"
+	query = "select " + ts + ", extract(day from " + ts + "), extract(hour from " + ts + "), " +
+		"extract(epoch from " + ts + "), strftime('%Y-%m-%d %H:%M', " + ts + ")"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"2024-02-01 05:00:00+05:30", "1", "5", "1706743800", "2024-02-01 05:00"}})
+}
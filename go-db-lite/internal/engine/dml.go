@@ -0,0 +1,215 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// Insert executes an INSERT statement and returns the number of rows inserted. Columns
+// without a value take their default. In strict mode over-length strings are rejected
+// rather than truncated.
+func Insert(db *types.Database, stmt *parser.InsertStmt, strict bool) (int, error) {
+	table := FindTable(db, stmt.Table)
+	if table == nil {
+		return 0, fmt.Errorf("table not found: %s", stmt.Table)
+	}
+	attachValidator(db, strict)
+
+	// without a column list the values are given in table order
+	tableColumns := table.GetColumnNames()
+	columnNames := stmt.Columns
+	if len(columnNames) == 0 {
+		columnNames = tableColumns
+	}
+	for _, col := range columnNames {
+		if table.ColumnIndex(col) == -1 {
+			return 0, fmt.Errorf("column %s not found in table %s", col, stmt.Table)
+		}
+	}
+
+	scope := &Scope{db: db}
+	for _, values := range stmt.Rows {
+		if len(columnNames) != len(values) {
+			return 0, fmt.Errorf("number of columns does not match number of values")
+		}
+		rowData := make([]interface{}, len(table.Columns))
+		for i, col := range table.Columns {
+			colName := col.GetName()
+			valueIndex := indexOfColumn(columnNames, colName)
+			if valueIndex == -1 {
+				value, err := db.DefaultValue(table, i)
+				if err != nil {
+					return 0, err
+				}
+				rowData[i] = value
+				continue
+			}
+			if col.Identity == types.IdentityAlways {
+				return 0, fmt.Errorf("cannot insert into column %s, it is GENERATED ALWAYS AS IDENTITY", colName)
+			}
+			value, err := Eval(values[valueIndex], scope)
+			if err != nil {
+				return 0, fmt.Errorf("error evaluating value for column %s: %v", colName, err)
+			}
+			if rowData[i], err = ConvertValue(value, col, strict); err != nil {
+				return 0, fmt.Errorf("error converting value for column %s: %v", colName, err)
+			}
+		}
+		if err := db.InsertRow(table, rowData); err != nil {
+			return 0, err
+		}
+	}
+	return len(stmt.Rows), nil
+}
+
+// Update executes an UPDATE statement and returns the number of rows changed.
+// Assignments are evaluated against the values of the row before the update.
+func Update(db *types.Database, stmt *parser.UpdateStmt, strict bool) (int, error) {
+	table := FindTable(db, stmt.Table)
+	if table == nil {
+		return 0, fmt.Errorf("table not found: %s", stmt.Table)
+	}
+	attachValidator(db, strict)
+	targets := make([]int, len(stmt.Set))
+	for i, assignment := range stmt.Set {
+		if targets[i] = table.ColumnIndex(assignment.Column); targets[i] == -1 {
+			return 0, fmt.Errorf("column %s not found in table %s", assignment.Column, stmt.Table)
+		}
+		if table.Columns[targets[i]].Identity == types.IdentityAlways {
+			return 0, fmt.Errorf("cannot update column %s, it is GENERATED ALWAYS AS IDENTITY", assignment.Column)
+		}
+	}
+	rows, err := matchingRows(table, stmt.Where)
+	if err != nil {
+		return 0, err
+	}
+	scope := tableScope(table)
+	scope.db = db
+	for _, r := range rows {
+		old, err := table.GetRowValues(r)
+		if err != nil {
+			return 0, err
+		}
+		values := append([]interface{}(nil), old...)
+		for i, assignment := range stmt.Set {
+			col := table.Columns[targets[i]]
+			value, err := Eval(assignment.Value, scope.WithValues(old))
+			if err != nil {
+				return 0, fmt.Errorf("error evaluating value for column %s: %v", col.GetName(), err)
+			}
+			if values[targets[i]], err = ConvertValue(value, col, strict); err != nil {
+				return 0, fmt.Errorf("error converting value for column %s: %v", col.GetName(), err)
+			}
+		}
+		if err := db.UpdateRow(table, r, values); err != nil {
+			return 0, err
+		}
+	}
+	return len(rows), nil
+}
+
+// Delete executes a DELETE statement and returns the number of rows deleted from the table.
+// Rows of other tables removed by ON DELETE CASCADE are not counted.
+func Delete(db *types.Database, stmt *parser.DeleteStmt) (int, error) {
+	table := FindTable(db, stmt.Table)
+	if table == nil {
+		return 0, fmt.Errorf("table not found: %s", stmt.Table)
+	}
+	attachValidator(db, true)
+	rows, err := matchingRows(table, stmt.Where)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return len(rows), db.DeleteRows(table, rows)
+}
+
+// tableScope creates a scope over the columns of a table
+func tableScope(table *types.Table) *Scope {
+	var columns []ScopeColumn
+	for _, name := range table.GetColumnNames() {
+		columns = append(columns, ScopeColumn{Table: table.GetName(), Name: name})
+	}
+	return NewScope(columns)
+}
+
+// matchingRows returns the indexes of the rows of a table satisfying the condition; all rows if it is nil
+func matchingRows(table *types.Table, where parser.Expr) ([]int, error) {
+	scope := tableScope(table)
+	var rows []int
+	for r := range table.Rows {
+		if where != nil {
+			values, err := table.GetRowValues(r)
+			if err != nil {
+				return nil, err
+			}
+			ok, err := EvalCondition(where, scope.WithValues(values))
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		rows = append(rows, r)
+	}
+	return rows, nil
+}
+
+func indexOfColumn(names []string, name string) int {
+	for i, s := range names {
+		if strings.EqualFold(s, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ConvertValue converts an evaluated value to the representation stored in the given column
+func ConvertValue(value interface{}, col types.Column, strict bool) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	// non-string values are converted through their text form, e.g. 1 for BOOL or 2024 for YEAR
+	text, isString := value.(string)
+	if !isString {
+		text = types.FormatValue(value)
+	}
+
+	switch col.DataType {
+	case types.SQL_TYPE_INT, types.SQL_TYPE_BIGINT, types.SQL_TYPE_BLOB:
+		return strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	case types.SQL_TYPE_DATE, types.SQL_TYPE_DATETIME, types.SQL_TYPE_TIMESTAMP:
+		return types.ParseDateTime(text, col.DataType)
+	case types.SQL_TYPE_TIME:
+		return types.ParseTimeOfDay(text)
+	case types.SQL_TYPE_YEAR:
+		return types.ParseYear(text)
+	case types.SQL_TYPE_DECIMAL:
+		d, err := types.ParseDecimal(strings.TrimSpace(text))
+		if err != nil {
+			return nil, err
+		}
+		return types.FitDecimal(d, col.Precision, col.Scale)
+	case types.SQL_TYPE_VARCHAR, types.SQL_TYPE_CHAR, types.SQL_TYPE_BINARY, types.SQL_TYPE_VARBINARY:
+		return col.FitString(text, strict)
+	case types.SQL_TYPE_TINYTEXT, types.SQL_TYPE_TEXT, types.SQL_TYPE_MEDIUMTEXT, types.SQL_TYPE_LONGTEXT:
+		return text, nil
+	case types.SQL_TYPE_ENUM:
+		return col.EnumValue(text)
+	case types.SQL_TYPE_SET:
+		return col.SetValue(text)
+	case types.SQL_TYPE_BOOL:
+		return strconv.ParseBool(strings.TrimSpace(text))
+	case types.SQL_TYPE_FLOAT, types.SQL_TYPE_DOUBLE:
+		return strconv.ParseFloat(strings.TrimSpace(text), 64)
+	default:
+		return nil, fmt.Errorf("unsupported data type")
+	}
+}
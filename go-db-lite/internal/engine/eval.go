@@ -0,0 +1,374 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// ScopeColumn names one value slot of a Scope
+type ScopeColumn struct {
+	Table string // table name or alias the column belongs to
+	Name  string
+}
+
+// Scope is the context an expression is evaluated in: the columns visible to it and the current row's values
+type Scope struct {
+	Columns []ScopeColumn
+	Values  []interface{}
+
+	// aggregates holds the computed value of each aggregate call for the current group
+	aggregates map[*parser.FuncCall]interface{}
+	// db is the database the statement runs against, for the sequence functions
+	db *types.Database
+}
+
+// NewScope creates a scope over the given columns with no current row
+func NewScope(columns []ScopeColumn) *Scope {
+	return &Scope{Columns: columns}
+}
+
+// WithValues returns a copy of the scope positioned on another row
+func (s *Scope) WithValues(values []interface{}) *Scope {
+	return &Scope{Columns: s.Columns, Values: values, aggregates: s.aggregates, db: s.db}
+}
+
+// resolve returns the index of the referenced column in the scope
+func (s *Scope) resolve(ref *parser.ColumnRef) (int, error) {
+	found := -1
+	for i, col := range s.Columns {
+		if !strings.EqualFold(col.Name, ref.Name) {
+			continue
+		}
+		if ref.Table != "" && !strings.EqualFold(col.Table, ref.Table) {
+			continue
+		}
+		if found != -1 {
+			return -1, fmt.Errorf("column reference %q is ambiguous", ref.String())
+		}
+		found = i
+	}
+	if found == -1 {
+		return -1, fmt.Errorf("unknown column %q", ref.String())
+	}
+	return found, nil
+}
+
+// evalSequence evaluates NEXTVAL('name') or CURRVAL('name') against the database of the scope
+func evalSequence(e *parser.FuncCall, scope *Scope) (interface{}, error) {
+	if scope == nil || scope.db == nil {
+		return nil, fmt.Errorf("%s is not allowed here", e.Name)
+	}
+	if len(e.Args) != 1 {
+		return nil, fmt.Errorf("%s takes a sequence name", e.Name)
+	}
+	value, err := Eval(e.Args[0], scope)
+	if err != nil {
+		return nil, err
+	}
+	name, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s takes a sequence name", e.Name)
+	}
+	if e.Name == "NEXTVAL" {
+		return scope.db.NextVal(name)
+	}
+	return scope.db.CurrVal(name)
+}
+
+// Eval evaluates an expression against the current row of the scope
+func Eval(expr parser.Expr, scope *Scope) (interface{}, error) {
+	switch e := expr.(type) {
+	case *parser.Literal:
+		return e.Value, nil
+
+	case *parser.ColumnRef:
+		if scope == nil {
+			return nil, fmt.Errorf("unknown column %q", e.String())
+		}
+		i, err := scope.resolve(e)
+		if err != nil {
+			return nil, err
+		}
+		if scope.Values == nil {
+			return nil, nil
+		}
+		return scope.Values[i], nil
+
+	case *parser.UnaryExpr:
+		value, err := Eval(e.Expr, scope)
+		if err != nil {
+			return nil, err
+		}
+		if e.Op == "NOT" {
+			b, err := truthy(value)
+			if err != nil || b == nil {
+				return nil, err
+			}
+			return !b.(bool), nil
+		}
+		return negate(value)
+
+	case *parser.BinaryExpr:
+		return evalBinary(e, scope)
+
+	case *parser.IntervalExpr:
+		value, err := Eval(e.Value, scope)
+		if err != nil {
+			return nil, err
+		}
+		return evalInterval(value, e.Unit)
+
+	case *parser.IsNullExpr:
+		value, err := Eval(e.Expr, scope)
+		if err != nil {
+			return nil, err
+		}
+		return (value == nil) != e.Not, nil
+
+	case *parser.BetweenExpr:
+		value, err := Eval(e.Expr, scope)
+		if err != nil {
+			return nil, err
+		}
+		low, err := Eval(e.Low, scope)
+		if err != nil {
+			return nil, err
+		}
+		high, err := Eval(e.High, scope)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil || low == nil || high == nil {
+			return nil, nil
+		}
+		c1, err := compareValues(value, low)
+		if err != nil {
+			return nil, err
+		}
+		c2, err := compareValues(value, high)
+		if err != nil {
+			return nil, err
+		}
+		return (c1 >= 0 && c2 <= 0) != e.Not, nil
+
+	case *parser.InExpr:
+		value, err := Eval(e.Expr, scope)
+		if err != nil || value == nil {
+			return nil, err
+		}
+		sawNull := false
+		for _, item := range e.List {
+			candidate, err := Eval(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			if candidate == nil {
+				sawNull = true
+				continue
+			}
+			c, err := compareValues(value, candidate)
+			if err != nil {
+				return nil, err
+			}
+			if c == 0 {
+				return !e.Not, nil
+			}
+		}
+		if sawNull {
+			return nil, nil
+		}
+		return e.Not, nil
+
+	case *parser.CaseExpr:
+		return evalCase(e, scope)
+
+	case *parser.FuncCall:
+		if isAggregate(e.Name) {
+			if scope == nil || scope.aggregates == nil {
+				return nil, fmt.Errorf("aggregate function %s is not allowed here", e.Name)
+			}
+			return scope.aggregates[e], nil
+		}
+		if e.Name == "NEXTVAL" || e.Name == "CURRVAL" {
+			return evalSequence(e, scope)
+		}
+		fn, ok := scalarFunctions[e.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown function %s", e.Name)
+		}
+		args := make([]interface{}, len(e.Args))
+		for i, arg := range e.Args {
+			value, err := Eval(arg, scope)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = value
+		}
+		return fn(args)
+	}
+	return nil, fmt.Errorf("unsupported expression %s", expr.String())
+}
+
+func evalBinary(e *parser.BinaryExpr, scope *Scope) (interface{}, error) {
+	left, err := Eval(e.Left, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	// AND/OR use three valued logic and short circuit
+	if e.Op == "AND" || e.Op == "OR" {
+		l, err := truthy(left)
+		if err != nil {
+			return nil, err
+		}
+		if l != nil && l.(bool) == (e.Op == "OR") {
+			return l, nil
+		}
+		right, err := Eval(e.Right, scope)
+		if err != nil {
+			return nil, err
+		}
+		r, err := truthy(right)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil && r.(bool) == (e.Op == "OR") {
+			return r, nil
+		}
+		if l == nil || r == nil {
+			return nil, nil
+		}
+		return e.Op == "AND", nil
+	}
+
+	right, err := Eval(e.Right, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.Op {
+	case "+", "-", "*", "/", "%", "DIV", "MOD":
+		return arithmetic(e.Op, left, right)
+	case "||":
+		if left == nil || right == nil {
+			return nil, nil
+		}
+		return valueToString(left) + valueToString(right), nil
+	case "LIKE":
+		if left == nil || right == nil {
+			return nil, nil
+		}
+		return matchLike(valueToString(left), valueToString(right)), nil
+	case "=", "<>", "<", "<=", ">", ">=":
+		if left == nil || right == nil {
+			return nil, nil
+		}
+		c, err := compareValues(left, right)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Op {
+		case "=":
+			return c == 0, nil
+		case "<>":
+			return c != 0, nil
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported operator %s", e.Op)
+}
+
+func evalCase(e *parser.CaseExpr, scope *Scope) (interface{}, error) {
+	var operand interface{}
+	if e.Operand != nil {
+		value, err := Eval(e.Operand, scope)
+		if err != nil {
+			return nil, err
+		}
+		operand = value
+	}
+	for _, when := range e.Whens {
+		cond, err := Eval(when.Cond, scope)
+		if err != nil {
+			return nil, err
+		}
+		matched := false
+		if e.Operand != nil {
+			if operand != nil && cond != nil {
+				c, err := compareValues(operand, cond)
+				if err != nil {
+					return nil, err
+				}
+				matched = c == 0
+			}
+		} else {
+			b, err := truthy(cond)
+			if err != nil {
+				return nil, err
+			}
+			matched = b == true
+		}
+		if matched {
+			return Eval(when.Result, scope)
+		}
+	}
+	if e.Else != nil {
+		return Eval(e.Else, scope)
+	}
+	return nil, nil
+}
+
+// EvalCondition evaluates a predicate; NULL (unknown) counts as false
+func EvalCondition(expr parser.Expr, scope *Scope) (bool, error) {
+	if expr == nil {
+		return true, nil
+	}
+	value, err := Eval(expr, scope)
+	if err != nil {
+		return false, err
+	}
+	b, err := truthy(value)
+	if err != nil {
+		return false, err
+	}
+	return b == true, nil
+}
+
+// matchLike matches s against a SQL LIKE pattern with % and _ wildcards (case insensitive)
+func matchLike(s, pattern string) bool {
+	str, pat := []rune(strings.ToLower(s)), []rune(strings.ToLower(pattern))
+	// classic two pointer wildcard matching with backtracking on %
+	si, pi, starP, starS := 0, 0, -1, 0
+	for si < len(str) {
+		switch {
+		case pi < len(pat) && pat[pi] == '\\' && pi+1 < len(pat) && pat[pi+1] == str[si]:
+			si++
+			pi += 2
+		case pi < len(pat) && (pat[pi] == '_' || (pat[pi] == str[si] && pat[pi] != '%')):
+			si++
+			pi++
+		case pi < len(pat) && pat[pi] == '%':
+			starP, starS = pi, si
+			pi++
+		case starP != -1:
+			starS++
+			si, pi = starS, starP+1
+		default:
+			return false
+		}
+	}
+	for pi < len(pat) && pat[pi] == '%' {
+		pi++
+	}
+	return pi == len(pat)
+}
@@ -0,0 +1,215 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// scalarFunc computes a scalar function from its evaluated arguments
+type scalarFunc func(args []interface{}) (interface{}, error)
+
+// scalarFunctions maps upper case function names to their implementations
+var scalarFunctions = map[string]scalarFunc{
+	"ABS":      fnAbs,
+	"ROUND":    fnRound,
+	"TRUNCATE": fnTruncate,
+	"CEIL":     fnCeil,
+	"CEILING":  fnCeil,
+	"FLOOR":    fnFloor,
+	"COALESCE": fnCoalesce,
+	"IFNULL":   fnCoalesce,
+	"NULLIF":   fnNullIf,
+	"UPPER":    fnUpper,
+	"LOWER":    fnLower,
+	"LENGTH":   fnLength,
+	"CONCAT":   fnConcat,
+}
+
+// valueToString converts a value to its string form for string functions and concatenation
+func valueToString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return types.FormatValue(value)
+}
+
+func checkArgs(name string, args []interface{}, min, max int) error {
+	if len(args) < min || (max >= 0 && len(args) > max) {
+		return fmt.Errorf("wrong number of arguments to %s", name)
+	}
+	return nil
+}
+
+func fnAbs(args []interface{}) (interface{}, error) {
+	if err := checkArgs("ABS", args, 1, 1); err != nil || args[0] == nil {
+		return nil, err
+	}
+	n, kind, err := toNumber(args[0])
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case kindInt:
+		if i := n.(int64); i < 0 {
+			return negate(i)
+		}
+		return n, nil
+	case kindDecimal:
+		return n.(types.Decimal).Abs(), nil
+	}
+	return math.Abs(n.(float64)), nil
+}
+
+// roundArgs returns the number and the requested scale of ROUND/TRUNCATE
+func roundArgs(name string, args []interface{}) (interface{}, numericKind, int32, error) {
+	if err := checkArgs(name, args, 1, 2); err != nil {
+		return nil, 0, 0, err
+	}
+	n, kind, err := toNumber(args[0])
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	scale := int64(0)
+	if len(args) == 2 && args[1] != nil {
+		s, skind, err := toNumber(args[1])
+		if err != nil || skind != kindInt {
+			return nil, 0, 0, fmt.Errorf("%s expects an integer number of decimal places", name)
+		}
+		scale = s.(int64)
+	}
+	if scale < 0 || scale > types.MaxDecimalScale {
+		return nil, 0, 0, fmt.Errorf("%s: decimal places must be between 0 and %d", name, types.MaxDecimalScale)
+	}
+	return n, kind, int32(scale), nil
+}
+
+func fnRound(args []interface{}) (interface{}, error) {
+	if len(args) > 0 && args[0] == nil {
+		return nil, nil
+	}
+	n, kind, scale, err := roundArgs("ROUND", args)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case kindInt:
+		return n, nil
+	case kindDecimal:
+		return n.(types.Decimal).Round(scale), nil
+	}
+	pow := math.Pow(10, float64(scale))
+	return math.Round(n.(float64)*pow) / pow, nil
+}
+
+func fnTruncate(args []interface{}) (interface{}, error) {
+	if len(args) > 0 && args[0] == nil {
+		return nil, nil
+	}
+	n, kind, scale, err := roundArgs("TRUNCATE", args)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case kindInt:
+		return n, nil
+	case kindDecimal:
+		return n.(types.Decimal).Truncate(scale), nil
+	}
+	pow := math.Pow(10, float64(scale))
+	return math.Trunc(n.(float64)*pow) / pow, nil
+}
+
+func fnCeil(args []interface{}) (interface{}, error) {
+	return roundToInteger("CEIL", args, math.Ceil, 1)
+}
+
+func fnFloor(args []interface{}) (interface{}, error) {
+	return roundToInteger("FLOOR", args, math.Floor, -1)
+}
+
+// roundToInteger implements CEIL (direction 1) and FLOOR (direction -1)
+func roundToInteger(name string, args []interface{}, floatFn func(float64) float64, direction int) (interface{}, error) {
+	if err := checkArgs(name, args, 1, 1); err != nil || args[0] == nil {
+		return nil, err
+	}
+	n, kind, err := toNumber(args[0])
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case kindInt:
+		return n, nil
+	case kindDecimal:
+		d := n.(types.Decimal)
+		truncated := d.Truncate(0)
+		if truncated.Cmp(d) != 0 && d.Sign() == direction {
+			truncated = truncated.Add(types.NewDecimal(int64(direction), 0))
+		}
+		if i, ok := truncated.Int64(); ok {
+			return i, nil
+		}
+		return truncated, nil
+	}
+	return floatFn(n.(float64)), nil
+}
+
+func fnCoalesce(args []interface{}) (interface{}, error) {
+	for _, arg := range args {
+		if arg != nil {
+			return arg, nil
+		}
+	}
+	return nil, nil
+}
+
+func fnNullIf(args []interface{}) (interface{}, error) {
+	if err := checkArgs("NULLIF", args, 2, 2); err != nil {
+		return nil, err
+	}
+	if args[0] == nil || args[1] == nil {
+		return args[0], nil
+	}
+	c, err := compareValues(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if c == 0 {
+		return nil, nil
+	}
+	return args[0], nil
+}
+
+func fnUpper(args []interface{}) (interface{}, error) {
+	if err := checkArgs("UPPER", args, 1, 1); err != nil || args[0] == nil {
+		return nil, err
+	}
+	return strings.ToUpper(valueToString(args[0])), nil
+}
+
+func fnLower(args []interface{}) (interface{}, error) {
+	if err := checkArgs("LOWER", args, 1, 1); err != nil || args[0] == nil {
+		return nil, err
+	}
+	return strings.ToLower(valueToString(args[0])), nil
+}
+
+func fnLength(args []interface{}) (interface{}, error) {
+	if err := checkArgs("LENGTH", args, 1, 1); err != nil || args[0] == nil {
+		return nil, err
+	}
+	return int64(len([]rune(valueToString(args[0])))), nil
+}
+
+func fnConcat(args []interface{}) (interface{}, error) {
+	var sb strings.Builder
+	for _, arg := range args {
+		if arg == nil {
+			return nil, nil
+		}
+		sb.WriteString(valueToString(arg))
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// NullField is the CSV field that stands for NULL in imported files
+const NullField = `\N`
+
+// ImportCSV loads rows into a table from CSV. The first record names the columns; columns
+// not named take their default. Every row is converted and validated like an INSERT, and
+// the first bad row stops the import with its line number.
+func ImportCSV(db *types.Database, tableName string, r io.Reader, strict bool) (int, error) {
+	table := FindTable(db, tableName)
+	if table == nil {
+		return 0, fmt.Errorf("table not found: %s", tableName)
+	}
+	attachValidator(db, strict)
+
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("error reading CSV header: %v", err)
+	}
+	columns := make([]int, len(header))
+	for i, name := range header {
+		if columns[i] = table.ColumnIndex(name); columns[i] == -1 {
+			return 0, fmt.Errorf("column %s not found in table %s", name, tableName)
+		}
+		if table.Columns[columns[i]].Identity == types.IdentityAlways {
+			return 0, fmt.Errorf("cannot import into column %s, it is GENERATED ALWAYS AS IDENTITY", name)
+		}
+	}
+
+	count := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return count, nil
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			return count, err
+		}
+		values := make([]interface{}, len(table.Columns))
+		given := make([]bool, len(table.Columns))
+		for i, field := range record {
+			col := table.Columns[columns[i]]
+			given[columns[i]] = true
+			if field == NullField {
+				continue
+			}
+			if values[columns[i]], err = ConvertValue(field, col, strict); err != nil {
+				return count, fmt.Errorf("line %d: error converting value for column %s: %v", line, col.GetName(), err)
+			}
+		}
+		for i := range table.Columns {
+			if !given[i] {
+				if values[i], err = db.DefaultValue(table, i); err != nil {
+					return count, fmt.Errorf("line %d: %v", line, err)
+				}
+			}
+		}
+		if err := db.InsertRow(table, values); err != nil {
+			return count, fmt.Errorf("line %d: %v", line, err)
+		}
+		count++
+	}
+}
@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// InformationSchema is the name tables describing the schema of a database are read under,
+// e.g. SELECT * FROM information_schema.columns
+const InformationSchema = "information_schema"
+
+// catalogTable builds one table of information_schema from the catalog of a database
+type catalogTable struct {
+	columns []string
+	rows    func(db *types.Database) [][]interface{}
+}
+
+// informationSchema returns the tables of information_schema by name. It is a function, not
+// a variable, since the columns of views are found by running the queries that read it.
+func informationSchema() map[string]catalogTable {
+	return map[string]catalogTable{
+		"tables": {
+			columns: []string{"table_name", "table_type", "column_count", "table_comment"},
+			rows:    catalogTables,
+		},
+		"columns": {
+			columns: []string{"table_name", "column_name", "ordinal_position", "data_type", "is_nullable",
+				"column_default", "identity", "character_maximum_length", "numeric_precision", "numeric_scale", "column_comment"},
+			rows: catalogColumns,
+		},
+		"indexes": {
+			columns: []string{"index_name", "table_name", "column_name", "ordinal_position", "is_unique", "is_primary"},
+			rows:    catalogIndexes,
+		},
+		"views": {
+			columns: []string{"table_name", "view_definition", "is_materialized", "refresh"},
+			rows:    catalogViews,
+		},
+		"constraints": {
+			columns: []string{"constraint_name", "table_name", "constraint_type", "column_names",
+				"referenced_table", "referenced_columns", "check_clause", "delete_rule", "update_rule"},
+			rows: catalogConstraints,
+		},
+		"routines": {
+			columns: []string{"routine_name", "parameters", "routine_definition"},
+			rows:    catalogRoutines,
+		},
+		"triggers": {
+			columns: []string{"trigger_name", "event_object_table", "action_timing", "event_manipulation", "action_statement"},
+			rows:    catalogTriggers,
+		},
+	}
+}
+
+// InformationSchemaTables returns the names of the tables of information_schema
+func InformationSchemaTables() []string {
+	names := make([]string, 0, len(informationSchema()))
+	for name := range informationSchema() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// informationSchemaSource reads a table of information_schema. Its rows are built from the
+// catalog as it is when the query runs.
+func informationSchemaSource(db *types.Database, from *parser.TableName) (*source, error) {
+	table, ok := informationSchema()[strings.ToLower(from.Name)]
+	if !ok {
+		return nil, fmt.Errorf("table not found: %s.%s; it has %s", InformationSchema, from.Name, strings.Join(InformationSchemaTables(), ", "))
+	}
+	name := relationName(from)
+	src := &source{rows: table.rows(db)}
+	for _, column := range table.columns {
+		src.columns = append(src.columns, ScopeColumn{Table: name, Name: column})
+	}
+	return src, nil
+}
+
+// yesNo is how information_schema shows a flag
+func yesNo(b bool) string {
+	if b {
+		return "YES"
+	}
+	return "NO"
+}
+
+// orNull returns the value, or nil for the zero value meaning it is not set
+func orNull[T comparable](value T) interface{} {
+	var zero T
+	if value == zero {
+		return nil
+	}
+	return value
+}
+
+func catalogTables(db *types.Database) [][]interface{} {
+	var rows [][]interface{}
+	for _, t := range db.Tables {
+		kind := "BASE TABLE"
+		if v := db.FindView(t.GetName()); v != nil && v.Materialized {
+			kind = "MATERIALIZED VIEW"
+		}
+		rows = append(rows, []interface{}{t.GetName(), kind, int64(len(t.Columns)), orNull(t.Metadata[types.MetadataComment])})
+	}
+	for _, v := range db.Views {
+		if !v.Materialized {
+			rows = append(rows, []interface{}{v.Name, "VIEW", nil, nil})
+		}
+	}
+	return rows
+}
+
+func catalogColumns(db *types.Database) [][]interface{} {
+	var rows [][]interface{}
+	for _, t := range db.Tables {
+		for i, c := range t.Columns {
+			var length, precision, scale interface{}
+			if c.DataType.IsSized() && c.Size > 0 {
+				length = int64(c.Size)
+			}
+			if c.DataType == types.SQL_TYPE_DECIMAL {
+				precision, scale = int64(c.Precision), int64(c.Scale)
+			}
+			rows = append(rows, []interface{}{t.GetName(), c.GetName(), int64(i + 1), c.TypeString(), yesNo(c.Nullable),
+				orNull(c.Default), orNull(c.Identity.String()), length, precision, scale, orNull(t.Metadata[types.ColumnCommentKey(c.GetName())])})
+		}
+	}
+	// the columns of a plain view are those of its query, whose types are not declared
+	for i := range db.Views {
+		v := &db.Views[i]
+		if v.Materialized {
+			continue
+		}
+		src, err := viewSource(db, v, "")
+		if err != nil {
+			continue // a view whose query no longer runs has no columns to show
+		}
+		for j, c := range src.columns {
+			rows = append(rows, []interface{}{v.Name, c.Name, int64(j + 1), nil, "YES", nil, nil, nil, nil, nil, nil})
+		}
+	}
+	return rows
+}
+
+func catalogIndexes(db *types.Database) [][]interface{} {
+	var rows [][]interface{}
+	for _, t := range db.Tables {
+		for i, column := range t.PrimaryKey {
+			rows = append(rows, []interface{}{t.PrimaryKeyIndexName(), t.GetName(), t.Columns[column].GetName(), int64(i + 1), "YES", "YES"})
+		}
+	}
+	return rows
+}
+
+func catalogViews(db *types.Database) [][]interface{} {
+	var rows [][]interface{}
+	for _, v := range db.Views {
+		var refresh interface{}
+		if v.Materialized {
+			refresh = "MANUAL"
+			if v.Incremental {
+				refresh = "INCREMENTAL"
+			}
+		}
+		rows = append(rows, []interface{}{v.Name, v.Query, yesNo(v.Materialized), refresh})
+	}
+	return rows
+}
+
+func catalogConstraints(db *types.Database) [][]interface{} {
+	var rows [][]interface{}
+	for i := range db.Tables {
+		t := &db.Tables[i]
+		columnNames := func(columns []int) string {
+			names := make([]string, len(columns))
+			for i, column := range columns {
+				names[i] = t.Columns[column].GetName()
+			}
+			return strings.Join(names, ", ")
+		}
+		if len(t.PrimaryKey) > 0 {
+			rows = append(rows, []interface{}{t.PrimaryKeyIndexName(), t.GetName(), "PRIMARY KEY", columnNames(t.PrimaryKey), nil, nil, nil, nil, nil})
+		}
+		for _, fk := range t.ForeignKeys {
+			rows = append(rows, []interface{}{fk.Name, t.GetName(), "FOREIGN KEY", columnNames(fk.Columns),
+				fk.RefTable, strings.Join(fk.RefColumns, ", "), nil, fk.OnDelete.String(), fk.OnUpdate.String()})
+		}
+		for _, check := range t.Checks {
+			rows = append(rows, []interface{}{check.Name, t.GetName(), "CHECK", nil, nil, nil, check.Expr, nil, nil})
+		}
+	}
+	return rows
+}
+
+func catalogRoutines(db *types.Database) [][]interface{} {
+	var rows [][]interface{}
+	for _, p := range db.Procedures {
+		rows = append(rows, []interface{}{p.Name, p.Signature(), p.Body})
+	}
+	return rows
+}
+
+func catalogTriggers(db *types.Database) [][]interface{} {
+	var rows [][]interface{}
+	for _, tr := range db.Triggers {
+		rows = append(rows, []interface{}{tr.Name, tr.Table, tr.Timing, tr.Event, tr.Body})
+	}
+	return rows
+}
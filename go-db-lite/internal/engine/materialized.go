@@ -0,0 +1,431 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// createMaterializedView stores the result of the query of a new materialized view in a
+// table named after it. Views whose query can be maintained row by row are refreshed
+// incrementally from then on; the others only by REFRESH MATERIALIZED VIEW.
+func createMaterializedView(db *types.Database, stmt *parser.CreateViewStmt, view types.View) error {
+	if len(stmt.Name) > len(types.Table{}.Name) {
+		return fmt.Errorf("view name %s is longer than %d bytes", stmt.Name, len(types.Table{}.Name))
+	}
+	result, err := Select(db, stmt.Query)
+	if err != nil {
+		return err
+	}
+	names, err := viewColumns(&view, result.Columns)
+	if err != nil {
+		return err
+	}
+	table := types.Table{}
+	table.CreateTable(stmt.Name, nil)
+	static := staticColumns(db, stmt.Query)
+	for i, name := range names {
+		if len(name) > len(types.Column{}.Name) {
+			return fmt.Errorf("column name %s is longer than %d bytes", name, len(types.Column{}.Name))
+		}
+		var column types.Column
+		if len(static) == len(names) && static[i] != nil {
+			column = *static[i]
+		} else {
+			column = valueColumn(result.Rows, i)
+		}
+		copy(column.Name[:], name)
+		table.Columns = append(table.Columns, column)
+	}
+	if err := fillMaterializedView(&table, result); err != nil {
+		return err
+	}
+	view.Materialized = true
+	view.Incremental = ManualRefreshReason(db, stmt.Query) == ""
+	if err := db.CreateView(view, false); err != nil {
+		return err
+	}
+	db.AddTable(table)
+	return nil
+}
+
+// RefreshMaterializedView runs the query of a materialized view again and replaces its rows
+// with the result, returning how many rows it now holds
+func RefreshMaterializedView(db *types.Database, name string) (int, error) {
+	view := db.FindView(name)
+	if view == nil || !view.Materialized {
+		return 0, fmt.Errorf("materialized view not found: %s", name)
+	}
+	table := db.FindTable(view.Name)
+	if table == nil {
+		return 0, fmt.Errorf("the table of materialized view %s is missing", view.Name)
+	}
+	stmt, err := parser.ParseSelect(view.Query)
+	if err != nil {
+		return 0, err
+	}
+	result, err := Select(db, stmt)
+	if err != nil {
+		return 0, err
+	}
+	if len(result.Columns) != len(table.Columns) {
+		return 0, fmt.Errorf("the query of materialized view %s now returns %d columns instead of %d; create it again", view.Name, len(result.Columns), len(table.Columns))
+	}
+	table.Rows = nil
+	if err := fillMaterializedView(table, result); err != nil {
+		return 0, err
+	}
+	return len(table.Rows), nil
+}
+
+// fillMaterializedView adds the rows of a query result to the table of a materialized view
+func fillMaterializedView(table *types.Table, result *types.ResultSet) error {
+	for _, row := range result.Rows {
+		values := make([]interface{}, len(row))
+		for i, value := range row {
+			var err error
+			if values[i], err = ConvertValue(value, table.Columns[i], false); err != nil {
+				return fmt.Errorf("column %s: %v", table.Columns[i].GetName(), err)
+			}
+		}
+		if err := table.AddRow(values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// staticColumns returns the column types of a query over a single table that follow from its
+// select list: those of the columns it names, BIGINT for counts and the type of a sum of a
+// column. Entries are nil where the type is only known from the values.
+func staticColumns(db *types.Database, stmt *parser.SelectStmt) []*types.Column {
+	if stmt.From == nil || len(stmt.Joins) > 0 {
+		return nil
+	}
+	table := db.FindTable(stmt.From.Name)
+	if table == nil {
+		return nil
+	}
+	var columns []*types.Column
+	for _, item := range stmt.Columns {
+		if !item.Star {
+			columns = append(columns, exprColumn(item.Expr, table))
+			continue
+		}
+		for i := range table.Columns {
+			columns = append(columns, baseColumn(table.Columns[i]))
+		}
+	}
+	return columns
+}
+
+// baseColumn copies a column of a table read by a materialized view, without its constraints
+func baseColumn(c types.Column) *types.Column {
+	c.Nullable, c.IsPrimaryKey, c.Default, c.Identity = true, false, "", types.IdentityNone
+	return &c
+}
+
+// exprColumn returns the column type of a select expression over a table, or nil if it
+// cannot be told without evaluating it
+func exprColumn(expr parser.Expr, table *types.Table) *types.Column {
+	switch e := expr.(type) {
+	case *parser.ColumnRef:
+		if i := table.ColumnIndex(e.Name); i != -1 {
+			return baseColumn(table.Columns[i])
+		}
+	case *parser.FuncCall:
+		switch {
+		case e.Name == "COUNT":
+			return &types.Column{DataType: types.SQL_TYPE_BIGINT, Nullable: true}
+		case (e.Name == "MIN" || e.Name == "MAX") && len(e.Args) == 1:
+			return exprColumn(e.Args[0], table)
+		case e.Name == "SUM" && len(e.Args) == 1:
+			arg := exprColumn(e.Args[0], table)
+			if arg == nil {
+				return nil
+			}
+			switch arg.DataType {
+			case types.SQL_TYPE_INT, types.SQL_TYPE_BIGINT:
+				return &types.Column{DataType: types.SQL_TYPE_BIGINT, Nullable: true}
+			case types.SQL_TYPE_DECIMAL:
+				return &types.Column{DataType: types.SQL_TYPE_DECIMAL, Nullable: true, Precision: types.MaxDecimalPrecision, Scale: arg.Scale}
+			case types.SQL_TYPE_FLOAT, types.SQL_TYPE_DOUBLE:
+				return &types.Column{DataType: types.SQL_TYPE_DOUBLE, Nullable: true}
+			}
+		}
+	}
+	return nil
+}
+
+// valueColumn returns a column able to hold the values of one column of a query result,
+// TEXT when it has no value to go by
+func valueColumn(rows [][]interface{}, i int) types.Column {
+	column := types.Column{DataType: types.SQL_TYPE_TEXT, Nullable: true}
+	for _, row := range rows {
+		switch v := row[i].(type) {
+		case nil:
+			continue
+		case int64:
+			column.DataType = types.SQL_TYPE_BIGINT
+		case float64:
+			column.DataType = types.SQL_TYPE_DOUBLE
+		case bool:
+			column.DataType = types.SQL_TYPE_BOOL
+		case types.DateTime:
+			column.DataType = v.Kind()
+		case types.TimeOfDay:
+			column.DataType = types.SQL_TYPE_TIME
+		case types.Decimal:
+			// the widest scale of any row, so that no row loses digits
+			column.DataType, column.Precision = types.SQL_TYPE_DECIMAL, types.MaxDecimalPrecision
+			for _, row := range rows {
+				if d, ok := row[i].(types.Decimal); ok && int(d.Scale()) > column.Scale {
+					column.Scale = min(int(d.Scale()), types.MaxDecimalScale)
+				}
+			}
+		}
+		return column
+	}
+	return column
+}
+
+// ManualRefreshReason returns why a materialized view over the query cannot be kept up to
+// date row by row, and so is refreshed only by REFRESH MATERIALIZED VIEW, or "" if it can.
+// The query must group the rows of one table, with no DISTINCT, HAVING, ORDER BY or LIMIT,
+// and select only its GROUP BY expressions and COUNT and SUM aggregates. It must select
+// COUNT(*), to tell when a group empties, and COUNT(x) along with every SUM(x), to tell
+// when the sum becomes NULL.
+func ManualRefreshReason(db *types.Database, stmt *parser.SelectStmt) string {
+	switch {
+	case stmt.From == nil || len(stmt.Joins) > 0:
+		return "it does not read exactly one table"
+	case db.FindView(stmt.From.Name) != nil:
+		return "it reads view " + stmt.From.Name
+	case db.FindTable(stmt.From.Name) == nil:
+		return "it does not read a table of this database"
+	case stmt.Distinct:
+		return "it uses DISTINCT"
+	case stmt.Having != nil:
+		return "it uses HAVING"
+	case len(stmt.OrderBy) > 0:
+		return "it uses ORDER BY"
+	case stmt.Limit != nil || stmt.Offset != nil:
+		return "it uses LIMIT or OFFSET"
+	case len(stmt.GroupBy) == 0:
+		return "it has no GROUP BY"
+	}
+	groups := make(map[string]bool)
+	for _, expr := range stmt.GroupBy {
+		groups[expr.String()] = false
+	}
+	countStar := false
+	for i, item := range stmt.Columns {
+		if item.Star {
+			return "it selects *"
+		}
+		call, ok := item.Expr.(*parser.FuncCall)
+		if !ok || !isAggregate(call.Name) {
+			if _, ok := groups[item.Expr.String()]; !ok || len(findAggregates(item.Expr)) > 0 {
+				return "it selects " + item.Expr.String() + ", which is neither a GROUP BY expression nor COUNT or SUM"
+			}
+			groups[item.Expr.String()] = true
+			continue
+		}
+		switch {
+		case call.Distinct:
+			return "it selects " + call.String()
+		case call.Name == "COUNT" && call.Star:
+			countStar = true
+		case call.Name == "COUNT":
+		case call.Name == "SUM":
+			if countOf(stmt, i) == -1 {
+				return "it selects " + call.String() + " without COUNT(" + call.Args[0].String() + "), to tell when the sum becomes NULL"
+			}
+		default:
+			return "it selects " + call.String() + ", and only COUNT and SUM are maintained row by row"
+		}
+		for _, arg := range call.Args {
+			if len(findAggregates(arg)) > 0 {
+				return "it nests aggregates in " + call.String()
+			}
+		}
+	}
+	for _, expr := range stmt.GroupBy {
+		if !groups[expr.String()] {
+			return "it does not select GROUP BY expression " + expr.String()
+		}
+	}
+	if !countStar {
+		return "it does not select COUNT(*), to tell when a group empties"
+	}
+	return ""
+}
+
+// countOf returns the position of COUNT(x) in the select list of a query, for the SUM(x)
+// at position i, or -1
+func countOf(stmt *parser.SelectStmt, i int) int {
+	sum := stmt.Columns[i].Expr.(*parser.FuncCall)
+	for j, item := range stmt.Columns {
+		if call, ok := item.Expr.(*parser.FuncCall); ok && call.Name == "COUNT" && !call.Star && !call.Distinct &&
+			len(call.Args) == 1 && call.Args[0].String() == sum.Args[0].String() {
+			return j
+		}
+	}
+	return -1
+}
+
+// viewMaintainer applies the changes of rows to the materialized views refreshed incrementally
+type viewMaintainer struct {
+	db      *types.Database
+	queries map[string]*parser.SelectStmt // parsed queries by SQL text
+}
+
+func (m *viewMaintainer) RowChanged(view *types.View, t *types.Table, old, new []interface{}) error {
+	stmt, ok := m.queries[view.Query]
+	if !ok {
+		var err error
+		if stmt, err = parser.ParseSelect(view.Query); err != nil {
+			return err
+		}
+		m.queries[view.Query] = stmt
+	}
+	table := m.db.FindTable(view.Name)
+	if table == nil {
+		return fmt.Errorf("its table is missing")
+	}
+	var columns []ScopeColumn
+	for _, name := range t.GetColumnNames() {
+		columns = append(columns, ScopeColumn{Table: relationName(stmt.From), Name: name})
+	}
+	scope := NewScope(columns)
+	scope.db = m.db
+	if old != nil {
+		if err := applyRowChange(stmt, table, scope.WithValues(old), -1); err != nil {
+			return err
+		}
+	}
+	if new != nil {
+		return applyRowChange(stmt, table, scope.WithValues(new), 1)
+	}
+	return nil
+}
+
+// applyRowChange adds (sign 1) or removes (sign -1) one row of the table read by a grouping
+// query to the stored result of the query: the counts and sums of its group change, a new
+// group gets a row and a group left without rows loses it
+func applyRowChange(stmt *parser.SelectStmt, table *types.Table, scope *Scope, sign int64) error {
+	ok, err := EvalCondition(stmt.Where, scope)
+	if err != nil || !ok {
+		return err
+	}
+	delta := make([]interface{}, len(stmt.Columns))
+	var group []int
+	for i, item := range stmt.Columns {
+		call, isCall := item.Expr.(*parser.FuncCall)
+		if !isCall || !isAggregate(call.Name) {
+			value, err := Eval(item.Expr, scope)
+			if err != nil {
+				return err
+			}
+			if delta[i], err = ConvertValue(value, table.Columns[i], false); err != nil {
+				return err
+			}
+			group = append(group, i)
+			continue
+		}
+		if call.Star {
+			delta[i] = int64(1)
+			continue
+		}
+		value, err := Eval(call.Args[0], scope)
+		if err != nil {
+			return err
+		}
+		if call.Name == "COUNT" {
+			delta[i] = int64(0)
+			if value != nil {
+				delta[i] = int64(1)
+			}
+			continue
+		}
+		delta[i] = value
+	}
+
+	key := groupKey(pick(delta, group))
+	target := -1
+	var current []interface{}
+	for r := range table.Rows {
+		values, err := table.GetRowValues(r)
+		if err != nil {
+			return err
+		}
+		if groupKey(pick(values, group)) == key {
+			target, current = r, values
+			break
+		}
+	}
+	if target == -1 {
+		if sign < 0 {
+			return fmt.Errorf("a removed row has no group; REFRESH MATERIALIZED VIEW rebuilds it")
+		}
+		return addStored(table, delta)
+	}
+
+	remaining := int64(0)
+	for i, item := range stmt.Columns {
+		call, isCall := item.Expr.(*parser.FuncCall)
+		if !isCall || !isAggregate(call.Name) || delta[i] == nil {
+			continue
+		}
+		if call.Name == "COUNT" {
+			n, _ := current[i].(int64)
+			current[i] = n + sign*delta[i].(int64)
+			if call.Star {
+				remaining = current[i].(int64)
+			}
+			continue
+		}
+		if current[i] == nil {
+			current[i] = delta[i]
+		} else if current[i], err = arithmetic(map[int64]string{1: "+", -1: "-"}[sign], current[i], delta[i]); err != nil {
+			return err
+		}
+	}
+	// a sum over no values is NULL
+	for i, item := range stmt.Columns {
+		if call, ok := item.Expr.(*parser.FuncCall); ok && call.Name == "SUM" && current[countOf(stmt, i)] == int64(0) {
+			current[i] = nil
+		}
+	}
+	if remaining <= 0 {
+		table.DeleteRows([]int{target})
+		return nil
+	}
+	for i := range current {
+		if current[i], err = ConvertValue(current[i], table.Columns[i], false); err != nil {
+			return err
+		}
+	}
+	return table.UpdateRow(target, current)
+}
+
+// addStored adds a row to the table of a materialized view, converting its values
+func addStored(table *types.Table, values []interface{}) error {
+	for i := range values {
+		var err error
+		if values[i], err = ConvertValue(values[i], table.Columns[i], false); err != nil {
+			return fmt.Errorf("column %s: %v", table.Columns[i].GetName(), err)
+		}
+	}
+	return table.AddRow(values)
+}
+
+// pick returns the values at the given positions
+func pick(values []interface{}, positions []int) []interface{} {
+	picked := make([]interface{}, len(positions))
+	for i, p := range positions {
+		picked[i] = values[p]
+	}
+	return picked
+}
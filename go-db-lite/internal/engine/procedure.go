@@ -0,0 +1,560 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// maxCallDepth bounds how deeply procedures may call each other
+const maxCallDepth = 32
+
+// maxProcedureSteps bounds the statements and loop iterations of one CALL, so that a loop
+// that never ends fails instead of hanging the session
+const maxProcedureSteps = 1000000
+
+// CallOptions configures how the statements of a procedure run
+type CallOptions struct {
+	// Strict rejects over-length strings rather than truncating them
+	Strict bool
+	// Authorize, if set, checks each SELECT, INSERT, UPDATE, DELETE and CALL of the body
+	// before it runs, given the statement as written
+	Authorize func(sql string) error
+}
+
+// CallResult is what a procedure call produced
+type CallResult struct {
+	Results  []*types.ResultSet // of the SELECT statements without INTO, in order
+	Changed  int                // rows inserted, updated and deleted
+	Returned bool               // whether the procedure ended with RETURN value
+	Value    interface{}        // the value returned
+}
+
+// CreateProcedure checks the parameter and variable types of a CREATE PROCEDURE statement
+// and stores the procedure. The statements of the body are only checked when they run.
+func CreateProcedure(db *types.Database, stmt *parser.CreateProcedureStmt) error {
+	proc := types.Procedure{Name: stmt.Name, Body: stmt.BodyText}
+	seen := make(map[string]bool)
+	for _, param := range stmt.Params {
+		if seen[strings.ToLower(param.Name)] {
+			return fmt.Errorf("parameter %s is listed twice", param.Name)
+		}
+		seen[strings.ToLower(param.Name)] = true
+		if _, err := variableColumn(param.Name, param.Type); err != nil {
+			return err
+		}
+		proc.Params = append(proc.Params, types.ProcedureParam{Name: param.Name, Type: param.Type.String()})
+	}
+	if err := checkDeclarations(stmt.Body); err != nil {
+		return err
+	}
+	return db.CreateProcedure(proc, stmt.OrReplace)
+}
+
+// checkDeclarations checks the types of the variables declared in a procedure body
+func checkDeclarations(body []parser.Statement) error {
+	for _, s := range body {
+		var err error
+		switch s := s.(type) {
+		case *parser.DeclareStmt:
+			for _, name := range s.Names {
+				if _, err = variableColumn(name, s.Type); err != nil {
+					break
+				}
+			}
+		case *parser.IfStmt:
+			for _, branch := range append(s.Bodies, s.Else) {
+				if err = checkDeclarations(branch); err != nil {
+					break
+				}
+			}
+		case *parser.WhileStmt:
+			err = checkDeclarations(s.Body)
+		case *parser.ForStmt:
+			err = checkDeclarations(s.Body)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// variableColumn returns a column of the given type, which converts the values of a variable
+func variableColumn(name string, spec parser.TypeSpec) (types.Column, error) {
+	column := types.Column{Nullable: true}
+	copy(column.Name[:], name)
+	if err := setColumnType(&column, spec); err != nil {
+		return types.Column{}, err
+	}
+	return column, nil
+}
+
+// CallProcedure runs a stored procedure against the database. Its changes are made to db
+// as it goes, so a caller that wants a failed call to change nothing discards db.
+func CallProcedure(db *types.Database, stmt *parser.CallStmt, opts CallOptions) (*CallResult, error) {
+	args, err := evalArgs(stmt.Args, func(e parser.Expr) parser.Expr { return e }, db)
+	if err != nil {
+		return nil, err
+	}
+	r := &procedureRun{db: db, opts: opts, result: &CallResult{}}
+	value, returned, err := r.call(stmt.Name, args, 0)
+	if err != nil {
+		return nil, err
+	}
+	r.result.Value, r.result.Returned = value, returned
+	return r.result, nil
+}
+
+// evalArgs evaluates the arguments of a CALL, bound to the variables of the caller
+func evalArgs(args []parser.Expr, bind func(parser.Expr) parser.Expr, db *types.Database) ([]interface{}, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		var err error
+		if values[i], err = Eval(bind(arg), &Scope{db: db}); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// procedureRun is the state one CALL statement shares with the procedures it calls
+type procedureRun struct {
+	db     *types.Database
+	opts   CallOptions
+	steps  int
+	result *CallResult
+}
+
+// procedureError is an error raised while a procedure runs, naming the innermost procedure
+// it came from
+type procedureError struct {
+	procedure string
+	err       error
+}
+
+func (e *procedureError) Error() string {
+	return "procedure " + e.procedure + ": " + e.err.Error()
+}
+
+// variable is a parameter or declared variable of a procedure
+type variable struct {
+	column types.Column // gives the type values are converted to
+	value  interface{}
+}
+
+// record is the current row of a FOR loop, or the OLD or NEW row of a trigger
+type record struct {
+	columns []string
+	values  []interface{}
+	table   *types.Table // set for the NEW row of a BEFORE trigger, whose columns can be set
+}
+
+// frame is one running procedure
+type frame struct {
+	run      *procedureRun
+	depth    int
+	vars     map[string]*variable // by lower case name
+	rows     map[string]*record   // by lower case name
+	value    interface{}          // set by RETURN
+	returned bool
+}
+
+// call runs the named procedure with the given arguments, returning the value of its RETURN
+func (r *procedureRun) call(name string, args []interface{}, depth int) (interface{}, bool, error) {
+	if depth >= maxCallDepth {
+		return nil, false, fmt.Errorf("procedures nested more than %d calls deep", maxCallDepth)
+	}
+	proc := r.db.FindProcedure(name)
+	if proc == nil {
+		return nil, false, fmt.Errorf("procedure not found: %s", name)
+	}
+	if len(args) != len(proc.Params) {
+		return nil, false, fmt.Errorf("procedure %s takes %d arguments, got %d", proc.Name, len(proc.Params), len(args))
+	}
+	body, err := parser.ParseProcedureBody(proc.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("procedure %s: %v", proc.Name, err)
+	}
+	f := &frame{run: r, depth: depth, vars: make(map[string]*variable), rows: make(map[string]*record)}
+	for i, param := range proc.Params {
+		spec, err := parser.ParseType(param.Type)
+		if err == nil {
+			err = f.declare(param.Name, spec, args[i])
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("procedure %s: parameter %s: %v", proc.Name, param.Name, err)
+		}
+	}
+	if _, err := f.exec(body); err != nil {
+		if _, nested := err.(*procedureError); nested {
+			return nil, false, err
+		}
+		return nil, false, &procedureError{procedure: proc.Name, err: err}
+	}
+	return f.value, f.returned, nil
+}
+
+// declare adds a variable to the frame with an initial value
+func (f *frame) declare(name string, spec parser.TypeSpec, value interface{}) error {
+	key := strings.ToLower(name)
+	if f.vars[key] != nil {
+		return fmt.Errorf("variable %s is already declared", name)
+	}
+	column, err := variableColumn(name, spec)
+	if err != nil {
+		return err
+	}
+	v := &variable{column: column}
+	if v.value, err = ConvertValue(value, column, f.run.opts.Strict); err != nil {
+		return fmt.Errorf("variable %s: %v", name, err)
+	}
+	f.vars[key] = v
+	return nil
+}
+
+// assign sets a declared variable, converting the value to its type
+func (f *frame) assign(name string, value interface{}) error {
+	v := f.vars[strings.ToLower(name)]
+	if v == nil {
+		return fmt.Errorf("unknown variable %s", name)
+	}
+	converted, err := ConvertValue(value, v.column, f.run.opts.Strict)
+	if err != nil {
+		return fmt.Errorf("variable %s: %v", name, err)
+	}
+	v.value = converted
+	return nil
+}
+
+// setColumn sets a column of the NEW row of a BEFORE trigger, converting the value to the
+// type of the column
+func (f *frame) setColumn(row, name string, value interface{}) error {
+	r := f.rows[strings.ToLower(row)]
+	if r == nil || r.table == nil {
+		return fmt.Errorf("cannot set %s.%s; only NEW can be set, in a BEFORE INSERT or BEFORE UPDATE trigger", row, name)
+	}
+	i := r.table.ColumnIndex(name)
+	if i == -1 {
+		return fmt.Errorf("column %s not found in table %s", name, r.table.GetName())
+	}
+	converted, err := ConvertValue(value, r.table.Columns[i], f.run.opts.Strict)
+	if err != nil {
+		return fmt.Errorf("column %s: %v", name, err)
+	}
+	r.values[i] = converted
+	return nil
+}
+
+// step counts one statement or loop iteration against the limit of the call
+func (f *frame) step() error {
+	f.run.steps++
+	if f.run.steps > maxProcedureSteps {
+		return fmt.Errorf("more than %d statements ran; does a loop never end?", maxProcedureSteps)
+	}
+	return nil
+}
+
+// exec runs statements in order until one of them returns, reporting whether one did
+func (f *frame) exec(body []parser.Statement) (bool, error) {
+	for _, s := range body {
+		if err := f.step(); err != nil {
+			return false, err
+		}
+		switch s := s.(type) {
+		case *parser.DeclareStmt:
+			var value interface{}
+			if s.Default != nil {
+				var err error
+				if value, err = f.eval(s.Default); err != nil {
+					return false, err
+				}
+			}
+			for _, name := range s.Names {
+				if err := f.declare(name, s.Type, value); err != nil {
+					return false, err
+				}
+			}
+
+		case *parser.SetStmt:
+			value, err := f.eval(s.Value)
+			if err != nil {
+				return false, err
+			}
+			if s.Row != "" {
+				err = f.setColumn(s.Row, s.Name, value)
+			} else {
+				err = f.assign(s.Name, value)
+			}
+			if err != nil {
+				return false, err
+			}
+
+		case *parser.IfStmt:
+			branch := s.Else
+			for i, cond := range s.Conds {
+				ok, err := f.condition(cond)
+				if err != nil {
+					return false, err
+				}
+				if ok {
+					branch = s.Bodies[i]
+					break
+				}
+			}
+			if done, err := f.exec(branch); done || err != nil {
+				return done, err
+			}
+
+		case *parser.WhileStmt:
+			for {
+				ok, err := f.condition(s.Cond)
+				if err != nil {
+					return false, err
+				}
+				if !ok {
+					break
+				}
+				if done, err := f.exec(s.Body); done || err != nil {
+					return done, err
+				}
+				if err := f.step(); err != nil {
+					return false, err
+				}
+			}
+
+		case *parser.ForStmt:
+			if done, err := f.loop(s); done || err != nil {
+				return done, err
+			}
+
+		case *parser.ReturnStmt:
+			if s.Value != nil {
+				value, err := f.eval(s.Value)
+				if err != nil {
+					return false, err
+				}
+				f.value, f.returned = value, true
+			}
+			return true, nil
+
+		case *parser.SignalStmt:
+			message := "unhandled user-defined exception"
+			if s.Message != nil {
+				value, err := f.eval(s.Message)
+				if err != nil {
+					return false, err
+				}
+				message = types.FormatValue(value)
+			}
+			return false, fmt.Errorf("%s (SQLSTATE %s)", message, s.State)
+
+		case *parser.SQLStatement:
+			if err := f.sql(s); err != nil {
+				return false, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// loop runs the body of a FOR loop for each row of its query. The rows are read before the
+// body first runs, so changes the body makes do not affect which rows it sees.
+func (f *frame) loop(s *parser.ForStmt) (bool, error) {
+	key := strings.ToLower(s.Row)
+	if f.rows[key] != nil {
+		return false, fmt.Errorf("row %s is already in use by an enclosing FOR loop", s.Row)
+	}
+	if err := f.authorize(s.QueryText); err != nil {
+		return false, err
+	}
+	query := f.bindSelect(s.Query)
+	if query.Into != nil {
+		return false, fmt.Errorf("the query of a FOR loop cannot have INTO")
+	}
+	result, err := Select(f.run.db, query)
+	if err != nil {
+		return false, err
+	}
+	defer delete(f.rows, key)
+	for _, row := range result.Rows {
+		f.rows[key] = &record{columns: result.Columns, values: row}
+		if done, err := f.exec(s.Body); done || err != nil {
+			return done, err
+		}
+		if err := f.step(); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// authorize checks a SQL statement of the body with the caller's Authorize function
+func (f *frame) authorize(sql string) error {
+	if f.run.opts.Authorize == nil {
+		return nil
+	}
+	return f.run.opts.Authorize(sql)
+}
+
+// sql runs a SELECT, INSERT, UPDATE, DELETE or CALL of the body, with the current values of
+// the variables in place of their names
+func (f *frame) sql(s *parser.SQLStatement) error {
+	if err := f.authorize(s.Text); err != nil {
+		return err
+	}
+	db, strict := f.run.db, f.run.opts.Strict
+	switch stmt := s.Stmt.(type) {
+	case *parser.SelectStmt:
+		query := f.bindSelect(stmt)
+		into := query.Into
+		query.Into = nil
+		result, err := Select(db, query)
+		if err != nil {
+			return err
+		}
+		if into == nil {
+			f.run.result.Results = append(f.run.result.Results, result)
+			return nil
+		}
+		return f.selectInto(into, result)
+
+	case *parser.InsertStmt:
+		bound := *stmt
+		bound.Rows = make([][]parser.Expr, len(stmt.Rows))
+		for i, row := range stmt.Rows {
+			bound.Rows[i] = f.bindAll(row)
+		}
+		n, err := Insert(db, &bound, strict)
+		f.run.result.Changed += n
+		return err
+
+	case *parser.UpdateStmt:
+		bound := *stmt
+		bound.Set = make([]parser.Assignment, len(stmt.Set))
+		for i, a := range stmt.Set {
+			bound.Set[i] = parser.Assignment{Column: a.Column, Value: f.bind(a.Value)}
+		}
+		bound.Where = f.bind(stmt.Where)
+		n, err := Update(db, &bound, strict)
+		f.run.result.Changed += n
+		return err
+
+	case *parser.DeleteStmt:
+		bound := *stmt
+		bound.Where = f.bind(stmt.Where)
+		n, err := Delete(db, &bound)
+		f.run.result.Changed += n
+		return err
+
+	case *parser.CallStmt:
+		args, err := evalArgs(stmt.Args, f.bind, db)
+		if err != nil {
+			return err
+		}
+		_, _, err = f.run.call(stmt.Name, args, f.depth+1)
+		return err
+	}
+	return fmt.Errorf("unsupported statement: %s", s.Text)
+}
+
+// selectInto sets variables from the single row of a query result, or to NULL if it has none
+func (f *frame) selectInto(into []string, result *types.ResultSet) error {
+	if len(into) != len(result.Columns) {
+		return fmt.Errorf("SELECT INTO sets %d variables but selects %d columns", len(into), len(result.Columns))
+	}
+	if len(result.Rows) > 1 {
+		return fmt.Errorf("SELECT INTO returned %d rows, expected at most one", len(result.Rows))
+	}
+	for i, name := range into {
+		var value interface{}
+		if len(result.Rows) == 1 {
+			value = result.Rows[0][i]
+		}
+		if err := f.assign(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eval evaluates an expression of the body, e.g. the value of SET
+func (f *frame) eval(expr parser.Expr) (interface{}, error) {
+	return Eval(f.bind(expr), &Scope{db: f.run.db})
+}
+
+// condition evaluates the condition of IF or WHILE; NULL counts as false
+func (f *frame) condition(expr parser.Expr) (bool, error) {
+	return EvalCondition(f.bind(expr), &Scope{db: f.run.db})
+}
+
+// bind replaces the names of variables and the columns of FOR loop rows in an expression
+// with their current values. Variables take precedence over the columns of tables with
+// the same name.
+func (f *frame) bind(expr parser.Expr) parser.Expr {
+	return parser.Rewrite(expr, func(e parser.Expr) parser.Expr {
+		ref, ok := e.(*parser.ColumnRef)
+		if !ok {
+			return nil
+		}
+		if ref.Table == "" {
+			if v := f.vars[strings.ToLower(ref.Name)]; v != nil {
+				return &parser.Literal{Value: v.value}
+			}
+			return nil
+		}
+		if row := f.rows[strings.ToLower(ref.Table)]; row != nil {
+			if i := indexOfColumn(row.columns, ref.Name); i != -1 {
+				return &parser.Literal{Value: row.values[i]}
+			}
+		}
+		return nil
+	})
+}
+
+func (f *frame) bindAll(exprs []parser.Expr) []parser.Expr {
+	if exprs == nil {
+		return nil
+	}
+	bound := make([]parser.Expr, len(exprs))
+	for i, e := range exprs {
+		bound[i] = f.bind(e)
+	}
+	return bound
+}
+
+// bindSelect returns a copy of a SELECT with variables bound. A selected variable keeps
+// its name as the column label.
+func (f *frame) bindSelect(stmt *parser.SelectStmt) *parser.SelectStmt {
+	bound := *stmt
+	bound.Columns = make([]parser.SelectItem, len(stmt.Columns))
+	for i, item := range stmt.Columns {
+		bound.Columns[i] = item
+		if item.Star {
+			continue
+		}
+		bound.Columns[i].Expr = f.bind(item.Expr)
+		if ref, ok := item.Expr.(*parser.ColumnRef); ok && item.Alias == "" {
+			if _, replaced := bound.Columns[i].Expr.(*parser.Literal); replaced {
+				bound.Columns[i].Alias = ref.Name
+			}
+		}
+	}
+	bound.Joins = make([]parser.Join, len(stmt.Joins))
+	for i, join := range stmt.Joins {
+		bound.Joins[i] = join
+		bound.Joins[i].On = f.bind(join.On)
+	}
+	bound.Where = f.bind(stmt.Where)
+	bound.GroupBy = f.bindAll(stmt.GroupBy)
+	bound.Having = f.bind(stmt.Having)
+	bound.OrderBy = make([]parser.OrderItem, len(stmt.OrderBy))
+	for i, item := range stmt.OrderBy {
+		bound.OrderBy[i] = parser.OrderItem{Expr: f.bind(item.Expr), Desc: item.Desc}
+	}
+	bound.Limit = f.bind(stmt.Limit)
+	bound.Offset = f.bind(stmt.Offset)
+	return &bound
+}
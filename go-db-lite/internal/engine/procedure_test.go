@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+func callProcedure(t *testing.T, db *types.Database, input string) (*CallResult, error) {
+	t.Helper()
+	stmt, err := parser.ParseCall(input)
+	if err != nil {
+		t.Fatalf("parse %q: %v", input, err)
+	}
+	return CallProcedure(db, stmt, CallOptions{Strict: true})
+}
+
+// Test that a procedure can loop over a query, branch on its variables, change rows and
+// return a value
+func TestProcedures(t *testing.T) {
+	db := newTestDatabase(t)
+	UseValidator(db, true)
+	body := "create procedure settle (acc varchar, fee decimal(10,2)) begin " +
+		"declare total decimal(10,2) default 0; declare n int; " +
+		"for r in (select amount from ledger where account = acc) do " +
+		"if r.amount is not null then set total = total + r.amount; end if; end for; " +
+		"select count(*) into n from ledger where account = acc; " +
+		"while n > 0 do set total = total - fee; set n = n - 1; end while; " +
+		"insert into ledger values (acc, total); return total; end"
+	if err := execSQL(t, db, body); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := callProcedure(t, db, "call settle('b', 0.5)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Returned || types.FormatValue(result.Value) != "18.99" || result.Changed != 1 {
+		t.Errorf("settle returned %v (%v) after changing %d rows", result.Value, result.Returned, result.Changed)
+	}
+	query := "select amount from ledger where account = 'b' order by amount desc"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"19.99"}, {"18.99"}, {"NULL"}})
+
+	if _, err := callProcedure(t, db, "call settle('b')"); err == nil {
+		t.Error("a call with too few arguments ran")
+	}
+	if _, err := callProcedure(t, db, "call missing()"); err == nil {
+		t.Error("a missing procedure was called")
+	}
+}
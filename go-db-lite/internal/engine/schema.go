@@ -0,0 +1,204 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// NewTable builds a table from the parsed columns and table constraints of a CREATE TABLE statement.
+// Foreign keys are resolved against the database later, by Database.ValidateForeignKeys.
+func NewTable(name string, defs []parser.ColumnDef, constraints []parser.TableConstraint) (types.Table, error) {
+	columns := []types.Column{}
+	for _, def := range defs {
+		column, err := ColumnFromDef(def)
+		if err != nil {
+			return types.Table{}, err
+		}
+		columns = append(columns, column)
+	}
+	table := types.Table{}
+	table.CreateTable(name, columns)
+
+	var primaryKey, identity []string
+	for _, def := range defs {
+		if def.PrimaryKey {
+			primaryKey = append(primaryKey, def.Name)
+		}
+		if def.Identity != "" {
+			identity = append(identity, def.Name)
+		}
+	}
+	if len(identity) > 1 {
+		return types.Table{}, fmt.Errorf("a table can have only one identity column, found %s", strings.Join(identity, ", "))
+	}
+	if len(primaryKey) > 1 {
+		return types.Table{}, fmt.Errorf("multiple columns are declared PRIMARY KEY; use a table-level PRIMARY KEY (%s) for a composite key", strings.Join(primaryKey, ", "))
+	}
+	for _, def := range defs {
+		if err := addColumnConstraints(&table, def); err != nil {
+			return types.Table{}, err
+		}
+	}
+	for _, constraint := range constraints {
+		switch constraint.Kind {
+		case "PRIMARY KEY":
+			if err := table.SetPrimaryKey(constraint.Columns); err != nil {
+				return types.Table{}, err
+			}
+		case "FOREIGN KEY":
+			if err := addForeignKey(&table, constraint.Name, constraint.Columns, constraint.References); err != nil {
+				return types.Table{}, err
+			}
+		case "CHECK":
+			table.AddCheck(constraint.Name, ExprText(constraint.Check))
+		}
+	}
+	return table, nil
+}
+
+// addColumnConstraints adds the inline PRIMARY KEY, CHECK and REFERENCES constraints of a column definition
+func addColumnConstraints(table *types.Table, def parser.ColumnDef) error {
+	if def.PrimaryKey {
+		if err := table.SetPrimaryKey([]string{def.Name}); err != nil {
+			return err
+		}
+	}
+	if def.Check != nil {
+		table.AddCheck("", ExprText(def.Check))
+	}
+	if def.References != nil {
+		return addForeignKey(table, "", []string{def.Name}, def.References)
+	}
+	return nil
+}
+
+// ExprText returns the SQL text of an expression stored in the schema, without enclosing parentheses
+func ExprText(expr parser.Expr) string {
+	text := expr.String()
+	if _, ok := expr.(*parser.BinaryExpr); ok {
+		text = text[1 : len(text)-1]
+	}
+	return text
+}
+
+// addForeignKey adds a parsed REFERENCES clause on the given columns to the table
+func addForeignKey(table *types.Table, name string, columns []string, refs *parser.References) error {
+	onDelete, err := types.ParseReferentialAction(refs.OnDelete)
+	if err != nil {
+		return err
+	}
+	onUpdate, err := types.ParseReferentialAction(refs.OnUpdate)
+	if err != nil {
+		return err
+	}
+	return table.AddForeignKey(name, columns, refs.Table, refs.Columns, onDelete, onUpdate)
+}
+
+// ColumnFromDef converts a parsed column definition to a column. Constraints that involve
+// the table, such as PRIMARY KEY, CHECK and REFERENCES, are added separately.
+func ColumnFromDef(def parser.ColumnDef) (types.Column, error) {
+	var nameBytes [64]byte
+	copy(nameBytes[:], def.Name)
+	column := types.Column{Name: nameBytes, Nullable: !def.NotNull}
+	if err := setColumnType(&column, def.Type); err != nil {
+		return types.Column{}, err
+	}
+	if def.Default != nil {
+		column.Default = ExprText(def.Default)
+	}
+	if def.Identity != "" {
+		if column.DataType != types.SQL_TYPE_INT && column.DataType != types.SQL_TYPE_BIGINT {
+			return types.Column{}, fmt.Errorf("column %s: only INT and BIGINT columns can be %s", def.Name, def.Identity)
+		}
+		if def.Default != nil {
+			return types.Column{}, fmt.Errorf("column %s: an identity column cannot have a DEFAULT", def.Name)
+		}
+		column.Identity = types.IdentityByDefault
+		if def.Identity == "ALWAYS" {
+			column.Identity = types.IdentityAlways
+		}
+		column.Nullable = false
+	}
+	return column, nil
+}
+
+// setColumnType sets the data type of a column and its type parameters from a type specification
+func setColumnType(column *types.Column, spec parser.TypeSpec) error {
+	name := column.GetName()
+	dataType := types.GetDataTypeFromString(spec.Name)
+	if dataType == types.SQL_TYPE_UNKNOWN {
+		return fmt.Errorf("unknown data type %s for column %s", spec.Name, name)
+	}
+	column.DataType = dataType
+	column.Precision, column.Scale, column.Size, column.Labels = 0, 0, 0, nil
+	var err error
+	switch dataType {
+	case types.SQL_TYPE_DECIMAL:
+		if column.Precision, column.Scale, err = parseDecimalArgs(spec.Args); err != nil {
+			return fmt.Errorf("column %s: %v", name, err)
+		}
+	case types.SQL_TYPE_VARCHAR, types.SQL_TYPE_CHAR, types.SQL_TYPE_BINARY, types.SQL_TYPE_VARBINARY:
+		if column.Size, err = parseSizeArg(dataType, spec.Args); err != nil {
+			return fmt.Errorf("column %s: %v", name, err)
+		}
+	case types.SQL_TYPE_ENUM, types.SQL_TYPE_SET:
+		column.Labels = spec.Args
+		if err := column.ValidateLabels(); err != nil {
+			return fmt.Errorf("column %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// parseDecimalArgs validates the (precision, scale) parameters of a DECIMAL declaration
+func parseDecimalArgs(args []string) (int, int, error) {
+	precision, scale := types.DefaultDecimalPrecision, types.DefaultDecimalScale
+	if len(args) > 2 {
+		return 0, 0, fmt.Errorf("DECIMAL takes at most two parameters")
+	}
+	var err error
+	if len(args) >= 1 {
+		if precision, err = strconv.Atoi(args[0]); err != nil {
+			return 0, 0, fmt.Errorf("invalid DECIMAL precision %q", args[0])
+		}
+	}
+	if len(args) == 2 {
+		if scale, err = strconv.Atoi(args[1]); err != nil {
+			return 0, 0, fmt.Errorf("invalid DECIMAL scale %q", args[1])
+		}
+	}
+	if precision < 1 || precision > types.MaxDecimalPrecision {
+		return 0, 0, fmt.Errorf("DECIMAL precision must be between 1 and %d", types.MaxDecimalPrecision)
+	}
+	if scale < 0 || scale > types.MaxDecimalScale || scale > precision {
+		return 0, 0, fmt.Errorf("DECIMAL scale must be between 0 and min(precision, %d)", types.MaxDecimalScale)
+	}
+	return precision, scale, nil
+}
+
+// parseSizeArg validates the length parameter of a CHAR, VARCHAR, BINARY or VARBINARY declaration.
+// CHAR and BINARY default to a length of 1, VARCHAR and VARBINARY to unbounded (0).
+func parseSizeArg(dataType types.DataType, args []string) (int, error) {
+	typeName := dataType.GetDataTypeString()
+	if len(args) == 0 {
+		if dataType == types.SQL_TYPE_CHAR || dataType == types.SQL_TYPE_BINARY {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	if len(args) > 1 {
+		return 0, fmt.Errorf("%s takes a single length parameter", typeName)
+	}
+	size, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s length %q", typeName, args[0])
+	}
+	if size < 1 || size > dataType.MaxSize() {
+		return 0, fmt.Errorf("%s length must be between 1 and %d", typeName, dataType.MaxSize())
+	}
+	return size, nil
+}
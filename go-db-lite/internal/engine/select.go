@@ -0,0 +1,455 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// FindTable returns the table with the given name (case insensitive), or nil
+func FindTable(db *types.Database, name string) *types.Table {
+	return db.FindTable(name)
+}
+
+// source is the input of a query: the visible columns and the rows feeding them
+type source struct {
+	columns []ScopeColumn
+	rows    [][]interface{}
+}
+
+// tableSource reads every row of a table into a source
+func tableSource(table *types.Table, alias string) (*source, error) {
+	name := table.GetName()
+	if alias != "" {
+		name = alias
+	}
+	src := &source{}
+	for _, colName := range table.GetColumnNames() {
+		src.columns = append(src.columns, ScopeColumn{Table: name, Name: colName})
+	}
+	for i := range table.Rows {
+		values, err := table.GetRowValues(i)
+		if err != nil {
+			return nil, err
+		}
+		src.rows = append(src.rows, values)
+	}
+	return src, nil
+}
+
+// relationSource reads a table, or expands a view, named in a FROM clause. A name
+// qualified with that of an attached database is looked up there.
+func relationSource(db *types.Database, from *parser.TableName) (*source, error) {
+	if strings.EqualFold(from.Schema, InformationSchema) {
+		return informationSchemaSource(db, from)
+	}
+	if from.Schema != "" {
+		other, err := db.AttachedDatabase(from.Schema)
+		if err != nil {
+			return nil, err
+		}
+		return relationSource(other, &parser.TableName{Name: from.Name, Alias: from.Alias})
+	}
+	if table := FindTable(db, from.Name); table != nil {
+		return tableSource(table, from.Alias)
+	}
+	if view := db.FindView(from.Name); view != nil {
+		return viewSource(db, view, from.Alias)
+	}
+	return nil, fmt.Errorf("table not found: %s", from.Name)
+}
+
+// fromSource reads the tables and views of the FROM clause, joining them in order
+func fromSource(db *types.Database, stmt *parser.SelectStmt) (*source, error) {
+	src, err := relationSource(db, stmt.From)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{strings.ToLower(relationName(stmt.From)): true}
+	for _, join := range stmt.Joins {
+		name := relationName(join.Table)
+		if names[strings.ToLower(name)] {
+			return nil, fmt.Errorf("table name %s is used more than once in FROM; give it an alias", name)
+		}
+		names[strings.ToLower(name)] = true
+		right, err := relationSource(db, join.Table)
+		if err != nil {
+			return nil, err
+		}
+		if src, err = joinSources(db, src, right, join); err != nil {
+			return nil, err
+		}
+	}
+	return src, nil
+}
+
+// relationName is the name the columns of a table or view in FROM are qualified with
+func relationName(from *parser.TableName) string {
+	if from.Alias != "" {
+		return from.Alias
+	}
+	return from.Name
+}
+
+// joinSources pairs every row of left with every row of right meeting the join condition.
+// A LEFT join keeps the left rows matching none, with NULLs for the right columns, and a
+// RIGHT join likewise keeps the unmatched right rows.
+func joinSources(db *types.Database, left, right *source, join parser.Join) (*source, error) {
+	joined := &source{columns: append(append([]ScopeColumn{}, left.columns...), right.columns...)}
+	scope := NewScope(joined.columns)
+	scope.db = db
+	matchedRight := make([]bool, len(right.rows))
+	for _, l := range left.rows {
+		matched := false
+		for j, r := range right.rows {
+			values := append(append(make([]interface{}, 0, len(joined.columns)), l...), r...)
+			ok, err := EvalCondition(join.On, scope.WithValues(values))
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				joined.rows = append(joined.rows, values)
+				matched, matchedRight[j] = true, true
+			}
+		}
+		if !matched && join.Kind == "LEFT" {
+			joined.rows = append(joined.rows, append(append([]interface{}{}, l...), make([]interface{}, len(right.columns))...))
+		}
+	}
+	if join.Kind == "RIGHT" {
+		for j, r := range right.rows {
+			if !matchedRight[j] {
+				joined.rows = append(joined.rows, append(make([]interface{}, len(left.columns)), r...))
+			}
+		}
+	}
+	return joined, nil
+}
+
+// outputRow is one row of the result together with its ORDER BY keys
+type outputRow struct {
+	values []interface{}
+	keys   []interface{}
+}
+
+// Select executes a SELECT statement against the database
+func Select(db *types.Database, stmt *parser.SelectStmt) (*types.ResultSet, error) {
+	if stmt.Into != nil {
+		return nil, fmt.Errorf("SELECT INTO is only allowed in a procedure")
+	}
+	src := &source{rows: [][]interface{}{nil}} // SELECT without FROM yields a single row
+	if stmt.From != nil {
+		var err error
+		if src, err = fromSource(db, stmt); err != nil {
+			return nil, err
+		}
+	}
+	scope := NewScope(src.columns)
+	scope.db = db
+
+	// WHERE
+	var rows [][]interface{}
+	for _, values := range src.rows {
+		ok, err := EvalCondition(stmt.Where, scope.WithValues(values))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rows = append(rows, values)
+		}
+	}
+
+	// expand the select list
+	var items []parser.SelectItem
+	for _, item := range stmt.Columns {
+		if !item.Star {
+			items = append(items, item)
+			continue
+		}
+		expanded := false
+		for _, col := range src.columns {
+			if item.Table == "" || strings.EqualFold(item.Table, col.Table) {
+				items = append(items, parser.SelectItem{Expr: &parser.ColumnRef{Table: col.Table, Name: col.Name}, Alias: col.Name})
+				expanded = true
+			}
+		}
+		if !expanded {
+			return nil, fmt.Errorf("no columns match %s.*", item.Table)
+		}
+	}
+
+	result := &types.ResultSet{}
+	for _, item := range items {
+		name := item.Alias
+		if name == "" {
+			name = columnLabel(item.Expr)
+		}
+		result.Columns = append(result.Columns, name)
+	}
+
+	exprs := make([]parser.Expr, 0, len(items)+len(stmt.OrderBy)+1)
+	for _, item := range items {
+		exprs = append(exprs, item.Expr)
+	}
+	for _, order := range stmt.OrderBy {
+		exprs = append(exprs, order.Expr)
+	}
+	exprs = append(exprs, stmt.Having)
+	aggregates := findAggregates(exprs...)
+
+	var output []outputRow
+	if len(aggregates) > 0 || len(stmt.GroupBy) > 0 {
+		groups, err := groupRows(rows, stmt.GroupBy, aggregates, scope)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			groupScope := scope.WithValues(group.first)
+			groupScope.aggregates = group.values
+			ok, err := EvalCondition(stmt.Having, groupScope)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			row, err := projectRow(items, result.Columns, stmt.OrderBy, groupScope)
+			if err != nil {
+				return nil, err
+			}
+			output = append(output, row)
+		}
+	} else {
+		if stmt.Having != nil {
+			return nil, fmt.Errorf("HAVING requires GROUP BY or an aggregate")
+		}
+		for _, values := range rows {
+			row, err := projectRow(items, result.Columns, stmt.OrderBy, scope.WithValues(values))
+			if err != nil {
+				return nil, err
+			}
+			output = append(output, row)
+		}
+	}
+
+	if stmt.Distinct {
+		seen := map[string]bool{}
+		unique := output[:0]
+		for _, row := range output {
+			key := groupKey(row.values)
+			if !seen[key] {
+				seen[key] = true
+				unique = append(unique, row)
+			}
+		}
+		output = unique
+	}
+
+	if len(stmt.OrderBy) > 0 {
+		var sortErr error
+		sort.SliceStable(output, func(i, j int) bool {
+			for k, order := range stmt.OrderBy {
+				c, err := compareNullsFirst(output[i].keys[k], output[j].keys[k])
+				if err != nil {
+					sortErr = err
+					return false
+				}
+				if c != 0 {
+					return (c < 0) != order.Desc
+				}
+			}
+			return false
+		})
+		if sortErr != nil {
+			return nil, sortErr
+		}
+	}
+
+	output, err := applyLimit(output, stmt.Limit, stmt.Offset)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range output {
+		result.Rows = append(result.Rows, row.values)
+	}
+	return result, nil
+}
+
+// group is a set of rows sharing the same GROUP BY key, with its computed aggregate values
+type group struct {
+	first       []interface{}
+	aggregators []aggregator
+	values      map[*parser.FuncCall]interface{}
+}
+
+func groupRows(rows [][]interface{}, groupBy []parser.Expr, aggregates []*parser.FuncCall, scope *Scope) ([]*group, error) {
+	var groups []*group
+	byKey := map[string]*group{}
+	for _, values := range rows {
+		rowScope := scope.WithValues(values)
+		keyValues := make([]interface{}, len(groupBy))
+		for i, expr := range groupBy {
+			value, err := Eval(expr, rowScope)
+			if err != nil {
+				return nil, err
+			}
+			keyValues[i] = value
+		}
+		key := groupKey(keyValues)
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{first: values}
+			for _, call := range aggregates {
+				agg, err := newAggregator(call)
+				if err != nil {
+					return nil, err
+				}
+				g.aggregators = append(g.aggregators, agg)
+			}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		for i, call := range aggregates {
+			var value interface{}
+			if !call.Star {
+				var err error
+				if value, err = Eval(call.Args[0], rowScope); err != nil {
+					return nil, err
+				}
+			}
+			if err := g.aggregators[i].add(value); err != nil {
+				return nil, fmt.Errorf("%s: %v", call.Name, err)
+			}
+		}
+	}
+
+	// an aggregate query without GROUP BY always produces one row, even over no input
+	if len(groups) == 0 && len(groupBy) == 0 {
+		g := &group{}
+		for _, call := range aggregates {
+			agg, err := newAggregator(call)
+			if err != nil {
+				return nil, err
+			}
+			g.aggregators = append(g.aggregators, agg)
+		}
+		groups = append(groups, g)
+	}
+
+	for _, g := range groups {
+		g.values = map[*parser.FuncCall]interface{}{}
+		for i, call := range aggregates {
+			value, err := g.aggregators[i].result()
+			if err != nil {
+				return nil, err
+			}
+			g.values[call] = value
+		}
+	}
+	return groups, nil
+}
+
+// projectRow evaluates the select list and ORDER BY keys for one row (or group)
+func projectRow(items []parser.SelectItem, names []string, orderBy []parser.OrderItem, scope *Scope) (outputRow, error) {
+	row := outputRow{values: make([]interface{}, len(items))}
+	for i, item := range items {
+		value, err := Eval(item.Expr, scope)
+		if err != nil {
+			return row, err
+		}
+		row.values[i] = value
+	}
+	for _, order := range orderBy {
+		key, err := orderKey(order.Expr, items, names, row.values, scope)
+		if err != nil {
+			return row, err
+		}
+		row.keys = append(row.keys, key)
+	}
+	return row, nil
+}
+
+// orderKey evaluates an ORDER BY expression, which may name an output column or be a 1-based position
+func orderKey(expr parser.Expr, items []parser.SelectItem, names []string, values []interface{}, scope *Scope) (interface{}, error) {
+	if lit, ok := expr.(*parser.Literal); ok {
+		if pos, ok := lit.Value.(int64); ok {
+			if pos < 1 || int(pos) > len(values) {
+				return nil, fmt.Errorf("ORDER BY position %d is out of range", pos)
+			}
+			return values[pos-1], nil
+		}
+	}
+	if ref, ok := expr.(*parser.ColumnRef); ok && ref.Table == "" {
+		for i, item := range items {
+			if item.Alias != "" && strings.EqualFold(names[i], ref.Name) {
+				return values[i], nil
+			}
+		}
+	}
+	return Eval(expr, scope)
+}
+
+// compareNullsFirst orders NULL before every other value
+func compareNullsFirst(a, b interface{}) (int, error) {
+	switch {
+	case a == nil && b == nil:
+		return 0, nil
+	case a == nil:
+		return -1, nil
+	case b == nil:
+		return 1, nil
+	}
+	return compareValues(a, b)
+}
+
+func applyLimit(rows []outputRow, limitExpr, offsetExpr parser.Expr) ([]outputRow, error) {
+	offset, err := evalCount(offsetExpr, "OFFSET")
+	if err != nil {
+		return nil, err
+	}
+	if offset >= int64(len(rows)) {
+		return nil, nil
+	}
+	rows = rows[offset:]
+	if limitExpr != nil {
+		limit, err := evalCount(limitExpr, "LIMIT")
+		if err != nil {
+			return nil, err
+		}
+		if limit < int64(len(rows)) {
+			rows = rows[:limit]
+		}
+	}
+	return rows, nil
+}
+
+// evalCount evaluates a constant non-negative integer such as a LIMIT
+func evalCount(expr parser.Expr, clause string) (int64, error) {
+	if expr == nil {
+		return 0, nil
+	}
+	value, err := Eval(expr, nil)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := value.(int64)
+	if !ok || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", clause)
+	}
+	return n, nil
+}
+
+// columnLabel returns the header used for an unaliased select expression
+func columnLabel(expr parser.Expr) string {
+	if ref, ok := expr.(*parser.ColumnRef); ok {
+		return ref.Name
+	}
+	label := expr.String()
+	if strings.HasPrefix(label, "(") && strings.HasSuffix(label, ")") {
+		label = label[1 : len(label)-1]
+	}
+	return label
+}
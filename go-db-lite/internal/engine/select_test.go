@@ -0,0 +1,230 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// newTestDatabase creates a database with a "ledger" table holding a few decimal amounts, then
+// runs the given statements on it, which may create more tables
+func newTestDatabase(t *testing.T, statements ...string) *types.Database {
+	t.Helper()
+	db := types.NewDatabase()
+	table := types.Table{}
+	table.CreateTable("ledger", nil)
+	table.AddColumn("account", types.SQL_TYPE_VARCHAR, false)
+	table.AddColumn("amount", types.SQL_TYPE_DECIMAL, true)
+	table.Columns[1].Precision, table.Columns[1].Scale = 10, 2
+	rows := [][]interface{}{
+		{"a", "0.10"},
+		{"a", "0.20"},
+		{"b", "19.99"},
+		{"b", nil},
+	}
+	for _, row := range rows {
+		if row[1] != nil {
+			d, err := types.ParseDecimal(row[1].(string))
+			if err != nil {
+				t.Fatal(err)
+			}
+			row[1] = d
+		}
+		if err := table.AddRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.AddTable(table)
+	for _, statement := range statements {
+		if err := execSQL(t, db, statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	return db
+}
+
+// execSQL runs a CREATE TABLE, VIEW, TRIGGER or PROCEDURE, ALTER TABLE, INSERT, UPDATE or
+// DELETE statement on db
+func execSQL(t *testing.T, db *types.Database, input string) error {
+	t.Helper()
+	var err error
+	words := strings.Fields(strings.ToUpper(input))
+	switch words[0] {
+	case "CREATE":
+		return execCreate(db, input, words)
+	case "ALTER":
+		var stmt *parser.AlterTableStmt
+		if stmt, err = parser.ParseAlterTable(input); err == nil {
+			err = AlterTable(db, stmt, true)
+		}
+	case "INSERT":
+		var stmt *parser.InsertStmt
+		if stmt, err = parser.ParseInsert(input); err == nil {
+			_, err = Insert(db, stmt, true)
+		}
+	case "UPDATE":
+		var stmt *parser.UpdateStmt
+		if stmt, err = parser.ParseUpdate(input); err == nil {
+			_, err = Update(db, stmt, true)
+		}
+	case "DELETE":
+		var stmt *parser.DeleteStmt
+		if stmt, err = parser.ParseDelete(input); err == nil {
+			_, err = Delete(db, stmt)
+		}
+	default:
+		t.Fatalf("execSQL cannot run %q", input)
+	}
+	return err
+}
+
+// execCreate runs the CREATE statements of execSQL, given the upper case words of the input
+func execCreate(db *types.Database, input string, words []string) error {
+	for _, word := range words[1:] {
+		switch word {
+		case "TABLE":
+			open, end := strings.Index(input, "("), strings.LastIndex(input, ")")
+			defs, constraints, err := parser.ParseColumnDefs(input[open+1 : end])
+			if err != nil {
+				return err
+			}
+			table, err := NewTable(strings.Fields(input[:open])[2], defs, constraints)
+			if err != nil {
+				return err
+			}
+			db.AddTable(table)
+			return nil
+		case "VIEW":
+			stmt, err := parser.ParseCreateView(input)
+			if err != nil {
+				return err
+			}
+			return CreateView(db, stmt)
+		case "TRIGGER":
+			stmt, err := parser.ParseCreateTrigger(input)
+			if err != nil {
+				return err
+			}
+			return CreateTrigger(db, stmt)
+		case "PROCEDURE":
+			stmt, err := parser.ParseCreateProcedure(input)
+			if err != nil {
+				return err
+			}
+			return CreateProcedure(db, stmt)
+		}
+	}
+	return fmt.Errorf("execSQL cannot run %q", input)
+}
+
+func runQuery(t *testing.T, db *types.Database, query string) [][]string {
+	t.Helper()
+	stmt, err := parser.ParseSelect(query)
+	if err != nil {
+		t.Fatalf("parse %q: %v", query, err)
+	}
+	result, err := Select(db, stmt)
+	if err != nil {
+		t.Fatalf("select %q: %v", query, err)
+	}
+	var rows [][]string
+	for _, row := range result.Rows {
+		var formatted []string
+		for _, value := range row {
+			formatted = append(formatted, types.FormatValue(value))
+		}
+		rows = append(rows, formatted)
+	}
+	return rows
+}
+
+func expectRows(t *testing.T, query string, got [][]string, want [][]string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %v, want %v", query, got, want)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if got[i][j] != want[i][j] {
+				t.Errorf("%s: row %d column %d = %s, want %s", query, i, j, got[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+// Test exact decimal arithmetic in expressions
+func TestDecimalExpressions(t *testing.T) {
+	db := newTestDatabase(t)
+	query := "select 0.1 + 0.2, 19.99 * 3, 7 / 2, 1.005 * 1000, round(2.345, 2)"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"0.3", "59.97", "3.5000", "1005.000", "2.35"}})
+
+	query = "select amount * 2 from ledger where amount >= 0.2 order by amount desc"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"39.98"}, {"0.40"}})
+}
+
+// Test exact decimal aggregates with and without GROUP BY
+func TestDecimalAggregates(t *testing.T) {
+	db := newTestDatabase(t)
+	query := "select sum(amount), avg(amount), count(*), count(amount), min(amount), max(amount) from ledger"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"20.29", "6.763333", "4", "3", "0.10", "19.99"}})
+
+	query = "select account, sum(amount) as total from ledger group by account having sum(amount) > 1 order by total"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"b", "19.99"}})
+
+	query = "select sum(amount) from ledger where account = 'z'"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"NULL"}})
+}
+
+// Test that tables of an attached database are read with their qualified names
+func TestAttachedDatabases(t *testing.T) {
+	db := newTestDatabase(t)
+	other := newTestDatabase(t,
+		"create table owners (account varchar(10) not null, owner varchar(10) not null)",
+		"insert into owners values ('b', 'bob')")
+	db.Attached = map[string]*types.Database{"crm": other}
+
+	query := "select o.owner, count(*) from ledger l join crm.owners o on o.account = l.account group by o.owner"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"bob", "2"}})
+
+	stmt, err := parser.ParseSelect("select * from owners")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Select(db, stmt); err == nil {
+		t.Error("a table of an attached database was read without its database name")
+	}
+	if err := execSQL(t, db, "create view owned as select * from crm.owners"); err == nil {
+		t.Error("a view reading an attached database was created")
+	}
+}
+
+// Test that information_schema describes the tables, columns and views of the database
+func TestInformationSchema(t *testing.T) {
+	db := newTestDatabase(t)
+	if err := execSQL(t, db, "create view big as select account from ledger where amount > 1"); err != nil {
+		t.Fatal(err)
+	}
+	query := "select table_name, table_type, column_count from information_schema.tables order by table_name"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"big", "VIEW", "NULL"}, {"ledger", "BASE TABLE", "2"}})
+
+	query = "select column_name, ordinal_position, data_type, is_nullable, numeric_scale from information_schema.columns order by table_name, ordinal_position"
+	expectRows(t, query, runQuery(t, db, query), [][]string{
+		{"account", "1", "NULL", "YES", "NULL"},
+		{"account", "1", "VARCHAR", "NO", "NULL"},
+		{"amount", "2", "DECIMAL(10,2)", "YES", "2"},
+	})
+
+	query = "select v.table_name, v.is_materialized, t.table_type from information_schema.views v join information_schema.tables t on t.table_name = v.table_name"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"big", "NO", "VIEW"}})
+
+	stmt, err := parser.ParseSelect("select * from information_schema.nothing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Select(db, stmt); err == nil {
+		t.Error("an unknown information_schema table was read")
+	}
+}
@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// CreateTrigger checks the body of a CREATE TRIGGER statement against its table and stores
+// the trigger. Like those of a procedure, the SQL statements of the body are only checked
+// when they run.
+func CreateTrigger(db *types.Database, stmt *parser.CreateTriggerStmt) error {
+	table := FindTable(db, stmt.Table)
+	if table == nil {
+		return fmt.Errorf("table not found: %s", stmt.Table)
+	}
+	if err := checkDeclarations(stmt.Body); err != nil {
+		return err
+	}
+	if err := checkTriggerBody(stmt, table, stmt.Body); err != nil {
+		return err
+	}
+	return db.CreateTrigger(types.Trigger{
+		Name:   stmt.Name,
+		Table:  stmt.Table,
+		Timing: stmt.Timing,
+		Event:  stmt.Event,
+		Body:   stmt.BodyText,
+	})
+}
+
+// checkTriggerBody checks that a trigger body only sets the columns of NEW where it can be
+// changed, and neither returns rows nor a value
+func checkTriggerBody(stmt *parser.CreateTriggerStmt, table *types.Table, body []parser.Statement) error {
+	for _, s := range body {
+		var err error
+		switch s := s.(type) {
+		case *parser.SetStmt:
+			if s.Row == "" {
+				break
+			}
+			if !strings.EqualFold(s.Row, "NEW") || stmt.Timing != "BEFORE" || stmt.Event == "DELETE" {
+				return fmt.Errorf("cannot set %s.%s; only NEW can be set, in a BEFORE INSERT or BEFORE UPDATE trigger", s.Row, s.Name)
+			}
+			if table.ColumnIndex(s.Name) == -1 {
+				return fmt.Errorf("column %s not found in table %s", s.Name, table.GetName())
+			}
+		case *parser.IfStmt:
+			for _, branch := range append(s.Bodies, s.Else) {
+				if err = checkTriggerBody(stmt, table, branch); err != nil {
+					break
+				}
+			}
+		case *parser.WhileStmt:
+			err = checkTriggerBody(stmt, table, s.Body)
+		case *parser.ForStmt:
+			err = checkTriggerBody(stmt, table, s.Body)
+		case *parser.ReturnStmt:
+			if s.Value != nil {
+				err = fmt.Errorf("a trigger cannot return a value")
+			}
+		case *parser.SQLStatement:
+			if query, ok := s.Stmt.(*parser.SelectStmt); ok && query.Into == nil {
+				err = fmt.Errorf("a trigger cannot return rows; use SELECT ... INTO")
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BodyStatements returns the text of every SQL statement a trigger or procedure body can run:
+// its own statements, the queries of its FOR loops and, through CALL, the statements of the
+// procedures it calls. Procedures that do not exist or do not parse are left out.
+func BodyStatements(db *types.Database, body []parser.Statement) []string {
+	return bodyStatements(db, body, make(map[string]bool))
+}
+
+func bodyStatements(db *types.Database, body []parser.Statement, called map[string]bool) []string {
+	var texts []string
+	for _, s := range body {
+		switch s := s.(type) {
+		case *parser.SQLStatement:
+			texts = append(texts, s.Text)
+			call, ok := s.Stmt.(*parser.CallStmt)
+			if !ok || called[strings.ToLower(call.Name)] {
+				break
+			}
+			called[strings.ToLower(call.Name)] = true
+			if proc := db.FindProcedure(call.Name); proc != nil {
+				if procBody, err := parser.ParseProcedureBody(proc.Body); err == nil {
+					texts = append(texts, bodyStatements(db, procBody, called)...)
+				}
+			}
+		case *parser.IfStmt:
+			for _, branch := range append(s.Bodies, s.Else) {
+				texts = append(texts, bodyStatements(db, branch, called)...)
+			}
+		case *parser.WhileStmt:
+			texts = append(texts, bodyStatements(db, s.Body, called)...)
+		case *parser.ForStmt:
+			texts = append(texts, s.QueryText)
+			texts = append(texts, bodyStatements(db, s.Body, called)...)
+		}
+	}
+	return texts
+}
+
+// triggerExecutor runs the triggers of a Database
+type triggerExecutor struct {
+	db     *types.Database
+	strict bool
+	bodies map[string][]parser.Statement // parsed bodies by SQL text
+}
+
+// RunTrigger runs the body of a trigger for one row. The columns of the row are readable
+// as OLD.column and NEW.column; OLD is all NULL for INSERT and NEW is all NULL for DELETE.
+// A BEFORE trigger changes the row that is written by setting NEW.column.
+func (e *triggerExecutor) RunTrigger(tr *types.Trigger, t *types.Table, old, new []interface{}) error {
+	body, ok := e.bodies[tr.Body]
+	if !ok {
+		var err error
+		if body, err = parser.ParseTriggerBody(tr.Body); err != nil {
+			return err
+		}
+		e.bodies[tr.Body] = body
+	}
+	columns := t.GetColumnNames()
+	run := &procedureRun{db: e.db, opts: CallOptions{Strict: e.strict}, result: &CallResult{}}
+	f := &frame{run: run, vars: make(map[string]*variable), rows: make(map[string]*record)}
+	f.rows["old"] = &record{columns: columns, values: old}
+	f.rows["new"] = &record{columns: columns, values: new}
+	if old == nil {
+		f.rows["old"].values = make([]interface{}, len(columns))
+	}
+	if new == nil {
+		f.rows["new"].values = make([]interface{}, len(columns))
+	} else if tr.Timing == "BEFORE" {
+		f.rows["new"].table = t
+	}
+	_, err := f.exec(body)
+	return err
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test that triggers see the OLD and NEW rows, that BEFORE triggers can change the row
+// written, and that an error raised by a trigger fails the change
+func TestTriggers(t *testing.T) {
+	db := newTestDatabase(t, "create table history (account varchar(10), before decimal(10,2), after decimal(10,2))")
+	UseValidator(db, true)
+
+	triggers := []string{
+		"create trigger round before insert on ledger for each row set new.amount = coalesce(new.amount, 0)",
+		"create trigger cap before update on ledger for each row begin " +
+			"if new.amount > 100 then signal sqlstate '45000' set message_text = 'amount over limit'; end if; end",
+		"create trigger keep after update on ledger for each row insert into history values (old.account, old.amount, new.amount)",
+		"create trigger gone after delete on ledger for each row insert into history values (old.account, old.amount, new.amount)",
+	}
+	for _, input := range triggers {
+		if err := execSQL(t, db, input); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := execSQL(t, db, "create trigger late after insert on ledger for each row set new.amount = 1"); err == nil {
+		t.Error("an AFTER trigger setting NEW was created")
+	}
+
+	// a failed statement leaves its changes, so try this one on a copy
+	scratch, err := db.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	UseValidator(scratch, true)
+	if err := execSQL(t, scratch, "create trigger loop after insert on ledger for each row delete from ledger"); err != nil {
+		t.Fatal(err)
+	}
+	if err := execSQL(t, scratch, "insert into ledger values ('c', null)"); err == nil {
+		t.Error("a trigger changed the table whose change fired it")
+	}
+
+	if err := execSQL(t, db, "insert into ledger values ('c', null)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := execSQL(t, db, "update ledger set amount = 200 where account = 'c'"); err == nil || !strings.Contains(err.Error(), "amount over limit") {
+		t.Errorf("update over the limit gave %v", err)
+	}
+	if err := execSQL(t, db, "update ledger set amount = 5 where account = 'c'"); err != nil {
+		t.Fatal(err)
+	}
+	if err := execSQL(t, db, "delete from ledger where account = 'c'"); err != nil {
+		t.Fatal(err)
+	}
+	query := "select account, before, after from history"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"c", "0.00", "5.00"}, {"c", "5.00", "NULL"}})
+}
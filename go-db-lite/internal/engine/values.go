@@ -0,0 +1,297 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// Runtime values are nil (SQL NULL), int64, float64, types.Decimal, string, bool,
+// types.DateTime, types.TimeOfDay or types.Interval.
+
+// numericKind orders the numeric representations by how they promote: int < decimal < float
+type numericKind int
+
+const (
+	kindInt numericKind = iota
+	kindDecimal
+	kindFloat
+)
+
+// toNumber coerces a value to a number, parsing strings and mapping booleans to 0/1
+func toNumber(value interface{}) (interface{}, numericKind, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, kindInt, nil
+	case int32:
+		return int64(v), kindInt, nil
+	case int:
+		return int64(v), kindInt, nil
+	case float64:
+		return v, kindFloat, nil
+	case float32:
+		return float64(v), kindFloat, nil
+	case types.Decimal:
+		return v, kindDecimal, nil
+	case bool:
+		if v {
+			return int64(1), kindInt, nil
+		}
+		return int64(0), kindInt, nil
+	case string:
+		s := strings.TrimSpace(v)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, kindInt, nil
+		}
+		if d, err := types.ParseDecimal(s); err == nil && !strings.ContainsAny(s, "eE") {
+			return d, kindDecimal, nil
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, kindFloat, nil
+		}
+		return nil, 0, fmt.Errorf("cannot use %s as a number", QuoteValue(v))
+	}
+	return nil, 0, fmt.Errorf("cannot use %v as a number", value)
+}
+
+// asDecimal converts an int64 or decimal number to a decimal
+func asDecimal(value interface{}) types.Decimal {
+	switch v := value.(type) {
+	case int64:
+		return types.NewDecimal(v, 0)
+	case types.Decimal:
+		return v
+	}
+	return types.Decimal{}
+}
+
+// asFloat converts any number to a float64
+func asFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case types.Decimal:
+		return v.Float64()
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// promote converts both operands to their common numeric representation
+func promote(a, b interface{}) (interface{}, interface{}, numericKind, error) {
+	x, kx, err := toNumber(a)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	y, ky, err := toNumber(b)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	kind := kx
+	if ky > kind {
+		kind = ky
+	}
+	switch kind {
+	case kindDecimal:
+		return asDecimal(x), asDecimal(y), kind, nil
+	case kindFloat:
+		return asFloat(x), asFloat(y), kind, nil
+	}
+	return x, y, kind, nil
+}
+
+// arithmetic applies a binary arithmetic operator. Integer and decimal arithmetic is exact;
+// integer overflow falls back to decimal, and "/" always produces a decimal unless a float is involved.
+func arithmetic(op string, a, b interface{}) (interface{}, error) {
+	if a == nil || b == nil {
+		return nil, nil
+	}
+	if result, ok, err := temporalArithmetic(op, a, b); ok {
+		return result, err
+	}
+	x, y, kind, err := promote(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case kindInt:
+		i, j := x.(int64), y.(int64)
+		switch op {
+		case "+":
+			if r := i + j; (r > i) == (j > 0) {
+				return r, nil
+			}
+		case "-":
+			if r := i - j; (r < i) == (j > 0) {
+				return r, nil
+			}
+		case "*":
+			if i == 0 || j == 0 {
+				return int64(0), nil
+			}
+			if r := i * j; r/j == i && !(i == -1 && j == math.MinInt64) && !(j == -1 && i == math.MinInt64) {
+				return r, nil
+			}
+		case "DIV":
+			if j == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return i / j, nil
+		case "%", "MOD":
+			if j == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return i % j, nil
+		}
+		// overflow or "/": continue exactly in decimal
+		return decimalArithmetic(op, asDecimal(i), asDecimal(j))
+	case kindDecimal:
+		return decimalArithmetic(op, x.(types.Decimal), y.(types.Decimal))
+	default:
+		f, g := x.(float64), y.(float64)
+		switch op {
+		case "+":
+			return f + g, nil
+		case "-":
+			return f - g, nil
+		case "*":
+			return f * g, nil
+		case "/":
+			if g == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return f / g, nil
+		case "DIV":
+			if g == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return int64(f / g), nil
+		case "%", "MOD":
+			if g == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return math.Mod(f, g), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown arithmetic operator %s", op)
+}
+
+func decimalArithmetic(op string, x, y types.Decimal) (interface{}, error) {
+	switch op {
+	case "+":
+		return x.Add(y), nil
+	case "-":
+		return x.Sub(y), nil
+	case "*":
+		return x.Mul(y), nil
+	case "/":
+		return x.Div(y)
+	case "DIV":
+		q, err := x.IntDiv(y)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := q.Int64(); ok {
+			return i, nil
+		}
+		return q, nil
+	case "%", "MOD":
+		return x.Mod(y)
+	}
+	return nil, fmt.Errorf("unknown arithmetic operator %s", op)
+}
+
+// negate returns -value for numbers
+func negate(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	return arithmetic("-", int64(0), value)
+}
+
+// compareValues compares two non-null values and returns -1, 0 or 1.
+// Numbers compare numerically (strings are coerced when compared with numbers), strings compare bytewise,
+// and dates and times compare chronologically (strings are parsed when compared with them).
+func compareValues(a, b interface{}) (int, error) {
+	if c, ok, err := compareTemporal(a, b); ok {
+		return c, err
+	}
+	if sa, ok := a.(string); ok {
+		if sb, ok := b.(string); ok {
+			return strings.Compare(sa, sb), nil
+		}
+	}
+	if ba, ok := a.(bool); ok {
+		if bb, ok := b.(bool); ok {
+			switch {
+			case ba == bb:
+				return 0, nil
+			case !ba:
+				return -1, nil
+			default:
+				return 1, nil
+			}
+		}
+	}
+
+	x, y, kind, err := promote(a, b)
+	if err != nil {
+		return 0, fmt.Errorf("cannot compare %s with %s", QuoteValue(a), QuoteValue(b))
+	}
+	switch kind {
+	case kindInt:
+		i, j := x.(int64), y.(int64)
+		switch {
+		case i < j:
+			return -1, nil
+		case i > j:
+			return 1, nil
+		}
+		return 0, nil
+	case kindDecimal:
+		return x.(types.Decimal).Cmp(y.(types.Decimal)), nil
+	default:
+		f, g := x.(float64), y.(float64)
+		switch {
+		case f < g:
+			return -1, nil
+		case f > g:
+			return 1, nil
+		}
+		return 0, nil
+	}
+}
+
+// truthy converts a value to a SQL boolean; nil means unknown
+func truthy(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		return v, nil
+	}
+	n, kind, err := toNumber(value)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case kindInt:
+		return n.(int64) != 0, nil
+	case kindDecimal:
+		return n.(types.Decimal).Sign() != 0, nil
+	}
+	return n.(float64) != 0, nil
+}
+
+// QuoteValue formats a value for use in messages, quoting strings
+func QuoteValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return "'" + s + "'"
+	}
+	return types.FormatValue(value)
+}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// CreateView checks the query of a CREATE VIEW statement against the database and adds
+// the view, replacing an existing one for CREATE OR REPLACE VIEW
+func CreateView(db *types.Database, stmt *parser.CreateViewStmt) error {
+	for _, expr := range selectExprs(stmt.Query) {
+		var err error
+		parser.Walk(expr, func(e parser.Expr) bool {
+			if call, ok := e.(*parser.FuncCall); ok && call.Name == "NEXTVAL" {
+				err = fmt.Errorf("NEXTVAL is not allowed in a view")
+			}
+			return err == nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, from := range tableNames(stmt.Query) {
+		if from.Schema != "" {
+			return fmt.Errorf("view %s cannot read %s.%s; a view only reads its own database", stmt.Name, from.Schema, from.Name)
+		}
+	}
+	view := types.View{Name: stmt.Name, Columns: stmt.Columns, Query: stmt.QueryText, Uses: Relations(stmt.Query)}
+	if stmt.Materialized {
+		return createMaterializedView(db, stmt, view)
+	}
+	// running the query checks that what it reads exists, and gives the names of its columns
+	result, err := Select(db, stmt.Query)
+	if err != nil {
+		return err
+	}
+	if _, err := viewColumns(&view, result.Columns); err != nil {
+		return err
+	}
+	return db.CreateView(view, stmt.OrReplace)
+}
+
+// Relations returns the names of the tables and views a SELECT reads
+func Relations(stmt *parser.SelectStmt) []string {
+	var names []string
+	for _, from := range tableNames(stmt) {
+		names = append(names, from.Name)
+	}
+	return names
+}
+
+// tableNames returns the tables and views named in the FROM clause of a SELECT
+func tableNames(stmt *parser.SelectStmt) []*parser.TableName {
+	var names []*parser.TableName
+	if stmt.From != nil {
+		names = append(names, stmt.From)
+	}
+	for _, join := range stmt.Joins {
+		names = append(names, join.Table)
+	}
+	return names
+}
+
+// selectExprs returns every expression of a SELECT
+func selectExprs(stmt *parser.SelectStmt) []parser.Expr {
+	var exprs []parser.Expr
+	for _, item := range stmt.Columns {
+		exprs = append(exprs, item.Expr)
+	}
+	for _, join := range stmt.Joins {
+		exprs = append(exprs, join.On)
+	}
+	exprs = append(exprs, stmt.Where, stmt.Having, stmt.Limit, stmt.Offset)
+	exprs = append(exprs, stmt.GroupBy...)
+	for _, order := range stmt.OrderBy {
+		exprs = append(exprs, order.Expr)
+	}
+	return exprs
+}
+
+// viewColumns returns the column names of a view given those of its query
+func viewColumns(view *types.View, queried []string) ([]string, error) {
+	names := queried
+	if len(view.Columns) > 0 {
+		if len(view.Columns) != len(queried) {
+			return nil, fmt.Errorf("view %s names %d columns but its query returns %d", view.Name, len(view.Columns), len(queried))
+		}
+		names = view.Columns
+	}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[strings.ToLower(name)] {
+			return nil, fmt.Errorf("view %s has more than one column named %s; name them with AS or a column list", view.Name, name)
+		}
+		seen[strings.ToLower(name)] = true
+	}
+	return names, nil
+}
+
+// viewSource expands a view: its query runs against the current rows of the tables it reads
+func viewSource(db *types.Database, view *types.View, alias string) (*source, error) {
+	stmt, err := parser.ParseSelect(view.Query)
+	if err != nil {
+		return nil, fmt.Errorf("view %s: %v", view.Name, err)
+	}
+	result, err := Select(db, stmt)
+	if err != nil {
+		return nil, fmt.Errorf("view %s: %v", view.Name, err)
+	}
+	names, err := viewColumns(view, result.Columns)
+	if err != nil {
+		return nil, err
+	}
+	name := view.Name
+	if alias != "" {
+		name = alias
+	}
+	src := &source{rows: result.Rows}
+	for _, column := range names {
+		src.columns = append(src.columns, ScopeColumn{Table: name, Name: column})
+	}
+	return src, nil
+}
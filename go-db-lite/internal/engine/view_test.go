@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/parser"
+)
+
+// Test that views are expanded at query time and can be joined like tables
+func TestViewsAndJoins(t *testing.T) {
+	db := newTestDatabase(t,
+		"create table owners (account varchar(10) not null, owner varchar(10) not null)",
+		"insert into owners values ('a', 'ann')",
+		"insert into owners values ('c', 'cid')")
+
+	if err := execSQL(t, db, "create view totals (account, total) as select account, sum(amount) from ledger group by account"); err != nil {
+		t.Fatal(err)
+	}
+	query := "select o.owner, t.total from totals t left join owners o on o.account = t.account order by t.account"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"ann", "0.30"}, {"NULL", "19.99"}})
+
+	query = "select o.owner, t.total from totals t right join owners o on o.account = t.account order by o.owner"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"ann", "0.30"}, {"cid", "NULL"}})
+
+	// rows added after the view was created show through it
+	if err := db.Tables[0].AddRow([]interface{}{"c", nil}); err != nil {
+		t.Fatal(err)
+	}
+	query = "select count(*) from totals, owners"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"6"}})
+
+	if err := execSQL(t, db, "create view owned as select t.total from totals t join owners o on o.account = t.account"); err != nil {
+		t.Fatal(err)
+	}
+	if err := execSQL(t, db, "create or replace view totals as select * from owned"); err == nil {
+		t.Error("a view reading itself was created")
+	}
+	if err := db.DropTable("ledger"); err == nil {
+		t.Error("a table read by a view was dropped")
+	}
+	for _, input := range []string{
+		"alter table ledger drop column amount",
+		"alter table ledger rename column amount to total",
+		"alter table ledger alter column amount type varchar(10)",
+	} {
+		if err := execSQL(t, db, input); err == nil || !strings.Contains(err.Error(), "view totals depends on it") {
+			t.Errorf("%s on a table read by a view: %v", input, err)
+		}
+	}
+	if _, err := db.DropView("totals", false); err == nil {
+		t.Error("a view read by another view was dropped without CASCADE")
+	}
+	dropped, err := db.DropView("totals", true)
+	if err != nil || len(dropped) != 2 || len(db.Views) != 0 {
+		t.Errorf("DROP VIEW totals CASCADE dropped %v (%v), left %d views", dropped, err, len(db.Views))
+	}
+}
+
+// Test that an aggregate materialized view follows inserts, updates and deletes of the table
+// it reads, while one that cannot be maintained changes only on refresh
+func TestMaterializedViews(t *testing.T) {
+	db := newTestDatabase(t)
+	grouped := "select account, count(*) as n, count(amount) as c, sum(amount) as total from ledger group by account"
+	if err := execSQL(t, db, "create materialized view totals as "+grouped); err != nil {
+		t.Fatal(err)
+	}
+	if err := execSQL(t, db, "create materialized view top as select account, amount from ledger order by amount desc limit 1"); err != nil {
+		t.Fatal(err)
+	}
+	if !db.FindView("totals").Incremental || db.FindView("top").Incremental {
+		t.Fatal("only the grouped view should be refreshed incrementally")
+	}
+	for query, want := range map[string]string{
+		grouped: "",
+		"select account, amount from ledger order by amount desc limit 1":          "ORDER BY",
+		"select account, count(amount) from ledger group by account":               "COUNT(*)",
+		"select account, count(*), sum(amount) from ledger group by account":       "without COUNT(amount)",
+		"select account, count(*), max(amount) from ledger group by account":       "MAX(amount)",
+		"select count(*), count(amount), sum(amount) from ledger group by account": "GROUP BY expression account",
+	} {
+		stmt, err := parser.ParseSelect(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ManualRefreshReason(db, stmt); (want == "") != (got == "") || !strings.Contains(got, want) {
+			t.Errorf("ManualRefreshReason(%s) = %q, want one mentioning %q", query, got, want)
+		}
+	}
+
+	for _, input := range []string{
+		"insert into ledger values ('c', 1.00)",
+		"update ledger set amount = 5.00 where amount = 19.99",
+		"update ledger set account = 'c' where amount is null",
+		"delete from ledger where account = 'a'",
+	} {
+		if err := execSQL(t, db, input); err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+	}
+	want := [][]string{{"b", "1", "1", "5.00"}, {"c", "2", "1", "1.00"}}
+	query := "select * from totals order by account"
+	expectRows(t, query, runQuery(t, db, query), want)
+	expectRows(t, grouped, runQuery(t, db, grouped+" order by account"), want)
+
+	query = "select amount from top"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"19.99"}})
+	if _, err := RefreshMaterializedView(db, "top"); err != nil {
+		t.Fatal(err)
+	}
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"5.00"}})
+	if err := db.CheckWritable(db.FindTable("top")); err == nil {
+		t.Error("the table of a materialized view was writable")
+	}
+
+	// the columns the views read cannot change under them, which leaves the table writable
+	if err := execSQL(t, db, "alter table ledger drop column amount"); err == nil || !strings.Contains(err.Error(), "materialized view") {
+		t.Errorf("dropping a column read by a materialized view: %v", err)
+	}
+	if err := execSQL(t, db, "insert into ledger values ('b', 2.00)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RefreshMaterializedView(db, "totals"); err != nil {
+		t.Fatal(err)
+	}
+	query = "select total from totals where account = 'b'"
+	expectRows(t, query, runQuery(t, db, query), [][]string{{"7.00"}})
+}
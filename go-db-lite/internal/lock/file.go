@@ -0,0 +1,125 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrBusy is returned when another process holds a conflicting lock on the database file
+var ErrBusy = errors.New("database is locked")
+
+// pollInterval is how often a lock held by another process is tried again
+const pollInterval = 10 * time.Millisecond
+
+// Suffix names the file beside a database that processes lock. The database file itself
+// cannot carry the lock, as every write replaces it with a new file.
+const Suffix = "-lock"
+
+// FileLock is an advisory OS lock on a database file, shared by everything in the process
+// that uses the file. It holds the file exclusively while anyone in the process needs
+// exclusive access, shared while anyone needs shared access, and not at all otherwise.
+// The OS lock is taken on the lock file of the database, created when first needed.
+type FileLock struct {
+	mu        sync.Mutex
+	path      string
+	f         *os.File
+	shared    int
+	exclusive int
+}
+
+var (
+	filesMu sync.Mutex
+	files   = make(map[string]*FileLock)
+)
+
+// OpenFile returns the lock of a database file. Every call for the same file returns the
+// same lock, as OS locks taken through different handles of one process conflict.
+func OpenFile(path string) *FileLock {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	filesMu.Lock()
+	defer filesMu.Unlock()
+	l := files[path]
+	if l == nil {
+		l = &FileLock{path: path}
+		files[path] = l
+	}
+	return l
+}
+
+// Lock takes the file in shared or exclusive mode, waiting up to timeout for other
+// processes to release it
+func (l *FileLock) Lock(mode Mode, timeout time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if mode == Exclusive {
+		l.exclusive++
+	} else {
+		l.shared++
+	}
+	if err := l.update(timeout); err != nil {
+		if mode == Exclusive {
+			l.exclusive--
+		} else {
+			l.shared--
+		}
+		l.update(0)
+		return err
+	}
+	return nil
+}
+
+// Unlock gives up one Lock of the given mode
+func (l *FileLock) Unlock(mode Mode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if mode == Exclusive && l.exclusive > 0 {
+		l.exclusive--
+	} else if mode != Exclusive && l.shared > 0 {
+		l.shared--
+	}
+	l.update(0)
+}
+
+// update brings the OS lock in line with the locks held in the process
+func (l *FileLock) update(timeout time.Duration) error {
+	if l.exclusive == 0 && l.shared == 0 {
+		if l.f != nil {
+			err := unlockFile(l.f)
+			l.f.Close()
+			l.f = nil
+			return err
+		}
+		return nil
+	}
+	if l.f == nil {
+		if _, err := os.Stat(l.path); err != nil {
+			return fmt.Errorf("error opening database file: %v", err)
+		}
+		f, err := os.OpenFile(l.path+Suffix, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return fmt.Errorf("error opening lock file: %v", err)
+		}
+		l.f = f
+	}
+	exclusive := l.exclusive > 0
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := tryLockFile(l.f, exclusive)
+		if err != nil {
+			return fmt.Errorf("error locking database file: %v", err)
+		}
+		if ok {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("%w: %s is in use by another process", ErrBusy, filepath.Base(l.path))
+		}
+		time.Sleep(pollInterval)
+	}
+}
@@ -0,0 +1,53 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package lock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Test that the file lock keeps out a writer from another process while anyone reads, and
+// waits for the other process to finish rather than failing at once
+func TestFileLockBusy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// a handle of its own takes locks the way another process would
+	other, err := os.OpenFile(path+Suffix, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if ok, err := tryLockFile(other, false); !ok {
+		t.Fatal(err)
+	}
+
+	l := OpenFile(path)
+	if err := l.Lock(Shared, 0); err != nil {
+		t.Errorf("reading while another process reads: %v", err)
+	}
+	if err := l.Lock(Exclusive, wait); !errors.Is(err, ErrBusy) {
+		t.Errorf("writing while another process reads: got %v, want ErrBusy", err)
+	}
+	l.Unlock(Shared)
+
+	released := make(chan struct{})
+	go func() {
+		time.Sleep(wait)
+		unlockFile(other)
+		close(released)
+	}()
+	if err := l.Lock(Exclusive, time.Second); err != nil {
+		t.Errorf("writing once the other process is done: %v", err)
+	}
+	<-released
+	if ok, _ := tryLockFile(other, false); ok {
+		t.Errorf("another process reads while the file is written")
+	}
+	l.Unlock(Exclusive)
+}
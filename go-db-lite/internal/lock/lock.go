@@ -0,0 +1,298 @@
+// Package lock arbitrates access to a database between sessions. Locks are taken on the
+// whole database or on single tables, in shared or exclusive mode; a table lock first takes
+// the matching intention lock on the database, so that database and table locks conflict
+// as expected. Waits that would close a cycle fail as deadlocks, and waits end after a timeout.
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrDeadlock is returned when waiting for a lock would wait forever. The transaction
+	// should be rolled back, releasing its locks.
+	ErrDeadlock = errors.New("deadlock detected")
+	// ErrTimeout is returned when a lock is not granted within the timeout
+	ErrTimeout = errors.New("lock wait timeout exceeded")
+)
+
+// Mode is the kind of access a lock protects
+type Mode int
+
+const (
+	IntentionShared    Mode = iota // some tables of the database are locked in shared mode
+	IntentionExclusive             // some tables of the database are written or locked in exclusive mode
+	Shared                         // no one else may write
+	Exclusive                      // no one else may write or take any lock
+)
+
+func (m Mode) String() string {
+	return [...]string{"INTENTION SHARE", "INTENTION EXCLUSIVE", "SHARE", "EXCLUSIVE"}[m]
+}
+
+// ParseMode parses SHARE or EXCLUSIVE, as written in LOCK statements
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToUpper(s) {
+	case "SHARE", "SHARED":
+		return Shared, nil
+	case "EXCLUSIVE":
+		return Exclusive, nil
+	}
+	return 0, fmt.Errorf("unsupported lock mode %s", s)
+}
+
+// compatible[held][wanted] reports whether two owners may hold the modes at the same time
+var compatible = [4][4]bool{
+	IntentionShared:    {true, true, true, false},
+	IntentionExclusive: {true, true, false, false},
+	Shared:             {true, false, true, false},
+	Exclusive:          {false, false, false, false},
+}
+
+// intention returns the mode a table lock takes on its database
+func (m Mode) intention() Mode {
+	if m == Shared || m == IntentionShared {
+		return IntentionShared
+	}
+	return IntentionExclusive
+}
+
+// covers reports whether holding the mode grants everything the other mode does
+func (m Mode) covers(o Mode) bool {
+	return m == o || m == Exclusive || (m == IntentionExclusive && o == IntentionShared) ||
+		(m == Shared && o == IntentionShared)
+}
+
+// Resource is a lockable part of a database
+type Resource string
+
+// Database is the resource standing for the whole database
+const Database Resource = "database"
+
+// Table returns the resource of a table
+func Table(name string) Resource {
+	return Resource("table " + strings.ToLower(name))
+}
+
+func (r Resource) String() string {
+	return string(r)
+}
+
+// Owner identifies who holds locks, usually a session. Locks of the same owner never
+// conflict with each other.
+type Owner uint64
+
+// hold is a lock granted to an owner. The tag groups the locks to release together, such
+// as those of one transaction.
+type hold struct {
+	owner Owner
+	tag   uint64
+	mode  Mode
+}
+
+type request struct {
+	res  Resource
+	mode Mode
+}
+
+// Manager grants locks. A manager bound to a file also holds an OS lock on it while any of
+// its owners holds a shared or exclusive lock, so that other processes are kept out too.
+type Manager struct {
+	mu        sync.Mutex
+	holds     map[Resource][]hold
+	waiting   map[Owner]request // requests blocked, for deadlock detection
+	changed   chan struct{}     // closed and replaced whenever a lock is released
+	nextOwner Owner
+	file      *FileLock
+}
+
+// NewManager creates a lock manager, coordinating with other processes through the given
+// file lock if it is not nil
+func NewManager(file *FileLock) *Manager {
+	return &Manager{
+		holds:     make(map[Resource][]hold),
+		waiting:   make(map[Owner]request),
+		changed:   make(chan struct{}),
+		nextOwner: 1,
+		file:      file,
+	}
+}
+
+// NewOwner returns an owner id not used before
+func (m *Manager) NewOwner() Owner {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o := m.nextOwner
+	m.nextOwner++
+	return o
+}
+
+// Lock grants a lock on a resource to an owner, waiting up to timeout for conflicting locks
+// of other owners to be released. A lock already held in a weaker mode is upgraded.
+func (m *Manager) Lock(owner Owner, tag uint64, res Resource, mode Mode, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	if res != Database {
+		if err := m.Lock(owner, tag, Database, mode.intention(), timeout); err != nil {
+			return err
+		}
+	}
+	if mode == Shared || mode == Exclusive {
+		if err := m.lockFile(mode, deadline); err != nil {
+			return err
+		}
+	}
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for {
+		if m.holding(owner, tag, res, mode) {
+			m.unlockFile(mode)
+			return nil
+		}
+		if len(m.blockers(owner, res, mode)) == 0 {
+			m.holds[res] = append(m.holds[res], hold{owner: owner, tag: tag, mode: mode})
+			return nil
+		}
+		if m.deadlocked(owner, request{res, mode}) {
+			m.unlockFile(mode)
+			return fmt.Errorf("%w: waiting for %s lock on %s", ErrDeadlock, mode, res)
+		}
+		m.waiting[owner] = request{res, mode}
+		changed := m.changed
+		m.mu.Unlock()
+		select {
+		case <-changed:
+			m.mu.Lock()
+			delete(m.waiting, owner)
+		case <-timer.C:
+			m.mu.Lock()
+			delete(m.waiting, owner)
+			m.unlockFile(mode)
+			return fmt.Errorf("%w: waiting for %s lock on %s", ErrTimeout, mode, res)
+		}
+	}
+}
+
+// holding reports whether the owner already holds the lock under the tag
+func (m *Manager) holding(owner Owner, tag uint64, res Resource, mode Mode) bool {
+	for _, h := range m.holds[res] {
+		if h.owner == owner && h.tag == tag && h.mode.covers(mode) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockers returns the other owners holding locks that conflict with the request
+func (m *Manager) blockers(owner Owner, res Resource, mode Mode) []Owner {
+	var owners []Owner
+	for _, h := range m.holds[res] {
+		if h.owner != owner && !compatible[h.mode][mode] {
+			owners = append(owners, h.owner)
+		}
+	}
+	return owners
+}
+
+// deadlocked reports whether the owner would wait, directly or through other waiting
+// owners, for itself
+func (m *Manager) deadlocked(owner Owner, req request) bool {
+	visited := make(map[Owner]bool)
+	var reaches func(req request) bool
+	reaches = func(req request) bool {
+		for _, b := range m.blockers(0, req.res, req.mode) {
+			if b == owner {
+				return true
+			}
+			if visited[b] {
+				continue
+			}
+			visited[b] = true
+			if next, ok := m.waiting[b]; ok && reaches(next) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, b := range m.blockers(owner, req.res, req.mode) {
+		visited[b] = true
+		if next, ok := m.waiting[b]; ok && reaches(next) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unlock releases the locks an owner holds under a tag
+func (m *Manager) Unlock(owner Owner, tag uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	released := false
+	for res, holds := range m.holds {
+		kept := holds[:0]
+		for _, h := range holds {
+			if h.owner == owner && h.tag == tag {
+				m.unlockFile(h.mode)
+				released = true
+				continue
+			}
+			kept = append(kept, h)
+		}
+		if len(kept) == 0 {
+			delete(m.holds, res)
+		} else {
+			m.holds[res] = kept
+		}
+	}
+	if released {
+		close(m.changed)
+		m.changed = make(chan struct{})
+	}
+}
+
+// Held returns the mode of every lock an owner holds under a tag, intention locks excluded
+func (m *Manager) Held(owner Owner, tag uint64) map[Resource]Mode {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	held := make(map[Resource]Mode)
+	for res, holds := range m.holds {
+		for _, h := range holds {
+			if h.owner == owner && h.tag == tag && (h.mode == Shared || h.mode == Exclusive) {
+				if cur, ok := held[res]; !ok || h.mode > cur {
+					held[res] = h.mode
+				}
+			}
+		}
+	}
+	return held
+}
+
+// LockFile holds the file of the manager in shared or exclusive mode, outside of any lock
+// on a resource, until UnlockFile. Other processes wait for it as for any lock; owners of
+// the manager do not.
+func (m *Manager) LockFile(mode Mode, timeout time.Duration) error {
+	return m.lockFile(mode, time.Now().Add(timeout))
+}
+
+// UnlockFile gives up one LockFile of the given mode
+func (m *Manager) UnlockFile(mode Mode) {
+	m.unlockFile(mode)
+}
+
+func (m *Manager) lockFile(mode Mode, deadline time.Time) error {
+	if m.file == nil {
+		return nil
+	}
+	return m.file.Lock(mode, time.Until(deadline))
+}
+
+func (m *Manager) unlockFile(mode Mode) {
+	if m.file != nil && (mode == Shared || mode == Exclusive) {
+		m.file.Unlock(mode)
+	}
+}
@@ -0,0 +1,64 @@
+package lock
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const wait = 50 * time.Millisecond
+
+// Test that shared locks coexist, that exclusive and database locks conflict with table
+// locks, and that a released lock is granted to the owner waiting for it
+func TestLockModes(t *testing.T) {
+	m := NewManager(nil)
+	a, b := m.NewOwner(), m.NewOwner()
+	if err := m.Lock(a, 1, Table("items"), Shared, wait); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Lock(b, 1, Table("items"), Shared, wait); err != nil {
+		t.Errorf("second SHARE lock: %v", err)
+	}
+	if err := m.Lock(b, 1, Table("items"), Exclusive, wait); !errors.Is(err, ErrTimeout) {
+		t.Errorf("EXCLUSIVE lock on a shared table: got %v, want ErrTimeout", err)
+	}
+	if err := m.Lock(b, 1, Table("orders"), IntentionExclusive, wait); err != nil {
+		t.Errorf("writing another table: %v", err)
+	}
+	if err := m.Lock(b, 2, Database, Exclusive, wait); !errors.Is(err, ErrTimeout) {
+		t.Errorf("EXCLUSIVE database lock while a table is locked: got %v, want ErrTimeout", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- m.Lock(b, 1, Table("items"), Exclusive, time.Second) }()
+	time.Sleep(wait)
+	m.Unlock(a, 1)
+	if err := <-done; err != nil {
+		t.Errorf("lock released while waiting: %v", err)
+	}
+	if held := m.Held(b, 1); held[Table("items")] != Exclusive {
+		t.Errorf("held locks %v, want EXCLUSIVE on items", held)
+	}
+}
+
+// Test that the owner closing a cycle of waits fails with ErrDeadlock
+func TestDeadlock(t *testing.T) {
+	m := NewManager(nil)
+	a, b := m.NewOwner(), m.NewOwner()
+	if err := m.Lock(a, 1, Table("items"), Exclusive, wait); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Lock(b, 1, Table("orders"), Exclusive, wait); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() { done <- m.Lock(a, 1, Table("orders"), Exclusive, time.Second) }()
+	time.Sleep(wait)
+	if err := m.Lock(b, 1, Table("items"), Exclusive, time.Second); !errors.Is(err, ErrDeadlock) {
+		t.Errorf("closing a cycle of waits: got %v, want ErrDeadlock", err)
+	}
+	m.Unlock(b, 1)
+	if err := <-done; err != nil {
+		t.Errorf("lock released by the deadlock victim: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package mvcc
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/lock"
+)
+
+// Test that a transaction keeps other processes out of the database file from its first
+// write until it ends, but not before
+func TestWriterHoldsFile(t *testing.T) {
+	s := newTestStore(t)
+	// a handle of its own takes locks the way another process would
+	other, err := os.OpenFile(s.Path()+lock.Suffix, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	otherReads := func() bool {
+		if err := syscall.Flock(int(other.Fd()), syscall.LOCK_SH|syscall.LOCK_NB); err != nil {
+			return false
+		}
+		syscall.Flock(int(other.Fd()), syscall.LOCK_UN)
+		return true
+	}
+
+	tx := begin(t, s, Snapshot)
+	if !otherReads() {
+		t.Errorf("another process cannot read before the transaction writes")
+	}
+	if err := insert(tx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if otherReads() {
+		t.Errorf("another process reads while the transaction is writing")
+	}
+	if err := insert(tx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if !otherReads() {
+		t.Errorf("another process cannot read after COMMIT")
+	}
+
+	tx = begin(t, s, Snapshot)
+	if err := insert(tx, 3); err != nil {
+		t.Fatal(err)
+	}
+	tx.Rollback()
+	if !otherReads() {
+		t.Errorf("another process cannot read after ROLLBACK")
+	}
+}
@@ -0,0 +1,428 @@
+// Package mvcc runs concurrent transactions over a database. Every change to a row creates
+// a new version of it, stamped with the transaction that made it, so readers see a consistent
+// snapshot without blocking writers and writers never overwrite changes they have not seen.
+package mvcc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/lock"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// ErrConflict is returned when a transaction writes data changed by a concurrent transaction.
+// The transaction should be rolled back and retried.
+var ErrConflict = errors.New("could not serialize access due to a concurrent update")
+
+// DefaultLockTimeout is how long a transaction waits for a lock unless told otherwise
+const DefaultLockTimeout = 5 * time.Second
+
+// IsolationLevel decides which committed changes a transaction sees
+type IsolationLevel int
+
+const (
+	ReadCommitted IsolationLevel = iota // every statement sees the changes committed before it began
+	Snapshot                            // every statement sees the changes committed before the transaction began
+)
+
+// RepeatableRead is provided by snapshot isolation
+const RepeatableRead = Snapshot
+
+func (l IsolationLevel) String() string {
+	if l == ReadCommitted {
+		return "READ COMMITTED"
+	}
+	return "SNAPSHOT"
+}
+
+// ParseIsolationLevel parses READ COMMITTED, REPEATABLE READ or SNAPSHOT
+func ParseIsolationLevel(s string) (IsolationLevel, error) {
+	switch strings.ToUpper(strings.Join(strings.Fields(s), " ")) {
+	case "READ COMMITTED":
+		return ReadCommitted, nil
+	case "REPEATABLE READ", "SNAPSHOT":
+		return Snapshot, nil
+	}
+	return 0, fmt.Errorf("unsupported isolation level %s", s)
+}
+
+// version is one state of a row. Versions are never changed once written, except for xmax.
+type version struct {
+	table  string
+	values []byte
+	xmin   uint64 // transaction that wrote the version
+	xmax   uint64 // transaction that replaced or deleted it, 0 while current
+}
+
+// catalog is one state of the schema: the tables without their rows, and the sequences
+type catalog struct {
+	db   *types.Database
+	xmin uint64
+}
+
+// snapshot decides which transactions' changes are visible
+type snapshot struct {
+	tx     uint64          // the transaction reading, whose own changes are visible
+	xmax   uint64          // transactions from this one on began after the snapshot
+	active map[uint64]bool // transactions in progress when the snapshot was taken
+}
+
+// sees reports whether the changes of a transaction are visible. Changes of rolled back
+// transactions are removed, so every transaction seen as finished has committed.
+func (s *snapshot) sees(id uint64) bool {
+	return id == s.tx || (id < s.xmax && !s.active[id])
+}
+
+func (s *snapshot) visible(v *version) bool {
+	return s.sees(v.xmin) && (v.xmax == 0 || !s.sees(v.xmax))
+}
+
+// Store holds the versions of a database shared by concurrent transactions. A store opened
+// on a file writes every commit to it. Processes sharing the file take turns through OS
+// locks: any number may read it at once, but one writes it at a time and with no one
+// reading, holding it from the first write of a transaction until the transaction ends.
+// Transactions of other processes that wrote the file since the store read it are detected
+// when committing.
+type Store struct {
+	mu       sync.Mutex
+	path     string
+	checksum [sha256.Size]byte // checksum of the file as last read or written
+	file     *lock.FileLock    // nil for a store in memory
+	locks    *lock.Manager
+
+	// LockTimeout is how long transactions wait for locks held by others, including other
+	// processes holding the file
+	LockTimeout time.Duration
+
+	nextTx   uint64
+	active   map[uint64]*Tx
+	catalogs []*catalog            // oldest first
+	rows     map[uint64][]*version // versions of every row, oldest first
+	order    []uint64              // row ids in insertion order
+	nextRow  uint64
+	dirty    bool // counters changed since the file was written
+
+	// identity counters and sequence positions are not transactional: values handed out
+	// are never handed out again, even if the transaction rolls back
+	counters  map[string]int64
+	sequences map[string]types.Sequence
+}
+
+// NewStore creates a store holding the given database in memory
+func NewStore(db *types.Database) (*Store, error) {
+	s := &Store{locks: lock.NewManager(nil), LockTimeout: DefaultLockTimeout}
+	if err := s.load(db); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Open creates a store on a database file, waiting up to timeout for other processes
+// writing it. The timeout becomes the LockTimeout of the store.
+func Open(path string, timeout time.Duration) (*Store, error) {
+	s := &Store{path: path, file: lock.OpenFile(path), LockTimeout: timeout}
+	s.locks = lock.NewManager(s.file)
+	if err := s.file.Lock(lock.Shared, s.LockTimeout); err != nil {
+		return nil, err
+	}
+	defer s.file.Unlock(lock.Shared)
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Path returns the file of the store, empty for a store in memory
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Locks returns the lock manager of the store, for locks held by a session rather than by
+// one transaction
+func (s *Store) Locks() *lock.Manager {
+	return s.locks
+}
+
+// reload reads the database file into the store
+func (s *Store) reload() error {
+	checksum, err := fileChecksum(s.path)
+	if err != nil {
+		return err
+	}
+	db := types.NewDatabase()
+	if err := db.ReadFromFile(s.path); err != nil {
+		return err
+	}
+	s.checksum = checksum
+	return s.load(db)
+}
+
+// load replaces the contents of the store with the committed state of a database
+func (s *Store) load(db *types.Database) error {
+	cat, err := catalogOf(db)
+	if err != nil {
+		return err
+	}
+	s.nextTx = 1
+	s.active = make(map[uint64]*Tx)
+	s.catalogs = []*catalog{{db: cat}}
+	s.rows = make(map[uint64][]*version)
+	s.order = nil
+	s.nextRow = 1
+	s.dirty = false
+	s.counters = make(map[string]int64)
+	s.sequences = make(map[string]types.Sequence)
+	for _, t := range db.Tables {
+		name := t.GetName()
+		s.counters[name] = t.AutoIncrement
+		for _, row := range t.Rows {
+			s.rows[s.nextRow] = []*version{{table: name, values: row.Values}}
+			s.order = append(s.order, s.nextRow)
+			s.nextRow++
+		}
+	}
+	for _, seq := range db.Sequences {
+		s.sequences[seq.Name] = seq
+	}
+	return nil
+}
+
+// Begin starts a transaction. Without other transactions in progress, a database file
+// changed by another process is read again first, locked in shared mode so that no process
+// writes it meanwhile.
+func (s *Store) Begin(level IsolationLevel) (*Tx, error) {
+	return s.BeginFor(s.locks.NewOwner(), level)
+}
+
+// BeginFor starts a transaction taking its locks on behalf of an owner, usually the session
+// running it, so that the locks the owner holds do not block the transaction
+func (s *Store) BeginFor(owner lock.Owner, level IsolationLevel) (*Tx, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path != "" && len(s.active) == 0 {
+		if err := s.file.Lock(lock.Shared, s.LockTimeout); err != nil {
+			return nil, err
+		}
+		defer s.file.Unlock(lock.Shared)
+		checksum, err := fileChecksum(s.path)
+		if err != nil {
+			return nil, err
+		}
+		if checksum != s.checksum {
+			if err := s.reload(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	tx := &Tx{store: s, id: s.nextTx, level: level, owner: owner}
+	s.nextTx++
+	tx.snap = s.takeSnapshot(tx.id)
+	s.active[tx.id] = tx
+	return tx, nil
+}
+
+func (s *Store) takeSnapshot(tx uint64) *snapshot {
+	snap := &snapshot{tx: tx, xmax: s.nextTx, active: make(map[uint64]bool, len(s.active))}
+	for id := range s.active {
+		if id != tx {
+			snap.active[id] = true
+		}
+	}
+	return snap
+}
+
+// materialize builds the database as seen by a snapshot. The version each row was read
+// from is recorded in the view, for Apply to find the changes made to it.
+func (s *Store) materialize(snap *snapshot) (*types.Database, *view, error) {
+	var cat *catalog
+	for i := len(s.catalogs) - 1; i >= 0; i-- {
+		if snap.sees(s.catalogs[i].xmin) {
+			cat = s.catalogs[i]
+			break
+		}
+	}
+	db, err := cat.db.Clone()
+	if err != nil {
+		return nil, nil, err
+	}
+	v := &view{db: db, catalog: cat, rows: make(map[uint64]*version),
+		counters: make(map[string]int64), sequences: make(map[string]types.Sequence)}
+	tables := make(map[string]*types.Table, len(db.Tables))
+	for i := range db.Tables {
+		t := &db.Tables[i]
+		if n, ok := s.counters[t.GetName()]; ok {
+			t.AutoIncrement = n
+		}
+		v.counters[t.GetName()] = t.AutoIncrement
+		tables[t.GetName()] = t
+	}
+	for i := range db.Sequences {
+		if seq, ok := s.sequences[db.Sequences[i].Name]; ok {
+			db.Sequences[i].Last, db.Sequences[i].Called = seq.Last, seq.Called
+		}
+		v.sequences[db.Sequences[i].Name] = db.Sequences[i]
+	}
+	for _, id := range s.order {
+		chain := s.rows[id]
+		for i := len(chain) - 1; i >= 0; i-- {
+			if !snap.visible(chain[i]) {
+				continue
+			}
+			if t := tables[chain[i].table]; t != nil {
+				t.Rows = append(t.Rows, types.Row{ID: id, Values: chain[i].values})
+				v.rows[id] = chain[i]
+			}
+			break
+		}
+	}
+	return db, v, nil
+}
+
+// visibleToAll reports whether the changes of a transaction are seen by every transaction
+// in progress, so that the versions it replaced can no longer be read
+func (s *Store) visibleToAll(id uint64) bool {
+	if s.active[id] != nil {
+		return false
+	}
+	for _, tx := range s.active {
+		if !tx.snap.sees(id) {
+			return false
+		}
+	}
+	return true
+}
+
+// Vacuum removes the row versions and schemas no transaction can see any more and returns
+// the number removed. It runs after every commit and rollback.
+func (s *Store) Vacuum() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.vacuum()
+}
+
+func (s *Store) vacuum() int {
+	removed := 0
+	order := s.order[:0]
+	for _, id := range s.order {
+		chain := s.rows[id]
+		kept := chain[:0]
+		for _, v := range chain {
+			if v.xmax != 0 && s.visibleToAll(v.xmax) {
+				removed++
+				continue
+			}
+			kept = append(kept, v)
+		}
+		if len(kept) == 0 {
+			delete(s.rows, id)
+			continue
+		}
+		s.rows[id] = kept
+		order = append(order, id)
+	}
+	s.order = order
+	for i := len(s.catalogs) - 1; i > 0; i-- {
+		if s.visibleToAll(s.catalogs[i].xmin) {
+			removed += i
+			s.catalogs = s.catalogs[i:]
+			break
+		}
+	}
+	return removed
+}
+
+// write saves the database as seen by a snapshot to the store's file
+func (s *Store) write(snap *snapshot) error {
+	db, _, err := s.materialize(snap)
+	if err != nil {
+		return err
+	}
+	if err := db.WriteToFile(s.path); err != nil {
+		return err
+	}
+	checksum, err := fileChecksum(s.path)
+	if err != nil {
+		return err
+	}
+	s.checksum = checksum
+	s.dirty = false
+	return nil
+}
+
+// catalogOf returns a copy of the schema of a database, without rows
+func catalogOf(db *types.Database) (*types.Database, error) {
+	cat, err := db.Clone()
+	if err != nil {
+		return nil, err
+	}
+	for i := range cat.Tables {
+		cat.Tables[i].Rows = nil
+	}
+	return cat, nil
+}
+
+// schemaBytes encodes the definition of a table, leaving out its rows and counter
+func schemaBytes(t types.Table) []byte {
+	t.Rows, t.AutoIncrement = nil, 0
+	var buf bytes.Buffer
+	t.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+// sameCatalog reports whether two schemas define the same tables, sequences and views
+func sameCatalog(a, b *types.Database) bool {
+	if len(a.Tables) != len(b.Tables) || len(a.Sequences) != len(b.Sequences) || len(a.Views) != len(b.Views) ||
+		len(a.Procedures) != len(b.Procedures) || len(a.Triggers) != len(b.Triggers) {
+		return false
+	}
+	for i := range a.Tables {
+		if !bytes.Equal(schemaBytes(a.Tables[i]), schemaBytes(b.Tables[i])) {
+			return false
+		}
+	}
+	for i := range a.Sequences {
+		x, y := a.Sequences[i], b.Sequences[i]
+		if x.Name != y.Name || x.Start != y.Start || x.Increment != y.Increment {
+			return false
+		}
+	}
+	for i := range a.Views {
+		if a.Views[i].String() != b.Views[i].String() {
+			return false
+		}
+	}
+	for i := range a.Procedures {
+		if a.Procedures[i].String() != b.Procedures[i].String() {
+			return false
+		}
+	}
+	for i := range a.Triggers {
+		if a.Triggers[i].String() != b.Triggers[i].String() {
+			return false
+		}
+	}
+	return types.SameMetadata(a, b)
+}
+
+// tableSchema returns the encoded definition of the named table, or nil
+func tableSchema(db *types.Database, name string) []byte {
+	if t := db.FindTable(name); t != nil {
+		return schemaBytes(*t)
+	}
+	return nil
+}
+
+func fileChecksum(path string) ([sha256.Size]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}, fmt.Errorf("error opening database file: %v", err)
+	}
+	return sha256.Sum256(data), nil
+}
@@ -0,0 +1,140 @@
+package mvcc
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	db := types.NewDatabase()
+	table := types.Table{}
+	table.CreateTable("items", nil)
+	table.AddColumn("id", types.SQL_TYPE_INT, false)
+	db.AddTable(table)
+	path := filepath.Join(t.TempDir(), "items.db")
+	if err := db.WriteToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(path, DefaultLockTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func begin(t *testing.T, s *Store, level IsolationLevel) *Tx {
+	t.Helper()
+	tx, err := s.Begin(level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func insert(tx *Tx, id int64) error {
+	return tx.Exec(func(db *types.Database) error {
+		return db.InsertRow(db.FindTable("items"), []interface{}{id})
+	})
+}
+
+// count returns the number of rows of the items table seen by the transaction
+func count(t *testing.T, tx *Tx) int {
+	t.Helper()
+	db, err := tx.Database()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(db.FindTable("items").Rows)
+}
+
+// Test that uncommitted changes stay invisible, that snapshots keep their view while READ
+// COMMITTED sees new commits, and that savepoints and rollback undo changes
+func TestSnapshotIsolation(t *testing.T) {
+	s := newTestStore(t)
+	writer := begin(t, s, Snapshot)
+	if err := insert(writer, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Savepoint("one"); err != nil {
+		t.Fatal(err)
+	}
+	if err := insert(writer, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.RollbackTo("one"); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := begin(t, s, Snapshot)
+	committed := begin(t, s, ReadCommitted)
+	if n := count(t, committed); n != 0 {
+		t.Errorf("uncommitted rows are visible: %d", n)
+	}
+	if err := writer.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(t, snapshot); n != 0 {
+		t.Errorf("SNAPSHOT sees %d rows committed after it began, want 0", n)
+	}
+	if n := count(t, committed); n != 1 {
+		t.Errorf("READ COMMITTED sees %d rows, want 1", n)
+	}
+	snapshot.Rollback()
+	committed.Rollback()
+
+	reopened, err := Open(s.Path(), DefaultLockTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := count(t, begin(t, reopened, Snapshot)); n != 1 {
+		t.Errorf("file has %d rows after COMMIT, want 1", n)
+	}
+}
+
+// Test that concurrent transactions changing the same row conflict, and that the versions
+// they leave behind are removed once no transaction can see them
+func TestWriteConflict(t *testing.T) {
+	s := newTestStore(t)
+	setup := begin(t, s, Snapshot)
+	if err := insert(setup, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := setup.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	update := func(tx *Tx, id int64) error {
+		return tx.Exec(func(db *types.Database) error {
+			return db.FindTable("items").UpdateRow(0, []interface{}{id})
+		})
+	}
+	first := begin(t, s, Snapshot)
+	second := begin(t, s, Snapshot)
+	reader := begin(t, s, Snapshot)
+	if err := update(first, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := update(second, 3); !errors.Is(err, ErrConflict) {
+		t.Errorf("updating a row changed by a transaction in progress: got %v, want ErrConflict", err)
+	}
+	if err := first.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := update(second, 3); !errors.Is(err, ErrConflict) {
+		t.Errorf("updating a row changed after the snapshot: got %v, want ErrConflict", err)
+	}
+	second.Rollback()
+
+	// the reader still sees the old version, so it survives until the reader ends
+	if n := s.Vacuum(); n != 0 {
+		t.Errorf("vacuum removed %d versions still visible to a reader", n)
+	}
+	reader.Rollback()
+	if len(s.rows) != 1 || len(s.rows[s.order[0]]) != 1 {
+		t.Errorf("dead versions remain after the last reader ended: %v", s.rows)
+	}
+}
@@ -0,0 +1,447 @@
+package mvcc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/lock"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// Tx is a transaction of a Store. Statements run on a copy of the database returned by
+// Database, and their changes become part of the transaction through Apply. Changes stay
+// invisible to other transactions until Commit. The locks a transaction takes are held
+// until it ends.
+type Tx struct {
+	store      *Store
+	id         uint64
+	level      IsolationLevel
+	owner      lock.Owner
+	snap       *snapshot
+	view       *view
+	log        []undo // changes made, undone in reverse order on rollback
+	savepoints []savepoint
+	writing    bool // holds the database file exclusively, from its first write until it ends
+	done       bool
+}
+
+// view is the state of the store a copy of the database was built from
+type view struct {
+	db        *types.Database
+	catalog   *catalog
+	rows      map[uint64]*version // version each row was read from, by row id
+	counters  map[string]int64
+	sequences map[string]types.Sequence
+}
+
+// undo records a change of a transaction: a version or schema it wrote, or a version it ended
+type undo struct {
+	row     uint64
+	created *version
+	ended   *version
+	catalog *catalog
+}
+
+type savepoint struct {
+	name string
+	log  int
+}
+
+// ID returns the transaction id
+func (tx *Tx) ID() uint64 {
+	return tx.id
+}
+
+// Level returns the isolation level of the transaction
+func (tx *Tx) Level() IsolationLevel {
+	return tx.level
+}
+
+// Lock takes a lock held until the transaction ends
+func (tx *Tx) Lock(res lock.Resource, mode lock.Mode) error {
+	if tx.done {
+		return fmt.Errorf("the transaction has ended")
+	}
+	return tx.store.locks.Lock(tx.owner, tx.id, res, mode, tx.store.LockTimeout)
+}
+
+// Database returns a copy of the database as seen by the transaction, for a statement to
+// read or change. Under READ COMMITTED every call takes a new snapshot.
+func (tx *Tx) Database() (*types.Database, error) {
+	s := tx.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tx.done {
+		return nil, fmt.Errorf("the transaction has ended")
+	}
+	if tx.level == ReadCommitted {
+		tx.snap = s.takeSnapshot(tx.id)
+	}
+	db, v, err := s.materialize(tx.snap)
+	if err != nil {
+		return nil, err
+	}
+	tx.view = v
+	return db, nil
+}
+
+// Exec runs a statement on the database as seen by the transaction and applies its changes
+// if it succeeds
+func (tx *Tx) Exec(fn func(db *types.Database) error) error {
+	db, err := tx.Database()
+	if err != nil {
+		return err
+	}
+	if err := fn(db); err != nil {
+		return err
+	}
+	return tx.Apply(db)
+}
+
+// rowChange is a change of a statement to one row: an insert when old is nil, a delete
+// when values is nil, an update otherwise
+type rowChange struct {
+	id     uint64
+	old    *version
+	table  string
+	values []byte
+}
+
+// Apply makes the changes of a statement, made to the copy last returned by Database,
+// part of the transaction. Tables written are locked in intention exclusive mode, tables
+// redefined in exclusive mode. Rows, schemas and counters changed by a concurrent transaction
+// since they were read fail with ErrConflict and leave the transaction as it was.
+func (tx *Tx) Apply(db *types.Database) error {
+	if tx.done {
+		return fmt.Errorf("the transaction has ended")
+	}
+	v := tx.view
+	if v == nil || v.db != db {
+		return fmt.Errorf("the database was not returned by the last call to Database")
+	}
+	tx.view = nil
+
+	newCatalog, err := catalogOf(db)
+	if err != nil {
+		return err
+	}
+	schemaChanged := !sameCatalog(newCatalog, v.catalog.db)
+	changes := tx.rowChanges(db, v)
+	if schemaChanged || len(changes) > 0 {
+		if err := tx.lockFile(); err != nil {
+			return err
+		}
+	}
+	if err := tx.lockChanges(v, newCatalog, schemaChanged, changes); err != nil {
+		return err
+	}
+
+	s := tx.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tx.done {
+		return fmt.Errorf("the transaction has ended")
+	}
+
+	// schema
+	latest := s.catalogs[len(s.catalogs)-1]
+	if schemaChanged {
+		if latest != v.catalog {
+			return fmt.Errorf("%w: the schema was changed by a concurrent transaction", ErrConflict)
+		}
+		for _, name := range changedTables(v.catalog.db, newCatalog) {
+			if err := tx.checkTableUnchanged(name); err != nil {
+				return err
+			}
+		}
+	}
+
+	// rows
+	for _, c := range changes {
+		if c.old != nil {
+			if chain := s.rows[c.id]; chain[len(chain)-1] != c.old || c.old.xmax != 0 {
+				return fmt.Errorf("%w: a row of table %s was changed by a concurrent transaction", ErrConflict, c.old.table)
+			}
+		}
+		if c.values != nil && !schemaChanged && latest != v.catalog &&
+			!bytes.Equal(tableSchema(latest.db, c.table), tableSchema(v.catalog.db, c.table)) {
+			return fmt.Errorf("%w: table %s was altered by a concurrent transaction", ErrConflict, c.table)
+		}
+	}
+
+	// counters
+	for _, t := range db.Tables {
+		name := t.GetName()
+		if before, ok := v.counters[name]; ok && t.AutoIncrement != before && s.counters[name] != before {
+			return fmt.Errorf("%w: the identity counter of table %s was advanced by a concurrent transaction", ErrConflict, name)
+		}
+	}
+	for _, seq := range db.Sequences {
+		if before, ok := v.sequences[seq.Name]; ok && seq != before && s.sequences[seq.Name] != before {
+			return fmt.Errorf("%w: sequence %s was advanced by a concurrent transaction", ErrConflict, seq.Name)
+		}
+	}
+
+	// every check passed: write the changes
+	if schemaChanged {
+		c := &catalog{db: newCatalog, xmin: tx.id}
+		s.catalogs = append(s.catalogs, c)
+		tx.log = append(tx.log, undo{catalog: c})
+	}
+	for _, c := range changes {
+		if c.old != nil {
+			c.old.xmax = tx.id
+			tx.log = append(tx.log, undo{row: c.id, ended: c.old})
+		}
+		if c.values == nil {
+			continue
+		}
+		if c.id == 0 {
+			c.id = s.nextRow
+			s.nextRow++
+			s.order = append(s.order, c.id)
+		}
+		created := &version{table: c.table, values: c.values, xmin: tx.id}
+		s.rows[c.id] = append(s.rows[c.id], created)
+		tx.log = append(tx.log, undo{row: c.id, created: created})
+	}
+	// counters of new tables and sequences start from their definition; a table dropped
+	// and created again keeps counting past the old values
+	for _, t := range db.Tables {
+		name := t.GetName()
+		if before, ok := v.counters[name]; !ok || t.AutoIncrement != before {
+			if t.AutoIncrement > s.counters[name] || ok {
+				s.counters[name] = t.AutoIncrement
+			}
+			s.dirty = true
+		}
+	}
+	for _, seq := range db.Sequences {
+		if before, ok := v.sequences[seq.Name]; !ok || seq != before {
+			s.sequences[seq.Name] = seq
+			s.dirty = true
+		}
+	}
+	return nil
+}
+
+// lockFile takes the database file exclusively at the first write of the transaction, so
+// that other processes neither read nor write it until the transaction ends
+func (tx *Tx) lockFile() error {
+	if tx.writing {
+		return nil
+	}
+	if err := tx.store.locks.LockFile(lock.Exclusive, tx.store.LockTimeout); err != nil {
+		return err
+	}
+	tx.writing = true
+	return nil
+}
+
+// lockChanges takes the locks for writing the changes of a statement
+func (tx *Tx) lockChanges(v *view, newCatalog *types.Database, schemaChanged bool, changes []rowChange) error {
+	redefined := make(map[string]bool)
+	if schemaChanged {
+		for _, name := range changedTables(v.catalog.db, newCatalog) {
+			redefined[name] = true
+			if err := tx.Lock(lock.Table(name), lock.Exclusive); err != nil {
+				return err
+			}
+		}
+	}
+	for _, c := range changes {
+		if !redefined[c.table] {
+			if err := tx.Lock(lock.Table(c.table), lock.IntentionExclusive); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// rowChanges compares the rows of a changed copy of the database with the versions it was built from
+func (tx *Tx) rowChanges(db *types.Database, v *view) []rowChange {
+	var changes []rowChange
+	seen := make(map[uint64]bool)
+	for _, t := range db.Tables {
+		name := t.GetName()
+		for _, row := range t.Rows {
+			old := v.rows[row.ID]
+			if row.ID == 0 || old == nil || seen[row.ID] {
+				changes = append(changes, rowChange{table: name, values: row.Values})
+				continue
+			}
+			seen[row.ID] = true
+			if old.table != name || !bytes.Equal(old.values, row.Values) {
+				changes = append(changes, rowChange{id: row.ID, old: old, table: name, values: row.Values})
+			}
+		}
+	}
+	for id, old := range v.rows {
+		if !seen[id] {
+			changes = append(changes, rowChange{id: id, old: old, table: old.table})
+		}
+	}
+	return changes
+}
+
+// checkTableUnchanged fails if a transaction the snapshot does not see has written rows of
+// the named table, whose definition is about to change
+func (tx *Tx) checkTableUnchanged(name string) error {
+	for _, chain := range tx.store.rows {
+		for _, v := range chain {
+			if v.table != name {
+				continue
+			}
+			if !tx.snap.sees(v.xmin) || (v.xmax != 0 && !tx.snap.sees(v.xmax)) {
+				return fmt.Errorf("%w: rows of table %s were changed by a concurrent transaction", ErrConflict, name)
+			}
+		}
+	}
+	return nil
+}
+
+// changedTables returns the names of the tables created, dropped or redefined between two schemas
+func changedTables(before, after *types.Database) []string {
+	var names []string
+	for _, t := range before.Tables {
+		if !bytes.Equal(schemaBytes(t), tableSchema(after, t.GetName())) {
+			names = append(names, t.GetName())
+		}
+	}
+	for _, t := range after.Tables {
+		if before.FindTable(t.GetName()) == nil {
+			names = append(names, t.GetName())
+		}
+	}
+	return names
+}
+
+// Savepoint marks the current state of the transaction under a name. A savepoint may reuse
+// the name of an earlier one, which it then hides until released.
+func (tx *Tx) Savepoint(name string) error {
+	if tx.done {
+		return fmt.Errorf("the transaction has ended")
+	}
+	tx.savepoints = append(tx.savepoints, savepoint{name: strings.ToLower(name), log: len(tx.log)})
+	return nil
+}
+
+// findSavepoint returns the position of the latest savepoint with the given name, or -1
+func (tx *Tx) findSavepoint(name string) int {
+	for i := len(tx.savepoints) - 1; i >= 0; i-- {
+		if strings.EqualFold(tx.savepoints[i].name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// RollbackTo undoes the changes made since a savepoint. The savepoint itself remains, while
+// savepoints set after it are discarded.
+func (tx *Tx) RollbackTo(name string) error {
+	i := tx.findSavepoint(name)
+	if i == -1 {
+		return fmt.Errorf("savepoint not found: %s", name)
+	}
+	tx.store.mu.Lock()
+	defer tx.store.mu.Unlock()
+	tx.undo(tx.savepoints[i].log)
+	tx.savepoints = tx.savepoints[:i+1]
+	return nil
+}
+
+// Release discards a savepoint and those set after it, keeping the changes made since
+func (tx *Tx) Release(name string) error {
+	i := tx.findSavepoint(name)
+	if i == -1 {
+		return fmt.Errorf("savepoint not found: %s", name)
+	}
+	tx.savepoints = tx.savepoints[:i]
+	return nil
+}
+
+// undo reverts the changes logged from the given position on
+func (tx *Tx) undo(from int) {
+	s := tx.store
+	for i := len(tx.log) - 1; i >= from; i-- {
+		u := tx.log[i]
+		switch {
+		case u.created != nil:
+			chain := s.rows[u.row]
+			if chain = chain[:len(chain)-1]; len(chain) == 0 {
+				delete(s.rows, u.row)
+			} else {
+				s.rows[u.row] = chain
+			}
+		case u.ended != nil:
+			u.ended.xmax = 0
+		case u.catalog != nil:
+			s.catalogs = s.catalogs[:len(s.catalogs)-1]
+		}
+	}
+	tx.log = tx.log[:from]
+	tx.view = nil
+}
+
+// Commit makes the changes of the transaction visible to transactions beginning after it
+// and writes them to the store's file, which the transaction holds from its first write. It
+// fails, rolling back, if another process has written the file since the store read it or
+// keeps it locked past the lock timeout.
+func (tx *Tx) Commit() error {
+	s := tx.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tx.done {
+		return fmt.Errorf("the transaction has ended")
+	}
+	if s.path != "" && (len(tx.log) > 0 || s.dirty) {
+		err := s.file.Lock(lock.Exclusive, s.LockTimeout)
+		if err == nil {
+			var checksum [sha256.Size]byte
+			checksum, err = fileChecksum(s.path)
+			if err == nil && checksum != s.checksum {
+				err = fmt.Errorf("%w: the database file was changed by another process", ErrConflict)
+			}
+			if err == nil {
+				// the transaction's changes and those committed before, but none in progress
+				err = s.write(s.takeSnapshot(tx.id))
+			}
+			s.file.Unlock(lock.Exclusive)
+		}
+		if err != nil {
+			tx.end(true)
+			return err
+		}
+	}
+	tx.end(false)
+	return nil
+}
+
+// Rollback undoes every change of the transaction
+func (tx *Tx) Rollback() {
+	s := tx.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !tx.done {
+		tx.end(true)
+	}
+}
+
+// end finishes the transaction, releases its locks and removes the versions no one can see
+// any more
+func (tx *Tx) end(rollback bool) {
+	if rollback {
+		tx.undo(0)
+	}
+	tx.done = true
+	delete(tx.store.active, tx.id)
+	tx.store.locks.Unlock(tx.owner, tx.id)
+	if tx.writing {
+		tx.store.locks.UnlockFile(lock.Exclusive)
+		tx.writing = false
+	}
+	tx.store.vacuum()
+}
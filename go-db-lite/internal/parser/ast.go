@@ -0,0 +1,681 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// Expr is a node of a SQL expression tree. String returns the SQL text of the expression.
+type Expr interface {
+	exprNode()
+	String() string
+}
+
+// Literal is a constant: nil, int64, float64, types.Decimal, string or bool
+type Literal struct {
+	Value interface{}
+}
+
+// ColumnRef is a (possibly table-qualified) column name
+type ColumnRef struct {
+	Table string
+	Name  string
+}
+
+// BinaryExpr is an infix operation such as a + b, a = b, a AND b or a LIKE b
+type BinaryExpr struct {
+	Op    string // upper case for word operators (AND, OR, LIKE)
+	Left  Expr
+	Right Expr
+}
+
+// UnaryExpr is a prefix operation such as -a or NOT a
+type UnaryExpr struct {
+	Op   string
+	Expr Expr
+}
+
+// FuncCall is a scalar or aggregate function call
+type FuncCall struct {
+	Name     string // upper case
+	Args     []Expr
+	Star     bool // COUNT(*)
+	Distinct bool // COUNT(DISTINCT x)
+}
+
+// IsNullExpr is "expr IS [NOT] NULL"
+type IsNullExpr struct {
+	Expr Expr
+	Not  bool
+}
+
+// BetweenExpr is "expr [NOT] BETWEEN low AND high"
+type BetweenExpr struct {
+	Expr Expr
+	Low  Expr
+	High Expr
+	Not  bool
+}
+
+// InExpr is "expr [NOT] IN (list)"
+type InExpr struct {
+	Expr Expr
+	List []Expr
+	Not  bool
+}
+
+// WhenClause is one "WHEN cond THEN result" branch of a CASE expression
+type WhenClause struct {
+	Cond   Expr
+	Result Expr
+}
+
+// CaseExpr is "CASE [operand] WHEN ... THEN ... [ELSE ...] END"
+type CaseExpr struct {
+	Operand Expr
+	Whens   []WhenClause
+	Else    Expr
+}
+
+// IntervalExpr is "INTERVAL expr unit", e.g. INTERVAL 3 DAY
+type IntervalExpr struct {
+	Value Expr
+	Unit  string // upper case, singular
+}
+
+func (*Literal) exprNode()      {}
+func (*ColumnRef) exprNode()    {}
+func (*BinaryExpr) exprNode()   {}
+func (*UnaryExpr) exprNode()    {}
+func (*FuncCall) exprNode()     {}
+func (*IsNullExpr) exprNode()   {}
+func (*BetweenExpr) exprNode()  {}
+func (*InExpr) exprNode()       {}
+func (*CaseExpr) exprNode()     {}
+func (*IntervalExpr) exprNode() {}
+
+func (e *Literal) String() string {
+	switch v := e.Value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return QuoteString(v)
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func (e *ColumnRef) String() string {
+	if e.Table != "" {
+		return e.Table + "." + e.Name
+	}
+	return e.Name
+}
+
+func (e *BinaryExpr) String() string {
+	return "(" + e.Left.String() + " " + e.Op + " " + e.Right.String() + ")"
+}
+
+func (e *UnaryExpr) String() string {
+	if e.Op == "NOT" {
+		return "NOT " + e.Expr.String()
+	}
+	return e.Op + e.Expr.String()
+}
+
+func (e *FuncCall) String() string {
+	if e.Star {
+		return e.Name + "(*)"
+	}
+	if field, ok := e.extractField(); ok {
+		return "EXTRACT(" + field + " FROM " + e.Args[1].String() + ")"
+	}
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = arg.String()
+	}
+	distinct := ""
+	if e.Distinct {
+		distinct = "DISTINCT "
+	}
+	return e.Name + "(" + distinct + strings.Join(args, ", ") + ")"
+}
+
+// extractField returns the field name of an EXTRACT(field FROM expr) call
+func (e *FuncCall) extractField() (string, bool) {
+	if e.Name != "EXTRACT" || len(e.Args) != 2 {
+		return "", false
+	}
+	lit, ok := e.Args[0].(*Literal)
+	if !ok {
+		return "", false
+	}
+	field, ok := lit.Value.(string)
+	return field, ok
+}
+
+func (e *IsNullExpr) String() string {
+	if e.Not {
+		return e.Expr.String() + " IS NOT NULL"
+	}
+	return e.Expr.String() + " IS NULL"
+}
+
+func (e *BetweenExpr) String() string {
+	not := ""
+	if e.Not {
+		not = "NOT "
+	}
+	return e.Expr.String() + " " + not + "BETWEEN " + e.Low.String() + " AND " + e.High.String()
+}
+
+func (e *InExpr) String() string {
+	items := make([]string, len(e.List))
+	for i, item := range e.List {
+		items[i] = item.String()
+	}
+	not := ""
+	if e.Not {
+		not = "NOT "
+	}
+	return e.Expr.String() + " " + not + "IN (" + strings.Join(items, ", ") + ")"
+}
+
+func (e *CaseExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString("CASE")
+	if e.Operand != nil {
+		sb.WriteString(" " + e.Operand.String())
+	}
+	for _, when := range e.Whens {
+		sb.WriteString(" WHEN " + when.Cond.String() + " THEN " + when.Result.String())
+	}
+	if e.Else != nil {
+		sb.WriteString(" ELSE " + e.Else.String())
+	}
+	sb.WriteString(" END")
+	return sb.String()
+}
+
+func (e *IntervalExpr) String() string {
+	return "INTERVAL " + e.Value.String() + " " + e.Unit
+}
+
+// QuoteString returns s as a single quoted SQL string literal
+func QuoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// SelectItem is one entry of a select list
+type SelectItem struct {
+	Expr  Expr
+	Alias string
+	Star  bool   // * or table.*
+	Table string // qualifier of table.*
+}
+
+// OrderItem is one entry of an ORDER BY clause
+type OrderItem struct {
+	Expr Expr
+	Desc bool
+}
+
+// TableName is a table reference in a FROM clause
+type TableName struct {
+	Schema string // the attached database of other.table, if named
+	Name   string
+	Alias  string
+}
+
+// Join is a table joined to the ones before it in a FROM clause
+type Join struct {
+	Kind  string // INNER, LEFT, RIGHT or CROSS
+	Table *TableName
+	On    Expr // nil for CROSS joins
+}
+
+// SelectStmt is a parsed SELECT statement
+type SelectStmt struct {
+	Distinct bool
+	Columns  []SelectItem
+	Into     []string   // variables set by SELECT ... INTO, in procedures only
+	From     *TableName // nil for SELECT without FROM
+	Joins    []Join     // tables joined to From, in order
+	Where    Expr
+	GroupBy  []Expr
+	Having   Expr
+	OrderBy  []OrderItem
+	Limit    Expr
+	Offset   Expr
+}
+
+// InsertStmt is a parsed INSERT statement; Columns is empty when no column list is given
+type InsertStmt struct {
+	Table   string
+	Columns []string
+	Rows    [][]Expr
+}
+
+// Assignment is one "column = expr" of an UPDATE statement
+type Assignment struct {
+	Column string
+	Value  Expr
+}
+
+// UpdateStmt is a parsed UPDATE statement
+type UpdateStmt struct {
+	Table string
+	Set   []Assignment
+	Where Expr
+}
+
+// DeleteStmt is a parsed DELETE statement
+type DeleteStmt struct {
+	Table string
+	Where Expr
+}
+
+// ImportStmt is a parsed IMPORT statement, loading the rows of a CSV file into a table
+type ImportStmt struct {
+	Table string
+	File  string
+}
+
+// AlterTableStmt is a parsed ALTER TABLE statement with a single action
+type AlterTableStmt struct {
+	Table   string
+	Action  string    // ADD COLUMN, DROP COLUMN, RENAME COLUMN, RENAME TO or ALTER COLUMN TYPE
+	Column  ColumnDef // ADD COLUMN only
+	Name    string    // column dropped, renamed or altered
+	NewName string    // RENAME COLUMN and RENAME TO
+	Type    TypeSpec  // ALTER COLUMN TYPE only
+}
+
+// CreateSequenceStmt is a parsed CREATE SEQUENCE statement
+type CreateSequenceStmt struct {
+	Name      string
+	Start     int64
+	Increment int64
+}
+
+// DropSequenceStmt is a parsed DROP SEQUENCE statement
+type DropSequenceStmt struct {
+	Name     string
+	IfExists bool
+}
+
+// DropStmt is a parsed DROP TABLE, DROP INDEX or DROP DATABASE statement
+type DropStmt struct {
+	Object   string // TABLE, INDEX, VIEW, MATERIALIZED VIEW or DATABASE
+	Name     string
+	Table    string // DROP INDEX name ON table only
+	IfExists bool
+	Cascade  bool // DROP TABLE and DROP [MATERIALIZED] VIEW only: drop the views depending on the object too
+}
+
+// RefreshStmt is a parsed REFRESH MATERIALIZED VIEW statement
+type RefreshStmt struct {
+	View string
+}
+
+// CreateViewStmt is a parsed CREATE [OR REPLACE] VIEW or CREATE MATERIALIZED VIEW statement
+type CreateViewStmt struct {
+	Name         string
+	OrReplace    bool
+	Materialized bool
+	Columns      []string // names given to the columns of the query, if listed
+	Query        *SelectStmt
+	QueryText    string // the SELECT as written, which is what the catalog keeps
+}
+
+// Parameter is a parameter of a stored procedure
+type Parameter struct {
+	Name string
+	Type TypeSpec
+}
+
+// CreateProcedureStmt is a parsed CREATE [OR REPLACE] PROCEDURE statement
+type CreateProcedureStmt struct {
+	Name      string
+	OrReplace bool
+	Params    []Parameter
+	Body      []Statement
+	BodyText  string // BEGIN ... END as written, which is what the catalog keeps
+}
+
+// AlterProcedureStmt is a parsed ALTER PROCEDURE name RENAME TO new_name statement
+type AlterProcedureStmt struct {
+	Name    string
+	NewName string
+}
+
+// CallStmt is a parsed CALL statement
+type CallStmt struct {
+	Name string
+	Args []Expr
+}
+
+// CreateTriggerStmt is a parsed CREATE TRIGGER statement
+type CreateTriggerStmt struct {
+	Name     string
+	Timing   string // BEFORE or AFTER
+	Event    string // INSERT, UPDATE or DELETE
+	Table    string
+	Body     []Statement
+	BodyText string // the body as written, which is what the catalog keeps
+}
+
+// Statement is one statement of the body of a stored procedure or trigger
+type Statement interface {
+	statementNode()
+}
+
+// DeclareStmt is "DECLARE name [, ...] type [DEFAULT expr]"
+type DeclareStmt struct {
+	Names   []string
+	Type    TypeSpec
+	Default Expr // nil for NULL
+}
+
+// SetStmt is "SET name = expr", or "SET NEW.column = expr" in a trigger
+type SetStmt struct {
+	Row   string // NEW when a column of the row is set
+	Name  string
+	Value Expr
+}
+
+// IfStmt is "IF cond THEN ... [ELSEIF cond THEN ...] [ELSE ...] END IF"; Conds and Bodies
+// hold the IF and ELSEIF branches in order
+type IfStmt struct {
+	Conds  []Expr
+	Bodies [][]Statement
+	Else   []Statement
+}
+
+// WhileStmt is "WHILE cond DO ... END WHILE"
+type WhileStmt struct {
+	Cond Expr
+	Body []Statement
+}
+
+// ForStmt is "FOR row IN (SELECT ...) DO ... END FOR", running the body once for every row
+// of the query with its columns readable as row.column
+type ForStmt struct {
+	Row       string
+	Query     *SelectStmt
+	QueryText string // the SELECT as written
+	Body      []Statement
+}
+
+// ReturnStmt is "RETURN [expr]", ending the procedure
+type ReturnStmt struct {
+	Value Expr // nil if no value is returned
+}
+
+// SignalStmt is "SIGNAL SQLSTATE 'state' [SET MESSAGE_TEXT = expr]", raising an error
+type SignalStmt struct {
+	State   string
+	Message Expr // nil for the default message
+}
+
+// SQLStatement is a SELECT, INSERT, UPDATE, DELETE or CALL in the body of a procedure
+type SQLStatement struct {
+	Text string      // the statement as written
+	Stmt interface{} // *SelectStmt, *InsertStmt, *UpdateStmt, *DeleteStmt or *CallStmt
+}
+
+func (*DeclareStmt) statementNode()  {}
+func (*SetStmt) statementNode()      {}
+func (*IfStmt) statementNode()       {}
+func (*WhileStmt) statementNode()    {}
+func (*ForStmt) statementNode()      {}
+func (*ReturnStmt) statementNode()   {}
+func (*SignalStmt) statementNode()   {}
+func (*SQLStatement) statementNode() {}
+
+// TruncateStmt is a parsed TRUNCATE TABLE statement
+type TruncateStmt struct {
+	Table string
+}
+
+// LockStmt is a parsed LOCK or UNLOCK TABLES statement
+type LockStmt struct {
+	Unlock   bool     // UNLOCK TABLES, releasing the locks of the session
+	Database bool     // LOCK DATABASE
+	Name     string   // name of the database locked, if given
+	Tables   []string // LOCK TABLE
+	Mode     string   // SHARE or EXCLUSIVE
+}
+
+// UserStmt is a parsed CREATE, ALTER or DROP statement for a user or role
+type UserStmt struct {
+	Action   string // CREATE, ALTER or DROP
+	Role     bool
+	Name     string
+	Password *string // nil when no PASSWORD clause is given
+	IfExists bool    // DROP only
+}
+
+// Privilege is a privilege named in GRANT or REVOKE, optionally limited to some columns
+type Privilege struct {
+	Name    string // upper case, ALL for ALL [PRIVILEGES]
+	Columns []string
+}
+
+// GrantStmt is a parsed GRANT or REVOKE statement
+type GrantStmt struct {
+	Revoke      bool
+	Privileges  []Privilege
+	Role        string // GRANT ROLE role TO ... instead of privileges
+	Database    string // * for every database, empty for the database in use
+	Table       string // empty for the whole database
+	Grantees    []string
+	GrantOption bool // WITH GRANT OPTION
+}
+
+// ShowGrantsStmt is a parsed SHOW GRANTS statement
+type ShowGrantsStmt struct {
+	User string // empty for the session user
+}
+
+// ShowStmt is a parsed statement describing the tables of the database: SHOW TABLES,
+// DESCRIBE table, SHOW CREATE TABLE table or SHOW INDEXES FROM table
+type ShowStmt struct {
+	Object string // TABLES, DESCRIBE, CREATE TABLE or INDEXES
+	Table  string // empty for SHOW TABLES
+}
+
+// CommentStmt is a parsed COMMENT ON TABLE table IS 'text' or COMMENT ON COLUMN
+// table.column IS 'text' statement
+type CommentStmt struct {
+	Table  string
+	Column string // empty for a comment on the table
+	Text   string // empty for IS NULL, which removes the comment
+}
+
+// PragmaStmt is a parsed PRAGMA [table.]key [= value] statement. Without a key it lists the
+// settings of the database, or of the table.
+type PragmaStmt struct {
+	Table string // empty for a setting of the database
+	Key   string
+	Set   bool
+	Value string // empty for = NULL, which removes the setting
+}
+
+// AttachStmt is a parsed ATTACH DATABASE 'file' AS alias or DETACH DATABASE alias statement
+type AttachStmt struct {
+	Detach bool
+	File   string // ATTACH only
+	Alias  string
+}
+
+// TransactionStmt is a parsed transaction control statement
+type TransactionStmt struct {
+	Action    string // BEGIN, COMMIT, ROLLBACK, SAVEPOINT, ROLLBACK TO or RELEASE
+	Savepoint string
+	Isolation string // BEGIN only, e.g. READ COMMITTED
+}
+
+// TypeSpec is a column type as written in a column definition, e.g. DECIMAL(10,2)
+type TypeSpec struct {
+	Name string   // upper case type name
+	Args []string // raw parameter literals; the value list of ENUM and SET
+}
+
+func (t TypeSpec) String() string {
+	if len(t.Args) == 0 {
+		return t.Name
+	}
+	if t.Name == "ENUM" || t.Name == "SET" {
+		labels := make([]string, len(t.Args))
+		for i, arg := range t.Args {
+			labels[i] = QuoteString(arg)
+		}
+		return t.Name + "(" + strings.Join(labels, ",") + ")"
+	}
+	return t.Name + "(" + strings.Join(t.Args, ",") + ")"
+}
+
+// ColumnDef is a single column definition of a CREATE TABLE statement
+type ColumnDef struct {
+	Name       string
+	Type       TypeSpec
+	NotNull    bool
+	PrimaryKey bool
+	References *References // inline REFERENCES clause, nil if none
+	Default    Expr        // DEFAULT expression, nil if none
+	Check      Expr        // inline CHECK condition, nil if none
+	Identity   string      // AUTO_INCREMENT, BY DEFAULT or ALWAYS; empty if not an identity column
+}
+
+// TableConstraint is a table-level constraint of a CREATE TABLE statement, e.g. PRIMARY KEY (a, b)
+type TableConstraint struct {
+	Name       string // from CONSTRAINT name, may be empty
+	Kind       string // PRIMARY KEY, FOREIGN KEY or CHECK
+	Columns    []string
+	References *References // FOREIGN KEY only
+	Check      Expr        // CHECK only
+}
+
+// References is the target of a foreign key: "REFERENCES table [(columns)] [ON DELETE action] [ON UPDATE action]"
+type References struct {
+	Table    string
+	Columns  []string // empty to reference the primary key
+	OnDelete string   // RESTRICT, CASCADE, SET NULL, SET DEFAULT or NO ACTION; empty if not given
+	OnUpdate string
+}
+
+// literalValue converts a number token to its literal value: int64, types.Decimal or float64
+func literalValue(text string) (interface{}, error) {
+	if strings.ContainsAny(text, "eE") {
+		var f float64
+		if _, err := fmt.Sscanf(text, "%g", &f); err != nil {
+			return nil, fmt.Errorf("invalid number %q", text)
+		}
+		return f, nil
+	}
+	d, err := types.ParseDecimal(text)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(text, ".") {
+		if i, ok := d.Int64(); ok {
+			return i, nil
+		}
+	}
+	return d, nil
+}
+
+// Walk calls fn for expr and, depth first, each of its sub-expressions; returning false stops descending
+func Walk(expr Expr, fn func(Expr) bool) {
+	if expr == nil || !fn(expr) {
+		return
+	}
+	switch e := expr.(type) {
+	case *BinaryExpr:
+		Walk(e.Left, fn)
+		Walk(e.Right, fn)
+	case *UnaryExpr:
+		Walk(e.Expr, fn)
+	case *FuncCall:
+		for _, arg := range e.Args {
+			Walk(arg, fn)
+		}
+	case *IsNullExpr:
+		Walk(e.Expr, fn)
+	case *BetweenExpr:
+		Walk(e.Expr, fn)
+		Walk(e.Low, fn)
+		Walk(e.High, fn)
+	case *InExpr:
+		Walk(e.Expr, fn)
+		for _, item := range e.List {
+			Walk(item, fn)
+		}
+	case *CaseExpr:
+		Walk(e.Operand, fn)
+		for _, when := range e.Whens {
+			Walk(when.Cond, fn)
+			Walk(when.Result, fn)
+		}
+		Walk(e.Else, fn)
+	case *IntervalExpr:
+		Walk(e.Value, fn)
+	}
+}
+
+// Rewrite returns a copy of an expression tree in which every node for which fn returns a
+// replacement is replaced by it. Nodes fn returns nil for are copied, with their children
+// rewritten; the tree passed in is left unchanged.
+func Rewrite(expr Expr, fn func(Expr) Expr) Expr {
+	if expr == nil {
+		return nil
+	}
+	if replaced := fn(expr); replaced != nil {
+		return replaced
+	}
+	rewriteAll := func(exprs []Expr) []Expr {
+		if exprs == nil {
+			return nil
+		}
+		out := make([]Expr, len(exprs))
+		for i, e := range exprs {
+			out[i] = Rewrite(e, fn)
+		}
+		return out
+	}
+	switch e := expr.(type) {
+	case *BinaryExpr:
+		return &BinaryExpr{Op: e.Op, Left: Rewrite(e.Left, fn), Right: Rewrite(e.Right, fn)}
+	case *UnaryExpr:
+		return &UnaryExpr{Op: e.Op, Expr: Rewrite(e.Expr, fn)}
+	case *FuncCall:
+		return &FuncCall{Name: e.Name, Args: rewriteAll(e.Args), Star: e.Star, Distinct: e.Distinct}
+	case *IsNullExpr:
+		return &IsNullExpr{Expr: Rewrite(e.Expr, fn), Not: e.Not}
+	case *BetweenExpr:
+		return &BetweenExpr{Expr: Rewrite(e.Expr, fn), Low: Rewrite(e.Low, fn), High: Rewrite(e.High, fn), Not: e.Not}
+	case *InExpr:
+		return &InExpr{Expr: Rewrite(e.Expr, fn), List: rewriteAll(e.List), Not: e.Not}
+	case *CaseExpr:
+		c := &CaseExpr{Operand: Rewrite(e.Operand, fn), Else: Rewrite(e.Else, fn)}
+		for _, when := range e.Whens {
+			c.Whens = append(c.Whens, WhenClause{Cond: Rewrite(when.Cond, fn), Result: Rewrite(when.Result, fn)})
+		}
+		return c
+	case *IntervalExpr:
+		return &IntervalExpr{Value: Rewrite(e.Value, fn), Unit: e.Unit}
+	case *Literal:
+		return &Literal{Value: e.Value}
+	case *ColumnRef:
+		return &ColumnRef{Table: e.Table, Name: e.Name}
+	}
+	return expr
+}
@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TokenKind identifies the lexical class of a token
+type TokenKind int
+
+const (
+	TokenEOF    TokenKind = iota
+	TokenIdent            // bare word, keywords included (compared case insensitive)
+	TokenQuoted           // `quoted` or "quoted" identifier
+	TokenNumber           // integer or decimal literal, e.g. 42, 12.50, 1e3
+	TokenString           // 'single quoted' string literal
+	TokenSymbol           // operators and punctuation
+)
+
+// Token is a single lexical token of a SQL statement
+type Token struct {
+	Kind TokenKind
+	Text string // for strings and quoted identifiers, the unquoted text
+	Pos  int    // byte offset in the input
+}
+
+// multiCharSymbols are the operators made of more than one character, longest first
+var multiCharSymbols = []string{"<=>", "<>", "!=", "<=", ">=", "||", "::", ":="}
+
+// Tokenize splits a SQL statement into tokens
+func Tokenize(input string) ([]Token, error) {
+	var tokens []Token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '-' && i+1 < len(input) && input[i+1] == '-':
+			// line comment
+			for i < len(input) && input[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(input) && input[i+1] == '*':
+			end := strings.Index(input[i+2:], "*/")
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated comment at position %d", i)
+			}
+			i += end + 4
+		case isIdentStart(c):
+			start := i
+			for i < len(input) && isIdentPart(input[i]) {
+				i++
+			}
+			tokens = append(tokens, Token{Kind: TokenIdent, Text: input[start:i], Pos: start})
+		case isDigit(c) || (c == '.' && i+1 < len(input) && isDigit(input[i+1])):
+			start := i
+			for i < len(input) && isDigit(input[i]) {
+				i++
+			}
+			if i < len(input) && input[i] == '.' {
+				i++
+				for i < len(input) && isDigit(input[i]) {
+					i++
+				}
+			}
+			if i < len(input) && (input[i] == 'e' || input[i] == 'E') {
+				j := i + 1
+				if j < len(input) && (input[j] == '+' || input[j] == '-') {
+					j++
+				}
+				if j < len(input) && isDigit(input[j]) {
+					i = j
+					for i < len(input) && isDigit(input[i]) {
+						i++
+					}
+				}
+			}
+			tokens = append(tokens, Token{Kind: TokenNumber, Text: input[start:i], Pos: start})
+		case c == '\'':
+			text, next, err := readQuoted(input, i, '\'')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, Token{Kind: TokenString, Text: text, Pos: i})
+			i = next
+		case c == '"' || c == '`':
+			text, next, err := readQuoted(input, i, c)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, Token{Kind: TokenQuoted, Text: text, Pos: i})
+			i = next
+		default:
+			symbol := string(c)
+			for _, s := range multiCharSymbols {
+				if strings.HasPrefix(input[i:], s) {
+					symbol = s
+					break
+				}
+			}
+			if !strings.Contains("(),.;*+-/%=<>!|:?@", symbol[:1]) {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			tokens = append(tokens, Token{Kind: TokenSymbol, Text: symbol, Pos: i})
+			i += len(symbol)
+		}
+	}
+	tokens = append(tokens, Token{Kind: TokenEOF, Pos: len(input)})
+	return tokens, nil
+}
+
+// readQuoted reads a quoted run starting at input[start] == quote; a doubled quote is an escaped quote
+func readQuoted(input string, start int, quote byte) (string, int, error) {
+	var sb strings.Builder
+	i := start + 1
+	for i < len(input) {
+		c := input[i]
+		if c == quote {
+			if i+1 < len(input) && input[i+1] == quote {
+				sb.WriteByte(quote)
+				i += 2
+				continue
+			}
+			return sb.String(), i + 1, nil
+		}
+		if c == '\\' && quote == '\'' && i+1 < len(input) {
+			switch input[i+1] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(input[i+1])
+			}
+			i += 2
+			continue
+		}
+		sb.WriteByte(c)
+		i++
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string at position %d", start)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '$'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -10,7 +10,8 @@ import (
 
 // ParseCommand parses the command from the input buffer
 func ParseCommand(inputBuffer *types.InputBuffer) (types.CommandType, error) {
-	tokens := strings.Fields(strings.ToLower(string(inputBuffer.Buffer)))
+	// a trailing semicolon must not stick to the command word, as in "commit;"
+	tokens := strings.Fields(strings.ToLower(strings.TrimSuffix(strings.TrimSpace(string(inputBuffer.Buffer)), ";")))
 
 	if len(tokens) == 0 {
 		return types.UnknownCommand{}, errors.New("no command provided")
@@ -22,8 +23,7 @@ func ParseCommand(inputBuffer *types.InputBuffer) (types.CommandType, error) {
 			if len(tokens) < 2 {
 				return nil, fmt.Errorf("The top level command \"%s\" requires a subcommand. Available options: %v", coreCommand.CommandName(), listSubCommands(subCommandMap))
 			}
-			subCommand := findCommand(tokens[1], subCommandMap)
-			if subCommand != nil {
+			if subCommand := findSubCommand(tokens[1:], subCommandMap); subCommand != nil {
 				return subCommand, nil
 			}
 			return types.UnknownCommand{}, fmt.Errorf("Unknown subcommand \"%s\" for command \"%s\"", tokens[1], coreCommand.CommandName())
@@ -38,6 +38,30 @@ func ParseCommand(inputBuffer *types.InputBuffer) (types.CommandType, error) {
 	return types.UnknownCommand{}, fmt.Errorf("Unknown command \"%s\"", tokens[0])
 }
 
+// maxSubCommandWords is the most words a subcommand spans, as in "or replace view"
+const maxSubCommandWords = 3
+
+// findSubCommand finds the longest subcommand the tokens start with, so that
+// "create unique index" is not taken for a CREATE of something named unique
+func findSubCommand(tokens []string, subCommandMap []types.CommandMapping) types.CommandType {
+	for n := min(len(tokens), maxSubCommandWords); n > 0; n-- {
+		words := append(append([]string{}, tokens[:n-1]...), subCommandWord(tokens[n-1]))
+		if subCommand := findCommand(strings.Join(words, " "), subCommandMap); subCommand != nil {
+			return subCommand
+		}
+	}
+	return nil
+}
+
+// subCommandWord cuts off a list or parenthesis the subcommand runs into, as in
+// "grant select(name), insert ..."
+func subCommandWord(token string) string {
+	if i := strings.IndexAny(token, ",("); i > 0 {
+		return token[:i]
+	}
+	return token
+}
+
 // findCommand finds the command in the command map
 func findCommand(commandStr string, commandMap []types.CommandMapping) types.CommandType {
 	for _, mapping := range commandMap {
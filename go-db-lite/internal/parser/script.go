@@ -0,0 +1,180 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// SplitScript splits a script into the statements it holds. A statement ends at a semicolon,
+// or at the end of a line when the next line starts with a command, so that scripts written
+// one statement per line, as typed at the prompt, need no semicolons. In a script that ends
+// its statements with semicolons, a line only ends a statement the parser takes as whole, so
+// that a statement such as CREATE VIEW ... AS may go on with SELECT on the next line.
+// Semicolons and lines inside strings, comments, parentheses, CASE ... END and the
+// BEGIN ... END body of a procedure or trigger do not end a statement, nor do the lines of a
+// procedure or trigger header, whose body usually starts with BEGIN on a line of its own.
+func SplitScript(input string) []string {
+	statements, terminated := splitScript(input, false)
+	if terminated {
+		statements, _ = splitScript(input, true)
+	}
+	return statements
+}
+
+// splitScript splits a script, ending statements at lines only where they are complete if
+// the script is terminated, and reports whether a semicolon ended any statement
+func splitScript(input string, terminated bool) ([]string, bool) {
+	var statements []string
+	semicolons := false
+	start, depth, blocks := 0, 0, 0
+	var words []string // the first words of the statement, upper case
+	body := false      // whether the BEGIN of a procedure or trigger has been read
+	flush := func(end int) {
+		if s := strings.TrimSpace(input[start:end]); s != "" {
+			statements = append(statements, s)
+		}
+		start, depth, blocks, words, body = end, 0, 0, nil, false
+	}
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == '-' && i+1 < len(input) && input[i+1] == '-':
+			for i < len(input) && input[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(input) && input[i+1] == '*':
+			end := strings.Index(input[i+2:], "*/")
+			if end == -1 {
+				i = len(input)
+				break
+			}
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`':
+			_, next, err := readQuoted(input, i, c)
+			if err != nil {
+				i = len(input) // an unterminated string runs to the end, where it fails to parse
+				break
+			}
+			i = next
+		case isIdentStart(c):
+			begin := i
+			for i < len(input) && isIdentPart(input[i]) {
+				i++
+			}
+			word := strings.ToUpper(input[begin:i])
+			if len(words) < 4 {
+				words = append(words, word)
+			}
+			switch {
+			case word == "CASE":
+				blocks++
+			case word == "BEGIN" && isRoutine(words):
+				blocks++
+				body = true
+			case word == "END" && blocks > 0:
+				if next := nextWord(input[i:]); next != "IF" && next != "WHILE" && next != "FOR" {
+					blocks--
+				}
+			}
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			depth = max(depth-1, 0)
+			i++
+		case c == ';' && depth == 0 && blocks == 0:
+			i++
+			flush(i)
+			semicolons = true
+		case c == '\n' && depth == 0 && blocks == 0 && (body || !isRoutine(words)) && startsCommand(input[i:]) &&
+			(!terminated || complete(input[start:i])):
+			i++
+			flush(i)
+		default:
+			i++
+		}
+		if len(words) == 0 && strings.IndexByte(" \t\r\n-/", c) != -1 {
+			start = i // comments and blank lines before a statement are not part of it
+		}
+	}
+	flush(len(input))
+	return statements, semicolons
+}
+
+// complete reports whether text holds a whole statement. Only the statements the parser
+// has a grammar for can be found incomplete; the others are taken as complete.
+func complete(text string) bool {
+	command, err := ParseCommand(&types.InputBuffer{Buffer: []byte(text)})
+	if err != nil {
+		return true
+	}
+	switch command.Command() {
+	case types.CmdSelect:
+		_, err = ParseSelect(text)
+	case types.CmdInsert:
+		_, err = ParseInsert(text)
+	case types.CmdUpdate:
+		_, err = ParseUpdate(text)
+	case types.CmdDelete:
+		_, err = ParseDelete(text)
+	case types.CmdCall:
+		_, err = ParseCall(text)
+	case types.CmdCreateView, types.CmdCreateOrReplaceView, types.CmdCreateMaterializedView:
+		_, err = ParseCreateView(text)
+	case types.CmdAlterTable:
+		_, err = ParseAlterTable(text)
+	}
+	return err == nil
+}
+
+// isRoutine reports whether a statement starting with the given words creates a procedure
+// or a trigger, whose body is a BEGIN ... END block
+func isRoutine(words []string) bool {
+	if len(words) == 0 || words[0] != "CREATE" {
+		return false
+	}
+	for _, word := range words[1:] {
+		if word == "PROCEDURE" || word == "TRIGGER" {
+			return true
+		}
+	}
+	return false
+}
+
+// nextWord returns the word the text starts with after white space, upper case
+func nextWord(text string) string {
+	text = strings.TrimLeft(text, " \t\r\n")
+	i := 0
+	for i < len(text) && isIdentPart(text[i]) {
+		i++
+	}
+	return strings.ToUpper(text[:i])
+}
+
+// startsCommand reports whether the first word after white space and comments is a
+// command, such as SELECT or USE
+func startsCommand(text string) bool {
+	for {
+		text = strings.TrimLeft(text, " \t\r\n")
+		if strings.HasPrefix(text, "--") {
+			if end := strings.IndexByte(text, '\n'); end != -1 {
+				text = text[end:]
+				continue
+			}
+			return false
+		}
+		break
+	}
+	word := strings.ToLower(nextWord(text))
+	if word == "" || word == "unknown" {
+		return false
+	}
+	for _, mapping := range types.CoreCommandMap {
+		if first, _, _ := strings.Cut(mapping.CommandStr, " "); first == word {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test that scripts split at semicolons and at lines starting a command, but not inside the
+// header or body of a procedure or trigger written over several lines
+func TestSplitScript(t *testing.T) {
+	script := `-- set up
+create table log (msg varchar)
+insert into log values ('a; b');
+
+create procedure note (msg varchar)
+begin
+  insert into log values (msg);
+  update log set msg = upper(msg);
+end;
+
+create trigger audit after insert on log
+for each row
+begin
+  delete from log where msg = 'x';
+end
+select * from log`
+	want := []string{
+		"create table log (msg varchar)",
+		"insert into log values ('a; b');",
+		"create procedure note (msg varchar)\nbegin\n  insert into log values (msg);\n  update log set msg = upper(msg);\nend;",
+		"create trigger audit after insert on log\nfor each row\nbegin\n  delete from log where msg = 'x';\nend",
+		"select * from log",
+	}
+	if got := SplitScript(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitScript split the script into\n%q\nwant\n%q", got, want)
+	}
+}
+
+// Test that in a script ending its statements with semicolons, a statement going on with a
+// line that starts with a command is not split there
+func TestSplitScriptMultiLine(t *testing.T) {
+	script := `create table vt (a int)
+create view vv as
+select a from vt;
+insert into vt
+select a from vt
+where a > 1;
+select a
+from vv;`
+	want := []string{
+		"create table vt (a int)",
+		"create view vv as\nselect a from vt;",
+		"insert into vt\nselect a from vt\nwhere a > 1;",
+		"select a\nfrom vv;",
+	}
+	if got := SplitScript(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitScript split the script into\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,2460 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/types"
+)
+
+// reservedWords cannot be used as implicit aliases or bare column names
+var reservedWords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "BY": true, "HAVING": true,
+	"ORDER": true, "LIMIT": true, "OFFSET": true, "AS": true, "AND": true, "OR": true,
+	"NOT": true, "IS": true, "NULL": true, "IN": true, "BETWEEN": true, "LIKE": true,
+	"CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true, "DISTINCT": true,
+	"ASC": true, "DESC": true, "ON": true, "JOIN": true, "INNER": true, "LEFT": true,
+	"RIGHT": true, "CROSS": true, "OUTER": true, "UNION": true, "VALUES": true, "SET": true,
+	"INTO": true,
+}
+
+// Parser is a recursive descent parser over the tokens of one SQL statement
+type Parser struct {
+	input  string
+	tokens []Token
+	pos    int
+}
+
+// NewParser tokenizes the input and returns a parser positioned at the first token
+func NewParser(input string) (*Parser, error) {
+	tokens, err := Tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	return &Parser{input: input, tokens: tokens}, nil
+}
+
+// ParseSelect parses a complete SELECT statement
+func ParseSelect(input string) (*SelectStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseSelect()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseInsert parses a complete INSERT statement
+func ParseInsert(input string) (*InsertStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseInsert()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseUpdate parses a complete UPDATE statement
+func ParseUpdate(input string) (*UpdateStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseUpdate()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseDelete parses a complete DELETE statement
+func ParseDelete(input string) (*DeleteStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseDelete()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseImport parses a complete IMPORT statement
+func ParseImport(input string) (*ImportStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseImport()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseAlterTable parses a complete ALTER TABLE statement
+func ParseAlterTable(input string) (*AlterTableStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseAlterTable()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseCreateSequence parses a complete CREATE SEQUENCE statement
+func ParseCreateSequence(input string) (*CreateSequenceStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseCreateSequence()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseRefresh parses a complete REFRESH MATERIALIZED VIEW statement
+func ParseRefresh(input string) (*RefreshStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseRefresh()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseCreateView parses a complete CREATE [OR REPLACE] VIEW or CREATE MATERIALIZED VIEW statement
+func ParseCreateView(input string) (*CreateViewStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseCreateView()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseCreateProcedure parses a complete CREATE [OR REPLACE] PROCEDURE statement
+func ParseCreateProcedure(input string) (*CreateProcedureStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseCreateProcedure()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseAlterProcedure parses a complete ALTER PROCEDURE statement
+func ParseAlterProcedure(input string) (*AlterProcedureStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseAlterProcedure()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseCall parses a complete CALL statement
+func ParseCall(input string) (*CallStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseCall()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseProcedureBody parses the BEGIN ... END body of a stored procedure
+func ParseProcedureBody(input string) ([]Statement, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	body, err := p.parseBlock()
+	if err != nil {
+		return nil, err
+	}
+	return body, p.ExpectEnd()
+}
+
+// ParseCreateTrigger parses a complete CREATE TRIGGER statement
+func ParseCreateTrigger(input string) (*CreateTriggerStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseCreateTrigger()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseTriggerBody parses the body of a trigger: BEGIN ... END or a single statement
+func ParseTriggerBody(input string) ([]Statement, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	body, err := p.parseTriggerBody()
+	if err != nil {
+		return nil, err
+	}
+	return body, p.ExpectEnd()
+}
+
+// ParseType parses a standalone data type, e.g. DECIMAL(10,2)
+func ParseType(input string) (TypeSpec, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return TypeSpec{}, err
+	}
+	spec, err := p.ParseTypeSpec()
+	if err != nil {
+		return TypeSpec{}, err
+	}
+	return spec, p.ExpectEnd()
+}
+
+// ParseDropSequence parses a complete DROP SEQUENCE statement
+func ParseDropSequence(input string) (*DropSequenceStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseDropSequence()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseDrop parses a complete DROP statement for the given kind of object, e.g. TABLE
+func ParseDrop(input string, object string) (*DropStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseDrop(object)
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseTruncate parses a complete TRUNCATE statement
+func ParseTruncate(input string) (*TruncateStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseTruncate()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseLock parses a complete LOCK or UNLOCK TABLES statement
+func ParseLock(input string) (*LockStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseLock()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseUser parses a complete CREATE, ALTER or DROP statement for a user or role
+func ParseUser(input string) (*UserStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseUser()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseGrant parses a complete GRANT or REVOKE statement
+func ParseGrant(input string) (*GrantStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseGrant()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseShowGrants parses a complete SHOW GRANTS statement
+func ParseShowGrants(input string) (*ShowGrantsStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	if !p.AcceptKeywords("SHOW", "GRANTS") {
+		return nil, p.Errorf("expected SHOW GRANTS")
+	}
+	stmt := &ShowGrantsStmt{}
+	if p.AcceptKeyword("FOR") {
+		if stmt.User, err = p.ParseIdent(); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseShow parses a complete SHOW TABLES, DESCRIBE|DESC table, SHOW CREATE TABLE table or
+// SHOW INDEX|INDEXES|KEYS FROM|IN table statement
+func ParseShow(input string) (*ShowStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt := &ShowStmt{}
+	switch {
+	case p.AcceptKeyword("DESCRIBE"), p.AcceptKeyword("DESC"):
+		stmt.Object = "DESCRIBE"
+	case p.AcceptKeywords("SHOW", "TABLES"):
+		return &ShowStmt{Object: "TABLES"}, p.ExpectEnd()
+	case p.AcceptKeywords("SHOW", "CREATE", "TABLE"):
+		stmt.Object = "CREATE TABLE"
+	case p.AcceptKeyword("SHOW"):
+		if !p.AcceptKeyword("INDEXES") && !p.AcceptKeyword("INDEX") && !p.AcceptKeyword("KEYS") {
+			return nil, p.Errorf("expected TABLES, CREATE TABLE or INDEXES")
+		}
+		if !p.AcceptKeyword("FROM") && !p.AcceptKeyword("IN") {
+			return nil, p.Errorf("expected FROM")
+		}
+		stmt.Object = "INDEXES"
+	default:
+		return nil, p.Errorf("expected SHOW or DESCRIBE")
+	}
+	if stmt.Table, err = p.ParseIdent(); err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseComment parses a complete COMMENT ON TABLE table IS 'text' | NULL or
+// COMMENT ON COLUMN table.column IS 'text' | NULL statement
+func ParseComment(input string) (*CommentStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.ExpectKeyword("COMMENT"); err != nil {
+		return nil, err
+	}
+	if err := p.ExpectKeyword("ON"); err != nil {
+		return nil, err
+	}
+	stmt := &CommentStmt{}
+	column := p.AcceptKeyword("COLUMN")
+	if !column {
+		if err := p.ExpectKeyword("TABLE"); err != nil {
+			return nil, p.Errorf("expected TABLE or COLUMN")
+		}
+	}
+	if stmt.Table, err = p.ParseIdent(); err != nil {
+		return nil, err
+	}
+	if column {
+		if err := p.ExpectSymbol("."); err != nil {
+			return nil, err
+		}
+		if stmt.Column, err = p.ParseIdent(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.ExpectKeyword("IS"); err != nil {
+		return nil, err
+	}
+	if !p.AcceptKeyword("NULL") {
+		tok := p.Next()
+		if tok.Kind != TokenString {
+			return nil, p.Errorf("expected a string or NULL")
+		}
+		stmt.Text = tok.Text
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParsePragma parses a complete PRAGMA [[table.]key [= value]] statement, where the value is
+// a string, a number, a word such as ON, or NULL
+func ParsePragma(input string) (*PragmaStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.ExpectKeyword("PRAGMA"); err != nil {
+		return nil, err
+	}
+	stmt := &PragmaStmt{}
+	if p.AtEnd() {
+		return stmt, nil
+	}
+	if stmt.Key, err = p.ParseIdent(); err != nil {
+		return nil, err
+	}
+	if p.AcceptSymbol(".") {
+		stmt.Table = stmt.Key
+		if stmt.Key, err = p.ParseIdent(); err != nil {
+			return nil, err
+		}
+	}
+	if !p.AcceptSymbol("=") {
+		return stmt, p.ExpectEnd()
+	}
+	stmt.Set = true
+	sign := ""
+	if p.AcceptSymbol("-") {
+		sign = "-"
+	}
+	tok := p.Next()
+	switch {
+	case tok.Kind == TokenNumber:
+		stmt.Value = sign + tok.Text
+	case sign != "":
+		return nil, p.Errorf("expected a number")
+	case tok.Kind == TokenIdent && strings.EqualFold(tok.Text, "NULL"):
+	case tok.Kind == TokenString, tok.Kind == TokenIdent, tok.Kind == TokenQuoted:
+		stmt.Value = tok.Text
+	default:
+		return nil, p.Errorf("expected a value")
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseTransaction parses a complete transaction control statement
+func ParseTransaction(input string) (*TransactionStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseTransaction()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseAttach parses a complete ATTACH or DETACH statement
+func ParseAttach(input string) (*AttachStmt, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.ParseAttach()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, p.ExpectEnd()
+}
+
+// ParseIdentifier parses a standalone identifier, such as the name of a database
+func ParseIdentifier(input string) (string, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return "", err
+	}
+	name, err := p.ParseIdent()
+	if err != nil {
+		return "", err
+	}
+	return name, p.ExpectEnd()
+}
+
+// ParseExpr parses a standalone expression
+func ParseExpr(input string) (Expr, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return expr, p.ExpectEnd()
+}
+
+// ParseColumnDefs parses the body of a CREATE TABLE statement: a comma separated list of column
+// definitions and table constraints, e.g. "id INT NOT NULL, price DECIMAL(10,2), PRIMARY KEY (id)"
+func ParseColumnDefs(input string) ([]ColumnDef, []TableConstraint, error) {
+	p, err := NewParser(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	var defs []ColumnDef
+	var constraints []TableConstraint
+	for {
+		if p.IsKeyword("CONSTRAINT", "PRIMARY", "FOREIGN", "CHECK") {
+			constraint, err := p.ParseTableConstraint()
+			if err != nil {
+				return nil, nil, err
+			}
+			constraints = append(constraints, constraint)
+		} else {
+			def, err := p.ParseColumnDef()
+			if err != nil {
+				return nil, nil, err
+			}
+			defs = append(defs, def)
+		}
+		if !p.AcceptSymbol(",") {
+			break
+		}
+	}
+	return defs, constraints, p.ExpectEnd()
+}
+
+// Peek returns the current token without consuming it
+func (p *Parser) Peek() Token {
+	return p.tokens[p.pos]
+}
+
+// PeekAt returns the token n positions ahead of the current one
+func (p *Parser) PeekAt(n int) Token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+// Next consumes and returns the current token
+func (p *Parser) Next() Token {
+	tok := p.tokens[p.pos]
+	if tok.Kind != TokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// AtEnd reports whether all tokens (ignoring a trailing semicolon) have been consumed
+func (p *Parser) AtEnd() bool {
+	tok := p.Peek()
+	return tok.Kind == TokenEOF || (tok.Kind == TokenSymbol && tok.Text == ";" && p.PeekAt(1).Kind == TokenEOF)
+}
+
+// ExpectEnd returns an error if there are tokens left over
+func (p *Parser) ExpectEnd() error {
+	if !p.AtEnd() {
+		return p.Errorf("unexpected %q", p.Peek().Text)
+	}
+	return nil
+}
+
+// Rest returns the unparsed remainder of the input, starting at the current token
+func (p *Parser) Rest() string {
+	return strings.TrimSpace(p.input[p.Peek().Pos:])
+}
+
+// Errorf returns a parse error annotated with the current position
+func (p *Parser) Errorf(format string, args ...interface{}) error {
+	tok := p.Peek()
+	near := tok.Text
+	if tok.Kind == TokenEOF {
+		near = "end of input"
+	}
+	return fmt.Errorf("syntax error near %q (position %d): %s", near, tok.Pos, fmt.Sprintf(format, args...))
+}
+
+// IsKeyword reports whether the current token is one of the given keywords
+func (p *Parser) IsKeyword(keywords ...string) bool {
+	tok := p.Peek()
+	if tok.Kind != TokenIdent {
+		return false
+	}
+	for _, kw := range keywords {
+		if strings.EqualFold(tok.Text, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptKeyword consumes the current token if it is the given keyword
+func (p *Parser) AcceptKeyword(keyword string) bool {
+	if p.IsKeyword(keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// AcceptKeywords consumes a sequence of keywords only if all of them match
+func (p *Parser) AcceptKeywords(keywords ...string) bool {
+	for i, kw := range keywords {
+		tok := p.PeekAt(i)
+		if tok.Kind != TokenIdent || !strings.EqualFold(tok.Text, kw) {
+			return false
+		}
+	}
+	p.pos += len(keywords)
+	return true
+}
+
+// ExpectKeyword consumes the given keyword or returns an error
+func (p *Parser) ExpectKeyword(keyword string) error {
+	if !p.AcceptKeyword(keyword) {
+		return p.Errorf("expected %s", keyword)
+	}
+	return nil
+}
+
+// IsSymbol reports whether the current token is the given symbol
+func (p *Parser) IsSymbol(symbol string) bool {
+	tok := p.Peek()
+	return tok.Kind == TokenSymbol && tok.Text == symbol
+}
+
+// AcceptSymbol consumes the current token if it is the given symbol
+func (p *Parser) AcceptSymbol(symbol string) bool {
+	if p.IsSymbol(symbol) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// ExpectSymbol consumes the given symbol or returns an error
+func (p *Parser) ExpectSymbol(symbol string) error {
+	if !p.AcceptSymbol(symbol) {
+		return p.Errorf("expected %q", symbol)
+	}
+	return nil
+}
+
+// ParseIdent consumes an identifier (bare or quoted) and returns its name
+func (p *Parser) ParseIdent() (string, error) {
+	tok := p.Peek()
+	if tok.Kind == TokenQuoted || (tok.Kind == TokenIdent && !reservedWords[strings.ToUpper(tok.Text)]) {
+		p.pos++
+		return tok.Text, nil
+	}
+	return "", p.Errorf("expected identifier")
+}
+
+// ParseSelect parses a SELECT statement starting at the SELECT keyword
+func (p *Parser) ParseSelect() (*SelectStmt, error) {
+	if err := p.ExpectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	stmt := &SelectStmt{}
+	if p.AcceptKeyword("DISTINCT") {
+		stmt.Distinct = true
+	} else {
+		p.AcceptKeyword("ALL")
+	}
+
+	for {
+		item, err := p.parseSelectItem()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Columns = append(stmt.Columns, item)
+		if !p.AcceptSymbol(",") {
+			break
+		}
+	}
+
+	if p.AcceptKeyword("INTO") {
+		for {
+			name, err := p.ParseIdent()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Into = append(stmt.Into, name)
+			if !p.AcceptSymbol(",") {
+				break
+			}
+		}
+	}
+
+	if p.AcceptKeyword("FROM") {
+		table, err := p.parseTableName()
+		if err != nil {
+			return nil, err
+		}
+		stmt.From = table
+		if stmt.Joins, err = p.parseJoins(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.AcceptKeyword("WHERE") {
+		where, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Where = where
+	}
+
+	if p.AcceptKeywords("GROUP", "BY") {
+		exprs, err := p.parseExprList()
+		if err != nil {
+			return nil, err
+		}
+		stmt.GroupBy = exprs
+	}
+
+	if p.AcceptKeyword("HAVING") {
+		having, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Having = having
+	}
+
+	if p.AcceptKeywords("ORDER", "BY") {
+		for {
+			expr, err := p.ParseExpr()
+			if err != nil {
+				return nil, err
+			}
+			item := OrderItem{Expr: expr}
+			if p.AcceptKeyword("DESC") {
+				item.Desc = true
+			} else {
+				p.AcceptKeyword("ASC")
+			}
+			stmt.OrderBy = append(stmt.OrderBy, item)
+			if !p.AcceptSymbol(",") {
+				break
+			}
+		}
+	}
+
+	if p.AcceptKeyword("LIMIT") {
+		limit, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Limit = limit
+		if p.AcceptKeyword("OFFSET") {
+			offset, err := p.ParseExpr()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Offset = offset
+		} else if p.AcceptSymbol(",") {
+			// LIMIT offset, count
+			count, err := p.ParseExpr()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Offset, stmt.Limit = limit, count
+		}
+	}
+
+	return stmt, nil
+}
+
+// ParseInsert parses "INSERT INTO table [(columns)] VALUES (values)[, (values) ...]"
+func (p *Parser) ParseInsert() (*InsertStmt, error) {
+	if err := p.ExpectKeyword("INSERT"); err != nil {
+		return nil, err
+	}
+	if err := p.ExpectKeyword("INTO"); err != nil {
+		return nil, err
+	}
+	table, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &InsertStmt{Table: table}
+	if p.IsSymbol("(") {
+		columns, err := p.parseIdentList()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Columns = columns
+	}
+	if err := p.ExpectKeyword("VALUES"); err != nil {
+		return nil, err
+	}
+	for {
+		if err := p.ExpectSymbol("("); err != nil {
+			return nil, err
+		}
+		values, err := p.parseExprList()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.ExpectSymbol(")"); err != nil {
+			return nil, err
+		}
+		stmt.Rows = append(stmt.Rows, values)
+		if !p.AcceptSymbol(",") {
+			return stmt, nil
+		}
+	}
+}
+
+// ParseUpdate parses "UPDATE table SET column = expr[, ...] [WHERE condition]"
+func (p *Parser) ParseUpdate() (*UpdateStmt, error) {
+	if err := p.ExpectKeyword("UPDATE"); err != nil {
+		return nil, err
+	}
+	table, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.ExpectKeyword("SET"); err != nil {
+		return nil, err
+	}
+	stmt := &UpdateStmt{Table: table}
+	for {
+		column, err := p.ParseIdent()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.ExpectSymbol("="); err != nil {
+			return nil, err
+		}
+		value, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Set = append(stmt.Set, Assignment{Column: column, Value: value})
+		if !p.AcceptSymbol(",") {
+			break
+		}
+	}
+	if p.AcceptKeyword("WHERE") {
+		if stmt.Where, err = p.ParseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
+
+// ParseDelete parses "DELETE FROM table [WHERE condition]"
+func (p *Parser) ParseDelete() (*DeleteStmt, error) {
+	if err := p.ExpectKeyword("DELETE"); err != nil {
+		return nil, err
+	}
+	if err := p.ExpectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	table, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &DeleteStmt{Table: table}
+	if p.AcceptKeyword("WHERE") {
+		if stmt.Where, err = p.ParseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
+
+// ParseImport parses "IMPORT INTO table FROM 'file'"
+func (p *Parser) ParseImport() (*ImportStmt, error) {
+	if err := p.ExpectKeyword("IMPORT"); err != nil {
+		return nil, err
+	}
+	if err := p.ExpectKeyword("INTO"); err != nil {
+		return nil, err
+	}
+	table, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.ExpectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	tok := p.Next()
+	if tok.Kind != TokenString {
+		return nil, p.Errorf("expected a quoted file name")
+	}
+	return &ImportStmt{Table: table, File: tok.Text}, nil
+}
+
+// ParseAlterTable parses one of
+//
+//	ALTER TABLE table ADD [COLUMN] column_definition
+//	ALTER TABLE table DROP [COLUMN] column
+//	ALTER TABLE table RENAME COLUMN column [TO] new_name
+//	ALTER TABLE table RENAME [TO] new_name
+//	ALTER TABLE table ALTER [COLUMN] column [SET DATA] TYPE type
+func (p *Parser) ParseAlterTable() (*AlterTableStmt, error) {
+	if !p.AcceptKeywords("ALTER", "TABLE") {
+		return nil, p.Errorf("expected ALTER TABLE")
+	}
+	table, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &AlterTableStmt{Table: table}
+	switch {
+	case p.AcceptKeyword("ADD"):
+		p.AcceptKeyword("COLUMN")
+		stmt.Action = "ADD COLUMN"
+		stmt.Column, err = p.ParseColumnDef()
+		return stmt, err
+	case p.AcceptKeyword("DROP"):
+		p.AcceptKeyword("COLUMN")
+		stmt.Action = "DROP COLUMN"
+		stmt.Name, err = p.ParseIdent()
+		return stmt, err
+	case p.AcceptKeywords("RENAME", "COLUMN"):
+		stmt.Action = "RENAME COLUMN"
+		if stmt.Name, err = p.ParseIdent(); err != nil {
+			return nil, err
+		}
+		p.AcceptKeyword("TO")
+		stmt.NewName, err = p.ParseIdent()
+		return stmt, err
+	case p.AcceptKeyword("RENAME"):
+		p.AcceptKeyword("TO")
+		stmt.Action = "RENAME TO"
+		stmt.NewName, err = p.ParseIdent()
+		return stmt, err
+	case p.AcceptKeyword("ALTER"):
+		p.AcceptKeyword("COLUMN")
+		stmt.Action = "ALTER COLUMN TYPE"
+		if stmt.Name, err = p.ParseIdent(); err != nil {
+			return nil, err
+		}
+		p.AcceptKeywords("SET", "DATA")
+		if err := p.ExpectKeyword("TYPE"); err != nil {
+			return nil, err
+		}
+		stmt.Type, err = p.ParseTypeSpec()
+		return stmt, err
+	}
+	return nil, p.Errorf("expected ADD, DROP, RENAME or ALTER")
+}
+
+// ParseCreateSequence parses "CREATE SEQUENCE name [START [WITH] n] [INCREMENT [BY] n]".
+// A sequence starts at 1 and counts up by 1 unless told otherwise.
+func (p *Parser) ParseCreateSequence() (*CreateSequenceStmt, error) {
+	if err := p.ExpectKeyword("CREATE"); err != nil {
+		return nil, err
+	}
+	if err := p.ExpectKeyword("SEQUENCE"); err != nil {
+		return nil, err
+	}
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &CreateSequenceStmt{Name: name, Start: 1, Increment: 1}
+	for !p.AtEnd() {
+		switch {
+		case p.AcceptKeyword("START"):
+			p.AcceptKeyword("WITH")
+			if stmt.Start, err = p.parseInteger(); err != nil {
+				return nil, err
+			}
+		case p.AcceptKeyword("INCREMENT"):
+			p.AcceptKeyword("BY")
+			if stmt.Increment, err = p.parseInteger(); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.Errorf("expected START or INCREMENT")
+		}
+	}
+	return stmt, nil
+}
+
+// ParseCreateView parses "CREATE [OR REPLACE] VIEW name [(column, ...)] AS SELECT ..." and
+// "CREATE MATERIALIZED VIEW name [(column, ...)] AS SELECT ..."
+func (p *Parser) ParseCreateView() (*CreateViewStmt, error) {
+	if err := p.ExpectKeyword("CREATE"); err != nil {
+		return nil, err
+	}
+	stmt := &CreateViewStmt{OrReplace: p.AcceptKeywords("OR", "REPLACE")}
+	if !stmt.OrReplace {
+		stmt.Materialized = p.AcceptKeyword("MATERIALIZED")
+	}
+	if err := p.ExpectKeyword("VIEW"); err != nil {
+		return nil, err
+	}
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = name
+	if p.AcceptSymbol("(") {
+		for {
+			column, err := p.ParseIdent()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Columns = append(stmt.Columns, column)
+			if !p.AcceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.ExpectSymbol(")"); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.ExpectKeyword("AS"); err != nil {
+		return nil, err
+	}
+	start := p.Peek().Pos
+	if stmt.Query, err = p.ParseSelect(); err != nil {
+		return nil, err
+	}
+	stmt.QueryText = strings.TrimSpace(p.input[start:p.Peek().Pos])
+	return stmt, nil
+}
+
+// ParseRefresh parses "REFRESH MATERIALIZED VIEW name"
+func (p *Parser) ParseRefresh() (*RefreshStmt, error) {
+	if !p.AcceptKeywords("REFRESH", "MATERIALIZED", "VIEW") {
+		return nil, p.Errorf("expected REFRESH MATERIALIZED VIEW")
+	}
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	return &RefreshStmt{View: name}, nil
+}
+
+// ParseCreateProcedure parses "CREATE [OR REPLACE] PROCEDURE name ([[IN] param type, ...]) BEGIN ... END"
+func (p *Parser) ParseCreateProcedure() (*CreateProcedureStmt, error) {
+	if err := p.ExpectKeyword("CREATE"); err != nil {
+		return nil, err
+	}
+	stmt := &CreateProcedureStmt{OrReplace: p.AcceptKeywords("OR", "REPLACE")}
+	if err := p.ExpectKeyword("PROCEDURE"); err != nil {
+		return nil, err
+	}
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = name
+	if err := p.ExpectSymbol("("); err != nil {
+		return nil, err
+	}
+	for !p.AcceptSymbol(")") {
+		if len(stmt.Params) > 0 {
+			if err := p.ExpectSymbol(","); err != nil {
+				return nil, err
+			}
+		}
+		p.AcceptKeyword("IN")
+		param := Parameter{}
+		if param.Name, err = p.ParseIdent(); err != nil {
+			return nil, err
+		}
+		if param.Type, err = p.ParseTypeSpec(); err != nil {
+			return nil, err
+		}
+		stmt.Params = append(stmt.Params, param)
+	}
+	start := p.Peek().Pos
+	if stmt.Body, err = p.parseBlock(); err != nil {
+		return nil, err
+	}
+	stmt.BodyText = strings.TrimSpace(p.input[start:p.Peek().Pos])
+	return stmt, nil
+}
+
+// ParseAlterProcedure parses "ALTER PROCEDURE name RENAME TO new_name"
+func (p *Parser) ParseAlterProcedure() (*AlterProcedureStmt, error) {
+	if !p.AcceptKeywords("ALTER", "PROCEDURE") {
+		return nil, p.Errorf("expected ALTER PROCEDURE")
+	}
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	if !p.AcceptKeywords("RENAME", "TO") {
+		return nil, p.Errorf("expected RENAME TO")
+	}
+	newName, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	return &AlterProcedureStmt{Name: name, NewName: newName}, nil
+}
+
+// ParseCreateTrigger parses
+//
+//	CREATE TRIGGER name BEFORE | AFTER INSERT | UPDATE | DELETE ON table FOR EACH ROW body
+//
+// where the body is BEGIN ... END or a single statement
+func (p *Parser) ParseCreateTrigger() (*CreateTriggerStmt, error) {
+	if !p.AcceptKeywords("CREATE", "TRIGGER") {
+		return nil, p.Errorf("expected CREATE TRIGGER")
+	}
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &CreateTriggerStmt{Name: name}
+	if !p.IsKeyword("BEFORE", "AFTER") {
+		return nil, p.Errorf("expected BEFORE or AFTER")
+	}
+	stmt.Timing = strings.ToUpper(p.Next().Text)
+	if !p.IsKeyword("INSERT", "UPDATE", "DELETE") {
+		return nil, p.Errorf("expected INSERT, UPDATE or DELETE")
+	}
+	stmt.Event = strings.ToUpper(p.Next().Text)
+	if err := p.ExpectKeyword("ON"); err != nil {
+		return nil, err
+	}
+	if stmt.Table, err = p.ParseIdent(); err != nil {
+		return nil, err
+	}
+	if !p.AcceptKeywords("FOR", "EACH", "ROW") {
+		return nil, p.Errorf("expected FOR EACH ROW")
+	}
+	start := p.Peek().Pos
+	if stmt.Body, err = p.parseTriggerBody(); err != nil {
+		return nil, err
+	}
+	stmt.BodyText = strings.TrimSpace(p.input[start:p.Peek().Pos])
+	return stmt, nil
+}
+
+// parseTriggerBody parses "BEGIN statement; ... END" or a single statement
+func (p *Parser) parseTriggerBody() ([]Statement, error) {
+	if p.IsKeyword("BEGIN") {
+		return p.parseBlock()
+	}
+	stmt, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+	return []Statement{stmt}, nil
+}
+
+// ParseCall parses "CALL name[(arg, ...)]"
+func (p *Parser) ParseCall() (*CallStmt, error) {
+	if err := p.ExpectKeyword("CALL"); err != nil {
+		return nil, err
+	}
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt := &CallStmt{Name: name}
+	if p.AcceptSymbol("(") && !p.AcceptSymbol(")") {
+		if stmt.Args, err = p.parseExprList(); err != nil {
+			return nil, err
+		}
+		if err := p.ExpectSymbol(")"); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
+
+// parseBlock parses "BEGIN statement; ... END"
+func (p *Parser) parseBlock() ([]Statement, error) {
+	if err := p.ExpectKeyword("BEGIN"); err != nil {
+		return nil, err
+	}
+	body, err := p.parseStatements("END")
+	if err != nil {
+		return nil, err
+	}
+	return body, p.ExpectKeyword("END")
+}
+
+// parseStatements parses procedure statements separated by semicolons, up to one of the
+// given keywords
+func (p *Parser) parseStatements(terminators ...string) ([]Statement, error) {
+	var body []Statement
+	for !p.IsKeyword(terminators...) {
+		if p.AcceptSymbol(";") {
+			continue
+		}
+		if p.Peek().Kind == TokenEOF {
+			return nil, p.Errorf("expected %s", strings.Join(terminators, " or "))
+		}
+		stmt, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, stmt)
+		if !p.AcceptSymbol(";") && !p.IsKeyword(terminators...) {
+			return nil, p.Errorf("expected \";\"")
+		}
+	}
+	return body, nil
+}
+
+// parseStatement parses one statement of a procedure body
+func (p *Parser) parseStatement() (Statement, error) {
+	var err error
+	switch {
+	case p.AcceptKeyword("DECLARE"):
+		stmt := &DeclareStmt{}
+		for {
+			name, err := p.ParseIdent()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Names = append(stmt.Names, name)
+			if !p.AcceptSymbol(",") {
+				break
+			}
+		}
+		if stmt.Type, err = p.ParseTypeSpec(); err != nil {
+			return nil, err
+		}
+		if p.AcceptKeyword("DEFAULT") {
+			if stmt.Default, err = p.ParseExpr(); err != nil {
+				return nil, err
+			}
+		}
+		return stmt, nil
+
+	case p.AcceptKeyword("SET"):
+		stmt := &SetStmt{}
+		if stmt.Name, err = p.ParseIdent(); err != nil {
+			return nil, err
+		}
+		if p.AcceptSymbol(".") {
+			stmt.Row = stmt.Name
+			if stmt.Name, err = p.ParseIdent(); err != nil {
+				return nil, err
+			}
+		}
+		if !p.AcceptSymbol("=") && !p.AcceptSymbol(":=") {
+			return nil, p.Errorf("expected \"=\"")
+		}
+		if stmt.Value, err = p.ParseExpr(); err != nil {
+			return nil, err
+		}
+		return stmt, nil
+
+	case p.AcceptKeyword("IF"):
+		stmt := &IfStmt{}
+		for {
+			cond, err := p.ParseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.ExpectKeyword("THEN"); err != nil {
+				return nil, err
+			}
+			body, err := p.parseStatements("ELSEIF", "ELSE", "END")
+			if err != nil {
+				return nil, err
+			}
+			stmt.Conds = append(stmt.Conds, cond)
+			stmt.Bodies = append(stmt.Bodies, body)
+			if !p.AcceptKeyword("ELSEIF") {
+				break
+			}
+		}
+		if p.AcceptKeyword("ELSE") {
+			if stmt.Else, err = p.parseStatements("END"); err != nil {
+				return nil, err
+			}
+		}
+		if !p.AcceptKeywords("END", "IF") {
+			return nil, p.Errorf("expected END IF")
+		}
+		return stmt, nil
+
+	case p.AcceptKeyword("WHILE"):
+		stmt := &WhileStmt{}
+		if stmt.Cond, err = p.ParseExpr(); err != nil {
+			return nil, err
+		}
+		if err := p.ExpectKeyword("DO"); err != nil {
+			return nil, err
+		}
+		if stmt.Body, err = p.parseStatements("END"); err != nil {
+			return nil, err
+		}
+		if !p.AcceptKeywords("END", "WHILE") {
+			return nil, p.Errorf("expected END WHILE")
+		}
+		return stmt, nil
+
+	case p.AcceptKeyword("FOR"):
+		stmt := &ForStmt{}
+		if stmt.Row, err = p.ParseIdent(); err != nil {
+			return nil, err
+		}
+		if err := p.ExpectKeyword("IN"); err != nil {
+			return nil, err
+		}
+		if err := p.ExpectSymbol("("); err != nil {
+			return nil, err
+		}
+		start := p.Peek().Pos
+		if stmt.Query, err = p.ParseSelect(); err != nil {
+			return nil, err
+		}
+		stmt.QueryText = strings.TrimSpace(p.input[start:p.Peek().Pos])
+		if err := p.ExpectSymbol(")"); err != nil {
+			return nil, err
+		}
+		if err := p.ExpectKeyword("DO"); err != nil {
+			return nil, err
+		}
+		if stmt.Body, err = p.parseStatements("END"); err != nil {
+			return nil, err
+		}
+		if !p.AcceptKeywords("END", "FOR") {
+			return nil, p.Errorf("expected END FOR")
+		}
+		return stmt, nil
+
+	case p.AcceptKeyword("SIGNAL"):
+		if err := p.ExpectKeyword("SQLSTATE"); err != nil {
+			return nil, err
+		}
+		p.AcceptKeyword("VALUE")
+		tok := p.Next()
+		if tok.Kind != TokenString || len(tok.Text) != 5 {
+			return nil, p.Errorf("expected a quoted five character SQLSTATE")
+		}
+		stmt := &SignalStmt{State: tok.Text}
+		if p.AcceptKeyword("SET") {
+			if err := p.ExpectKeyword("MESSAGE_TEXT"); err != nil {
+				return nil, err
+			}
+			if err := p.ExpectSymbol("="); err != nil {
+				return nil, err
+			}
+			if stmt.Message, err = p.ParseExpr(); err != nil {
+				return nil, err
+			}
+		}
+		return stmt, nil
+
+	case p.AcceptKeyword("RETURN"):
+		stmt := &ReturnStmt{}
+		if !p.IsSymbol(";") && !p.IsKeyword("END", "ELSE", "ELSEIF") {
+			if stmt.Value, err = p.ParseExpr(); err != nil {
+				return nil, err
+			}
+		}
+		return stmt, nil
+	}
+
+	start := p.Peek().Pos
+	stmt := &SQLStatement{}
+	switch {
+	case p.IsKeyword("SELECT"):
+		stmt.Stmt, err = p.ParseSelect()
+	case p.IsKeyword("INSERT"):
+		stmt.Stmt, err = p.ParseInsert()
+	case p.IsKeyword("UPDATE"):
+		stmt.Stmt, err = p.ParseUpdate()
+	case p.IsKeyword("DELETE"):
+		stmt.Stmt, err = p.ParseDelete()
+	case p.IsKeyword("CALL"):
+		stmt.Stmt, err = p.ParseCall()
+	default:
+		return nil, p.Errorf("expected a statement")
+	}
+	if err != nil {
+		return nil, err
+	}
+	stmt.Text = strings.TrimSpace(p.input[start:p.Peek().Pos])
+	return stmt, nil
+}
+
+// ParseDropSequence parses "DROP SEQUENCE [IF EXISTS] name"
+func (p *Parser) ParseDropSequence() (*DropSequenceStmt, error) {
+	if err := p.ExpectKeyword("DROP"); err != nil {
+		return nil, err
+	}
+	if err := p.ExpectKeyword("SEQUENCE"); err != nil {
+		return nil, err
+	}
+	stmt := &DropSequenceStmt{IfExists: p.AcceptKeywords("IF", "EXISTS")}
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = name
+	return stmt, nil
+}
+
+// ParseDrop parses "DROP <object> [IF EXISTS] name", for indexes with an optional
+// "ON table" and for tables and views an optional CASCADE or RESTRICT
+func (p *Parser) ParseDrop(object string) (*DropStmt, error) {
+	if !p.AcceptKeywords(append([]string{"DROP"}, strings.Fields(object)...)...) {
+		return nil, p.Errorf("expected DROP %s", object)
+	}
+	stmt := &DropStmt{Object: object, IfExists: p.AcceptKeywords("IF", "EXISTS")}
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = name
+	if object == "INDEX" && p.AcceptKeyword("ON") {
+		if stmt.Table, err = p.ParseIdent(); err != nil {
+			return nil, err
+		}
+	}
+	if object == "TABLE" || strings.HasSuffix(object, "VIEW") {
+		if stmt.Cascade = p.AcceptKeyword("CASCADE"); !stmt.Cascade {
+			p.AcceptKeyword("RESTRICT")
+		}
+	}
+	return stmt, nil
+}
+
+// ParseTruncate parses "TRUNCATE [TABLE] name"
+func (p *Parser) ParseTruncate() (*TruncateStmt, error) {
+	if err := p.ExpectKeyword("TRUNCATE"); err != nil {
+		return nil, err
+	}
+	p.AcceptKeyword("TABLE")
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	return &TruncateStmt{Table: name}, nil
+}
+
+// ParseLock parses "LOCK [TABLE] name [, ...] [IN SHARE | EXCLUSIVE MODE]",
+// "LOCK DATABASE [name] [IN SHARE | EXCLUSIVE MODE]" and "UNLOCK TABLES". The mode
+// defaults to EXCLUSIVE.
+func (p *Parser) ParseLock() (*LockStmt, error) {
+	if p.AcceptKeywords("UNLOCK", "TABLES") {
+		return &LockStmt{Unlock: true}, nil
+	}
+	if err := p.ExpectKeyword("LOCK"); err != nil {
+		return nil, err
+	}
+	stmt := &LockStmt{Mode: "EXCLUSIVE"}
+	if p.AcceptKeyword("DATABASE") {
+		stmt.Database = true
+		if !p.AtEnd() && !p.IsKeyword("IN") {
+			name, err := p.ParseIdent()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Name = name
+		}
+	} else {
+		_ = p.AcceptKeyword("TABLE") || p.AcceptKeyword("TABLES")
+		for {
+			name, err := p.ParseIdent()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Tables = append(stmt.Tables, name)
+			if !p.AcceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if p.AcceptKeyword("IN") {
+		switch {
+		case p.AcceptKeyword("SHARE"):
+			stmt.Mode = "SHARE"
+		case p.AcceptKeyword("EXCLUSIVE"):
+		default:
+			return nil, p.Errorf("expected SHARE or EXCLUSIVE")
+		}
+		if err := p.ExpectKeyword("MODE"); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
+
+// ParseUser parses "CREATE USER | ROLE name [[WITH] PASSWORD 'password']",
+// "ALTER USER name [WITH] PASSWORD 'password'" and "DROP USER | ROLE [IF EXISTS] name"
+func (p *Parser) ParseUser() (*UserStmt, error) {
+	stmt := &UserStmt{}
+	switch {
+	case p.AcceptKeyword("CREATE"):
+		stmt.Action = "CREATE"
+	case p.AcceptKeyword("ALTER"):
+		stmt.Action = "ALTER"
+	case p.AcceptKeyword("DROP"):
+		stmt.Action = "DROP"
+	default:
+		return nil, p.Errorf("expected CREATE, ALTER or DROP")
+	}
+	switch {
+	case p.AcceptKeyword("ROLE"):
+		stmt.Role = true
+	case !p.AcceptKeyword("USER"):
+		return nil, p.Errorf("expected USER or ROLE")
+	}
+	if stmt.Action == "DROP" {
+		stmt.IfExists = p.AcceptKeywords("IF", "EXISTS")
+	}
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Name = name
+	if stmt.Action == "DROP" {
+		return stmt, nil
+	}
+	p.AcceptKeyword("WITH")
+	if p.AcceptKeyword("PASSWORD") {
+		tok := p.Next()
+		if tok.Kind != TokenString {
+			return nil, p.Errorf("expected a quoted password")
+		}
+		stmt.Password = &tok.Text
+	} else if stmt.Action == "ALTER" {
+		return nil, p.Errorf("expected PASSWORD")
+	}
+	return stmt, nil
+}
+
+// ParseGrant parses
+//
+//	GRANT privilege [(column, ...)] [, ...] ON object TO name [, ...] [WITH GRANT OPTION]
+//	REVOKE privilege [(column, ...)] [, ...] ON object FROM name [, ...]
+//	GRANT ROLE role TO name [, ...]
+//	REVOKE ROLE role FROM name [, ...]
+//
+// where privilege is a privilege name or ALL [PRIVILEGES] and object is one of
+// DATABASE name, *.*, database.*, [TABLE] table or [TABLE] database.table
+func (p *Parser) ParseGrant() (*GrantStmt, error) {
+	stmt := &GrantStmt{}
+	to := "TO"
+	switch {
+	case p.AcceptKeyword("GRANT"):
+	case p.AcceptKeyword("REVOKE"):
+		stmt.Revoke, to = true, "FROM"
+	default:
+		return nil, p.Errorf("expected GRANT or REVOKE")
+	}
+	if p.AcceptKeyword("ROLE") {
+		role, err := p.ParseIdent()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Role = role
+	} else {
+		if err := p.parsePrivileges(stmt); err != nil {
+			return nil, err
+		}
+		if err := p.ExpectKeyword("ON"); err != nil {
+			return nil, err
+		}
+		if err := p.parseGrantObject(stmt); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.ExpectKeyword(to); err != nil {
+		return nil, err
+	}
+	for {
+		name, err := p.ParseIdent()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Grantees = append(stmt.Grantees, name)
+		if !p.AcceptSymbol(",") {
+			break
+		}
+	}
+	if !stmt.Revoke && stmt.Role == "" {
+		stmt.GrantOption = p.AcceptKeywords("WITH", "GRANT", "OPTION")
+	}
+	return stmt, nil
+}
+
+// parsePrivileges parses the privilege list of GRANT or REVOKE
+func (p *Parser) parsePrivileges(stmt *GrantStmt) error {
+	if p.AcceptKeyword("ALL") {
+		p.AcceptKeyword("PRIVILEGES")
+		stmt.Privileges = []Privilege{{Name: "ALL"}}
+		return nil
+	}
+	for {
+		tok := p.Next()
+		if tok.Kind != TokenIdent {
+			return p.Errorf("expected a privilege")
+		}
+		priv := Privilege{Name: strings.ToUpper(tok.Text)}
+		if p.IsSymbol("(") {
+			columns, err := p.parseIdentList()
+			if err != nil {
+				return err
+			}
+			priv.Columns = columns
+		}
+		stmt.Privileges = append(stmt.Privileges, priv)
+		if !p.AcceptSymbol(",") {
+			return nil
+		}
+	}
+}
+
+// parseGrantObject parses the object privileges are granted on
+func (p *Parser) parseGrantObject(stmt *GrantStmt) error {
+	if p.AcceptKeyword("DATABASE") {
+		name, err := p.ParseIdent()
+		stmt.Database = name
+		return err
+	}
+	if p.AcceptSymbol("*") {
+		if err := p.ExpectSymbol("."); err != nil {
+			return err
+		}
+		stmt.Database = "*"
+		return p.ExpectSymbol("*")
+	}
+	p.AcceptKeyword("TABLE")
+	name, err := p.ParseIdent()
+	if err != nil {
+		return err
+	}
+	if !p.AcceptSymbol(".") {
+		stmt.Table = name
+		return nil
+	}
+	stmt.Database = name
+	if p.AcceptSymbol("*") {
+		return nil
+	}
+	stmt.Table, err = p.ParseIdent()
+	return err
+}
+
+// ParseAttach parses "ATTACH [DATABASE] 'file' AS alias" and "DETACH [DATABASE] alias"
+func (p *Parser) ParseAttach() (*AttachStmt, error) {
+	stmt := &AttachStmt{}
+	if p.AcceptKeyword("DETACH") {
+		stmt.Detach = true
+		p.AcceptKeyword("DATABASE")
+		alias, err := p.ParseIdent()
+		stmt.Alias = alias
+		return stmt, err
+	}
+	if err := p.ExpectKeyword("ATTACH"); err != nil {
+		return nil, err
+	}
+	p.AcceptKeyword("DATABASE")
+	tok := p.Next()
+	if tok.Kind != TokenString {
+		return nil, p.Errorf("expected a quoted file name")
+	}
+	stmt.File = tok.Text
+	if err := p.ExpectKeyword("AS"); err != nil {
+		return nil, err
+	}
+	alias, err := p.ParseIdent()
+	stmt.Alias = alias
+	return stmt, err
+}
+
+// ParseTransaction parses "BEGIN [TRANSACTION | WORK] [ISOLATION LEVEL level]",
+// "START TRANSACTION [ISOLATION LEVEL level]", "COMMIT [WORK]",
+// "ROLLBACK [WORK]", "SAVEPOINT name", "ROLLBACK [WORK] TO [SAVEPOINT] name" and
+// "RELEASE [SAVEPOINT] name"
+func (p *Parser) ParseTransaction() (*TransactionStmt, error) {
+	stmt := &TransactionStmt{}
+	switch {
+	case p.AcceptKeyword("BEGIN"):
+		stmt.Action = "BEGIN"
+		_ = p.AcceptKeyword("TRANSACTION") || p.AcceptKeyword("WORK")
+		return stmt, p.parseIsolationLevel(stmt)
+	case p.AcceptKeywords("START", "TRANSACTION"):
+		stmt.Action = "BEGIN"
+		return stmt, p.parseIsolationLevel(stmt)
+	case p.AcceptKeyword("COMMIT"):
+		stmt.Action = "COMMIT"
+		p.AcceptKeyword("WORK")
+		return stmt, nil
+	case p.AcceptKeyword("ROLLBACK"):
+		stmt.Action = "ROLLBACK"
+		p.AcceptKeyword("WORK")
+		if !p.AcceptKeyword("TO") {
+			return stmt, nil
+		}
+		stmt.Action = "ROLLBACK TO"
+		p.AcceptKeyword("SAVEPOINT")
+	case p.AcceptKeyword("SAVEPOINT"):
+		stmt.Action = "SAVEPOINT"
+	case p.AcceptKeyword("RELEASE"):
+		stmt.Action = "RELEASE"
+		p.AcceptKeyword("SAVEPOINT")
+	default:
+		return nil, p.Errorf("expected BEGIN, COMMIT, ROLLBACK, SAVEPOINT or RELEASE")
+	}
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	stmt.Savepoint = name
+	return stmt, nil
+}
+
+// parseIsolationLevel parses an optional "ISOLATION LEVEL level" clause of BEGIN
+func (p *Parser) parseIsolationLevel(stmt *TransactionStmt) error {
+	if !p.AcceptKeywords("ISOLATION", "LEVEL") {
+		return nil
+	}
+	var words []string
+	for !p.AtEnd() {
+		word, err := p.ParseIdent()
+		if err != nil {
+			return err
+		}
+		words = append(words, strings.ToUpper(word))
+	}
+	if len(words) == 0 {
+		return p.Errorf("expected an isolation level")
+	}
+	stmt.Isolation = strings.Join(words, " ")
+	return nil
+}
+
+// parseInteger parses an optionally signed integer literal
+func (p *Parser) parseInteger() (int64, error) {
+	negative := p.AcceptSymbol("-")
+	tok := p.Peek()
+	if tok.Kind != TokenNumber {
+		return 0, p.Errorf("expected an integer")
+	}
+	value, err := literalValue(tok.Text)
+	n, ok := value.(int64)
+	if err != nil || !ok {
+		return 0, p.Errorf("expected an integer")
+	}
+	p.pos++
+	if negative {
+		n = -n
+	}
+	return n, nil
+}
+
+func (p *Parser) parseSelectItem() (SelectItem, error) {
+	if p.AcceptSymbol("*") {
+		return SelectItem{Star: true}, nil
+	}
+	// table.*
+	if tok := p.Peek(); (tok.Kind == TokenIdent || tok.Kind == TokenQuoted) &&
+		p.PeekAt(1).Kind == TokenSymbol && p.PeekAt(1).Text == "." &&
+		p.PeekAt(2).Kind == TokenSymbol && p.PeekAt(2).Text == "*" {
+		p.pos += 3
+		return SelectItem{Star: true, Table: tok.Text}, nil
+	}
+
+	expr, err := p.ParseExpr()
+	if err != nil {
+		return SelectItem{}, err
+	}
+	item := SelectItem{Expr: expr}
+	alias, err := p.parseAlias()
+	if err != nil {
+		return SelectItem{}, err
+	}
+	item.Alias = alias
+	return item, nil
+}
+
+// parseAlias parses an optional "[AS] alias"
+func (p *Parser) parseAlias() (string, error) {
+	if p.AcceptKeyword("AS") {
+		tok := p.Peek()
+		if tok.Kind == TokenString {
+			p.pos++
+			return tok.Text, nil
+		}
+		return p.ParseIdent()
+	}
+	tok := p.Peek()
+	if tok.Kind == TokenQuoted || (tok.Kind == TokenIdent && !reservedWords[strings.ToUpper(tok.Text)]) {
+		p.pos++
+		return tok.Text, nil
+	}
+	return "", nil
+}
+
+func (p *Parser) parseTableName() (*TableName, error) {
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	table := &TableName{Name: name}
+	if p.AcceptSymbol(".") {
+		table.Schema = name
+		if table.Name, err = p.ParseIdent(); err != nil {
+			return nil, err
+		}
+	}
+	alias, err := p.parseAlias()
+	if err != nil {
+		return nil, err
+	}
+	table.Alias = alias
+	return table, nil
+}
+
+// parseJoins parses the tables joined to the first one of a FROM clause: ", table",
+// "CROSS JOIN table", "[INNER] JOIN table ON condition" and
+// "LEFT | RIGHT [OUTER] JOIN table ON condition"
+func (p *Parser) parseJoins() ([]Join, error) {
+	var joins []Join
+	for {
+		var kind string
+		switch {
+		case p.AcceptSymbol(","), p.AcceptKeywords("CROSS", "JOIN"):
+			kind = "CROSS"
+		case p.AcceptKeyword("JOIN"), p.AcceptKeywords("INNER", "JOIN"):
+			kind = "INNER"
+		case p.IsKeyword("LEFT", "RIGHT"):
+			kind = strings.ToUpper(p.Next().Text)
+			p.AcceptKeyword("OUTER")
+			if err := p.ExpectKeyword("JOIN"); err != nil {
+				return nil, err
+			}
+		default:
+			return joins, nil
+		}
+		table, err := p.parseTableName()
+		if err != nil {
+			return nil, err
+		}
+		join := Join{Kind: kind, Table: table}
+		if kind != "CROSS" {
+			if err := p.ExpectKeyword("ON"); err != nil {
+				return nil, err
+			}
+			if join.On, err = p.ParseExpr(); err != nil {
+				return nil, err
+			}
+		}
+		joins = append(joins, join)
+	}
+}
+
+func (p *Parser) parseExprList() ([]Expr, error) {
+	var exprs []Expr
+	for {
+		expr, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if !p.AcceptSymbol(",") {
+			return exprs, nil
+		}
+	}
+}
+
+// ParseExpr parses an expression, lowest precedence first:
+// OR, AND, NOT, comparison/IS/IN/BETWEEN/LIKE, || , + -, * / %, unary, primary
+func (p *Parser) ParseExpr() (Expr, error) {
+	return p.parseOr()
+}
+
+func (p *Parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.AcceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: "OR", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *Parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.AcceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: "AND", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *Parser) parseNot() (Expr, error) {
+	if p.AcceptKeyword("NOT") {
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Op: "NOT", Expr: expr}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *Parser) parseComparison() (Expr, error) {
+	left, err := p.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.Peek()
+		if tok.Kind == TokenSymbol {
+			switch tok.Text {
+			case "=", "<>", "!=", "<", "<=", ">", ">=":
+				p.pos++
+				right, err := p.parseConcat()
+				if err != nil {
+					return nil, err
+				}
+				op := tok.Text
+				if op == "!=" {
+					op = "<>"
+				}
+				left = &BinaryExpr{Op: op, Left: left, Right: right}
+				continue
+			}
+		}
+
+		if p.AcceptKeyword("IS") {
+			not := p.AcceptKeyword("NOT")
+			if err := p.ExpectKeyword("NULL"); err != nil {
+				return nil, err
+			}
+			left = &IsNullExpr{Expr: left, Not: not}
+			continue
+		}
+
+		not := false
+		if p.IsKeyword("NOT") && (p.PeekAt(1).Kind == TokenIdent) {
+			next := strings.ToUpper(p.PeekAt(1).Text)
+			if next == "IN" || next == "BETWEEN" || next == "LIKE" {
+				p.pos++
+				not = true
+			}
+		}
+		switch {
+		case p.AcceptKeyword("IN"):
+			if err := p.ExpectSymbol("("); err != nil {
+				return nil, err
+			}
+			list, err := p.parseExprList()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.ExpectSymbol(")"); err != nil {
+				return nil, err
+			}
+			left = &InExpr{Expr: left, List: list, Not: not}
+		case p.AcceptKeyword("BETWEEN"):
+			low, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.ExpectKeyword("AND"); err != nil {
+				return nil, err
+			}
+			high, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			left = &BetweenExpr{Expr: left, Low: low, High: high, Not: not}
+		case p.AcceptKeyword("LIKE"):
+			right, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			left = &BinaryExpr{Op: "LIKE", Left: left, Right: right}
+			if not {
+				left = &UnaryExpr{Op: "NOT", Expr: left}
+			}
+		default:
+			return left, nil
+		}
+	}
+}
+
+func (p *Parser) parseConcat() (Expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for p.AcceptSymbol("||") {
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: "||", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *Parser) parseAdditive() (Expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.IsSymbol("+") || p.IsSymbol("-") {
+		op := p.Next().Text
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: op, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *Parser) parseMultiplicative() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.IsSymbol("*") || p.IsSymbol("/") || p.IsSymbol("%") || p.IsKeyword("DIV", "MOD") {
+		op := strings.ToUpper(p.Next().Text)
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: op, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *Parser) parseUnary() (Expr, error) {
+	if p.IsSymbol("-") || p.IsSymbol("+") {
+		op := p.Next().Text
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		// fold negative numeric literals so that -12.50 stays an exact literal
+		if lit, ok := expr.(*Literal); ok && op == "-" {
+			if negated, ok := negateLiteral(lit.Value); ok {
+				return &Literal{Value: negated}, nil
+			}
+		}
+		if op == "+" {
+			return expr, nil
+		}
+		return &UnaryExpr{Op: op, Expr: expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *Parser) parsePrimary() (Expr, error) {
+	tok := p.Peek()
+	switch tok.Kind {
+	case TokenNumber:
+		p.pos++
+		value, err := literalValue(tok.Text)
+		if err != nil {
+			return nil, err
+		}
+		return &Literal{Value: value}, nil
+	case TokenString:
+		p.pos++
+		return &Literal{Value: tok.Text}, nil
+	case TokenSymbol:
+		if p.AcceptSymbol("(") {
+			expr, err := p.ParseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.ExpectSymbol(")"); err != nil {
+				return nil, err
+			}
+			return expr, nil
+		}
+		return nil, p.Errorf("unexpected symbol")
+	case TokenQuoted:
+		return p.parseColumnRef()
+	case TokenIdent:
+		word := strings.ToUpper(tok.Text)
+		switch word {
+		case "NULL":
+			p.pos++
+			return &Literal{Value: nil}, nil
+		case "TRUE", "FALSE":
+			p.pos++
+			return &Literal{Value: word == "TRUE"}, nil
+		case "CASE":
+			return p.parseCase()
+		case "INTERVAL":
+			return p.parseInterval()
+		case "CURRENT_TIMESTAMP", "CURRENT_DATE", "CURRENT_TIME", "LOCALTIMESTAMP":
+			// niladic functions may be written without parentheses
+			if next := p.PeekAt(1); next.Kind != TokenSymbol || next.Text != "(" {
+				p.pos++
+				return &FuncCall{Name: word}, nil
+			}
+		case "DATE", "TIME", "DATETIME", "TIMESTAMP":
+			// typed literal such as DATE '2024-01-31'
+			if next := p.PeekAt(1); next.Kind == TokenString {
+				p.pos += 2
+				return &FuncCall{Name: word, Args: []Expr{&Literal{Value: next.Text}}}, nil
+			}
+		case "EXTRACT":
+			if next := p.PeekAt(1); next.Kind == TokenSymbol && next.Text == "(" {
+				return p.parseExtract()
+			}
+		}
+		if next := p.PeekAt(1); next.Kind == TokenSymbol && next.Text == "(" {
+			return p.parseFuncCall()
+		}
+		if reservedWords[word] {
+			return nil, p.Errorf("unexpected keyword")
+		}
+		return p.parseColumnRef()
+	}
+	return nil, p.Errorf("expected expression")
+}
+
+func (p *Parser) parseColumnRef() (Expr, error) {
+	name := p.Next().Text
+	if p.IsSymbol(".") {
+		p.pos++
+		column, err := p.ParseIdent()
+		if err != nil {
+			return nil, err
+		}
+		return &ColumnRef{Table: name, Name: column}, nil
+	}
+	return &ColumnRef{Name: name}, nil
+}
+
+func (p *Parser) parseFuncCall() (Expr, error) {
+	call := &FuncCall{Name: strings.ToUpper(p.Next().Text)}
+	p.pos++ // (
+	if p.AcceptSymbol(")") {
+		return call, nil
+	}
+	if p.AcceptSymbol("*") {
+		call.Star = true
+		return call, p.ExpectSymbol(")")
+	}
+	if p.AcceptKeyword("DISTINCT") {
+		call.Distinct = true
+	}
+	args, err := p.parseExprList()
+	if err != nil {
+		return nil, err
+	}
+	call.Args = args
+	return call, p.ExpectSymbol(")")
+}
+
+func (p *Parser) parseCase() (Expr, error) {
+	p.pos++ // CASE
+	expr := &CaseExpr{}
+	if !p.IsKeyword("WHEN") {
+		operand, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		expr.Operand = operand
+	}
+	for p.AcceptKeyword("WHEN") {
+		cond, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.ExpectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+		result, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		expr.Whens = append(expr.Whens, WhenClause{Cond: cond, Result: result})
+	}
+	if len(expr.Whens) == 0 {
+		return nil, p.Errorf("CASE requires at least one WHEN")
+	}
+	if p.AcceptKeyword("ELSE") {
+		elseExpr, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+		expr.Else = elseExpr
+	}
+	return expr, p.ExpectKeyword("END")
+}
+
+// parseInterval parses "INTERVAL value unit", where value is a number, a quoted number or a parenthesised expression
+func (p *Parser) parseInterval() (Expr, error) {
+	p.pos++ // INTERVAL
+	value, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.Peek()
+	if tok.Kind != TokenIdent {
+		return nil, p.Errorf("expected interval unit")
+	}
+	p.pos++
+	return &IntervalExpr{Value: value, Unit: strings.TrimSuffix(strings.ToUpper(tok.Text), "S")}, nil
+}
+
+// parseExtract parses "EXTRACT(unit FROM expr)" into a call EXTRACT('unit', expr)
+func (p *Parser) parseExtract() (Expr, error) {
+	p.pos += 2 // EXTRACT (
+	tok := p.Peek()
+	if tok.Kind != TokenIdent && tok.Kind != TokenString {
+		return nil, p.Errorf("expected field name")
+	}
+	p.pos++
+	if err := p.ExpectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	source, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.ExpectSymbol(")"); err != nil {
+		return nil, err
+	}
+	return &FuncCall{Name: "EXTRACT", Args: []Expr{&Literal{Value: strings.ToUpper(tok.Text)}, source}}, nil
+}
+
+// ParseColumnDef parses "name TYPE[(args)] [NOT NULL | NULL]"
+func (p *Parser) ParseColumnDef() (ColumnDef, error) {
+	name, err := p.ParseIdent()
+	if err != nil {
+		return ColumnDef{}, err
+	}
+	spec, err := p.ParseTypeSpec()
+	if err != nil {
+		return ColumnDef{}, err
+	}
+	def := ColumnDef{Name: name, Type: spec}
+	for !p.AtEnd() && !p.IsSymbol(",") && !p.IsSymbol(")") {
+		switch {
+		case p.AcceptKeywords("NOT", "NULL"):
+			def.NotNull = true
+		case p.AcceptKeyword("NULL"):
+			def.NotNull = false
+		case p.AcceptKeywords("PRIMARY", "KEY"):
+			def.PrimaryKey = true
+		case p.IsKeyword("REFERENCES"):
+			refs, err := p.parseReferences()
+			if err != nil {
+				return ColumnDef{}, err
+			}
+			def.References = refs
+		case p.AcceptKeyword("DEFAULT"):
+			if def.Default, err = p.ParseExpr(); err != nil {
+				return ColumnDef{}, err
+			}
+		case p.AcceptKeyword("CHECK"):
+			if def.Check, err = p.parseCheck(); err != nil {
+				return ColumnDef{}, err
+			}
+		case p.AcceptKeyword("AUTO_INCREMENT"), p.AcceptKeyword("AUTOINCREMENT"):
+			def.Identity = "AUTO_INCREMENT"
+		case p.AcceptKeyword("GENERATED"):
+			switch {
+			case p.AcceptKeyword("ALWAYS"):
+				def.Identity = "ALWAYS"
+			case p.AcceptKeywords("BY", "DEFAULT"):
+				def.Identity = "BY DEFAULT"
+			default:
+				return ColumnDef{}, p.Errorf("expected ALWAYS or BY DEFAULT")
+			}
+			if !p.AcceptKeywords("AS", "IDENTITY") {
+				return ColumnDef{}, p.Errorf("expected AS IDENTITY")
+			}
+		default:
+			return ColumnDef{}, p.Errorf("unexpected column constraint")
+		}
+	}
+	return def, nil
+}
+
+// ParseTableConstraint parses "[CONSTRAINT name] PRIMARY KEY (columns)",
+// "[CONSTRAINT name] FOREIGN KEY (columns) REFERENCES table [(columns)] [ON DELETE action] [ON UPDATE action]"
+// or "[CONSTRAINT name] CHECK (condition)"
+func (p *Parser) ParseTableConstraint() (TableConstraint, error) {
+	var constraint TableConstraint
+	if p.AcceptKeyword("CONSTRAINT") {
+		name, err := p.ParseIdent()
+		if err != nil {
+			return constraint, err
+		}
+		constraint.Name = name
+	}
+	switch {
+	case p.AcceptKeywords("PRIMARY", "KEY"):
+		constraint.Kind = "PRIMARY KEY"
+	case p.AcceptKeywords("FOREIGN", "KEY"):
+		constraint.Kind = "FOREIGN KEY"
+	case p.AcceptKeyword("CHECK"):
+		constraint.Kind = "CHECK"
+		check, err := p.parseCheck()
+		constraint.Check = check
+		return constraint, err
+	default:
+		return constraint, p.Errorf("expected PRIMARY KEY, FOREIGN KEY or CHECK")
+	}
+	columns, err := p.parseIdentList()
+	if err != nil {
+		return constraint, err
+	}
+	constraint.Columns = columns
+	if constraint.Kind == "FOREIGN KEY" {
+		if constraint.References, err = p.parseReferences(); err != nil {
+			return constraint, err
+		}
+	}
+	return constraint, nil
+}
+
+// parseCheck parses the parenthesized condition following CHECK
+func (p *Parser) parseCheck() (Expr, error) {
+	if err := p.ExpectSymbol("("); err != nil {
+		return nil, err
+	}
+	cond, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return cond, p.ExpectSymbol(")")
+}
+
+// parseReferences parses "REFERENCES table [(columns)] [ON DELETE action] [ON UPDATE action]"
+func (p *Parser) parseReferences() (*References, error) {
+	if err := p.ExpectKeyword("REFERENCES"); err != nil {
+		return nil, err
+	}
+	table, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+	refs := &References{Table: table}
+	if p.IsSymbol("(") {
+		if refs.Columns, err = p.parseIdentList(); err != nil {
+			return nil, err
+		}
+	}
+	for p.AcceptKeyword("ON") {
+		var target *string
+		switch {
+		case p.AcceptKeyword("DELETE"):
+			target = &refs.OnDelete
+		case p.AcceptKeyword("UPDATE"):
+			target = &refs.OnUpdate
+		default:
+			return nil, p.Errorf("expected DELETE or UPDATE")
+		}
+		switch {
+		case p.AcceptKeyword("RESTRICT"):
+			*target = "RESTRICT"
+		case p.AcceptKeyword("CASCADE"):
+			*target = "CASCADE"
+		case p.AcceptKeywords("SET", "NULL"):
+			*target = "SET NULL"
+		case p.AcceptKeywords("SET", "DEFAULT"):
+			*target = "SET DEFAULT"
+		case p.AcceptKeywords("NO", "ACTION"):
+			*target = "NO ACTION"
+		default:
+			return nil, p.Errorf("expected RESTRICT, CASCADE, SET NULL, SET DEFAULT or NO ACTION")
+		}
+	}
+	return refs, nil
+}
+
+// parseIdentList parses a parenthesised, comma separated list of identifiers
+func (p *Parser) parseIdentList() ([]string, error) {
+	if err := p.ExpectSymbol("("); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		name, err := p.ParseIdent()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if !p.AcceptSymbol(",") {
+			break
+		}
+	}
+	return names, p.ExpectSymbol(")")
+}
+
+// ParseTypeSpec parses a type name with optional parenthesised parameters, e.g. DECIMAL(10, 2)
+func (p *Parser) ParseTypeSpec() (TypeSpec, error) {
+	tok := p.Peek()
+	if tok.Kind != TokenIdent {
+		return TypeSpec{}, p.Errorf("expected data type")
+	}
+	p.pos++
+	spec := TypeSpec{Name: strings.ToUpper(tok.Text)}
+	// multi word type names such as DOUBLE PRECISION
+	if spec.Name == "DOUBLE" {
+		p.AcceptKeyword("PRECISION")
+	}
+	if p.AcceptSymbol("(") {
+		for {
+			arg := p.Next()
+			if arg.Kind != TokenNumber && arg.Kind != TokenString {
+				return TypeSpec{}, p.Errorf("expected type parameter")
+			}
+			spec.Args = append(spec.Args, arg.Text)
+			if !p.AcceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.ExpectSymbol(")"); err != nil {
+			return TypeSpec{}, err
+		}
+	}
+	return spec, nil
+}
+
+// negateLiteral negates a numeric literal value
+func negateLiteral(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case int64:
+		return -v, true
+	case float64:
+		return -v, true
+	case types.Decimal:
+		return v.Neg(), true
+	}
+	return nil, false
+}
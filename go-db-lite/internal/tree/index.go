@@ -0,0 +1,54 @@
+package tree
+
+import (
+	"errors"
+)
+
+// ErrDuplicateKey is returned when a key is inserted into a unique index twice
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// Default fan-out of index trees
+const (
+	IndexM = 64
+	IndexL = 64
+)
+
+// UniqueIndex maps byte-string keys to row numbers and rejects duplicate keys. The keys are
+// held in a B+ tree, in byte order.
+type UniqueIndex struct {
+	tree *OrderedTree[string]
+	size int
+}
+
+// NewUniqueIndex creates an empty unique index
+func NewUniqueIndex() *UniqueIndex {
+	return &UniqueIndex{tree: NewOrderedTree[string](IndexM, IndexL)}
+}
+
+// Insert adds key for the given row, or returns ErrDuplicateKey if the key is already present
+func (idx *UniqueIndex) Insert(key []byte, row int) error {
+	if err := idx.tree.Put(string(key), row); err != nil {
+		return err
+	}
+	idx.size++
+	return nil
+}
+
+// Lookup returns the row stored for key
+func (idx *UniqueIndex) Lookup(key []byte) (int, bool) {
+	return idx.tree.Get(string(key))
+}
+
+// Len returns the number of keys in the index
+func (idx *UniqueIndex) Len() int {
+	return idx.size
+}
+
+// Delete removes key from the index and reports whether it was present
+func (idx *UniqueIndex) Delete(key []byte) bool {
+	if idx.tree.Delete(string(key)) != nil {
+		return false
+	}
+	idx.size--
+	return true
+}
@@ -0,0 +1,38 @@
+package tree
+
+import (
+	"strconv"
+	"testing"
+)
+
+// Test that a unique index finds every inserted key, rejects duplicates and forgets deleted keys
+func TestUniqueIndex(t *testing.T) {
+	idx := NewUniqueIndex()
+	const n = 5000
+	for i := 0; i < n; i++ {
+		if err := idx.Insert([]byte("key"+strconv.Itoa(i)), i); err != nil {
+			t.Fatalf("Insert(key%d): %v", i, err)
+		}
+	}
+	if idx.Len() != n {
+		t.Errorf("Len() = %d, want %d", idx.Len(), n)
+	}
+	for i := 0; i < n; i++ {
+		row, found := idx.Lookup([]byte("key" + strconv.Itoa(i)))
+		if !found || row != i {
+			t.Fatalf("Lookup(key%d) = %d, %v", i, row, found)
+		}
+	}
+	if err := idx.Insert([]byte("key42"), n); err != ErrDuplicateKey {
+		t.Errorf("inserting a duplicate key returned %v, want ErrDuplicateKey", err)
+	}
+	if _, found := idx.Lookup([]byte("missing")); found {
+		t.Error("Lookup(missing) should not find a row")
+	}
+	if !idx.Delete([]byte("key42")) || idx.Delete([]byte("key42")) {
+		t.Error("Delete(key42) should remove the key once")
+	}
+	if err := idx.Insert([]byte("key42"), n); err != nil || idx.Len() != n {
+		t.Errorf("re-inserting a deleted key returned %v, Len() = %d", err, idx.Len())
+	}
+}
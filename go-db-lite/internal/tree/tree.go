@@ -1,8 +1,11 @@
 package tree
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
 	"math"
+	"slices"
 )
 
 /**
@@ -12,21 +15,43 @@ import (
  *
 **/
 
-// BPNode represents a node in the B+ Tree
-type BPNode struct {
-	keys     []int
-	children []*BPNode
+// Debug enables tracing of tree operations to stdout
+var Debug = false
+
+func debugf(format string, args ...interface{}) {
+	if Debug {
+		fmt.Printf(format, args...)
+	}
+}
+
+// ErrKeyNotFound is returned when a key to delete is not in the tree
+var ErrKeyNotFound = errors.New("key not found")
+
+// Node is a node of a B+ tree with keys of type K. Internal nodes hold one key fewer than
+// children: the keys of children[i] are at least keys[i-1] and less than keys[i]. Leaves
+// hold the keys themselves, each with the row stored for it, and link to the next leaf.
+type Node[K cmp.Ordered] struct {
+	keys     []K
+	rows     []int // leaf nodes only, parallel to keys
+	children []*Node[K]
 	isLeaf   bool
-	parent   *BPNode
-	next     *BPNode // For leaf nodes to link to the next sibling
+	parent   *Node[K]
+	next     *Node[K] // For leaf nodes to link to the next sibling
 }
 
-type BPTree struct {
-	root *BPNode
+// OrderedTree is a B+ tree of unique keys of type K, each mapped to a row number
+type OrderedTree[K cmp.Ordered] struct {
+	root *Node[K]
 	M    int // Maximum number of children in internal nodes, Minimum number of children in internal nodes is ceil(M/2)
 	L    int // Maximum number of elements in leaf nodes, Minimum number of elements in leaf nodes is ceil(L/2)
 }
 
+// BPNode represents a node in the B+ Tree
+type BPNode = Node[int]
+
+// BPTree is a B+ tree of integer keys
+type BPTree = OrderedTree[int]
+
 type Tree interface {
 	Insert(key int) error          // Returns an error if the key is already present
 	Delete(key int) error          // Returns an error if the key is not found
@@ -37,364 +62,261 @@ type Tree interface {
 
 // CreateTree creates a new B+ Tree with the given maximum number of children in internal nodes (M) and maximum number of elements in leaf nodes (L)
 func CreateTree(m, l int) *BPTree {
-	return &BPTree{
-		root: &BPNode{
-			keys:   []int{},
-			isLeaf: true,
-		},
-		M: m,
-		L: l,
+	return NewOrderedTree[int](m, l)
+}
+
+// NewOrderedTree creates an empty B+ tree with keys of type K, at most m children in
+// internal nodes and at most l keys in leaf nodes
+func NewOrderedTree[K cmp.Ordered](m, l int) *OrderedTree[K] {
+	return &OrderedTree[K]{
+		root: &Node[K]{isLeaf: true},
+		M:    max(m, 3),
+		L:    max(l, 2),
 	}
 }
 
-func (tree *BPTree) MaxChildrenInternalNodes() int {
+func (tree *OrderedTree[K]) MaxChildrenInternalNodes() int {
 	return tree.M
 }
 
-func (tree *BPTree) MinChildrenInternalNodes() int {
+func (tree *OrderedTree[K]) MinChildrenInternalNodes() int {
 	return int(math.Ceil(float64(tree.M) / 2))
 }
 
-func (tree *BPTree) MaxElementsLeafNodes() int {
+func (tree *OrderedTree[K]) MaxElementsLeafNodes() int {
 	return tree.L
 }
 
-func (tree *BPTree) MinElementsLeafNodes() int {
+func (tree *OrderedTree[K]) MinElementsLeafNodes() int {
 	return int(math.Ceil(float64(tree.L) / 2))
 }
 
-func (tree *BPTree) Insert(value int) {
-	fmt.Printf("Inserting value %d into the B+ tree\n", value)
-
-	fmt.Println("Finding leaf node for insertion")
-	node := findLeafNode(tree.root, value)
-	fmt.Printf("Found leaf node with keys: %v\n", node.keys)
-
-	fmt.Printf("Inserting value %d into leaf node\n", value)
-	insertIntoLeafNode(node, value, tree.L)
-	fmt.Printf("After insertion, leaf node keys: %v\n", node.keys)
-
-	if len(node.keys) > tree.L {
-		fmt.Printf("Leaf node overflow detected. Current keys: %v, Max allowed: %d\n", node.keys, tree.L)
-		fmt.Println("Splitting leaf node")
-		splitLeafNode(tree, node)
-		fmt.Println("Leaf node split complete")
-	} else {
-		fmt.Println("No split required")
-	}
-
-	fmt.Printf("Insertion of value %d complete\n", value)
+// Insert adds a key, or returns ErrDuplicateKey if it is already present
+func (tree *OrderedTree[K]) Insert(key K) error {
+	return tree.Put(key, 0)
 }
 
-func findLeafNode(node *BPNode, value int) *BPNode {
-	if node.isLeaf {
-		return node
+// Put adds a key with the row stored for it, or returns ErrDuplicateKey if the key is
+// already present
+func (tree *OrderedTree[K]) Put(key K, row int) error {
+	debugf("Inserting key %v into the B+ tree\n", key)
+	leaf := tree.findLeaf(key)
+	i, found := slices.BinarySearch(leaf.keys, key)
+	if found {
+		return ErrDuplicateKey
 	}
-
-	for i := 0; i < len(node.keys); i++ {
-		if value < node.keys[i] {
-			return findLeafNode(node.children[i], value)
-		}
-	}
-
-	return findLeafNode(node.children[len(node.children)-1], value)
-}
-
-func insertIntoLeafNode(node *BPNode, value int, L int) {
-	node.keys = append(node.keys, value)
-	for i := len(node.keys) - 1; i > 0 && node.keys[i] < node.keys[i-1]; i-- {
-		node.keys[i], node.keys[i-1] = node.keys[i-1], node.keys[i]
+	leaf.keys = slices.Insert(leaf.keys, i, key)
+	leaf.rows = slices.Insert(leaf.rows, i, row)
+	if len(leaf.keys) > tree.L {
+		debugf("Leaf node overflow detected. Current keys: %v, Max allowed: %d\n", leaf.keys, tree.L)
+		tree.splitLeafNode(leaf)
 	}
+	return nil
 }
 
-func splitLeafNode(tree *BPTree, node *BPNode) {
-	fmt.Println("Starting splitLeafNode function")
-	fmt.Printf("Original node keys: %v\n", node.keys)
-
-	newLeaf := &BPNode{
-		keys:   make([]int, 0),
-		isLeaf: true,
-	}
-	fmt.Println("Created new leaf node")
-
-	midIndex := (tree.L + 1) / 2
-	fmt.Printf("Calculated midIndex: %d\n", midIndex)
-
-	newLeaf.keys = append(newLeaf.keys, node.keys[midIndex:]...)
-	fmt.Printf("New leaf keys: %v\n", newLeaf.keys)
-
-	node.keys = node.keys[:midIndex]
-	fmt.Printf("Updated original node keys: %v\n", node.keys)
-
-	newLeaf.next = node.next
-	node.next = newLeaf
-	fmt.Println("Updated next pointers")
-
-	if node.parent == nil {
-		fmt.Println("Node has no parent, creating new root")
-		createNewRoot(tree, node, newLeaf)
-	} else {
-		fmt.Println("Inserting new leaf into parent")
-		fmt.Printf("Parent keys before insertion: %v\n", node.parent.keys)
-		insertIntoParent(tree, node.parent, newLeaf.keys[0], newLeaf)
-		fmt.Printf("Parent keys after insertion: %v\n", node.parent.keys)
+// Get returns the row stored for a key
+func (tree *OrderedTree[K]) Get(key K) (int, bool) {
+	node, i := tree.Search(key)
+	if node == nil {
+		return 0, false
 	}
-
-	fmt.Println("Finished splitLeafNode function")
+	return node.rows[i], true
 }
 
-func createNewRoot(tree *BPTree, leftChild, rightChild *BPNode) {
-	newRoot := &BPNode{
-		keys:     []int{rightChild.keys[0]},
-		children: []*BPNode{leftChild, rightChild},
-		isLeaf:   false,
+// Search returns the leaf holding a key and the index of the key in it, or nil and -1
+func (tree *OrderedTree[K]) Search(key K) (*Node[K], int) {
+	leaf := tree.findLeaf(key)
+	if i, found := slices.BinarySearch(leaf.keys, key); found {
+		return leaf, i
 	}
-
-	leftChild.parent = newRoot
-	rightChild.parent = newRoot
-
-	tree.root = newRoot
+	return nil, -1
 }
 
-func insertIntoParent(tree *BPTree, parent *BPNode, key int, rightChild *BPNode) {
-	insertKey(parent, key, rightChild)
-
-	if len(parent.children) > tree.M {
-		splitInternalNode(tree, parent)
+// findLeaf returns the leaf a key belongs in
+func (tree *OrderedTree[K]) findLeaf(key K) *Node[K] {
+	node := tree.root
+	for !node.isLeaf {
+		i, found := slices.BinarySearch(node.keys, key)
+		if found {
+			i++ // a key equal to a separator is in the child to its right
+		}
+		node = node.children[i]
 	}
+	return node
 }
 
-func insertKey(node *BPNode, key int, rightChild *BPNode) {
-	i := 0
-	for ; i < len(node.keys) && key > node.keys[i]; i++ {
+// splitLeafNode moves the upper keys of an overflowing leaf into a new leaf after it, whose
+// first key is copied into the parent
+func (tree *OrderedTree[K]) splitLeafNode(node *Node[K]) {
+	mid := (tree.L + 1) / 2
+	newLeaf := &Node[K]{
+		keys:   slices.Clone(node.keys[mid:]),
+		rows:   slices.Clone(node.rows[mid:]),
+		isLeaf: true,
+		next:   node.next,
 	}
-
-	node.keys = append(node.keys[:i], append([]int{key}, node.keys[i:]...)...)
-	node.children = append(node.children[:i+1], append([]*BPNode{rightChild}, node.children[i+1:]...)...)
-	rightChild.parent = node
+	node.keys, node.rows = node.keys[:mid], node.rows[:mid]
+	node.next = newLeaf
+	debugf("Split leaf node into %v and %v\n", node.keys, newLeaf.keys)
+	tree.insertIntoParent(node, newLeaf.keys[0], newLeaf)
 }
 
-func splitInternalNode(tree *BPTree, node *BPNode) {
-	fmt.Println("Starting splitInternalNode function")
-	newInternal := &BPNode{
-		keys:   make([]int, 0),
-		isLeaf: false,
+// splitInternalNode moves the upper children of an overflowing internal node into a new
+// node after it. The middle key separates the two and moves up into the parent.
+func (tree *OrderedTree[K]) splitInternalNode(node *Node[K]) {
+	mid := len(node.keys) / 2
+	midKey := node.keys[mid]
+	newInternal := &Node[K]{
+		keys:     slices.Clone(node.keys[mid+1:]),
+		children: slices.Clone(node.children[mid+1:]),
 	}
-
-	midIndex := len(node.keys) / 2
-	midKey := node.keys[midIndex]
-
-	newInternal.keys = append(newInternal.keys, node.keys[midIndex+1:]...)
-	newInternal.children = append(newInternal.children, node.children[midIndex+1:]...)
-
 	for _, child := range newInternal.children {
 		child.parent = newInternal
 	}
-
-	node.keys = node.keys[:midIndex] // Exclude the midKey
-	node.children = node.children[:midIndex+1]
-
-	if node.parent == nil {
-		createNewRoot(tree, node, newInternal)
-	} else {
-		insertIntoParent(tree, node.parent, midKey, newInternal)
-	}
-
-	fmt.Println("Finished splitInternalNode function")
+	node.keys, node.children = node.keys[:mid], node.children[:mid+1]
+	debugf("Split internal node into %v and %v, promoting %v\n", node.keys, newInternal.keys, midKey)
+	tree.insertIntoParent(node, midKey, newInternal)
 }
 
-func (tree *BPTree) Delete(value int) {
-	fmt.Printf("Deleting value %d from the B+ tree\n", value)
-
-	node := findLeafNode(tree.root, value)
-	fmt.Printf("Found leaf node with keys: %v\n", node.keys)
-
-	deleteFromLeafNode(node, value)
-	fmt.Printf("After deletion, leaf node keys: %v\n", node.keys)
-
-	if len(node.keys) < (tree.L+1)/2 {
-		handleLeafUnderflow(tree, node)
+// insertIntoParent adds a node split off to the right of another to their parent, growing
+// the tree by a new root when the split node was the root
+func (tree *OrderedTree[K]) insertIntoParent(left *Node[K], key K, right *Node[K]) {
+	parent := left.parent
+	if parent == nil {
+		tree.root = &Node[K]{keys: []K{key}, children: []*Node[K]{left, right}}
+		left.parent, right.parent = tree.root, tree.root
+		return
+	}
+	i := slices.Index(parent.children, left)
+	parent.keys = slices.Insert(parent.keys, i, key)
+	parent.children = slices.Insert(parent.children, i+1, right)
+	right.parent = parent
+	if len(parent.children) > tree.M {
+		tree.splitInternalNode(parent)
 	}
 }
 
-func deleteFromLeafNode(node *BPNode, value int) {
-	index := findKeyIndex(node.keys, value)
-	if index != -1 {
-		node.keys = append(node.keys[:index], node.keys[index+1:]...)
-	}
-
-	if node.parent != nil {
-		updateParentKey(node.parent)
+// Delete removes a key, or returns ErrKeyNotFound if it is not present
+func (tree *OrderedTree[K]) Delete(key K) error {
+	debugf("Deleting key %v from the B+ tree\n", key)
+	leaf := tree.findLeaf(key)
+	i, found := slices.BinarySearch(leaf.keys, key)
+	if !found {
+		return ErrKeyNotFound
 	}
+	leaf.keys = slices.Delete(leaf.keys, i, i+1)
+	leaf.rows = slices.Delete(leaf.rows, i, i+1)
+	tree.rebalance(leaf)
+	return nil
 }
 
-func findKeyIndex(keys []int, value int) int {
-	for i, key := range keys {
-		if key == value {
-			return i
+// rebalance restores the minimum size of a node after a deletion, borrowing from a sibling
+// that can spare a key or child, or else merging with a sibling, which may leave the parent
+// short in turn. A root left with a single child is replaced by it.
+func (tree *OrderedTree[K]) rebalance(node *Node[K]) {
+	if node == tree.root {
+		if !node.isLeaf && len(node.children) == 1 {
+			tree.root = node.children[0]
+			tree.root.parent = nil
 		}
+		return
 	}
-	return -1
-}
-
-func handleLeafUnderflow(tree *BPTree, node *BPNode) {
-	leftSibling := getLeftSibling(node)
-	rightSibling := getRightSibling(node)
-
-	if leftSibling != nil && len(leftSibling.keys) > (tree.L+1)/2 {
-		borrowFromLeftSibling(node, leftSibling)
-	} else if rightSibling != nil && len(rightSibling.keys) > (tree.L+1)/2 {
-		borrowFromRightSibling(node, rightSibling)
-	} else if leftSibling != nil {
-		mergeWithSibling(tree, leftSibling, node)
-	} else {
-		mergeWithSibling(tree, node, rightSibling)
+	if !tree.underflows(node) {
+		return
 	}
-}
-
-func borrowFromLeftSibling(node, leftSibling *BPNode) {
-	node.keys = append([]int{leftSibling.keys[len(leftSibling.keys)-1]}, node.keys...)
-	leftSibling.keys = leftSibling.keys[:len(leftSibling.keys)-1]
-	updateParentKey(node.parent)
-}
-
-func borrowFromRightSibling(node, rightSibling *BPNode) {
-	node.keys = append(node.keys, rightSibling.keys[0])
-	rightSibling.keys = rightSibling.keys[1:]
-	updateParentKey(node.parent)
-}
-
-func mergeWithSibling(tree *BPTree, leftSibling, node *BPNode) {
-	leftSibling.keys = append(leftSibling.keys, node.keys...)
-	leftSibling.next = node.next
-
-	if node.parent != nil {
-		removeChildFromParent(tree, node.parent, node)
-	}
-}
-
-func removeChildFromParent(tree *BPTree, parent, node *BPNode) {
-	index := findChildIndex(parent.children, node)
-	if index != -1 {
-		parent.children = append(parent.children[:index], parent.children[index+1:]...)
-		if index > 0 {
-			parent.keys = append(parent.keys[:index-1], parent.keys[index:]...)
-		} else {
-			parent.keys = parent.keys[1:]
-		}
-	}
-
-	if len(parent.children) < tree.MinChildrenInternalNodes() {
-		handleInternalUnderflow(tree, parent)
+	parent := node.parent
+	i := slices.Index(parent.children, node)
+	var left, right *Node[K]
+	if i > 0 {
+		left = parent.children[i-1]
 	}
-}
-func findChildIndex(children []*BPNode, node *BPNode) int {
-	for i, child := range children {
-		if child == node {
-			return i
-		}
+	if i < len(parent.children)-1 {
+		right = parent.children[i+1]
 	}
-	return -1
-}
-
-func handleInternalUnderflow(tree *BPTree, node *BPNode) {
-	leftSibling := getLeftSibling(node)
-	rightSibling := getRightSibling(node)
-
-	if leftSibling != nil && len(leftSibling.children) > tree.MinChildrenInternalNodes() {
-		borrowChildFromLeftSibling(node, leftSibling)
-	} else if rightSibling != nil && len(rightSibling.children) > tree.MinChildrenInternalNodes() {
-		borrowChildFromRightSibling(node, rightSibling)
-	} else if leftSibling != nil {
-		mergeInternalWithSibling(tree, leftSibling, node)
-	} else {
-		mergeInternalWithSibling(tree, node, rightSibling)
+	switch {
+	case left != nil && tree.canLend(left):
+		borrowFromLeftSibling(node, left, i)
+	case right != nil && tree.canLend(right):
+		borrowFromRightSibling(node, right, i)
+	case left != nil:
+		tree.mergeWithSibling(left, node, i-1)
+	default:
+		tree.mergeWithSibling(node, right, i)
 	}
 }
 
-func borrowChildFromLeftSibling(node, leftSibling *BPNode) {
-	node.children = append([]*BPNode{leftSibling.children[len(leftSibling.children)-1]}, node.children...)
-	leftSibling.children = leftSibling.children[:len(leftSibling.children)-1]
-	updateParentKey(node.parent)
-}
-func borrowChildFromRightSibling(node, rightSibling *BPNode) {
-	node.children = append(node.children, rightSibling.children[0])
-	rightSibling.children = rightSibling.children[1:]
-	updateParentKey(node.parent)
-}
-
-func mergeInternalWithSibling(tree *BPTree, leftSibling, node *BPNode) {
-	leftSibling.keys = append(leftSibling.keys, node.keys...)
-	leftSibling.children = append(leftSibling.children, node.children...)
-
-	if node.parent != nil {
-		removeChildFromParent(tree, node.parent, node)
+// underflows reports whether a node other than the root is below its minimum size
+func (tree *OrderedTree[K]) underflows(node *Node[K]) bool {
+	if node.isLeaf {
+		return len(node.keys) < tree.MinElementsLeafNodes()
 	}
+	return len(node.children) < tree.MinChildrenInternalNodes()
 }
 
-func getLeftSibling(node *BPNode) *BPNode {
-	parent := node.parent
-	if parent == nil {
-		return nil
-	}
-
-	index := findChildIndex(parent.children, node)
-	if index > 0 {
-		return parent.children[index-1]
+// canLend reports whether a node stays at or above its minimum size after giving up one
+// key or child
+func (tree *OrderedTree[K]) canLend(node *Node[K]) bool {
+	if node.isLeaf {
+		return len(node.keys) > tree.MinElementsLeafNodes()
 	}
-	return nil
+	return len(node.children) > tree.MinChildrenInternalNodes()
 }
 
-func getRightSibling(node *BPNode) *BPNode {
+// borrowFromLeftSibling moves the last key, or child, of the left sibling into node, the
+// i-th child of their parent
+func borrowFromLeftSibling[K cmp.Ordered](node, left *Node[K], i int) {
 	parent := node.parent
-	if parent == nil {
-		return nil
-	}
-
-	index := findChildIndex(parent.children, node)
-	if index < len(parent.children)-1 {
-		return parent.children[index+1]
-	}
-	return nil
-}
-
-func updateParentKey(parent *BPNode) {
-	for i := 1; i < len(parent.children); i++ {
-		parent.keys[i-1] = findMinValue(parent.children[i])
-	}
-}
-func findMinValue(node *BPNode) int {
+	last := len(left.keys) - 1
 	if node.isLeaf {
-		return node.keys[0]
-	}
-	return findMinValue(node.children[0])
-}
-
-func (tree *BPTree) Search(value int) (*BPNode, int) {
-	return searchInTree(tree.root, value)
-}
-
-func searchInTree(node *BPNode, value int) (*BPNode, int) {
-	fmt.Printf("Searching for value %d in the B+ tree\n", value)
-
+		node.keys = slices.Insert(node.keys, 0, left.keys[last])
+		node.rows = slices.Insert(node.rows, 0, left.rows[last])
+		left.keys, left.rows = left.keys[:last], left.rows[:last]
+		parent.keys[i-1] = node.keys[0]
+		return
+	}
+	child := left.children[len(left.children)-1]
+	node.keys = slices.Insert(node.keys, 0, parent.keys[i-1])
+	node.children = slices.Insert(node.children, 0, child)
+	child.parent = node
+	parent.keys[i-1] = left.keys[last]
+	left.keys, left.children = left.keys[:last], left.children[:len(left.children)-1]
+}
+
+// borrowFromRightSibling moves the first key, or child, of the right sibling into node, the
+// i-th child of their parent
+func borrowFromRightSibling[K cmp.Ordered](node, right *Node[K], i int) {
+	parent := node.parent
 	if node.isLeaf {
-		for i, key := range node.keys {
-			if key == value {
-				fmt.Printf("Value %d found at index %d in leaf node\n", value, i)
-				return node, i
-			}
-		}
-		fmt.Printf("Value %d not found in the B+ tree\n", value)
-		return nil, -1
-	}
-
-	fmt.Printf("Searching in internal node with keys: %v\n", node.keys)
-	for i, key := range node.keys {
-		if value < key {
-			fmt.Printf("Value %d is less than key %d, searching in child node\n", value, key)
-			return searchInTree(node.children[i], value)
+		node.keys = append(node.keys, right.keys[0])
+		node.rows = append(node.rows, right.rows[0])
+		right.keys, right.rows = slices.Delete(right.keys, 0, 1), slices.Delete(right.rows, 0, 1)
+		parent.keys[i] = right.keys[0]
+		return
+	}
+	child := right.children[0]
+	node.keys = append(node.keys, parent.keys[i])
+	node.children = append(node.children, child)
+	child.parent = node
+	parent.keys[i] = right.keys[0]
+	right.keys, right.children = slices.Delete(right.keys, 0, 1), slices.Delete(right.children, 0, 1)
+}
+
+// mergeWithSibling moves the contents of right into its left sibling and removes right and
+// the key separating them, at index sep of the parent
+func (tree *OrderedTree[K]) mergeWithSibling(left, right *Node[K], sep int) {
+	parent := left.parent
+	if left.isLeaf {
+		left.keys = append(left.keys, right.keys...)
+		left.rows = append(left.rows, right.rows...)
+		left.next = right.next
+	} else {
+		left.keys = append(append(left.keys, parent.keys[sep]), right.keys...)
+		for _, child := range right.children {
+			child.parent = left
 		}
+		left.children = append(left.children, right.children...)
 	}
-	fmt.Printf("Value %d is greater than all keys in the internal node, searching in the last child\n", value)
-	return searchInTree(node.children[len(node.children)-1], value)
+	parent.keys = slices.Delete(parent.keys, sep, sep+1)
+	parent.children = slices.Delete(parent.children, sep+1, sep+2)
+	tree.rebalance(parent)
 }
@@ -1,9 +1,99 @@
 package tree
 
 import (
+	"math/rand"
+	"slices"
 	"testing"
 )
 
+// insertAll inserts values into a tree, checking it after each insertion
+func insertAll(t *testing.T, tree *BPTree, values []int) {
+	t.Helper()
+	for _, value := range values {
+		tree.Insert(value)
+		checkTree(t, tree)
+	}
+}
+
+// deleteAll deletes values from a tree, checking it after each deletion
+func deleteAll(t *testing.T, tree *BPTree, values []int) {
+	t.Helper()
+	for _, value := range values {
+		if err := tree.Delete(value); err != nil {
+			t.Fatalf("Delete(%d): %v", value, err)
+		}
+		checkTree(t, tree)
+	}
+}
+
+// checkTree checks the invariants of a B+ tree: keys are sorted and lie between the keys
+// separating their node from its siblings, nodes other than the root are neither too small
+// nor too large, every leaf is at the same depth and the leaves link up in key order
+func checkTree(t *testing.T, tree *BPTree) {
+	t.Helper()
+	leafDepth := -1
+	var leaves []*BPNode
+	var check func(node *BPNode, depth int, low, high *int)
+	check = func(node *BPNode, depth int, low, high *int) {
+		if !slices.IsSorted(node.keys) || len(slices.Compact(slices.Clone(node.keys))) != len(node.keys) {
+			t.Fatalf("node keys %v are not sorted and unique", node.keys)
+		}
+		for _, key := range node.keys {
+			if (low != nil && key < *low) || (high != nil && key >= *high) {
+				t.Fatalf("key %d of node %v is outside the range of its parent's keys", key, node.keys)
+			}
+		}
+		if node.isLeaf {
+			if node != tree.root && (len(node.keys) < tree.MinElementsLeafNodes() || len(node.keys) > tree.L) {
+				t.Fatalf("leaf %v holds %d keys, want %d to %d", node.keys, len(node.keys), tree.MinElementsLeafNodes(), tree.L)
+			}
+			if leafDepth == -1 {
+				leafDepth = depth
+			} else if depth != leafDepth {
+				t.Fatalf("leaf %v is at depth %d, others at %d", node.keys, depth, leafDepth)
+			}
+			leaves = append(leaves, node)
+			return
+		}
+		if len(node.children) != len(node.keys)+1 {
+			t.Fatalf("internal node %v has %d children", node.keys, len(node.children))
+		}
+		min := tree.MinChildrenInternalNodes()
+		if node == tree.root {
+			min = 2
+		}
+		if len(node.children) < min || len(node.children) > tree.M {
+			t.Fatalf("internal node %v has %d children, want %d to %d", node.keys, len(node.children), min, tree.M)
+		}
+		for i, child := range node.children {
+			if child.parent != node {
+				t.Fatalf("child %v of node %v does not point back to it", child.keys, node.keys)
+			}
+			childLow, childHigh := low, high
+			if i > 0 {
+				childLow = &node.keys[i-1]
+			}
+			if i < len(node.keys) {
+				childHigh = &node.keys[i]
+			}
+			check(child, depth+1, childLow, childHigh)
+		}
+	}
+	if tree.root.parent != nil {
+		t.Fatalf("the root has a parent")
+	}
+	check(tree.root, 0, nil, nil)
+	for i, leaf := range leaves {
+		var next *BPNode
+		if i+1 < len(leaves) {
+			next = leaves[i+1]
+		}
+		if leaf.next != next {
+			t.Fatalf("leaf %v does not link to the leaf after it", leaf.keys)
+		}
+	}
+}
+
 // Test insertion of elements into the B+ tree
 func TestInsert(t *testing.T) {
 	tree := CreateTree(4, 4)
@@ -11,14 +101,12 @@ func TestInsert(t *testing.T) {
 	// Insert a sequence of elements
 	values := []int{20, 18, 22, 28, 25, 41, 23, 42, 53, 35, 62, 63, 84, 73, 99}
 
-	for _, value := range values {
-		tree.Insert(value)
-	}
+	insertAll(t, tree, values)
 
 	// Check the structure and values in the tree
 	root := tree.root
-	if len(root.keys) != 1 || root.keys[0] != 25 {
-		t.Errorf("Root node key is incorrect: got %v, want [25]", root.keys)
+	if len(root.keys) != 1 || root.keys[0] != 41 {
+		t.Errorf("Root node key is incorrect: got %v, want [41]", root.keys)
 	}
 
 	if len(root.children) != 2 {
@@ -28,12 +116,21 @@ func TestInsert(t *testing.T) {
 	leftChild := root.children[0]
 	rightChild := root.children[1]
 
-	if len(leftChild.keys) != 2 || leftChild.keys[0] != 23 || leftChild.keys[1] != 41 {
-		t.Errorf("Left child node keys are incorrect: got %v, want [23, 41]", leftChild.keys)
+	if len(leftChild.keys) != 2 || leftChild.keys[0] != 22 || leftChild.keys[1] != 25 {
+		t.Errorf("Left child node keys are incorrect: got %v, want [22, 25]", leftChild.keys)
 	}
 
-	if len(rightChild.keys) != 2 || rightChild.keys[0] != 63 || rightChild.keys[1] != 84 {
-		t.Errorf("Right child node keys are incorrect: got %v, want [63, 84]", rightChild.keys)
+	if len(rightChild.keys) != 2 || rightChild.keys[0] != 53 || rightChild.keys[1] != 63 {
+		t.Errorf("Right child node keys are incorrect: got %v, want [53, 63]", rightChild.keys)
+	}
+
+	for _, value := range values {
+		if node, _ := tree.Search(value); node == nil {
+			t.Errorf("Search(%d) did not find an inserted value", value)
+		}
+	}
+	if err := tree.Insert(42); err != ErrDuplicateKey {
+		t.Errorf("inserting a value twice returned %v, want ErrDuplicateKey", err)
 	}
 }
 
@@ -43,31 +140,35 @@ func TestDelete(t *testing.T) {
 
 	// Insert a sequence of elements
 	values := []int{20, 18, 22, 28, 25, 41, 23, 42, 53, 35, 62, 63, 84, 73, 99}
-	for _, value := range values {
-		tree.Insert(value)
-	}
+	insertAll(t, tree, values)
 
 	// Delete some elements
 	toDelete := []int{41, 28, 62, 99, 20, 63}
-	for _, value := range toDelete {
-		tree.Delete(value)
-	}
+	deleteAll(t, tree, toDelete)
 
 	// Check the structure and values in the tree after deletions
 	root := tree.root
-	if len(root.keys) != 1 || root.keys[0] != 25 {
-		t.Errorf("Root node key is incorrect after deletion: got %v, want [25]", root.keys)
+	if len(root.keys) != 2 || root.keys[0] != 25 || root.keys[1] != 53 {
+		t.Errorf("Root node keys are incorrect after deletion: got %v, want [25, 53]", root.keys)
 	}
 
-	leftChild := root.children[0]
-	rightChild := root.children[1]
-
-	if len(leftChild.keys) != 2 || leftChild.keys[0] != 23 || leftChild.keys[1] != 35 {
-		t.Errorf("Left child node keys are incorrect after deletion: got %v, want [23, 35]", leftChild.keys)
+	want := [][]int{{18, 22, 23}, {25, 35, 42}, {53, 73, 84}}
+	if len(root.children) != len(want) {
+		t.Fatalf("Root should have %d children after deletion: got %d", len(want), len(root.children))
+	}
+	for i, child := range root.children {
+		if !slices.Equal(child.keys, want[i]) {
+			t.Errorf("Child %d node keys are incorrect after deletion: got %v, want %v", i, child.keys, want[i])
+		}
 	}
 
-	if len(rightChild.keys) != 2 || rightChild.keys[0] != 53 || rightChild.keys[1] != 84 {
-		t.Errorf("Right child node keys are incorrect after deletion: got %v, want [53, 84]", rightChild.keys)
+	for _, value := range toDelete {
+		if node, _ := tree.Search(value); node != nil {
+			t.Errorf("Search(%d) found a deleted value", value)
+		}
+		if err := tree.Delete(value); err != ErrKeyNotFound {
+			t.Errorf("deleting a value twice returned %v, want ErrKeyNotFound", err)
+		}
 	}
 }
 
@@ -76,37 +177,18 @@ func TestRebalancing(t *testing.T) {
 	tree := CreateTree(3, 4)
 
 	// Insert elements to cause multiple splits
+	// The second insertions of 35, 65, 75, 85 and 95 are rejected as duplicates
 	values := []int{15, 25, 35, 45, 55, 65, 75, 85, 95, 5, 35, 65, 75, 85, 95}
-	for _, value := range values {
-		tree.Insert(value)
-	}
+	insertAll(t, tree, values)
 
-	// Delete elements to cause multiple merges
+	// Delete elements to cause multiple merges, until the tree shrinks back to a single leaf
 	toDelete := []int{15, 25, 35, 45, 55, 65, 75, 85, 95}
-	for _, value := range toDelete {
-		tree.Delete(value)
-	}
+	deleteAll(t, tree, toDelete)
 
 	// Check tree root
 	root := tree.root
-	if len(root.keys) != 1 || root.keys[0] != 65 {
-		t.Errorf("Root node key is incorrect after rebalancing: got %v, want [65]", root.keys)
-	}
-
-	// Check children
-	if len(root.children) != 2 {
-		t.Errorf("Root should have 2 children after rebalancing: got %d", len(root.children))
-	}
-
-	leftChild := root.children[0]
-	rightChild := root.children[1]
-
-	if len(leftChild.keys) != 2 || leftChild.keys[0] != 5 || leftChild.keys[1] != 35 {
-		t.Errorf("Left child node keys are incorrect after rebalancing: got %v, want [5, 35]", leftChild.keys)
-	}
-
-	if len(rightChild.keys) != 2 || rightChild.keys[0] != 75 || rightChild.keys[1] != 95 {
-		t.Errorf("Right child node keys are incorrect after rebalancing: got %v, want [75, 95]", rightChild.keys)
+	if !root.isLeaf || len(root.keys) != 1 || root.keys[0] != 5 {
+		t.Errorf("Root should be a leaf holding [5] after rebalancing: got %v", root.keys)
 	}
 }
 
@@ -116,20 +198,16 @@ func TestMerging(t *testing.T) {
 
 	// Insert elements
 	values := []int{10, 20, 30, 40, 50, 60, 70, 80, 90}
-	for _, value := range values {
-		tree.Insert(value)
-	}
+	insertAll(t, tree, values)
 
 	// Delete elements to trigger merges
 	toDelete := []int{80, 70, 60, 50, 40}
-	for _, value := range toDelete {
-		tree.Delete(value)
-	}
+	deleteAll(t, tree, toDelete)
 
 	// Check the structure of the tree after merges
 	root := tree.root
-	if len(root.keys) != 1 || root.keys[0] != 20 {
-		t.Errorf("Root node key is incorrect after merging: got %v, want [20]", root.keys)
+	if len(root.keys) != 1 || root.keys[0] != 30 {
+		t.Errorf("Root node key is incorrect after merging: got %v, want [30]", root.keys)
 	}
 
 	if len(root.children) != 2 {
@@ -139,8 +217,8 @@ func TestMerging(t *testing.T) {
 	leftChild := root.children[0]
 	rightChild := root.children[1]
 
-	if len(leftChild.keys) != 1 || leftChild.keys[0] != 10 {
-		t.Errorf("Left child node keys are incorrect after merging: got %v, want [10]", leftChild.keys)
+	if len(leftChild.keys) != 2 || leftChild.keys[0] != 10 || leftChild.keys[1] != 20 {
+		t.Errorf("Left child node keys are incorrect after merging: got %v, want [10, 20]", leftChild.keys)
 	}
 
 	if len(rightChild.keys) != 2 || rightChild.keys[0] != 30 || rightChild.keys[1] != 90 {
@@ -168,6 +246,7 @@ func TestEdgeCases(t *testing.T) {
 	// Insert the same element multiple times
 	for i := 0; i < 5; i++ {
 		tree.Insert(10)
+		checkTree(t, tree)
 	}
 
 	if len(tree.root.keys) != 1 || tree.root.keys[0] != 10 {
@@ -190,29 +269,49 @@ func TestBorrowing(t *testing.T) {
 	tree := CreateTree(3, 4)
 
 	// Insert elements to create a scenario for borrowing
+	// The leaves are [10, 20], [30, 40] and [50, 60, 70, 80]
 	values := []int{10, 20, 30, 40, 50, 60, 70, 80}
-	for _, value := range values {
-		tree.Insert(value)
-	}
+	insertAll(t, tree, values)
 
-	// Delete to trigger borrowing
-	tree.Delete(80)
-	tree.Delete(70)
+	// Delete to trigger borrowing: [30] is short and its left sibling has no key to spare,
+	// so it takes the first key of its right sibling
+	deleteAll(t, tree, []int{40})
 
 	root := tree.root
 
-	if len(root.keys) != 1 || root.keys[0] != 40 {
-		t.Errorf("Root node key is incorrect after borrowing: got %v, want [40]", root.keys)
+	if len(root.keys) != 2 || root.keys[0] != 30 || root.keys[1] != 60 {
+		t.Errorf("Root node keys are incorrect after borrowing: got %v, want [30, 60]", root.keys)
 	}
 
-	leftChild := root.children[0]
-	rightChild := root.children[1]
-
-	if len(leftChild.keys) != 2 || leftChild.keys[0] != 10 || leftChild.keys[1] != 30 {
-		t.Errorf("Left child node keys are incorrect after borrowing: got %v, want [10, 30]", leftChild.keys)
+	want := [][]int{{10, 20}, {30, 50}, {60, 70, 80}}
+	if len(root.children) != len(want) {
+		t.Fatalf("Root should have %d children after borrowing: got %d", len(want), len(root.children))
+	}
+	for i, child := range root.children {
+		if !slices.Equal(child.keys, want[i]) {
+			t.Errorf("Child %d node keys are incorrect after borrowing: got %v, want %v", i, child.keys, want[i])
+		}
 	}
+}
 
-	if len(rightChild.keys) != 2 || rightChild.keys[0] != 50 || rightChild.keys[1] != 60 {
-		t.Errorf("Right child node keys are incorrect after borrowing: got %v, want [50, 60]", rightChild.keys)
+// Test random insertions and deletions on trees small enough to split, borrow and merge
+// internal nodes often, checking the tree after every change
+func TestRandomOperations(t *testing.T) {
+	for _, size := range [][2]int{{3, 2}, {4, 3}, {5, 4}} {
+		tree := CreateTree(size[0], size[1])
+		rng := rand.New(rand.NewSource(int64(size[0])))
+		values := rng.Perm(300)
+		insertAll(t, tree, values)
+		rng.Shuffle(len(values), func(i, j int) { values[i], values[j] = values[j], values[i] })
+		deleteAll(t, tree, values[:250])
+		for i, value := range values {
+			if node, _ := tree.Search(value); (node != nil) != (i >= 250) {
+				t.Fatalf("M=%d L=%d: Search(%d) found %v", size[0], size[1], value, node != nil)
+			}
+		}
+		deleteAll(t, tree, values[250:])
+		if !tree.root.isLeaf || len(tree.root.keys) != 0 {
+			t.Errorf("M=%d L=%d: the tree is not empty after deleting every value: %v", size[0], size[1], tree.root.keys)
+		}
 	}
 }
@@ -0,0 +1,179 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RewriteRows re-encodes every row of the table for a new list of columns. convert maps the
+// values of a row under the old columns to its values under the new ones.
+func (t *Table) RewriteRows(columns []Column, convert func(values []interface{}) ([]interface{}, error)) error {
+	rows := make([]Row, len(t.Rows))
+	for i := range t.Rows {
+		values, err := t.GetRowValues(i)
+		if err != nil {
+			return err
+		}
+		if values, err = convert(values); err != nil {
+			return fmt.Errorf("row %d: %v", i+1, err)
+		}
+		encoded, err := serializeValues(values, columns)
+		if err != nil {
+			return fmt.Errorf("row %d: %v", i+1, err)
+		}
+		rows[i] = Row{ID: t.Rows[i].ID, Values: encoded}
+	}
+	t.Columns = columns
+	t.Rows = rows
+	t.pkIndex = nil
+	return nil
+}
+
+// DropColumn removes a column and its values from every row. The column must not be part
+// of the primary key or of a foreign key.
+func (t *Table) DropColumn(index int) error {
+	name := t.Columns[index].GetName()
+	if len(t.Columns) == 1 {
+		return fmt.Errorf("cannot drop %s, the only column of table %s", name, t.GetName())
+	}
+	if t.Columns[index].IsPrimaryKey {
+		return fmt.Errorf("cannot drop column %s, it is part of the %s", name, t.PrimaryKeyString())
+	}
+	for _, fk := range t.ForeignKeys {
+		for _, column := range fk.Columns {
+			if column == index {
+				return fmt.Errorf("cannot drop column %s, it is part of foreign key %s", name, fk.Name)
+			}
+		}
+	}
+	columns := append(append([]Column(nil), t.Columns[:index]...), t.Columns[index+1:]...)
+	err := t.RewriteRows(columns, func(values []interface{}) ([]interface{}, error) {
+		return append(values[:index], values[index+1:]...), nil
+	})
+	if err != nil {
+		return err
+	}
+	shift := func(column int) int {
+		if column > index {
+			return column - 1
+		}
+		return column
+	}
+	for i := range t.PrimaryKey {
+		t.PrimaryKey[i] = shift(t.PrimaryKey[i])
+	}
+	for k := range t.ForeignKeys {
+		for i := range t.ForeignKeys[k].Columns {
+			t.ForeignKeys[k].Columns[i] = shift(t.ForeignKeys[k].Columns[i])
+		}
+	}
+	delete(t.Metadata, ColumnCommentKey(name))
+	return nil
+}
+
+// CheckNotReadByViews fails if views read the table, as a change to its columns or its name
+// would break their queries, and leave a materialized view refreshed on every change to the
+// table unable to follow it. what is the change refused, for the error.
+func (db *Database) CheckNotReadByViews(t *Table, what string) error {
+	dependents := db.DependentViews(t.GetName())
+	if len(dependents) == 0 {
+		return nil
+	}
+	name, kind := dependents[len(dependents)-1], "view"
+	if v := db.FindView(name); v != nil && v.Materialized {
+		kind = "materialized view"
+	}
+	return fmt.Errorf("cannot %s, %s %s depends on it", what, kind, name)
+}
+
+// RenameColumn renames a column of a table, updating the foreign keys that reference it
+// and its comment. The columns of a table read by views keep their names, as their
+// queries name them.
+func (db *Database) RenameColumn(t *Table, index int, name string) error {
+	if err := db.CheckNotReadByViews(t, "rename column "+t.Columns[index].GetName()+" of table "+t.GetName()); err != nil {
+		return err
+	}
+	if len(name) > len(t.Columns[index].Name) {
+		return fmt.Errorf("column name %s is longer than %d bytes", name, len(t.Columns[index].Name))
+	}
+	if other := t.ColumnIndex(name); other != -1 && other != index {
+		return fmt.Errorf("column %s already exists in table %s", name, t.GetName())
+	}
+	old := t.Columns[index].GetName()
+	for _, ref := range db.referencing(t) {
+		for i := range ref.fk.RefColumns {
+			if strings.EqualFold(ref.fk.RefColumns[i], old) {
+				ref.fk.RefColumns[i] = name
+			}
+		}
+	}
+	t.renameColumnComment(old, name)
+	t.Columns[index].Name = [64]byte{}
+	copy(t.Columns[index].Name[:], name)
+	return nil
+}
+
+// RenameTable renames a table of the database, updating the foreign keys that reference it
+// and its triggers. A table read by views keeps its name, as their queries name it.
+func (db *Database) RenameTable(t *Table, name string) error {
+	if len(name) > len(t.Name) {
+		return fmt.Errorf("table name %s is longer than %d bytes", name, len(t.Name))
+	}
+	if other := db.FindTable(name); other != nil && other != t {
+		return fmt.Errorf("table already exists: %s", name)
+	}
+	if db.FindView(name) != nil {
+		return fmt.Errorf("a view named %s already exists", name)
+	}
+	if err := db.CheckWritable(t); err != nil {
+		return err
+	}
+	if err := db.CheckNotReadByViews(t, "rename table "+t.GetName()); err != nil {
+		return err
+	}
+	for _, ref := range db.referencing(t) {
+		ref.fk.RefTable = name
+	}
+	db.renameTableTriggers(t.GetName(), name)
+	t.Name = [64]byte{}
+	copy(t.Name[:], name)
+	return nil
+}
+
+// ReferencedBy returns the names of the foreign keys of other tables, or of t itself,
+// that reference the given column of t
+func (db *Database) ReferencedBy(t *Table, column int) []string {
+	var names []string
+	for _, ref := range db.referencing(t) {
+		for _, name := range ref.fk.RefColumns {
+			if strings.EqualFold(name, t.Columns[column].GetName()) {
+				names = append(names, ref.fk.Name)
+			}
+		}
+	}
+	return names
+}
+
+// ValidateTable checks every row of a table against its primary key, NOT NULL and CHECK
+// constraints and foreign keys, after a change to the schema
+func (db *Database) ValidateTable(t *Table) error {
+	t.pkIndex = nil
+	if len(t.PrimaryKey) > 0 {
+		if _, err := t.primaryIndex(); err != nil {
+			return err
+		}
+	}
+	for i := range t.Rows {
+		values, err := t.GetRowValues(i)
+		if err != nil {
+			return err
+		}
+		if err := db.ValidateRow(t, values); err != nil {
+			return fmt.Errorf("row %d: %v", i+1, err)
+		}
+		if err := db.checkReferences(t, values); err != nil {
+			return fmt.Errorf("row %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
@@ -14,21 +14,43 @@ type CommandLevel int
 
 // Core command enum
 const (
-	CmdExit          CoreCommandType = iota // single level
-	CmdInsert                               // single level
-	CmdSelect                               // single level
-	CmdUpdate                               // single level
-	CmdDelete                               // single level
-	CmdCreate                               // multi level
-	CmdDrop                                 // multi level
-	CmdAlter                                // multi level
-	CmdGrant                                // multi level
-	CmdRevoke                               // multi level
-	CmdLock                                 // multi level
-	CmdShowDatabases                        // single level
-	CmdUse                                  // single level
-	CmdHelp                                 // single level
-	CmdUnknown                              // single level
+	CmdExit           CoreCommandType = iota // single level
+	CmdInsert                                // single level
+	CmdSelect                                // single level
+	CmdUpdate                                // single level
+	CmdDelete                                // single level
+	CmdCreate                                // multi level
+	CmdDrop                                  // multi level
+	CmdAlter                                 // multi level
+	CmdGrant                                 // multi level
+	CmdRevoke                                // multi level
+	CmdLock                                  // multi level
+	CmdShowDatabases                         // single level
+	CmdUse                                   // single level
+	CmdHelp                                  // single level
+	CmdImport                                // single level
+	CmdTruncate                              // single level
+	CmdBegin                                 // single level
+	CmdCommit                                // single level
+	CmdRollback                              // single level
+	CmdSavepoint                             // single level
+	CmdRelease                               // single level
+	CmdUnlock                                // single level
+	CmdShowGrants                            // single level
+	CmdShowViews                             // single level
+	CmdRefresh                               // single level
+	CmdCall                                  // single level
+	CmdShowProcedures                        // single level
+	CmdShowTriggers                          // single level
+	CmdAttach                                // single level
+	CmdDetach                                // single level
+	CmdShowTables                            // single level
+	CmdDescribe                              // single level
+	CmdShowCreate                            // single level
+	CmdShowIndexes                           // single level
+	CmdComment                               // single level
+	CmdPragma                                // single level
+	CmdUnknown                               // single level
 )
 
 // Create-specific command enum
@@ -40,6 +62,12 @@ const (
 	CmdCreateView
 	CmdCreateOrReplaceView
 	CmdCreateProcedure
+	CmdCreateSequence
+	CmdCreateUser
+	CmdCreateRole
+	CmdCreateMaterializedView
+	CmdCreateOrReplaceProcedure
+	CmdCreateTrigger
 	CmdCreateUnknown
 )
 
@@ -49,6 +77,12 @@ const (
 	CmdDropIndex
 	CmdDropView
 	CmdDropProcedure
+	CmdDropSequence
+	CmdDropDatabase
+	CmdDropUser
+	CmdDropRole
+	CmdDropMaterializedView
+	CmdDropTrigger
 	CmdDropUnknown
 )
 
@@ -58,6 +92,7 @@ const (
 	CmdAlterIndex
 	CmdAlterView
 	CmdAlterProcedure
+	CmdAlterUser
 	CmdAlterUnknown
 )
 
@@ -73,6 +108,8 @@ const (
 	CmdGrantIndex
 	CmdGrantView
 	CmdGrantProcedure
+	CmdGrantAll
+	CmdGrantRole
 	CmdGrantUnknown
 )
 
@@ -88,6 +125,8 @@ const (
 	CmdRevokeIndex
 	CmdRevokeView
 	CmdRevokeProcedure
+	CmdRevokeAll
+	CmdRevokeRole
 	CmdRevokeUnknown
 )
 
@@ -158,6 +197,28 @@ func (c CoreCommand) CommandName() string {
 		"show databases",
 		"use",
 		"help",
+		"import",
+		"truncate",
+		"begin",
+		"commit",
+		"rollback",
+		"savepoint",
+		"release",
+		"unlock tables",
+		"show grants",
+		"show views",
+		"refresh",
+		"call",
+		"show procedures",
+		"show triggers",
+		"attach",
+		"detach",
+		"show tables",
+		"describe",
+		"show create",
+		"show indexes",
+		"comment",
+		"pragma",
 		"unknown"}[c.command]
 }
 
@@ -238,7 +299,14 @@ func (c CreateCommand) CommandName() string {
 		"unique index",
 		"view",
 		"or replace view",
-		"procedure"}[c.command]
+		"procedure",
+		"sequence",
+		"user",
+		"role",
+		"materialized view",
+		"or replace procedure",
+		"trigger",
+		"unknown"}[c.command]
 }
 
 func (c CreateCommand) Level() CommandLevel {
@@ -259,7 +327,14 @@ func (c DropCommand) CommandName() string {
 		"table",
 		"index",
 		"view",
-		"procedure"}[c.command]
+		"procedure",
+		"sequence",
+		"database",
+		"user",
+		"role",
+		"materialized view",
+		"trigger",
+		"unknown"}[c.command]
 }
 
 func (c DropCommand) Level() CommandLevel {
@@ -280,7 +355,9 @@ func (c AlterCommand) CommandName() string {
 		"table",
 		"index",
 		"view",
-		"procedure"}[c.command]
+		"procedure",
+		"user",
+		"unknown"}[c.command]
 }
 
 func (c AlterCommand) Level() CommandLevel {
@@ -307,7 +384,10 @@ func (c GrantCommand) CommandName() string {
 		"alter",
 		"index",
 		"view",
-		"procedure"}[c.command]
+		"procedure",
+		"all",
+		"role",
+		"unknown"}[c.command]
 }
 
 func (c GrantCommand) Level() CommandLevel {
@@ -334,7 +414,10 @@ func (c RevokeCommand) CommandName() string {
 		"alter",
 		"index",
 		"view",
-		"procedure"}[c.command]
+		"procedure",
+		"all",
+		"role",
+		"unknown"}[c.command]
 }
 
 func (c RevokeCommand) Level() CommandLevel {
@@ -419,6 +502,32 @@ var CoreCommandMap = []CommandMapping{
 	{"show databases", CoreCommand{CmdShowDatabases}},
 	{"use", CoreCommand{CmdUse}},
 	{"help", CoreCommand{CmdHelp}},
+	{"import", CoreCommand{CmdImport}},
+	{"truncate", CoreCommand{CmdTruncate}},
+	{"begin", CoreCommand{CmdBegin}},
+	{"start transaction", CoreCommand{CmdBegin}},
+	{"commit", CoreCommand{CmdCommit}},
+	{"rollback", CoreCommand{CmdRollback}},
+	{"savepoint", CoreCommand{CmdSavepoint}},
+	{"release", CoreCommand{CmdRelease}},
+	{"unlock tables", CoreCommand{CmdUnlock}},
+	{"show grants", CoreCommand{CmdShowGrants}},
+	{"show views", CoreCommand{CmdShowViews}},
+	{"refresh", CoreCommand{CmdRefresh}},
+	{"call", CoreCommand{CmdCall}},
+	{"show procedures", CoreCommand{CmdShowProcedures}},
+	{"show triggers", CoreCommand{CmdShowTriggers}},
+	{"attach", CoreCommand{CmdAttach}},
+	{"detach", CoreCommand{CmdDetach}},
+	{"show tables", CoreCommand{CmdShowTables}},
+	{"describe", CoreCommand{CmdDescribe}},
+	{"desc", CoreCommand{CmdDescribe}},
+	{"show create", CoreCommand{CmdShowCreate}},
+	{"show indexes", CoreCommand{CmdShowIndexes}},
+	{"show index", CoreCommand{CmdShowIndexes}},
+	{"show keys", CoreCommand{CmdShowIndexes}},
+	{"comment", CoreCommand{CmdComment}},
+	{"pragma", CoreCommand{CmdPragma}},
 	{"unknown", CoreCommand{CmdUnknown}},
 }
 
@@ -430,6 +539,12 @@ var CreateCommandMap = []CommandMapping{
 	{"view", CreateCommand{CmdCreateView}},
 	{"or replace view", CreateCommand{CmdCreateOrReplaceView}},
 	{"procedure", CreateCommand{CmdCreateProcedure}},
+	{"or replace procedure", CreateCommand{CmdCreateOrReplaceProcedure}},
+	{"sequence", CreateCommand{CmdCreateSequence}},
+	{"user", CreateCommand{CmdCreateUser}},
+	{"role", CreateCommand{CmdCreateRole}},
+	{"materialized view", CreateCommand{CmdCreateMaterializedView}},
+	{"trigger", CreateCommand{CmdCreateTrigger}},
 	{"unknown", CreateCommand{CmdCreateUnknown}},
 }
 
@@ -438,6 +553,12 @@ var DropCommandMap = []CommandMapping{
 	{"index", DropCommand{CmdDropIndex}},
 	{"view", DropCommand{CmdDropView}},
 	{"procedure", DropCommand{CmdDropProcedure}},
+	{"sequence", DropCommand{CmdDropSequence}},
+	{"database", DropCommand{CmdDropDatabase}},
+	{"user", DropCommand{CmdDropUser}},
+	{"role", DropCommand{CmdDropRole}},
+	{"materialized view", DropCommand{CmdDropMaterializedView}},
+	{"trigger", DropCommand{CmdDropTrigger}},
 	{"unknown", DropCommand{CmdDropUnknown}},
 }
 
@@ -446,6 +567,7 @@ var AlterCommandMap = []CommandMapping{
 	{"index", AlterCommand{CmdAlterIndex}},
 	{"view", AlterCommand{CmdAlterView}},
 	{"procedure", AlterCommand{CmdAlterProcedure}},
+	{"user", AlterCommand{CmdAlterUser}},
 	{"unknown", AlterCommand{CmdAlterUnknown}},
 }
 
@@ -460,6 +582,8 @@ var GrantCommandMap = []CommandMapping{
 	{"index", GrantCommand{CmdGrantIndex}},
 	{"view", GrantCommand{CmdGrantView}},
 	{"procedure", GrantCommand{CmdGrantProcedure}},
+	{"all", GrantCommand{CmdGrantAll}},
+	{"role", GrantCommand{CmdGrantRole}},
 	{"unknown", GrantCommand{CmdGrantUnknown}},
 }
 
@@ -474,10 +598,13 @@ var RevokeCommandMap = []CommandMapping{
 	{"index", RevokeCommand{CmdRevokeIndex}},
 	{"view", RevokeCommand{CmdRevokeView}},
 	{"procedure", RevokeCommand{CmdRevokeProcedure}},
+	{"all", RevokeCommand{CmdRevokeAll}},
+	{"role", RevokeCommand{CmdRevokeRole}},
 	{"unknown", RevokeCommand{CmdRevokeUnknown}},
 }
 
 var LockCommandMap = []CommandMapping{
+	{"database", LockCommand{CmdLockDatabase}},
 	{"table", LockCommand{CmdLockTable}},
 	{"index", LockCommand{CmdLockIndex}},
 	{"view", LockCommand{CmdLockView}},
@@ -0,0 +1,60 @@
+package types
+
+import "fmt"
+
+// CheckConstraint is a CHECK constraint of a table: a condition every row must not make false
+type CheckConstraint struct {
+	Name string
+	Expr string // condition as SQL text
+}
+
+// RowValidator evaluates the SQL expressions stored in a table schema. Expressions are
+// kept as text in this package; the engine parses and evaluates them.
+type RowValidator interface {
+	// DefaultValue evaluates the DEFAULT expression of a column, converted to the column type
+	DefaultValue(t *Table, column int) (interface{}, error)
+	// Check evaluates a CHECK constraint against a row; a NULL result passes
+	Check(t *Table, check *CheckConstraint, values []interface{}) (bool, error)
+}
+
+// AddCheck adds a CHECK constraint with the given condition, naming it after the table if name is empty
+func (t *Table) AddCheck(name string, expr string) {
+	if name == "" {
+		name = fmt.Sprintf("chk_%s_%d", t.GetName(), len(t.Checks)+1)
+	}
+	t.Checks = append(t.Checks, CheckConstraint{Name: name, Expr: expr})
+}
+
+// DefaultValue returns the value a column takes when a row gives none: its DEFAULT, or NULL
+func (db *Database) DefaultValue(t *Table, column int) (interface{}, error) {
+	if t.Columns[column].Default == "" {
+		return nil, nil
+	}
+	if db.Validator == nil {
+		return nil, fmt.Errorf("cannot evaluate DEFAULT of column %s", t.Columns[column].GetName())
+	}
+	return db.Validator.DefaultValue(t, column)
+}
+
+// ValidateRow enforces the NOT NULL and CHECK constraints of a table on a new or updated row.
+// Every row written through the Database goes through it, including cascaded changes.
+func (db *Database) ValidateRow(t *Table, values []interface{}) error {
+	for i := range t.Columns {
+		if values[i] == nil && !t.Columns[i].Nullable {
+			return fmt.Errorf("column %s cannot be NULL", t.Columns[i].GetName())
+		}
+	}
+	if len(t.Checks) > 0 && db.Validator == nil {
+		return fmt.Errorf("cannot evaluate CHECK constraints of table %s", t.GetName())
+	}
+	for i := range t.Checks {
+		ok, err := db.Validator.Check(t, &t.Checks[i], values)
+		if err != nil {
+			return fmt.Errorf("CHECK constraint %s: %v", t.Checks[i].Name, err)
+		}
+		if !ok {
+			return fmt.Errorf("CHECK constraint %s (%s) failed for table %s", t.Checks[i].Name, t.Checks[i].Expr, t.GetName())
+		}
+	}
+	return nil
+}
@@ -1,11 +1,23 @@
 package types
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
+// DatabaseMagic starts every database file
+const DatabaseMagic uint32 = 0x4744424C // "GDBL"
+
+// FormatVersion is the version of the file format written. Files of other versions, and
+// files from before versions were recorded, are rejected rather than misread.
+const FormatVersion uint32 = 1
+
 type FileHeader struct {
 	MagicNumber uint32
 	Version     uint32
@@ -14,68 +26,216 @@ type FileHeader struct {
 
 type Database struct {
 	Tables     []Table
+	Sequences  []Sequence
+	Views      []View
+	Procedures []Procedure
+	Triggers   []Trigger
 	Metadata   map[string]string
 	FileHeader FileHeader
+
+	// Validator evaluates defaults and CHECK constraints; without one, tables using them cannot be written
+	Validator RowValidator
+	// Maintainer refreshes materialized views incrementally; without one, tables they read cannot be written
+	Maintainer ViewMaintainer
+	// Executor runs triggers; without one, tables with triggers cannot be written
+	Executor TriggerExecutor
+	// Attached are the other databases queries can read as name.table, by lower case name.
+	// They are not saved with the database.
+	Attached map[string]*Database
+
+	sequencesChanged bool
+	changing         []string // tables the running statement is changing, see changingTables
 }
 
 func NewDatabase() *Database {
 	return &Database{
-		Tables: make([]Table, 0),
+		Tables:     make([]Table, 0),
+		FileHeader: FileHeader{MagicNumber: DatabaseMagic, Version: FormatVersion},
 	}
 }
 
+// WriteToFile writes the database to a file. It is written to a new file beside the old one,
+// which it replaces once safely on disk, so that a failure midway leaves the old file whole.
 func (db *Database) WriteToFile(filename string) error {
-	// create the file if it does not exist
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		file, err := os.Create(filename)
-		if err != nil {
-			return fmt.Errorf("error creating database file: %v", err)
-		}
-		defer file.Close()
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode().Perm()
 	}
-	file, err := os.Create(filename)
+	file, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp*")
 	if err != nil {
 		return fmt.Errorf("error creating database file: %v", err)
 	}
+	w := bufio.NewWriter(file)
+	err = db.encode(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = file.Chmod(mode)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("error writing database file: %v", err)
+	}
+	return nil
+}
+
+func (db *Database) ReadFromFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error opening database file: %v", err)
+	}
 	defer file.Close()
+	return db.decode(bufio.NewReader(file))
+}
+
+// Clone returns a deep copy of the database, without its validator
+func (db *Database) Clone() (*Database, error) {
+	var buf bytes.Buffer
+	if err := db.encode(&buf); err != nil {
+		return nil, err
+	}
+	clone := NewDatabase()
+	if err := clone.decode(&buf); err != nil {
+		return nil, err
+	}
+	clone.sequencesChanged = db.sequencesChanged
+	return clone, nil
+}
+
+// encode writes the database in its file format
+func (db *Database) encode(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, [2]uint32{DatabaseMagic, FormatVersion}); err != nil {
+		return fmt.Errorf("error writing file header: %v", err)
+	}
 
 	// Write the number of tables
-	if err := binary.Write(file, binary.LittleEndian, uint32(len(db.Tables))); err != nil {
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(db.Tables))); err != nil {
 		return fmt.Errorf("error writing number of tables: %v", err)
 	}
 
 	// Write each table
 	for _, table := range db.Tables {
-		if _, err := table.WriteTo(file); err != nil {
+		if _, err := table.WriteTo(w); err != nil {
 			return fmt.Errorf("error writing table: %v", err)
 		}
 	}
 
-	return nil
+	// Write the sequences
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(db.Sequences))); err != nil {
+		return fmt.Errorf("error writing number of sequences: %v", err)
+	}
+	for _, seq := range db.Sequences {
+		if _, err := seq.WriteTo(w); err != nil {
+			return fmt.Errorf("error writing sequence: %v", err)
+		}
+	}
+
+	// Write the views
+	var views []View
+	for _, v := range db.Views {
+		if !v.Materialized {
+			views = append(views, v)
+		}
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(views))); err != nil {
+		return fmt.Errorf("error writing number of views: %v", err)
+	}
+	for _, v := range views {
+		if _, err := v.WriteTo(w); err != nil {
+			return fmt.Errorf("error writing view: %v", err)
+		}
+	}
+
+	if err := db.writeMaterializedViews(w); err != nil {
+		return err
+	}
+	if err := db.writeProcedures(w); err != nil {
+		return err
+	}
+	if err := db.writeTriggers(w); err != nil {
+		return err
+	}
+	return db.writeMetadata(w)
 }
 
-func (db *Database) ReadFromFile(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("error opening database file: %v", err)
+// decode reads a database written by encode
+func (db *Database) decode(r io.Reader) error {
+	var header [2]uint32
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return fmt.Errorf("error reading file header: %v", err)
 	}
-	defer file.Close()
+	if header[0] != DatabaseMagic {
+		return fmt.Errorf("not a database file, or one written by a version that did not record its format")
+	}
+	if header[1] != FormatVersion {
+		return fmt.Errorf("unsupported database file format version %d, this version reads version %d", header[1], FormatVersion)
+	}
+	db.FileHeader.MagicNumber, db.FileHeader.Version = header[0], header[1]
 
 	// Read the number of tables
 	var numTables uint32
-	if err := binary.Read(file, binary.LittleEndian, &numTables); err != nil {
+	if err := binary.Read(r, binary.LittleEndian, &numTables); err != nil {
 		return fmt.Errorf("error reading number of tables: %v", err)
 	}
 
 	// Read each table
 	db.Tables = make([]Table, numTables)
+	db.FileHeader.TableCount = numTables
 	for i := range db.Tables {
-		if _, err := db.Tables[i].ReadFrom(file); err != nil {
+		if _, err := db.Tables[i].ReadFrom(r); err != nil {
 			return fmt.Errorf("error reading table: %v", err)
 		}
 	}
 
-	return nil
+	// Read the sequences; files written before sequences existed end here
+	var numSequences uint32
+	if err := binary.Read(r, binary.LittleEndian, &numSequences); err == io.EOF {
+		numSequences = 0
+	} else if err != nil {
+		return fmt.Errorf("error reading number of sequences: %v", err)
+	}
+	db.Sequences = make([]Sequence, numSequences)
+	for i := range db.Sequences {
+		if _, err := db.Sequences[i].ReadFrom(r); err != nil {
+			return fmt.Errorf("error reading sequence: %v", err)
+		}
+	}
+	db.sequencesChanged = false
+
+	// Read the views; files written before views existed end here
+	var numViews uint32
+	if err := binary.Read(r, binary.LittleEndian, &numViews); err == io.EOF {
+		numViews = 0
+	} else if err != nil {
+		return fmt.Errorf("error reading number of views: %v", err)
+	}
+	db.Views = make([]View, numViews)
+	for i := range db.Views {
+		if _, err := db.Views[i].ReadFrom(r); err != nil {
+			return fmt.Errorf("error reading view: %v", err)
+		}
+	}
+
+	if err := db.readMaterializedViews(r); err != nil {
+		return err
+	}
+	if err := db.readProcedures(r); err != nil {
+		return err
+	}
+	if err := db.readTriggers(r); err != nil {
+		return err
+	}
+	return db.readMetadata(r)
 }
 
 // printDatabase prints the database exhaustively, in a beautiful manner
@@ -84,8 +244,8 @@ func (db *Database) PrintDatabase() {
 	fmt.Printf("Magic Number: 0x%X\n", db.FileHeader.MagicNumber)
 	fmt.Println("Version:", db.FileHeader.Version)
 	fmt.Println("\nMetadata:")
-	for key, value := range db.Metadata {
-		fmt.Printf("  %s: %s\n", key, value)
+	for _, key := range MetadataKeys(db.Metadata) {
+		fmt.Printf("  %s: %s\n", key, db.Metadata[key])
 	}
 	fmt.Println("\nTables:")
 	for _, table := range db.Tables {
@@ -94,12 +254,36 @@ func (db *Database) PrintDatabase() {
 
 		// Print table metadata
 		fmt.Println("Metadata:")
-		for key, value := range table.Metadata {
-			fmt.Printf("  %s: %s\n", key, value)
+		for _, key := range MetadataKeys(table.Metadata) {
+			fmt.Printf("  %s: %s\n", key, table.Metadata[key])
 		}
 
 		table.PrintTableMetadata()
 	}
+	if len(db.Sequences) > 0 {
+		fmt.Println("\nSequences:")
+		for _, seq := range db.Sequences {
+			fmt.Printf("  %s\n", seq.String())
+		}
+	}
+	if len(db.Views) > 0 {
+		fmt.Println("\nViews:")
+		for _, v := range db.Views {
+			fmt.Printf("  %s\n", v.String())
+		}
+	}
+	if len(db.Procedures) > 0 {
+		fmt.Println("\nProcedures:")
+		for _, p := range db.Procedures {
+			fmt.Printf("  %s %s\n", p.Name, p.Signature())
+		}
+	}
+	if len(db.Triggers) > 0 {
+		fmt.Println("\nTriggers:")
+		for _, tr := range db.Triggers {
+			fmt.Printf("  %s %s %s ON %s\n", tr.Name, tr.Timing, tr.Event, tr.Table)
+		}
+	}
 }
 
 // AddTable adds a table to the database
@@ -107,3 +291,84 @@ func (db *Database) AddTable(table Table) {
 	db.Tables = append(db.Tables, table)
 	db.FileHeader.TableCount++
 }
+
+// DropTable removes a table and its rows from the database. A table still referenced by a
+// foreign key of another table or read by a view, or holding a materialized view, cannot be
+// dropped. Its triggers are dropped with it. The space of its rows is reclaimed when
+// the database is next written, as the file is rewritten in full.
+func (db *Database) DropTable(name string) error {
+	t := db.FindTable(name)
+	if t == nil {
+		return fmt.Errorf("table not found: %s", name)
+	}
+	for _, ref := range db.referencing(t) {
+		if ref.child != t {
+			return fmt.Errorf("cannot drop table %s, it is referenced by foreign key %s of table %s", t.GetName(), ref.fk.Name, ref.child.GetName())
+		}
+	}
+	if db.isMaterialized(t.GetName()) {
+		return fmt.Errorf("%s is a materialized view; use DROP MATERIALIZED VIEW", t.GetName())
+	}
+	if err := db.checkNoDependentViews("table", t.GetName()); err != nil {
+		return err
+	}
+	db.dropTableTriggers(t.GetName())
+	db.removeTable(t)
+	return nil
+}
+
+func (db *Database) removeTable(t *Table) {
+	for i := range db.Tables {
+		if &db.Tables[i] == t {
+			db.Tables = append(db.Tables[:i], db.Tables[i+1:]...)
+			break
+		}
+	}
+	db.FileHeader.TableCount--
+}
+
+// TruncateTable removes every row of a table and restarts its identity counter. Rows of a
+// table referenced by foreign keys of other tables can only be removed with DELETE.
+func (db *Database) TruncateTable(t *Table) error {
+	if err := db.CheckWritable(t); err != nil {
+		return err
+	}
+	for _, ref := range db.referencing(t) {
+		if ref.child != t {
+			return fmt.Errorf("cannot truncate table %s, it is referenced by foreign key %s of table %s", t.GetName(), ref.fk.Name, ref.child.GetName())
+		}
+	}
+	if db.maintained(t) {
+		for r := range t.Rows {
+			values, err := t.GetRowValues(r)
+			if err != nil {
+				return err
+			}
+			if err := db.rowChanged(t, values, nil); err != nil {
+				return err
+			}
+		}
+	}
+	t.Rows = nil
+	t.AutoIncrement = 0
+	t.pkIndex = nil
+	return nil
+}
+
+// AttachedDatabase returns the database attached under the given name, ignoring case
+func (db *Database) AttachedDatabase(name string) (*Database, error) {
+	if other := db.Attached[strings.ToLower(name)]; other != nil {
+		return other, nil
+	}
+	return nil, fmt.Errorf("database not attached: %s", name)
+}
+
+// FindTable returns the table with the given name, ignoring case, or nil
+func (db *Database) FindTable(name string) *Table {
+	for i := range db.Tables {
+		if strings.EqualFold(db.Tables[i].GetName(), name) {
+			return &db.Tables[i]
+		}
+	}
+	return nil
+}
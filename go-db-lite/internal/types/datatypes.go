@@ -1,5 +1,7 @@
 package types
 
+import "strings"
+
 type DataType uint32
 
 const (
@@ -74,12 +76,25 @@ func (d DataType) GetDataTypeString() string {
 	return dataTypeToString[d]
 }
 
-// GetDataTypeFromString returns the data type corresponding to the given string representation
+// dataTypeAliases maps alternative type names to their canonical data type
+var dataTypeAliases = map[string]DataType{
+	"INTEGER": SQL_TYPE_INT,
+	"BOOLEAN": SQL_TYPE_BOOL,
+	"NUMERIC": SQL_TYPE_DECIMAL,
+	"DEC":     SQL_TYPE_DECIMAL,
+	"REAL":    SQL_TYPE_DOUBLE,
+}
+
+// GetDataTypeFromString returns the data type corresponding to the given string representation (case insensitive)
 func GetDataTypeFromString(dataTypeStr string) DataType {
+	dataTypeStr = strings.ToUpper(dataTypeStr)
 	for key, value := range dataTypeToString {
 		if value == dataTypeStr {
 			return key
 		}
 	}
+	if dataType, ok := dataTypeAliases[dataTypeStr]; ok {
+		return dataType
+	}
 	return SQL_TYPE_UNKNOWN
 }
@@ -0,0 +1,346 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sessionLocation is the time zone TIMESTAMP values are entered and displayed in
+var sessionLocation = time.Local
+
+// SetSessionLocation sets the time zone used to read and print TIMESTAMP values
+func SetSessionLocation(loc *time.Location) {
+	sessionLocation = loc
+}
+
+// SessionLocation returns the time zone used to read and print TIMESTAMP values
+func SessionLocation() *time.Location {
+	return sessionLocation
+}
+
+// LoadLocation resolves a time zone name such as "UTC", "Europe/Paris", "Local" or a fixed offset such as "+05:30"
+func LoadLocation(name string) (*time.Location, error) {
+	if offset, ok := parseZoneOffset(name); ok {
+		return time.FixedZone(name, offset), nil
+	}
+	return time.LoadLocation(name)
+}
+
+// DateTime is a DATE, DATETIME or TIMESTAMP value. DATE and DATETIME are wall clock values without a
+// time zone; TIMESTAMP is an instant that is displayed in the session time zone. The time is always kept in UTC.
+type DateTime struct {
+	t    time.Time
+	kind DataType
+}
+
+// TimeOfDay is a TIME value: a signed time of day or elapsed time with microsecond precision
+type TimeOfDay time.Duration
+
+// Interval is an amount of calendar time, as in INTERVAL 3 MONTH or INTERVAL 90 MINUTE
+type Interval struct {
+	Months int64
+	Micros int64
+}
+
+// MaxTimeOfDay is the largest magnitude of a TIME value (838:59:59, as in MySQL)
+const MaxTimeOfDay = TimeOfDay(838*time.Hour + 59*time.Minute + 59*time.Second)
+
+// NewDateTime creates a temporal value of the given kind. DATE drops the time of day, TIMESTAMP keeps the instant,
+// and DATE/DATETIME keep the wall clock of t.
+func NewDateTime(t time.Time, kind DataType) DateTime {
+	switch kind {
+	case SQL_TYPE_TIMESTAMP:
+		t = t.UTC()
+	case SQL_TYPE_DATE:
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	default:
+		kind = SQL_TYPE_DATETIME
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+	return DateTime{t: t.Truncate(time.Microsecond), kind: kind}
+}
+
+// NewDateTimeFromMicros creates a temporal value from its stored representation (microseconds since the epoch)
+func NewDateTimeFromMicros(micros int64, kind DataType) DateTime {
+	return DateTime{t: time.UnixMicro(micros).UTC(), kind: kind}
+}
+
+// Kind returns SQL_TYPE_DATE, SQL_TYPE_DATETIME or SQL_TYPE_TIMESTAMP
+func (d DateTime) Kind() DataType {
+	return d.kind
+}
+
+// UnixMicro returns the stored representation of the value: microseconds since the epoch
+// (of the wall clock read as UTC for DATE and DATETIME, of the instant for TIMESTAMP)
+func (d DateTime) UnixMicro() int64 {
+	return d.t.UnixMicro()
+}
+
+// Time returns the value as a time.Time: in the session time zone for TIMESTAMP, in UTC otherwise
+func (d DateTime) Time() time.Time {
+	if d.kind == SQL_TYPE_TIMESTAMP {
+		return d.t.In(sessionLocation)
+	}
+	return d.t
+}
+
+// Wall returns the wall clock reading of the value in UTC, so values of different kinds can be compared
+func (d DateTime) Wall() time.Time {
+	local := d.Time()
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+}
+
+// WithWall returns a value of the same kind whose wall clock reading is the given time (read as UTC)
+func (d DateTime) WithWall(wall time.Time) DateTime {
+	if d.kind == SQL_TYPE_TIMESTAMP {
+		local := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), sessionLocation)
+		return NewDateTime(local, SQL_TYPE_TIMESTAMP)
+	}
+	return NewDateTime(wall, d.kind)
+}
+
+// Compare orders two temporal values; TIMESTAMP and wall clock values are compared in the session time zone
+func (d DateTime) Compare(other DateTime) int {
+	if (d.kind == SQL_TYPE_TIMESTAMP) == (other.kind == SQL_TYPE_TIMESTAMP) {
+		return d.t.Compare(other.t)
+	}
+	return d.Wall().Compare(other.Wall())
+}
+
+// String returns the canonical ISO-8601 style representation: 2006-01-02, 2006-01-02 15:04:05[.ffffff],
+// and for TIMESTAMP the session time zone offset, e.g. 2006-01-02 15:04:05+05:30
+func (d DateTime) String() string {
+	t := d.Time()
+	switch d.kind {
+	case SQL_TYPE_DATE:
+		return t.Format("2006-01-02")
+	case SQL_TYPE_TIMESTAMP:
+		return t.Format("2006-01-02 15:04:05.999999") + formatZoneOffset(t)
+	}
+	return t.Format("2006-01-02 15:04:05.999999")
+}
+
+// formatZoneOffset formats the UTC offset of t as +hh:mm
+func formatZoneOffset(t time.Time) string {
+	_, offset := t.Zone()
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, offset/3600, offset%3600/60)
+}
+
+// Duration returns the TIME value as a time.Duration
+func (t TimeOfDay) Duration() time.Duration {
+	return time.Duration(t)
+}
+
+// String returns the canonical representation of a TIME value: [-]hh:mm:ss[.ffffff]
+func (t TimeOfDay) String() string {
+	d := time.Duration(t)
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	hours := d / time.Hour
+	minutes := d % time.Hour / time.Minute
+	seconds := d % time.Minute / time.Second
+	micros := d % time.Second / time.Microsecond
+	s := fmt.Sprintf("%s%02d:%02d:%02d", sign, hours, minutes, seconds)
+	if micros != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%06d", micros), "0")
+	}
+	return s
+}
+
+// String returns the interval as INTERVAL syntax, e.g. "1 MONTH 36000000000 MICROSECOND"
+func (i Interval) String() string {
+	var parts []string
+	if i.Months != 0 {
+		parts = append(parts, fmt.Sprintf("%d MONTH", i.Months))
+	}
+	if i.Micros != 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%d MICROSECOND", i.Micros))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Negate returns the interval pointing the other way
+func (i Interval) Negate() Interval {
+	return Interval{Months: -i.Months, Micros: -i.Micros}
+}
+
+// intervalUnits maps INTERVAL units to their length: months for calendar units, microseconds otherwise
+var intervalUnits = map[string]Interval{
+	"MICROSECOND": {Micros: 1},
+	"MILLISECOND": {Micros: 1000},
+	"SECOND":      {Micros: int64(time.Second / time.Microsecond)},
+	"MINUTE":      {Micros: int64(time.Minute / time.Microsecond)},
+	"HOUR":        {Micros: int64(time.Hour / time.Microsecond)},
+	"DAY":         {Micros: int64(24 * time.Hour / time.Microsecond)},
+	"WEEK":        {Micros: int64(7 * 24 * time.Hour / time.Microsecond)},
+	"MONTH":       {Months: 1},
+	"QUARTER":     {Months: 3},
+	"YEAR":        {Months: 12},
+}
+
+// NewInterval creates an interval of n units, e.g. NewInterval(3, "MONTH")
+func NewInterval(n int64, unit string) (Interval, error) {
+	unit = strings.TrimSuffix(strings.ToUpper(unit), "S")
+	size, ok := intervalUnits[unit]
+	if !ok {
+		return Interval{}, fmt.Errorf("unknown interval unit %s", unit)
+	}
+	return Interval{Months: n * size.Months, Micros: n * size.Micros}, nil
+}
+
+// AddInterval adds an interval to a temporal value. Adding months clamps to the end of the month
+// (2024-01-31 + 1 MONTH = 2024-02-29); adding a sub-day amount to a DATE produces a DATETIME.
+func (d DateTime) AddInterval(i Interval) DateTime {
+	wall := d.Wall()
+	if i.Months != 0 {
+		year, month, day := wall.Date()
+		total := int64(year)*12 + int64(month-1) + i.Months
+		newYear, newMonth := int(total/12), time.Month(total%12+1)
+		if last := daysIn(newYear, newMonth); day > last {
+			day = last
+		}
+		wall = time.Date(newYear, newMonth, day, wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), time.UTC)
+	}
+	kind := d.kind
+	if kind == SQL_TYPE_DATE && i.Micros%int64(24*time.Hour/time.Microsecond) != 0 {
+		kind = SQL_TYPE_DATETIME
+	}
+	if d.kind == SQL_TYPE_TIMESTAMP {
+		// calendar arithmetic happens on the wall clock, exact arithmetic on the instant
+		return d.WithWall(wall).addMicros(i.Micros)
+	}
+	return NewDateTime(wall.Add(time.Duration(i.Micros)*time.Microsecond), kind)
+}
+
+func (d DateTime) addMicros(micros int64) DateTime {
+	return DateTime{t: d.t.Add(time.Duration(micros) * time.Microsecond), kind: d.kind}
+}
+
+// daysIn returns the number of days in the given month
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+var dateTimePattern = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})(?:[ Tt](\d{1,2}):(\d{2})(?::(\d{2})(?:\.(\d{1,9}))?)?)?\s*([Zz]|[+-]\d{2}(?::?\d{2})?)?$`)
+var timePattern = regexp.MustCompile(`^(-)?(\d{1,3}):(\d{2})(?::(\d{2})(?:\.(\d{1,9}))?)?$`)
+
+// ParseDateTime parses an ISO-8601 date or date-time into a value of the given kind.
+// A time zone designator (Z, +hh:mm) is honoured for TIMESTAMP and DATETIME; without one,
+// a TIMESTAMP is read in the session time zone.
+func ParseDateTime(s string, kind DataType) (DateTime, error) {
+	m := dateTimePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return DateTime{}, fmt.Errorf("invalid %s value: %q", kind.GetDataTypeString(), s)
+	}
+	fields := make([]int, 6)
+	for i := 1; i <= 6; i++ {
+		if m[i] != "" {
+			fields[i-1], _ = strconv.Atoi(m[i])
+		}
+	}
+	nanos := 0
+	if m[7] != "" {
+		frac := (m[7] + "000000000")[:9]
+		nanos, _ = strconv.Atoi(frac)
+	}
+	if kind == SQL_TYPE_DATE && m[4] != "" && (fields[3] != 0 || fields[4] != 0 || fields[5] != 0 || nanos != 0) {
+		return DateTime{}, fmt.Errorf("invalid DATE value: %q has a time of day", s)
+	}
+
+	loc := time.UTC
+	if kind == SQL_TYPE_TIMESTAMP {
+		loc = sessionLocation
+	}
+	if m[8] != "" {
+		offset, _ := parseZoneOffset(m[8])
+		loc = time.FixedZone("", offset)
+	}
+	t := time.Date(fields[0], time.Month(fields[1]), fields[2], fields[3], fields[4], fields[5], nanos, loc)
+	// reject out of range fields such as 2023-02-30 or 25:00, which time.Date would normalise
+	if t.Year() != fields[0] || int(t.Month()) != fields[1] || t.Day() != fields[2] ||
+		t.Hour() != fields[3] || t.Minute() != fields[4] || t.Second() != fields[5] {
+		return DateTime{}, fmt.Errorf("invalid %s value: %q is out of range", kind.GetDataTypeString(), s)
+	}
+	if kind == SQL_TYPE_DATETIME && m[8] != "" {
+		// a DATETIME has no zone: convert the given instant to the session wall clock
+		t = t.In(sessionLocation)
+	}
+	return NewDateTime(t, kind), nil
+}
+
+// ParseTimeOfDay parses a TIME value such as 15:04, 15:04:05, 15:04:05.123 or -100:00:00
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	m := timePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid TIME value: %q", s)
+	}
+	hours, _ := strconv.Atoi(m[2])
+	minutes, _ := strconv.Atoi(m[3])
+	seconds := 0
+	if m[4] != "" {
+		seconds, _ = strconv.Atoi(m[4])
+	}
+	if minutes > 59 || seconds > 59 {
+		return 0, fmt.Errorf("invalid TIME value: %q is out of range", s)
+	}
+	d := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	if m[5] != "" {
+		frac, _ := strconv.Atoi((m[5] + "000000000")[:9])
+		d += time.Duration(frac)
+	}
+	d = d.Truncate(time.Microsecond)
+	if m[1] == "-" {
+		d = -d
+	}
+	if TimeOfDay(d) > MaxTimeOfDay || TimeOfDay(d) < -MaxTimeOfDay {
+		return 0, fmt.Errorf("invalid TIME value: %q is out of range", s)
+	}
+	return TimeOfDay(d), nil
+}
+
+// ParseYear parses a YEAR value: 1901 to 2155, or 0
+func ParseYear(s string) (int64, error) {
+	year, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || (year != 0 && (year < 1901 || year > 2155)) {
+		return 0, fmt.Errorf("invalid YEAR value: %q", s)
+	}
+	return year, nil
+}
+
+// parseZoneOffset parses Z, +hh, +hhmm or +hh:mm into seconds east of UTC
+func parseZoneOffset(s string) (int, bool) {
+	if s == "Z" || s == "z" {
+		return 0, true
+	}
+	if len(s) < 3 || (s[0] != '+' && s[0] != '-') {
+		return 0, false
+	}
+	digits := strings.ReplaceAll(s[1:], ":", "")
+	if len(digits) != 2 && len(digits) != 4 {
+		return 0, false
+	}
+	hours, err := strconv.Atoi(digits[:2])
+	if err != nil {
+		return 0, false
+	}
+	minutes := 0
+	if len(digits) == 4 {
+		if minutes, err = strconv.Atoi(digits[2:]); err != nil {
+			return 0, false
+		}
+	}
+	offset := hours*3600 + minutes*60
+	if s[0] == '-' {
+		offset = -offset
+	}
+	return offset, true
+}
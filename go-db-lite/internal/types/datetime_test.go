@@ -0,0 +1,65 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+// Test parsing, range checking and formatting of dates and times
+func TestParseDateTime(t *testing.T) {
+	SetSessionLocation(time.UTC)
+	defer SetSessionLocation(time.Local)
+
+	tests := []struct {
+		input string
+		kind  DataType
+		want  string
+	}{
+		{"2024-02-29", SQL_TYPE_DATE, "2024-02-29"},
+		{"2024-01-31 10:15", SQL_TYPE_DATETIME, "2024-01-31 10:15:00"},
+		{"2024-01-31T10:15:30.25", SQL_TYPE_DATETIME, "2024-01-31 10:15:30.25"},
+		{"2024-01-31T10:15:30+02:00", SQL_TYPE_TIMESTAMP, "2024-01-31 08:15:30+00:00"},
+		{"2024-01-31 10:15:30Z", SQL_TYPE_DATETIME, "2024-01-31 10:15:30"},
+	}
+	for _, test := range tests {
+		got, err := ParseDateTime(test.input, test.kind)
+		if err != nil {
+			t.Errorf("ParseDateTime(%q): %v", test.input, err)
+			continue
+		}
+		if got.String() != test.want {
+			t.Errorf("ParseDateTime(%q) = %s, want %s", test.input, got, test.want)
+		}
+	}
+
+	for _, input := range []string{"2023-02-29", "2024-13-01", "2024-01-01 24:00", "01/02/2024", "2024-01-01 10:00"} {
+		if _, err := ParseDateTime(input, SQL_TYPE_DATE); err == nil {
+			t.Errorf("ParseDateTime(%q) as DATE should fail", input)
+		}
+	}
+
+	tod, err := ParseTimeOfDay("-100:30:15.5")
+	if err != nil || tod.String() != "-100:30:15.5" {
+		t.Errorf("ParseTimeOfDay = %s, %v", tod, err)
+	}
+}
+
+// Test interval arithmetic, including clamping to the end of the month
+func TestAddInterval(t *testing.T) {
+	d, _ := ParseDateTime("2024-01-31", SQL_TYPE_DATE)
+	month, _ := NewInterval(1, "MONTH")
+	if got := d.AddInterval(month).String(); got != "2024-02-29" {
+		t.Errorf("2024-01-31 + 1 month = %s", got)
+	}
+	years, _ := NewInterval(-1, "YEARS")
+	if got := d.AddInterval(month).AddInterval(years).String(); got != "2023-02-28" {
+		t.Errorf("2024-02-29 - 1 year = %s", got)
+	}
+	hours, _ := NewInterval(36, "HOUR")
+	if got := d.AddInterval(hours).String(); got != "2024-02-01 12:00:00" {
+		t.Errorf("2024-01-31 + 36 hours = %s", got)
+	}
+	if _, err := NewInterval(1, "FORTNIGHT"); err == nil {
+		t.Error("NewInterval should reject unknown units")
+	}
+}
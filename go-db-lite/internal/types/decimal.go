@@ -0,0 +1,291 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// MaxDecimalPrecision is the largest number of digits a DECIMAL column may declare
+const MaxDecimalPrecision = 65
+
+// MaxDecimalScale is the largest number of fractional digits a DECIMAL column may declare
+const MaxDecimalScale = 30
+
+// DefaultDecimalPrecision and DefaultDecimalScale are used for a bare DECIMAL declaration
+const (
+	DefaultDecimalPrecision = 10
+	DefaultDecimalScale     = 0
+)
+
+// DivisionScaleIncrement is the number of extra fractional digits a division result carries
+const DivisionScaleIncrement = 4
+
+// Decimal is an exact fixed-point number, stored as an unscaled integer and a scale
+// (the value is unscaled * 10^-scale). The zero value is 0 with scale 0.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+var bigTen = big.NewInt(10)
+
+// NewDecimal creates a decimal from an unscaled integer and a scale
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	return Decimal{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+// NewDecimalFromBigInt creates a decimal from an unscaled big integer and a scale
+func NewDecimalFromBigInt(unscaled *big.Int, scale int32) Decimal {
+	return Decimal{unscaled: new(big.Int).Set(unscaled), scale: scale}
+}
+
+// ParseDecimal parses a plain decimal literal such as "-12.50", "+3", ".5" or "1.25e2"
+func ParseDecimal(s string) (Decimal, error) {
+	str := strings.TrimSpace(s)
+	if str == "" {
+		return Decimal{}, fmt.Errorf("invalid decimal value: %q", s)
+	}
+
+	exponent := int64(0)
+	if i := strings.IndexAny(str, "eE"); i != -1 {
+		var exp big.Int
+		if _, ok := exp.SetString(str[i+1:], 10); !ok || !exp.IsInt64() {
+			return Decimal{}, fmt.Errorf("invalid decimal value: %q", s)
+		}
+		exponent = exp.Int64()
+		str = str[:i]
+	}
+
+	negative := false
+	if str != "" && (str[0] == '-' || str[0] == '+') {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+
+	intPart, fracPart := str, ""
+	if i := strings.IndexByte(str, '.'); i != -1 {
+		intPart, fracPart = str[:i], str[i+1:]
+	}
+	digits := intPart + fracPart
+	if digits == "" {
+		return Decimal{}, fmt.Errorf("invalid decimal value: %q", s)
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return Decimal{}, fmt.Errorf("invalid decimal value: %q", s)
+		}
+	}
+
+	unscaled, _ := new(big.Int).SetString(digits, 10)
+	if negative {
+		unscaled.Neg(unscaled)
+	}
+	scale := int64(len(fracPart)) - exponent
+	if scale < 0 {
+		unscaled.Mul(unscaled, pow10(int32(-scale)))
+		scale = 0
+	}
+	if scale > MaxDecimalScale*2 {
+		return Decimal{}, fmt.Errorf("decimal value has too many fractional digits: %q", s)
+	}
+	return Decimal{unscaled: unscaled, scale: int32(scale)}, nil
+}
+
+// DecimalFromFloat converts a float to a decimal rounded to the given scale
+func DecimalFromFloat(f float64, scale int32) (Decimal, error) {
+	d, err := ParseDecimal(fmt.Sprintf("%.*f", scale+1, f))
+	if err != nil {
+		return Decimal{}, err
+	}
+	return d.Round(scale), nil
+}
+
+// pow10 returns 10^n as a big integer
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) bigInt() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// Scale returns the number of fractional digits of the decimal
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Unscaled returns a copy of the unscaled integer value
+func (d Decimal) Unscaled() *big.Int {
+	return new(big.Int).Set(d.bigInt())
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of the decimal
+func (d Decimal) Sign() int {
+	return d.bigInt().Sign()
+}
+
+// IntegerDigits returns the number of digits to the left of the decimal point (0 for |d| < 1)
+func (d Decimal) IntegerDigits() int {
+	intPart := new(big.Int).Quo(new(big.Int).Abs(d.bigInt()), pow10(d.scale))
+	if intPart.Sign() == 0 {
+		return 0
+	}
+	return len(intPart.String())
+}
+
+// Round rounds the decimal to the given scale, half away from zero. Increasing the scale pads with zeros.
+func (d Decimal) Round(scale int32) Decimal {
+	if scale == d.scale {
+		return d
+	}
+	if scale > d.scale {
+		return Decimal{unscaled: new(big.Int).Mul(d.bigInt(), pow10(scale-d.scale)), scale: scale}
+	}
+	divisor := pow10(d.scale - scale)
+	quo, rem := new(big.Int).QuoRem(d.bigInt(), divisor, new(big.Int))
+	// round half away from zero: compare 2*|rem| against the divisor
+	rem.Abs(rem).Mul(rem, big.NewInt(2))
+	if rem.Cmp(divisor) >= 0 {
+		if d.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	return Decimal{unscaled: quo, scale: scale}
+}
+
+// Truncate drops fractional digits beyond the given scale without rounding
+func (d Decimal) Truncate(scale int32) Decimal {
+	if scale >= d.scale {
+		return d.Round(scale)
+	}
+	return Decimal{unscaled: new(big.Int).Quo(d.bigInt(), pow10(d.scale-scale)), scale: scale}
+}
+
+// align returns the unscaled values of a and b at their common (larger) scale
+func align(a, b Decimal) (*big.Int, *big.Int, int32) {
+	if a.scale == b.scale {
+		return a.bigInt(), b.bigInt(), a.scale
+	}
+	if a.scale > b.scale {
+		return a.bigInt(), b.Round(a.scale).bigInt(), a.scale
+	}
+	return a.Round(b.scale).bigInt(), b.bigInt(), b.scale
+}
+
+// Add returns d + other, exactly
+func (d Decimal) Add(other Decimal) Decimal {
+	x, y, scale := align(d, other)
+	return Decimal{unscaled: new(big.Int).Add(x, y), scale: scale}
+}
+
+// Sub returns d - other, exactly
+func (d Decimal) Sub(other Decimal) Decimal {
+	x, y, scale := align(d, other)
+	return Decimal{unscaled: new(big.Int).Sub(x, y), scale: scale}
+}
+
+// Mul returns d * other, exactly (the result scale is the sum of both scales)
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(d.bigInt(), other.bigInt()), scale: d.scale + other.scale}
+}
+
+// Quo returns d / other rounded half away from zero to the given scale
+func (d Decimal) Quo(other Decimal, scale int32) (Decimal, error) {
+	if other.Sign() == 0 {
+		return Decimal{}, fmt.Errorf("division by zero")
+	}
+	// d / other = (d.u * 10^(scale + 1 + other.scale - d.scale)) / other.u, computed with one guard digit
+	shift := scale + 1 + other.scale - d.scale
+	num := new(big.Int).Set(d.bigInt())
+	den := new(big.Int).Set(other.bigInt())
+	if shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	quo := new(big.Int).Quo(num, den)
+	return Decimal{unscaled: quo, scale: scale + 1}.Round(scale), nil
+}
+
+// Div returns d / other with the scale of the dividend plus DivisionScaleIncrement
+func (d Decimal) Div(other Decimal) (Decimal, error) {
+	return d.Quo(other, d.scale+DivisionScaleIncrement)
+}
+
+// IntDiv returns the integer part of d / other (truncated toward zero) as a decimal of scale 0
+func (d Decimal) IntDiv(other Decimal) (Decimal, error) {
+	if other.Sign() == 0 {
+		return Decimal{}, fmt.Errorf("division by zero")
+	}
+	x, y, _ := align(d, other)
+	return Decimal{unscaled: new(big.Int).Quo(x, y), scale: 0}, nil
+}
+
+// Mod returns the remainder of d / other, which has the sign of d
+func (d Decimal) Mod(other Decimal) (Decimal, error) {
+	if other.Sign() == 0 {
+		return Decimal{}, fmt.Errorf("division by zero")
+	}
+	x, y, scale := align(d, other)
+	return Decimal{unscaled: new(big.Int).Rem(x, y), scale: scale}, nil
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(d.bigInt()), scale: d.scale}
+}
+
+// Abs returns |d|
+func (d Decimal) Abs() Decimal {
+	return Decimal{unscaled: new(big.Int).Abs(d.bigInt()), scale: d.scale}
+}
+
+// Cmp compares d and other numerically and returns -1, 0 or 1
+func (d Decimal) Cmp(other Decimal) int {
+	x, y, _ := align(d, other)
+	return x.Cmp(y)
+}
+
+// Float64 returns the nearest float64 to the decimal
+func (d Decimal) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(d.bigInt(), pow10(d.scale)).Float64()
+	return f
+}
+
+// Int64 returns the integer part of the decimal, and false if it does not fit in an int64
+func (d Decimal) Int64() (int64, bool) {
+	intPart := new(big.Int).Quo(d.bigInt(), pow10(d.scale))
+	return intPart.Int64(), intPart.IsInt64()
+}
+
+// String returns the canonical representation of the decimal, keeping all scale digits (e.g. "12.50")
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.bigInt()).String()
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale <= 0 {
+		return sign + digits
+	}
+	if len(digits) <= int(d.scale) {
+		digits = strings.Repeat("0", int(d.scale)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(d.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// FitDecimal rounds d to the column's scale and checks that it fits the column's precision
+func FitDecimal(d Decimal, precision, scale int) (Decimal, error) {
+	rounded := d.Round(int32(scale))
+	if rounded.IntegerDigits() > precision-scale {
+		return Decimal{}, fmt.Errorf("value %s out of range for DECIMAL(%d,%d)", d.String(), precision, scale)
+	}
+	return rounded, nil
+}
@@ -0,0 +1,99 @@
+package types
+
+import (
+	"testing"
+)
+
+func mustDecimal(t *testing.T, s string) Decimal {
+	t.Helper()
+	d, err := ParseDecimal(s)
+	if err != nil {
+		t.Fatalf("ParseDecimal(%q) returned error: %v", s, err)
+	}
+	return d
+}
+
+// Test parsing and canonical formatting of decimal literals
+func TestParseDecimal(t *testing.T) {
+	cases := map[string]string{
+		"12.50":   "12.50",
+		"-0.5":    "-0.5",
+		".25":     "0.25",
+		"+7":      "7",
+		"1.25e2":  "125",
+		"1.5e-2":  "0.015",
+		"0000.10": "0.10",
+	}
+	for input, want := range cases {
+		if got := mustDecimal(t, input).String(); got != want {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"", "abc", "1.2.3", "-", "1e"} {
+		if _, err := ParseDecimal(input); err == nil {
+			t.Errorf("ParseDecimal(%q) should fail", input)
+		}
+	}
+}
+
+// Test that arithmetic is exact, unlike binary floating point
+func TestDecimalArithmetic(t *testing.T) {
+	a, b := mustDecimal(t, "0.1"), mustDecimal(t, "0.2")
+	if got := a.Add(b).String(); got != "0.3" {
+		t.Errorf("0.1 + 0.2 = %s, want 0.3", got)
+	}
+	if got := mustDecimal(t, "19.99").Mul(mustDecimal(t, "3")).String(); got != "59.97" {
+		t.Errorf("19.99 * 3 = %s, want 59.97", got)
+	}
+	if got := mustDecimal(t, "10.00").Sub(mustDecimal(t, "0.01")).String(); got != "9.99" {
+		t.Errorf("10.00 - 0.01 = %s, want 9.99", got)
+	}
+
+	quo, err := mustDecimal(t, "10").Div(mustDecimal(t, "3"))
+	if err != nil || quo.String() != "3.3333" {
+		t.Errorf("10 / 3 = %s (%v), want 3.3333", quo, err)
+	}
+	quo, err = mustDecimal(t, "2.00").Div(mustDecimal(t, "3"))
+	if err != nil || quo.String() != "0.666667" {
+		t.Errorf("2.00 / 3 = %s (%v), want 0.666667", quo, err)
+	}
+	if _, err := a.Div(Decimal{}); err == nil {
+		t.Errorf("division by zero should fail")
+	}
+
+	if a.Cmp(b) != -1 || b.Cmp(a) != 1 || mustDecimal(t, "1.50").Cmp(mustDecimal(t, "1.5")) != 0 {
+		t.Errorf("Cmp returned inconsistent results")
+	}
+}
+
+// Test rounding half away from zero and precision checks used on insert
+func TestDecimalRounding(t *testing.T) {
+	cases := []struct {
+		input string
+		scale int32
+		want  string
+	}{
+		{"2.345", 2, "2.35"},
+		{"-2.345", 2, "-2.35"},
+		{"2.344", 2, "2.34"},
+		{"0.5", 0, "1"},
+		{"-0.5", 0, "-1"},
+		{"1.2", 3, "1.200"},
+	}
+	for _, c := range cases {
+		if got := mustDecimal(t, c.input).Round(c.scale).String(); got != c.want {
+			t.Errorf("Round(%s, %d) = %s, want %s", c.input, c.scale, got, c.want)
+		}
+	}
+
+	if got, err := FitDecimal(mustDecimal(t, "12.505"), 5, 2); err != nil || got.String() != "12.51" {
+		t.Errorf("FitDecimal(12.505, 5, 2) = %s (%v), want 12.51", got, err)
+	}
+	if _, err := FitDecimal(mustDecimal(t, "1234.5"), 5, 2); err == nil {
+		t.Errorf("FitDecimal(1234.5, 5, 2) should be out of range")
+	}
+	if _, err := FitDecimal(mustDecimal(t, "999.995"), 5, 2); err == nil {
+		t.Errorf("FitDecimal(999.995, 5, 2) should be out of range after rounding")
+	}
+}
@@ -0,0 +1,133 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	MaxEnumLabels = 65535 // ENUM values are stored as a 16 bit ordinal
+	MaxSetLabels  = 64    // SET values are stored as a 64 bit mask
+)
+
+// ValidateLabels checks the declared value list of an ENUM or SET column
+func (c *Column) ValidateLabels() error {
+	typeName := c.DataType.GetDataTypeString()
+	if len(c.Labels) == 0 {
+		return fmt.Errorf("%s needs at least one value", typeName)
+	}
+	if c.DataType == SQL_TYPE_ENUM && len(c.Labels) > MaxEnumLabels {
+		return fmt.Errorf("ENUM can have at most %d values", MaxEnumLabels)
+	}
+	if c.DataType == SQL_TYPE_SET && len(c.Labels) > MaxSetLabels {
+		return fmt.Errorf("SET can have at most %d values", MaxSetLabels)
+	}
+	for i, label := range c.Labels {
+		if len(label) > math.MaxUint16 {
+			return fmt.Errorf("%s value %q is too long", typeName, label)
+		}
+		if c.DataType == SQL_TYPE_SET && strings.Contains(label, ",") {
+			return fmt.Errorf("SET value %q cannot contain a comma", label)
+		}
+		for _, other := range c.Labels[:i] {
+			if strings.EqualFold(label, other) {
+				return fmt.Errorf("duplicate %s value %q", typeName, label)
+			}
+		}
+	}
+	return nil
+}
+
+// labelIndex returns the position of label in the declared value list, ignoring case, or -1
+func (c *Column) labelIndex(label string) int {
+	for i, declared := range c.Labels {
+		if strings.EqualFold(declared, label) {
+			return i
+		}
+	}
+	return -1
+}
+
+// EnumValue validates s against the ENUM value list and returns the declared label
+func (c *Column) EnumValue(s string) (string, error) {
+	i := c.labelIndex(s)
+	if i == -1 {
+		return "", fmt.Errorf("%s is not one of %s", quoteLabel(s), c.labelList())
+	}
+	return c.Labels[i], nil
+}
+
+// SetValue validates a comma separated list of SET members and returns it
+// with duplicates removed, in declaration order
+func (c *Column) SetValue(s string) (string, error) {
+	mask, err := c.setMask(s)
+	if err != nil {
+		return "", err
+	}
+	return c.setLabels(mask), nil
+}
+
+func (c *Column) setMask(s string) (uint64, error) {
+	var mask uint64
+	if s == "" {
+		return 0, nil
+	}
+	for _, member := range strings.Split(s, ",") {
+		i := c.labelIndex(strings.TrimSpace(member))
+		if i == -1 {
+			return 0, fmt.Errorf("%s is not one of %s", quoteLabel(member), c.labelList())
+		}
+		mask |= 1 << uint(i)
+	}
+	return mask, nil
+}
+
+func (c *Column) setLabels(mask uint64) string {
+	var members []string
+	for i, label := range c.Labels {
+		if mask&(1<<uint(i)) != 0 {
+			members = append(members, label)
+		}
+	}
+	return strings.Join(members, ",")
+}
+
+// encodeLabels returns the stored form of an ENUM or SET value: the 1-based ordinal or the member bitmask
+func (c *Column) encodeLabels(value string) (uint64, error) {
+	if c.DataType == SQL_TYPE_SET {
+		return c.setMask(value)
+	}
+	i := c.labelIndex(value)
+	if i == -1 {
+		return 0, fmt.Errorf("%s is not one of %s", quoteLabel(value), c.labelList())
+	}
+	return uint64(i + 1), nil
+}
+
+// decodeLabels converts a stored ordinal or bitmask back to its labels
+func (c *Column) decodeLabels(stored uint64) (string, error) {
+	if c.DataType == SQL_TYPE_SET {
+		if len(c.Labels) < MaxSetLabels && stored>>uint(len(c.Labels)) != 0 {
+			return "", fmt.Errorf("SET bitmask %#x out of range for column %s", stored, c.GetName())
+		}
+		return c.setLabels(stored), nil
+	}
+	if stored == 0 || stored > uint64(len(c.Labels)) {
+		return "", fmt.Errorf("ENUM ordinal %d out of range for column %s", stored, c.GetName())
+	}
+	return c.Labels[stored-1], nil
+}
+
+// labelList formats the declared value list, e.g. ('open','closed')
+func (c *Column) labelList() string {
+	quoted := make([]string, len(c.Labels))
+	for i, label := range c.Labels {
+		quoted[i] = quoteLabel(label)
+	}
+	return "(" + strings.Join(quoted, ",") + ")"
+}
+
+func quoteLabel(label string) string {
+	return "'" + strings.ReplaceAll(label, "'", "''") + "'"
+}
@@ -0,0 +1,39 @@
+package types
+
+import "testing"
+
+// Test that ENUM and SET values are validated, stored as ordinals/bitmasks and read back as labels
+func TestEnumSetRoundTrip(t *testing.T) {
+	table := Table{}
+	table.CreateTable("tickets", nil)
+	table.AddColumn("status", SQL_TYPE_ENUM, false)
+	table.AddColumn("tags", SQL_TYPE_SET, true)
+	table.Columns[0].Labels = []string{"open", "Closed"}
+	table.Columns[1].Labels = []string{"a", "b", "c"}
+
+	status, err := table.Columns[0].EnumValue("CLOSED")
+	if err != nil || status != "Closed" {
+		t.Fatalf("EnumValue(CLOSED) = %q, %v", status, err)
+	}
+	if _, err := table.Columns[0].EnumValue("pending"); err == nil {
+		t.Error("EnumValue should reject undeclared labels")
+	}
+	tags, err := table.Columns[1].SetValue("c, a,c")
+	if err != nil || tags != "a,c" {
+		t.Fatalf("SetValue(c, a,c) = %q, %v", tags, err)
+	}
+
+	if err := table.AddRow([]interface{}{status, tags}); err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows[0].Values) != 1+2*4+2+8 {
+		t.Errorf("ENUM and SET should be stored as a 2 byte ordinal and an 8 byte mask, got %d bytes", len(table.Rows[0].Values))
+	}
+	values, err := table.GetRowValues(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "Closed" || values[1] != "a,c" {
+		t.Errorf("round trip = %v", values)
+	}
+}
@@ -0,0 +1,655 @@
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ReferentialAction is what happens to referencing rows when the referenced key is deleted or updated
+type ReferentialAction uint8
+
+const (
+	ActionNoAction ReferentialAction = iota // checked like RESTRICT
+	ActionRestrict
+	ActionCascade
+	ActionSetNull
+	ActionSetDefault
+)
+
+// maxCascadeDepth bounds chains of cascading updates, which may loop through self-referencing tables
+const maxCascadeDepth = 16
+
+func (a ReferentialAction) String() string {
+	switch a {
+	case ActionRestrict:
+		return "RESTRICT"
+	case ActionCascade:
+		return "CASCADE"
+	case ActionSetNull:
+		return "SET NULL"
+	case ActionSetDefault:
+		return "SET DEFAULT"
+	}
+	return "NO ACTION"
+}
+
+// ParseReferentialAction converts the action of an ON DELETE or ON UPDATE clause; an empty string is NO ACTION
+func ParseReferentialAction(s string) (ReferentialAction, error) {
+	switch strings.ToUpper(s) {
+	case "", "NO ACTION":
+		return ActionNoAction, nil
+	case "RESTRICT":
+		return ActionRestrict, nil
+	case "CASCADE":
+		return ActionCascade, nil
+	case "SET NULL":
+		return ActionSetNull, nil
+	case "SET DEFAULT":
+		return ActionSetDefault, nil
+	}
+	return ActionNoAction, fmt.Errorf("unknown referential action %s", s)
+}
+
+// ForeignKey is a FOREIGN KEY constraint of a table. A foreign key always references the
+// primary key of its parent table; Columns[i] holds the value of the i-th primary key column.
+type ForeignKey struct {
+	Name       string
+	Columns    []int    // column indexes in the referencing table
+	RefTable   string   // referenced (parent) table
+	RefColumns []string // referenced columns, in primary key order
+	OnDelete   ReferentialAction
+	OnUpdate   ReferentialAction
+}
+
+// String describes the constraint, e.g. FOREIGN KEY (customer_id) REFERENCES customers (id) ON DELETE CASCADE
+func (fk *ForeignKey) String(t *Table) string {
+	names := make([]string, len(fk.Columns))
+	for i, column := range fk.Columns {
+		names[i] = t.Columns[column].GetName()
+	}
+	s := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)", strings.Join(names, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "))
+	if fk.OnDelete != ActionNoAction {
+		s += " ON DELETE " + fk.OnDelete.String()
+	}
+	if fk.OnUpdate != ActionNoAction {
+		s += " ON UPDATE " + fk.OnUpdate.String()
+	}
+	return s
+}
+
+// parentKey encodes the foreign key values of a referencing row as a primary key of the parent table.
+// It reports false when any of the values is NULL, in which case the row references nothing.
+func (fk *ForeignKey) parentKey(parent *Table, values []interface{}) ([]byte, bool, error) {
+	keyValues := make([]interface{}, len(parent.Columns))
+	for i, column := range fk.Columns {
+		if values[column] == nil {
+			return nil, false, nil
+		}
+		keyValues[parent.PrimaryKey[i]] = values[column]
+	}
+	key, err := parent.encodeKey(keyValues, parent.PrimaryKey)
+	return key, err == nil, err
+}
+
+func (fk *ForeignKey) WriteTo(w io.Writer) (int64, error) {
+	written, err := writeString(w, fk.Name)
+	if err != nil {
+		return written, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(fk.Columns))); err != nil {
+		return written, err
+	}
+	for _, column := range fk.Columns {
+		if err := binary.Write(w, binary.LittleEndian, uint16(column)); err != nil {
+			return written, err
+		}
+	}
+	written += int64(2 + 2*len(fk.Columns))
+	n, err := writeString(w, fk.RefTable)
+	written += n
+	if err != nil {
+		return written, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(fk.RefColumns))); err != nil {
+		return written, err
+	}
+	written += 2
+	for _, column := range fk.RefColumns {
+		n, err := writeString(w, column)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	if err := binary.Write(w, binary.LittleEndian, [2]uint8{uint8(fk.OnDelete), uint8(fk.OnUpdate)}); err != nil {
+		return written, err
+	}
+	return written + 2, nil
+}
+
+func (fk *ForeignKey) ReadFrom(r io.Reader) (int64, error) {
+	var read, n int64
+	var err error
+	if fk.Name, read, err = readString(r); err != nil {
+		return read, err
+	}
+	var count uint16
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return read, err
+	}
+	fk.Columns = make([]int, count)
+	for i := range fk.Columns {
+		var column uint16
+		if err := binary.Read(r, binary.LittleEndian, &column); err != nil {
+			return read, err
+		}
+		fk.Columns[i] = int(column)
+	}
+	read += int64(2 + 2*count)
+	fk.RefTable, n, err = readString(r)
+	read += n
+	if err != nil {
+		return read, err
+	}
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return read, err
+	}
+	read += 2
+	fk.RefColumns = make([]string, count)
+	for i := range fk.RefColumns {
+		fk.RefColumns[i], n, err = readString(r)
+		read += n
+		if err != nil {
+			return read, err
+		}
+	}
+	var actions [2]uint8
+	if err := binary.Read(r, binary.LittleEndian, &actions); err != nil {
+		return read, err
+	}
+	fk.OnDelete, fk.OnUpdate = ReferentialAction(actions[0]), ReferentialAction(actions[1])
+	return read + 2, nil
+}
+
+// AddForeignKey declares the named columns as a foreign key referencing refColumns of refTable.
+// The reference is resolved and checked by Database.ValidateForeignKeys.
+func (t *Table) AddForeignKey(name string, columns []string, refTable string, refColumns []string, onDelete, onUpdate ReferentialAction) error {
+	if name == "" {
+		name = fmt.Sprintf("fk_%s_%d", t.GetName(), len(t.ForeignKeys)+1)
+	}
+	fk := ForeignKey{Name: name, RefTable: strings.ToLower(refTable), OnDelete: onDelete, OnUpdate: onUpdate}
+	for _, columnName := range columns {
+		i := t.ColumnIndex(columnName)
+		if i == -1 {
+			return fmt.Errorf("foreign key column %s does not exist", columnName)
+		}
+		fk.Columns = append(fk.Columns, i)
+	}
+	for _, column := range refColumns {
+		fk.RefColumns = append(fk.RefColumns, strings.ToLower(column))
+	}
+	t.ForeignKeys = append(t.ForeignKeys, fk)
+	return nil
+}
+
+// ValidateForeignKeys resolves the foreign keys of a table about to be added to the database.
+// Each must reference the full primary key of an existing table, or of t itself, with columns
+// of the same types. Omitted referenced columns default to the primary key.
+func (db *Database) ValidateForeignKeys(t *Table) error {
+	for k := range t.ForeignKeys {
+		fk := &t.ForeignKeys[k]
+		parent := t
+		if !strings.EqualFold(fk.RefTable, t.GetName()) {
+			if parent = db.FindTable(fk.RefTable); parent == nil {
+				return fmt.Errorf("foreign key %s references unknown table %s", fk.Name, fk.RefTable)
+			}
+		}
+		if len(parent.PrimaryKey) == 0 {
+			return fmt.Errorf("foreign key %s: table %s has no primary key to reference", fk.Name, fk.RefTable)
+		}
+		if len(fk.RefColumns) == 0 {
+			for _, column := range parent.PrimaryKey {
+				fk.RefColumns = append(fk.RefColumns, parent.Columns[column].GetName())
+			}
+		}
+		if len(fk.Columns) != len(fk.RefColumns) {
+			return fmt.Errorf("foreign key %s has %d columns but references %d", fk.Name, len(fk.Columns), len(fk.RefColumns))
+		}
+		if len(fk.RefColumns) != len(parent.PrimaryKey) {
+			return fmt.Errorf("foreign key %s must reference %s of table %s", fk.Name, parent.PrimaryKeyString(), fk.RefTable)
+		}
+		// put the columns in primary key order
+		columns := make([]int, len(fk.Columns))
+		refColumns := make([]string, len(fk.RefColumns))
+		for i, name := range fk.RefColumns {
+			ref := parent.ColumnIndex(name)
+			position := -1
+			for p, column := range parent.PrimaryKey {
+				if column == ref {
+					position = p
+				}
+			}
+			if position == -1 || refColumns[position] != "" {
+				return fmt.Errorf("foreign key %s must reference %s of table %s", fk.Name, parent.PrimaryKeyString(), fk.RefTable)
+			}
+			child, refCol := &t.Columns[fk.Columns[i]], &parent.Columns[ref]
+			if child.DataType != refCol.DataType || !compatibleKeyColumns(child, refCol) {
+				return fmt.Errorf("foreign key %s: column %s %s does not match referenced column %s.%s %s",
+					fk.Name, child.GetName(), child.TypeString(), fk.RefTable, refCol.GetName(), refCol.TypeString())
+			}
+			if fk.OnDelete == ActionSetNull || fk.OnUpdate == ActionSetNull {
+				if !child.Nullable {
+					return fmt.Errorf("foreign key %s: SET NULL needs column %s to be nullable", fk.Name, child.GetName())
+				}
+			}
+			columns[position] = fk.Columns[i]
+			refColumns[position] = refCol.GetName()
+		}
+		fk.Columns, fk.RefColumns = columns, refColumns
+	}
+	return nil
+}
+
+// compatibleKeyColumns reports whether key values of two columns of the same type are encoded alike
+func compatibleKeyColumns(a, b *Column) bool {
+	switch a.DataType {
+	case SQL_TYPE_DECIMAL:
+		return a.Scale == b.Scale
+	case SQL_TYPE_BINARY, SQL_TYPE_ENUM, SQL_TYPE_SET:
+		return a.TypeString() == b.TypeString()
+	}
+	return true
+}
+
+// InsertRow adds a row to a table of the database, generating its identity value and checking
+// its constraints and foreign keys. The BEFORE INSERT triggers of the table run before the
+// row is checked and may change it; the AFTER INSERT triggers run once it is added.
+func (db *Database) InsertRow(t *Table, values []interface{}) error {
+	if err := db.CheckWritable(t); err != nil {
+		return err
+	}
+	defer db.changingTables(t)()
+	if err := t.fillIdentity(values); err != nil {
+		return err
+	}
+	if err := db.fireTriggers(t, "BEFORE", "INSERT", nil, values); err != nil {
+		return err
+	}
+	if err := db.ValidateRow(t, values); err != nil {
+		return err
+	}
+	if err := db.checkReferences(t, values); err != nil {
+		return err
+	}
+	if err := t.AddRow(values); err != nil {
+		return err
+	}
+	if err := db.rowChanged(t, nil, values); err != nil {
+		return err
+	}
+	return db.fireTriggers(t, "AFTER", "INSERT", nil, values)
+}
+
+// checkReferences checks that every foreign key of a row refers to an existing parent row.
+// Foreign keys with a NULL column reference nothing and are not checked.
+func (db *Database) checkReferences(t *Table, values []interface{}) error {
+	for k := range t.ForeignKeys {
+		fk := &t.ForeignKeys[k]
+		parent := db.FindTable(fk.RefTable)
+		if parent == nil {
+			return fmt.Errorf("foreign key %s references unknown table %s", fk.Name, fk.RefTable)
+		}
+		key, ok, err := fk.parentKey(parent, values)
+		if err != nil || !ok {
+			return err
+		}
+		if parent == t && len(t.PrimaryKey) > 0 {
+			// a row may reference itself
+			if own, err := t.encodeKey(values, t.PrimaryKey); err == nil && bytes.Equal(own, key) {
+				continue
+			}
+		}
+		found, err := parent.hasPrimaryKey(key)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("cannot add or update a child row: foreign key %s fails, %s", fk.Name, fk.String(t))
+		}
+	}
+	return nil
+}
+
+// reference is a foreign key of a table referencing some other table
+type reference struct {
+	child *Table
+	fk    *ForeignKey
+}
+
+// referencing returns the foreign keys of all tables that reference the given table
+func (db *Database) referencing(parent *Table) []reference {
+	var refs []reference
+	for i := range db.Tables {
+		child := &db.Tables[i]
+		for k := range child.ForeignKeys {
+			if strings.EqualFold(child.ForeignKeys[k].RefTable, parent.GetName()) {
+				refs = append(refs, reference{child, &child.ForeignKeys[k]})
+			}
+		}
+	}
+	return refs
+}
+
+// matchingRows returns the rows of the child table whose foreign key is one of the given parent keys
+func (ref reference) matchingRows(parent *Table, keys map[string]bool) ([]int, error) {
+	var rows []int
+	for r := range ref.child.Rows {
+		values, err := ref.child.GetRowValues(r)
+		if err != nil {
+			return nil, err
+		}
+		key, ok, err := ref.fk.parentKey(parent, values)
+		if err != nil {
+			return nil, err
+		}
+		if ok && keys[string(key)] {
+			rows = append(rows, r)
+		}
+	}
+	return rows, nil
+}
+
+// referencedError is returned when a parent row cannot be changed because it is still referenced
+func referencedError(ref reference) error {
+	return fmt.Errorf("cannot delete or update a parent row: foreign key %s of table %s fails, %s",
+		ref.fk.Name, ref.child.GetName(), ref.fk.String(ref.child))
+}
+
+// applyAction sets the foreign key columns of a referencing row for SET NULL, SET DEFAULT or,
+// given the new values of the parent row, CASCADE
+func (db *Database) applyAction(ref reference, action ReferentialAction, values []interface{}, parent *Table, parentValues []interface{}) error {
+	for i, column := range ref.fk.Columns {
+		switch action {
+		case ActionCascade:
+			values[column] = parentValues[parent.PrimaryKey[i]]
+		case ActionSetNull:
+			values[column] = nil
+		case ActionSetDefault:
+			value, err := db.DefaultValue(ref.child, column)
+			if err != nil {
+				return err
+			}
+			values[column] = value
+		}
+	}
+	return nil
+}
+
+// UpdateRow replaces the values of a row of a table in the database. The constraints and foreign
+// keys of the row are checked, and when its primary key changes the ON UPDATE actions of referencing rows are applied.
+// Rows updated by those actions fire their UPDATE triggers as well.
+func (db *Database) UpdateRow(t *Table, row int, values []interface{}) error {
+	if err := db.CheckWritable(t); err != nil {
+		return err
+	}
+	return db.updateRow(t, row, values, 0)
+}
+
+func (db *Database) updateRow(t *Table, row int, values []interface{}, depth int) error {
+	if depth > maxCascadeDepth {
+		return fmt.Errorf("foreign key cascade exceeds %d levels", maxCascadeDepth)
+	}
+	var old []interface{}
+	if db.maintained(t) || db.hasTriggers(t, "UPDATE") {
+		var err error
+		if old, err = t.GetRowValues(row); err != nil {
+			return err
+		}
+	}
+	changing := []*Table{t}
+	for _, ref := range db.referencing(t) {
+		changing = append(changing, ref.child)
+	}
+	defer db.changingTables(changing...)()
+	if err := db.fireTriggers(t, "BEFORE", "UPDATE", old, values); err != nil {
+		return err
+	}
+	if err := db.ValidateRow(t, values); err != nil {
+		return err
+	}
+	if err := db.checkReferences(t, values); err != nil {
+		return err
+	}
+	var refs []reference
+	var children [][]int
+	if len(t.PrimaryKey) > 0 {
+		old, err := t.GetRowValues(row)
+		if err != nil {
+			return err
+		}
+		oldKey, err := t.encodeKey(old, t.PrimaryKey)
+		if err != nil {
+			return err
+		}
+		newKey, err := t.encodeKey(values, t.PrimaryKey)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(oldKey, newKey) {
+			keys := map[string]bool{string(oldKey): true}
+			for _, ref := range db.referencing(t) {
+				rows, err := ref.matchingRows(t, keys)
+				if err != nil {
+					return err
+				}
+				if ref.child == t {
+					// the row's own reference is part of the new values
+					rows = removeRow(rows, row)
+				}
+				if len(rows) == 0 {
+					continue
+				}
+				if ref.fk.OnUpdate == ActionRestrict || ref.fk.OnUpdate == ActionNoAction {
+					return referencedError(ref)
+				}
+				refs = append(refs, ref)
+				children = append(children, rows)
+			}
+		}
+	}
+	if err := t.UpdateRow(row, values); err != nil {
+		return err
+	}
+	if db.maintained(t) {
+		if err := db.rowChanged(t, old, values); err != nil {
+			return err
+		}
+	}
+	for i, ref := range refs {
+		for _, r := range children[i] {
+			childValues, err := ref.child.GetRowValues(r)
+			if err != nil {
+				return err
+			}
+			if err := db.applyAction(ref, ref.fk.OnUpdate, childValues, t, values); err != nil {
+				return err
+			}
+			if err := db.updateRow(ref.child, r, childValues, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return db.fireTriggers(t, "AFTER", "UPDATE", old, values)
+}
+
+func removeRow(rows []int, row int) []int {
+	kept := rows[:0]
+	for _, r := range rows {
+		if r != row {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// DeleteRows deletes rows of a table in the database, applying the ON DELETE actions of
+// referencing rows: CASCADE deletes them too, SET NULL and SET DEFAULT update them, and
+// RESTRICT and NO ACTION fail unless the referencing row is deleted as well. The BEFORE
+// DELETE triggers of every deleted row run before any row is changed, and the AFTER DELETE
+// triggers once they are all gone.
+func (db *Database) DeleteRows(t *Table, rows []int) error {
+	if err := db.CheckWritable(t); err != nil {
+		return err
+	}
+	deleted := map[*Table]map[int]bool{t: {}}
+	pending := map[*Table][]int{}
+	for _, r := range rows {
+		if !deleted[t][r] {
+			deleted[t][r] = true
+			pending[t] = append(pending[t], r)
+		}
+	}
+	updates := map[*Table]map[int][]interface{}{}
+	type referencingRow struct {
+		ref reference
+		row int
+	}
+	var blocked []referencingRow
+
+	for len(pending) > 0 {
+		next := map[*Table][]int{}
+		for parent, parentRows := range pending {
+			if len(parent.PrimaryKey) == 0 {
+				continue
+			}
+			keys := make(map[string]bool, len(parentRows))
+			for _, r := range parentRows {
+				values, err := parent.GetRowValues(r)
+				if err != nil {
+					return err
+				}
+				key, err := parent.encodeKey(values, parent.PrimaryKey)
+				if err != nil {
+					return err
+				}
+				keys[string(key)] = true
+			}
+			for _, ref := range db.referencing(parent) {
+				matches, err := ref.matchingRows(parent, keys)
+				if err != nil {
+					return err
+				}
+				if deleted[ref.child] == nil {
+					deleted[ref.child] = map[int]bool{}
+				}
+				for _, r := range matches {
+					if deleted[ref.child][r] {
+						continue
+					}
+					switch ref.fk.OnDelete {
+					case ActionCascade:
+						deleted[ref.child][r] = true
+						next[ref.child] = append(next[ref.child], r)
+					case ActionSetNull, ActionSetDefault:
+						if updates[ref.child] == nil {
+							updates[ref.child] = map[int][]interface{}{}
+						}
+						values, ok := updates[ref.child][r]
+						if !ok {
+							if values, err = ref.child.GetRowValues(r); err != nil {
+								return err
+							}
+						}
+						if err := db.applyAction(ref, ref.fk.OnDelete, values, parent, nil); err != nil {
+							return err
+						}
+						updates[ref.child][r] = values
+					default:
+						blocked = append(blocked, referencingRow{ref, r})
+					}
+				}
+			}
+		}
+		pending = next
+	}
+
+	// a restricted reference is fine when the referencing row goes as well
+	for _, b := range blocked {
+		if !deleted[b.ref.child][b.row] {
+			return referencedError(b.ref)
+		}
+	}
+	var changing []*Table
+	for table := range deleted {
+		changing = append(changing, table)
+	}
+	for table := range updates {
+		changing = append(changing, table)
+	}
+	defer db.changingTables(changing...)()
+	type deletedRow struct {
+		table  *Table
+		values []interface{}
+	}
+	var fired []deletedRow
+	for i := range db.Tables {
+		table := &db.Tables[i]
+		if len(deleted[table]) == 0 || !db.hasTriggers(table, "DELETE") {
+			continue
+		}
+		rows := make([]int, 0, len(deleted[table]))
+		for r := range deleted[table] {
+			rows = append(rows, r)
+		}
+		sort.Ints(rows)
+		for _, r := range rows {
+			values, err := table.GetRowValues(r)
+			if err != nil {
+				return err
+			}
+			if err := db.fireTriggers(table, "BEFORE", "DELETE", values, nil); err != nil {
+				return err
+			}
+			fired = append(fired, deletedRow{table, values})
+		}
+	}
+	for child, rows := range updates {
+		for r, values := range rows {
+			if deleted[child][r] {
+				continue
+			}
+			if err := db.updateRow(child, r, values, 1); err != nil {
+				return err
+			}
+		}
+	}
+	for table, rows := range deleted {
+		indexes := make([]int, 0, len(rows))
+		for r := range rows {
+			indexes = append(indexes, r)
+			if !db.maintained(table) {
+				continue
+			}
+			values, err := table.GetRowValues(r)
+			if err != nil {
+				return err
+			}
+			if err := db.rowChanged(table, values, nil); err != nil {
+				return err
+			}
+		}
+		if len(indexes) > 0 {
+			table.DeleteRows(indexes)
+		}
+	}
+	for _, d := range fired {
+		if err := db.fireTriggers(d.table, "AFTER", "DELETE", d.values, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
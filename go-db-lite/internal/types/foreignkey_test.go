@@ -0,0 +1,105 @@
+package types
+
+import "testing"
+
+// Test that foreign keys are checked on insert and that ON DELETE actions reach referencing rows
+func TestForeignKeys(t *testing.T) {
+	db := NewDatabase()
+	parent := Table{}
+	parent.CreateTable("parent", nil)
+	parent.AddColumn("id", SQL_TYPE_INT, false)
+	if err := parent.SetPrimaryKey([]string{"id"}); err != nil {
+		t.Fatal(err)
+	}
+	db.AddTable(parent)
+
+	for _, action := range []ReferentialAction{ActionCascade, ActionSetNull, ActionRestrict} {
+		child := Table{}
+		child.CreateTable("child_"+action.String(), nil)
+		child.AddColumn("parent_id", SQL_TYPE_INT, true)
+		if err := child.AddForeignKey("", []string{"parent_id"}, "PARENT", nil, action, ActionNoAction); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.ValidateForeignKeys(&child); err != nil {
+			t.Fatal(err)
+		}
+		db.AddTable(child)
+	}
+	p := db.FindTable("parent")
+	for _, id := range []int64{1, 2} {
+		if err := db.InsertRow(p, []interface{}{id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i < len(db.Tables); i++ {
+		for _, id := range []interface{}{int64(1), int64(2), nil} {
+			if err := db.InsertRow(&db.Tables[i], []interface{}{id}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := db.InsertRow(&db.Tables[i], []interface{}{int64(3)}); err == nil {
+			t.Errorf("%s: inserting a reference to a missing parent should fail", db.Tables[i].GetName())
+		}
+	}
+
+	if err := db.DeleteRows(p, []int{0}); err == nil {
+		t.Fatal("deleting a parent row referenced under RESTRICT should fail")
+	}
+	restricted := db.FindTable("child_RESTRICT")
+	if err := db.DeleteRows(restricted, []int{0}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DeleteRows(p, []int{0}); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Rows) != 1 {
+		t.Errorf("parent has %d rows, want 1", len(p.Rows))
+	}
+	if cascade := db.FindTable("child_CASCADE"); len(cascade.Rows) != 2 {
+		t.Errorf("CASCADE child has %d rows, want 2", len(cascade.Rows))
+	}
+	setNull := db.FindTable("child_SET NULL")
+	if value, _ := setNull.GetColumnValue(0, 0); value != nil {
+		t.Errorf("SET NULL child references %v, want NULL", value)
+	}
+}
+
+// Test that a referenced table can be neither dropped nor truncated until its children are gone
+func TestDropReferencedTable(t *testing.T) {
+	db := NewDatabase()
+	for _, name := range []string{"parent", "child"} {
+		table := Table{}
+		table.CreateTable(name, nil)
+		table.AddColumn("id", SQL_TYPE_INT, false)
+		if err := table.SetPrimaryKey([]string{"id"}); err != nil {
+			t.Fatal(err)
+		}
+		if name == "child" {
+			if err := table.AddForeignKey("", []string{"id"}, "parent", nil, ActionNoAction, ActionNoAction); err != nil {
+				t.Fatal(err)
+			}
+		}
+		db.AddTable(table)
+	}
+	if err := db.InsertRow(db.FindTable("parent"), []interface{}{int64(1)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.TruncateTable(db.FindTable("parent")); err == nil {
+		t.Error("truncating a referenced table should fail")
+	}
+	if err := db.DropTable("parent"); err == nil {
+		t.Error("dropping a referenced table should fail")
+	}
+	if err := db.DropTable("child"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.TruncateTable(db.FindTable("parent")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DropTable("parent"); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.Tables) != 0 || db.FileHeader.TableCount != 0 {
+		t.Errorf("%d tables remain, header counts %d", len(db.Tables), db.FileHeader.TableCount)
+	}
+}
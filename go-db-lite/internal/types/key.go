@@ -0,0 +1,213 @@
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/tree"
+)
+
+// SetPrimaryKey declares the named columns, in key order, as the primary key of the table.
+// Key columns are made NOT NULL.
+func (t *Table) SetPrimaryKey(names []string) error {
+	if len(t.PrimaryKey) > 0 {
+		return fmt.Errorf("multiple primary keys defined for table %s", t.GetName())
+	}
+	key := make([]int, 0, len(names))
+	for _, name := range names {
+		i := t.ColumnIndex(name)
+		if i == -1 {
+			return fmt.Errorf("primary key column %s does not exist", name)
+		}
+		for _, existing := range key {
+			if existing == i {
+				return fmt.Errorf("column %s appears twice in the primary key", name)
+			}
+		}
+		key = append(key, i)
+	}
+	for _, i := range key {
+		t.Columns[i].IsPrimaryKey = true
+		t.Columns[i].Nullable = false
+	}
+	t.PrimaryKey = key
+	t.pkIndex = nil
+	return nil
+}
+
+// ColumnIndex returns the position of the named column, ignoring case, or -1
+func (t *Table) ColumnIndex(name string) int {
+	for i := range t.Columns {
+		if strings.EqualFold(t.Columns[i].GetName(), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// PrimaryKeyString describes the primary key, e.g. PRIMARY KEY (id, name)
+func (t *Table) PrimaryKeyString() string {
+	names := make([]string, len(t.PrimaryKey))
+	for i, column := range t.PrimaryKey {
+		names[i] = t.Columns[column].GetName()
+	}
+	return "PRIMARY KEY (" + strings.Join(names, ", ") + ")"
+}
+
+// PrimaryKeyIndexName returns the name of the unique index backing the primary key, e.g. users_pkey
+func (t *Table) PrimaryKeyIndexName() string {
+	return t.GetName() + "_pkey"
+}
+
+// FindIndex returns the table owning the index with the given name, ignoring case, or nil
+func (db *Database) FindIndex(name string) *Table {
+	for i := range db.Tables {
+		if len(db.Tables[i].PrimaryKey) > 0 && strings.EqualFold(db.Tables[i].PrimaryKeyIndexName(), name) {
+			return &db.Tables[i]
+		}
+	}
+	return nil
+}
+
+// primaryIndex returns the unique index backing the primary key, building it from the stored rows on first use
+func (t *Table) primaryIndex() (*tree.UniqueIndex, error) {
+	if t.pkIndex != nil {
+		return t.pkIndex, nil
+	}
+	index := tree.NewUniqueIndex()
+	for i := range t.Rows {
+		values, err := t.GetRowValues(i)
+		if err != nil {
+			return nil, err
+		}
+		key, err := t.encodeKey(values, t.PrimaryKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := index.Insert(key, i); err != nil {
+			return nil, t.duplicateKeyError(values)
+		}
+	}
+	t.pkIndex = index
+	return index, nil
+}
+
+// checkPrimaryKey adds the key of a new row, about to be stored at position row, to the primary key index
+func (t *Table) checkPrimaryKey(values []interface{}, row int) error {
+	if len(t.PrimaryKey) == 0 {
+		return nil
+	}
+	index, err := t.primaryIndex()
+	if err != nil {
+		return err
+	}
+	key, err := t.encodeKey(values, t.PrimaryKey)
+	if err != nil {
+		return err
+	}
+	if err := index.Insert(key, row); err == tree.ErrDuplicateKey {
+		return t.duplicateKeyError(values)
+	}
+	return nil
+}
+
+func (t *Table) duplicateKeyError(values []interface{}) error {
+	parts := make([]string, len(t.PrimaryKey))
+	for i, column := range t.PrimaryKey {
+		parts[i] = FormatValue(values[column])
+		if s, ok := values[column].(string); ok {
+			parts[i] = quoteLabel(strings.TrimRight(s, " "))
+		}
+	}
+	return fmt.Errorf("duplicate entry (%s) for %s", strings.Join(parts, ", "), t.PrimaryKeyString())
+}
+
+// encodeKey builds an index key from the given columns of a row: each value in its row slot
+// encoding, prefixed by its length. CHAR padding is not significant.
+func (t *Table) encodeKey(values []interface{}, columns []int) ([]byte, error) {
+	var key, slot bytes.Buffer
+	for _, i := range columns {
+		col := &t.Columns[i]
+		value := values[i]
+		if value == nil {
+			return nil, fmt.Errorf("primary key column %s cannot be NULL", col.GetName())
+		}
+		if s, ok := value.(string); ok && col.DataType == SQL_TYPE_CHAR {
+			value = strings.TrimRight(s, " ")
+		}
+		slot.Reset()
+		if err := encodeValue(&slot, value, col); err != nil {
+			return nil, err
+		}
+		binary.Write(&key, binary.LittleEndian, uint32(slot.Len()))
+		key.Write(slot.Bytes())
+	}
+	return key.Bytes(), nil
+}
+
+// hasPrimaryKey reports whether a row with the given encoded primary key exists
+func (t *Table) hasPrimaryKey(key []byte) (bool, error) {
+	index, err := t.primaryIndex()
+	if err != nil {
+		return false, err
+	}
+	_, found := index.Lookup(key)
+	return found, nil
+}
+
+// UpdateRow replaces the values of the row at the given index, keeping the primary key unique
+func (t *Table) UpdateRow(index int, values []interface{}) error {
+	if len(values) != len(t.Columns) {
+		return fmt.Errorf("number of values (%d) does not match number of columns (%d)", len(values), len(t.Columns))
+	}
+	serializedValues, err := serializeValues(values, t.Columns)
+	if err != nil {
+		return fmt.Errorf("error serializing values: %v", err)
+	}
+	if len(t.PrimaryKey) > 0 {
+		old, err := t.GetRowValues(index)
+		if err != nil {
+			return err
+		}
+		oldKey, err := t.encodeKey(old, t.PrimaryKey)
+		if err != nil {
+			return err
+		}
+		newKey, err := t.encodeKey(values, t.PrimaryKey)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(oldKey, newKey) {
+			pkIndex, err := t.primaryIndex()
+			if err != nil {
+				return err
+			}
+			if _, found := pkIndex.Lookup(newKey); found {
+				return t.duplicateKeyError(values)
+			}
+			pkIndex.Delete(oldKey)
+			pkIndex.Insert(newKey, index)
+		}
+	}
+	t.Rows[index].Values = serializedValues
+	return nil
+}
+
+// DeleteRows removes the rows at the given indexes
+func (t *Table) DeleteRows(indexes []int) {
+	remove := make(map[int]bool, len(indexes))
+	for _, i := range indexes {
+		remove[i] = true
+	}
+	kept := t.Rows[:0]
+	for i, row := range t.Rows {
+		if !remove[i] {
+			kept = append(kept, row)
+		}
+	}
+	t.Rows = kept
+	// row numbers have shifted; rebuild the index on next use
+	t.pkIndex = nil
+}
@@ -0,0 +1,105 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// ViewMaintainer keeps incrementally refreshed materialized views up to date as the rows of
+// the tables they read change. Like the RowValidator it lives in the engine, which evaluates
+// the queries of the views.
+type ViewMaintainer interface {
+	// RowChanged applies the change of one row of a table to a view reading it: an insert
+	// when old is nil, a delete when new is nil, an update otherwise
+	RowChanged(v *View, t *Table, old, new []interface{}) error
+}
+
+// isMaterialized reports whether the named table holds the rows of a materialized view
+func (db *Database) isMaterialized(name string) bool {
+	v := db.FindView(name)
+	return v != nil && v.Materialized
+}
+
+// CheckWritable fails for the table of a materialized view, whose rows only change with the
+// tables its query reads or on REFRESH MATERIALIZED VIEW, and for a table a trigger's
+// statement is changing
+func (db *Database) CheckWritable(t *Table) error {
+	if db.isMaterialized(t.GetName()) {
+		return fmt.Errorf("%s is a materialized view; its rows change with REFRESH MATERIALIZED VIEW", t.GetName())
+	}
+	return db.checkNotChanging(t)
+}
+
+// maintained reports whether incrementally refreshed materialized views read the table
+func (db *Database) maintained(t *Table) bool {
+	for i := range db.Views {
+		if db.Views[i].Incremental && db.Views[i].dependsOn(t.GetName()) {
+			return true
+		}
+	}
+	return false
+}
+
+// rowChanged passes the change of one row to the materialized views maintaining themselves
+// from its table. Every row written through the Database goes through it, including
+// cascaded changes.
+func (db *Database) rowChanged(t *Table, old, new []interface{}) error {
+	for i := range db.Views {
+		v := &db.Views[i]
+		if !v.Incremental || !v.dependsOn(t.GetName()) {
+			continue
+		}
+		if db.Maintainer == nil {
+			return fmt.Errorf("cannot maintain materialized view %s", v.Name)
+		}
+		if err := db.Maintainer.RowChanged(v, t, old, new); err != nil {
+			return fmt.Errorf("materialized view %s: %v", v.Name, err)
+		}
+	}
+	return nil
+}
+
+// writeMaterializedViews writes the materialized views, which follow the plain views in the
+// file so that files written before they existed still read
+func (db *Database) writeMaterializedViews(w io.Writer) error {
+	var views []View
+	for _, v := range db.Views {
+		if v.Materialized {
+			views = append(views, v)
+		}
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(views))); err != nil {
+		return fmt.Errorf("error writing number of materialized views: %v", err)
+	}
+	for _, v := range views {
+		if _, err := v.WriteTo(w); err != nil {
+			return fmt.Errorf("error writing materialized view: %v", err)
+		}
+		if err := binary.Write(w, binary.LittleEndian, v.Incremental); err != nil {
+			return fmt.Errorf("error writing materialized view: %v", err)
+		}
+	}
+	return nil
+}
+
+// readMaterializedViews reads the materialized views written by writeMaterializedViews
+func (db *Database) readMaterializedViews(r io.Reader) error {
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err == io.EOF {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading number of materialized views: %v", err)
+	}
+	for i := 0; i < int(count); i++ {
+		v := View{Materialized: true}
+		if _, err := v.ReadFrom(r); err != nil {
+			return fmt.Errorf("error reading materialized view: %v", err)
+		}
+		if err := binary.Read(r, binary.LittleEndian, &v.Incremental); err != nil {
+			return fmt.Errorf("error reading materialized view: %v", err)
+		}
+		db.Views = append(db.Views, v)
+	}
+	return nil
+}
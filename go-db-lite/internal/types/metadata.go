@@ -0,0 +1,191 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// Keys of the Metadata maps set by the database itself. Any other key is free for
+// applications to use with PRAGMA.
+const (
+	MetadataComment = "comment" // COMMENT ON TABLE, in Table.Metadata
+	MetadataCreated = "created" // when the database was created, in Database.Metadata
+	MetadataOwner   = "owner"   // the user who created the database, in Database.Metadata
+)
+
+// ColumnCommentKey is the key of the COMMENT ON COLUMN of a column in Table.Metadata
+func ColumnCommentKey(column string) string {
+	return MetadataComment + "." + column
+}
+
+// SetMetadata sets a key of a metadata map, creating the map if needed; an empty value
+// removes the key
+func SetMetadata(m *map[string]string, key, value string) error {
+	if key == "" {
+		return fmt.Errorf("the metadata key must not be empty")
+	}
+	if len(key) > math.MaxUint16 || len(value) > math.MaxUint16 {
+		return fmt.Errorf("metadata %s is longer than %d bytes", key, math.MaxUint16)
+	}
+	if value == "" {
+		delete(*m, key)
+		return nil
+	}
+	if *m == nil {
+		*m = make(map[string]string)
+	}
+	(*m)[key] = value
+	return nil
+}
+
+// MetadataKeys returns the keys of a metadata map, sorted
+func MetadataKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SameMetadata reports whether the database and its tables carry the same metadata in both
+func SameMetadata(a, b *Database) bool {
+	if !sameMap(a.Metadata, b.Metadata) || len(a.Tables) != len(b.Tables) {
+		return false
+	}
+	for i := range a.Tables {
+		if !sameMap(a.Tables[i].Metadata, b.Tables[i].Metadata) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameMap(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// renameColumnComment moves the comment of a renamed column to its new name
+func (t *Table) renameColumnComment(old, name string) {
+	if comment, ok := t.Metadata[ColumnCommentKey(old)]; ok {
+		delete(t.Metadata, ColumnCommentKey(old))
+		t.Metadata[ColumnCommentKey(name)] = comment
+	}
+}
+
+// writeMetadata writes the metadata of the database, then that of each table by name. It
+// follows the triggers in the file, so that the tables keep their format.
+func (db *Database) writeMetadata(w io.Writer) error {
+	if err := writeMap(w, db.Metadata); err != nil {
+		return fmt.Errorf("error writing database metadata: %v", err)
+	}
+	var tables []*Table
+	for i := range db.Tables {
+		if len(db.Tables[i].Metadata) > 0 {
+			tables = append(tables, &db.Tables[i])
+		}
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(tables))); err != nil {
+		return fmt.Errorf("error writing number of tables with metadata: %v", err)
+	}
+	for _, t := range tables {
+		if _, err := writeString(w, t.GetName()); err != nil {
+			return fmt.Errorf("error writing table metadata: %v", err)
+		}
+		if err := writeMap(w, t.Metadata); err != nil {
+			return fmt.Errorf("error writing table metadata: %v", err)
+		}
+	}
+	return nil
+}
+
+// readMetadata reads the metadata written by writeMetadata; files written before metadata
+// was saved end before it
+func (db *Database) readMetadata(r io.Reader) error {
+	m, err := readMap(r)
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading database metadata: %v", err)
+	}
+	db.Metadata = m
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return fmt.Errorf("error reading number of tables with metadata: %v", err)
+	}
+	for i := uint32(0); i < count; i++ {
+		name, _, err := readString(r)
+		if err != nil {
+			return fmt.Errorf("error reading table metadata: %v", err)
+		}
+		m, err := readMap(r)
+		if err != nil {
+			return fmt.Errorf("error reading table metadata: %v", err)
+		}
+		t := db.FindTable(name)
+		if t == nil {
+			return fmt.Errorf("error reading table metadata: table not found: %s", name)
+		}
+		t.Metadata = m
+	}
+	return nil
+}
+
+// writeMap writes a metadata map in the order of its keys
+func writeMap(w io.Writer, m map[string]string) error {
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(m))); err != nil {
+		return err
+	}
+	for _, key := range MetadataKeys(m) {
+		if _, err := writeString(w, key); err != nil {
+			return err
+		}
+		if _, err := writeString(w, m[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readMap reads a map written by writeMap; it returns io.EOF only when nothing is left to read
+func readMap(r io.Reader) (map[string]string, error) {
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	m := make(map[string]string, count)
+	for i := uint32(0); i < count; i++ {
+		key, _, err := readString(r)
+		if err != nil {
+			return nil, noEOF(err)
+		}
+		value, _, err := readString(r)
+		if err != nil {
+			return nil, noEOF(err)
+		}
+		m[key] = value
+	}
+	return m, nil
+}
+
+// noEOF turns an end of file in the middle of a record into an unexpected one
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Procedure is a stored procedure. Like a view it is stored as text: its body is parsed
+// again every time it is called.
+type Procedure struct {
+	Name   string
+	Params []ProcedureParam
+	Body   string // BEGIN ... END
+}
+
+// ProcedureParam is a parameter of a stored procedure, its type as written, e.g. DECIMAL(10,2)
+type ProcedureParam struct {
+	Name string
+	Type string
+}
+
+// Signature describes the parameters of the procedure, e.g. (id INT, note TEXT)
+func (p *Procedure) Signature() string {
+	params := make([]string, len(p.Params))
+	for i, param := range p.Params {
+		params[i] = param.Name + " " + param.Type
+	}
+	return "(" + strings.Join(params, ", ") + ")"
+}
+
+// String describes the procedure, e.g. CREATE PROCEDURE purge (days INT) BEGIN ... END
+func (p *Procedure) String() string {
+	return "CREATE PROCEDURE " + p.Name + " " + p.Signature() + " " + p.Body
+}
+
+func (p *Procedure) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(p.Params))); err != nil {
+		return written, err
+	}
+	written += 2
+	strs := []string{p.Name, p.Body}
+	for _, param := range p.Params {
+		strs = append(strs, param.Name, param.Type)
+	}
+	for _, s := range strs {
+		n, err := writeString(w, s)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func (p *Procedure) ReadFrom(r io.Reader) (int64, error) {
+	var count uint16
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return 0, err
+	}
+	read := int64(2)
+	strs := make([]string, 2+2*int(count))
+	for i := range strs {
+		s, n, err := readString(r)
+		read += n
+		if err != nil {
+			return read, err
+		}
+		strs[i] = s
+	}
+	p.Name, p.Body = strs[0], strs[1]
+	p.Params = make([]ProcedureParam, count)
+	for i := range p.Params {
+		p.Params[i] = ProcedureParam{Name: strs[2+2*i], Type: strs[3+2*i]}
+	}
+	return read, nil
+}
+
+// FindProcedure returns the procedure with the given name, ignoring case, or nil
+func (db *Database) FindProcedure(name string) *Procedure {
+	for i := range db.Procedures {
+		if strings.EqualFold(db.Procedures[i].Name, name) {
+			return &db.Procedures[i]
+		}
+	}
+	return nil
+}
+
+// CreateProcedure adds a procedure, or with replace redefines an existing one
+func (db *Database) CreateProcedure(p Procedure, replace bool) error {
+	if len(p.Body) > math.MaxUint16 {
+		return fmt.Errorf("the body of procedure %s is longer than %d bytes", p.Name, math.MaxUint16)
+	}
+	if existing := db.FindProcedure(p.Name); existing != nil {
+		if !replace {
+			return fmt.Errorf("procedure already exists: %s", p.Name)
+		}
+		*existing = p
+		return nil
+	}
+	db.Procedures = append(db.Procedures, p)
+	return nil
+}
+
+// RenameProcedure renames a procedure. Procedures calling it by its old name are not changed.
+func (db *Database) RenameProcedure(name, newName string) error {
+	p := db.FindProcedure(name)
+	if p == nil {
+		return fmt.Errorf("procedure not found: %s", name)
+	}
+	if other := db.FindProcedure(newName); other != nil && other != p {
+		return fmt.Errorf("procedure already exists: %s", newName)
+	}
+	p.Name = newName
+	return nil
+}
+
+// DropProcedure removes a procedure
+func (db *Database) DropProcedure(name string) error {
+	for i := range db.Procedures {
+		if strings.EqualFold(db.Procedures[i].Name, name) {
+			db.Procedures = append(db.Procedures[:i], db.Procedures[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("procedure not found: %s", name)
+}
+
+// writeProcedures writes the procedures, which follow the materialized views in the file
+func (db *Database) writeProcedures(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(db.Procedures))); err != nil {
+		return fmt.Errorf("error writing number of procedures: %v", err)
+	}
+	for _, p := range db.Procedures {
+		if _, err := p.WriteTo(w); err != nil {
+			return fmt.Errorf("error writing procedure: %v", err)
+		}
+	}
+	return nil
+}
+
+// readProcedures reads the procedures written by writeProcedures; files written before
+// procedures existed end before them
+func (db *Database) readProcedures(r io.Reader) error {
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err == io.EOF {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading number of procedures: %v", err)
+	}
+	db.Procedures = make([]Procedure, count)
+	for i := range db.Procedures {
+		if _, err := db.Procedures[i].ReadFrom(r); err != nil {
+			return fmt.Errorf("error reading procedure: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ResultSet holds the output of a query: column headers and rows of values
+type ResultSet struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// Print prints the result set in the same grid format as Table.PrintTable, followed by the row count
+func (rs *ResultSet) Print() {
+	printRows(rs.Columns, rs.Rows)
+	if len(rs.Rows) == 1 {
+		fmt.Println("(1 row)")
+	} else {
+		fmt.Printf("(%d rows)\n", len(rs.Rows))
+	}
+}
+
+// FormatValue returns the canonical text representation of a stored or computed value
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
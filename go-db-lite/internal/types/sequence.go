@@ -0,0 +1,176 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Identity is how a column is filled from its table's counter
+type Identity uint8
+
+const (
+	IdentityNone      Identity = iota
+	IdentityByDefault          // AUTO_INCREMENT or GENERATED BY DEFAULT AS IDENTITY: used when no value is given
+	IdentityAlways             // GENERATED ALWAYS AS IDENTITY: values cannot be given
+)
+
+func (id Identity) String() string {
+	switch id {
+	case IdentityByDefault:
+		return "AUTO_INCREMENT"
+	case IdentityAlways:
+		return "GENERATED ALWAYS AS IDENTITY"
+	}
+	return ""
+}
+
+// IdentityColumn returns the index of the identity column of the table, or -1
+func (t *Table) IdentityColumn() int {
+	for i := range t.Columns {
+		if t.Columns[i].Identity != IdentityNone {
+			return i
+		}
+	}
+	return -1
+}
+
+// fillIdentity generates the identity value of a new row when none is given. A larger
+// value given explicitly moves the counter past it, so generated values never collide.
+func (t *Table) fillIdentity(values []interface{}) error {
+	column := t.IdentityColumn()
+	if column == -1 {
+		return nil
+	}
+	if values[column] == nil {
+		if t.AutoIncrement == math.MaxInt64 {
+			return fmt.Errorf("identity column %s is out of values", t.Columns[column].GetName())
+		}
+		t.AutoIncrement++
+		values[column] = t.AutoIncrement
+		return nil
+	}
+	if n, ok := values[column].(int64); ok && n > t.AutoIncrement {
+		t.AutoIncrement = n
+	}
+	return nil
+}
+
+// Sequence is a named counter handing out values with NEXTVAL
+type Sequence struct {
+	Name      string
+	Start     int64
+	Increment int64
+	Last      int64 // last value handed out, valid once Called
+	Called    bool
+}
+
+// String describes the sequence, e.g. CREATE SEQUENCE ids START WITH 1 INCREMENT BY 1
+func (s *Sequence) String() string {
+	return fmt.Sprintf("CREATE SEQUENCE %s START WITH %d INCREMENT BY %d", s.Name, s.Start, s.Increment)
+}
+
+func (s *Sequence) WriteTo(w io.Writer) (int64, error) {
+	written, err := writeString(w, s.Name)
+	if err != nil {
+		return written, err
+	}
+	fields := struct {
+		Start, Increment, Last int64
+		Called                 bool
+	}{s.Start, s.Increment, s.Last, s.Called}
+	if err := binary.Write(w, binary.LittleEndian, fields); err != nil {
+		return written, err
+	}
+	return written + int64(binary.Size(fields)), nil
+}
+
+func (s *Sequence) ReadFrom(r io.Reader) (int64, error) {
+	name, read, err := readString(r)
+	if err != nil {
+		return read, err
+	}
+	var fields struct {
+		Start, Increment, Last int64
+		Called                 bool
+	}
+	if err := binary.Read(r, binary.LittleEndian, &fields); err != nil {
+		return read, err
+	}
+	s.Name, s.Start, s.Increment, s.Last, s.Called = name, fields.Start, fields.Increment, fields.Last, fields.Called
+	return read + int64(binary.Size(fields)), nil
+}
+
+// FindSequence returns the sequence with the given name, ignoring case, or nil
+func (db *Database) FindSequence(name string) *Sequence {
+	for i := range db.Sequences {
+		if strings.EqualFold(db.Sequences[i].Name, name) {
+			return &db.Sequences[i]
+		}
+	}
+	return nil
+}
+
+// CreateSequence adds a sequence to the database
+func (db *Database) CreateSequence(seq Sequence) error {
+	if db.FindSequence(seq.Name) != nil {
+		return fmt.Errorf("sequence already exists: %s", seq.Name)
+	}
+	if seq.Increment == 0 {
+		return fmt.Errorf("INCREMENT must not be zero")
+	}
+	seq.Name = strings.ToLower(seq.Name)
+	db.Sequences = append(db.Sequences, seq)
+	return nil
+}
+
+// DropSequence removes a sequence from the database
+func (db *Database) DropSequence(name string) error {
+	for i := range db.Sequences {
+		if strings.EqualFold(db.Sequences[i].Name, name) {
+			db.Sequences = append(db.Sequences[:i], db.Sequences[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("sequence not found: %s", name)
+}
+
+// NextVal advances a sequence and returns its new value
+func (db *Database) NextVal(name string) (int64, error) {
+	seq := db.FindSequence(name)
+	if seq == nil {
+		return 0, fmt.Errorf("sequence not found: %s", name)
+	}
+	if !seq.Called {
+		seq.Last, seq.Called = seq.Start, true
+	} else {
+		next := seq.Last + seq.Increment
+		if (seq.Increment > 0 && next < seq.Last) || (seq.Increment < 0 && next > seq.Last) {
+			return 0, fmt.Errorf("sequence %s is out of values", seq.Name)
+		}
+		seq.Last = next
+	}
+	db.sequencesChanged = true
+	return seq.Last, nil
+}
+
+// CurrVal returns the value last handed out by a sequence. The database is reread for
+// every statement, so this is the last value of any session rather than of this one.
+func (db *Database) CurrVal(name string) (int64, error) {
+	seq := db.FindSequence(name)
+	if seq == nil {
+		return 0, fmt.Errorf("sequence not found: %s", name)
+	}
+	if !seq.Called {
+		return 0, fmt.Errorf("CURRVAL of sequence %s is not yet defined", seq.Name)
+	}
+	return seq.Last, nil
+}
+
+// SequencesChanged reports whether NEXTVAL advanced a sequence since the database was read,
+// so that statements which do not otherwise write, like SELECT, know to save it
+func (db *Database) SequencesChanged() bool {
+	return db.sequencesChanged
+}
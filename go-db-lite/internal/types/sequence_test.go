@@ -0,0 +1,56 @@
+package types
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// Test that identity counters and sequences continue where they stopped after a reload
+func TestCountersPersist(t *testing.T) {
+	db := NewDatabase()
+	table := Table{}
+	table.CreateTable("items", nil)
+	table.AddColumn("id", SQL_TYPE_INT, false)
+	table.Columns[0].Identity = IdentityByDefault
+	db.AddTable(table)
+	if err := db.CreateSequence(Sequence{Name: "Ids", Start: 10, Increment: -2}); err != nil {
+		t.Fatal(err)
+	}
+
+	items := db.FindTable("items")
+	for _, id := range []interface{}{nil, int64(5), nil} {
+		if err := db.InsertRow(items, []interface{}{id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := items.GetColumnValue(2, 0); got != int64(6) {
+		t.Errorf("generated id after an explicit 5 = %v, want 6", got)
+	}
+	for _, want := range []int64{10, 8} {
+		if got, err := db.NextVal("ids"); err != nil || got != want {
+			t.Errorf("NextVal = %d, %v, want %d", got, err, want)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	if err := db.WriteToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewDatabase()
+	if err := loaded.ReadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	items = loaded.FindTable("items")
+	if err := loaded.InsertRow(items, []interface{}{nil}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := items.GetColumnValue(3, 0); got != int64(7) {
+		t.Errorf("generated id after reload = %v, want 7", got)
+	}
+	if got, err := loaded.CurrVal("ids"); err != nil || got != 8 {
+		t.Errorf("CurrVal after reload = %d, %v, want 8", got, err)
+	}
+	if got, err := loaded.NextVal("ids"); err != nil || got != 6 {
+		t.Errorf("NextVal after reload = %d, %v, want 6", got, err)
+	}
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MaxCharSize    = 255   // CHAR(n) and BINARY(n)
+	MaxVarcharSize = 65535 // VARCHAR(n) and VARBINARY(n)
+)
+
+// IsSized reports whether the type takes a length, e.g. VARCHAR(255)
+func (dt DataType) IsSized() bool {
+	switch dt {
+	case SQL_TYPE_VARCHAR, SQL_TYPE_CHAR, SQL_TYPE_BINARY, SQL_TYPE_VARBINARY:
+		return true
+	}
+	return false
+}
+
+// IsBinary reports whether values of the type are byte strings rather than character strings
+func (dt DataType) IsBinary() bool {
+	return dt == SQL_TYPE_BINARY || dt == SQL_TYPE_VARBINARY
+}
+
+// MaxSize returns the largest length that can be declared for a sized type
+func (dt DataType) MaxSize() int {
+	if dt == SQL_TYPE_CHAR || dt == SQL_TYPE_BINARY {
+		return MaxCharSize
+	}
+	return MaxVarcharSize
+}
+
+// FitString checks a value against the declared length of a CHAR, VARCHAR, BINARY or VARBINARY column.
+// CHAR and VARCHAR count characters, BINARY and VARBINARY count bytes; a Size of 0 means unbounded.
+// Trailing blanks beyond the length are always dropped. Any other over-length value is an error in
+// strict mode and is truncated otherwise. CHAR values are padded with spaces and BINARY values
+// with zero bytes to the full length.
+func (c *Column) FitString(s string, strict bool) (string, error) {
+	if !c.DataType.IsSized() {
+		return s, nil
+	}
+	length, pad := utf8.RuneCountInString(s), " "
+	if c.DataType.IsBinary() {
+		length, pad = len(s), "\x00"
+	}
+	if c.Size > 0 && length > c.Size {
+		cut := c.Size
+		if !c.DataType.IsBinary() {
+			cut = runeOffset(s, c.Size)
+		}
+		if strings.TrimRight(s[cut:], pad) != "" && strict {
+			return "", fmt.Errorf("value of length %d is too long for %s", length, c.TypeString())
+		}
+		s = s[:cut]
+		length = c.Size
+	}
+	if c.DataType == SQL_TYPE_CHAR || c.DataType == SQL_TYPE_BINARY {
+		s += strings.Repeat(pad, c.Size-length)
+	}
+	return s, nil
+}
+
+// runeOffset returns the byte offset of the n-th character of s
+func runeOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(s)
+}
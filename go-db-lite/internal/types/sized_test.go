@@ -0,0 +1,39 @@
+package types
+
+import "testing"
+
+// Test length checks, truncation and padding of sized string types
+func TestFitString(t *testing.T) {
+	tests := []struct {
+		dataType DataType
+		size     int
+		input    string
+		strict   bool
+		want     string
+		fails    bool
+	}{
+		{SQL_TYPE_VARCHAR, 5, "héllo", true, "héllo", false},
+		{SQL_TYPE_VARCHAR, 5, "héllo   ", true, "héllo", false},
+		{SQL_TYPE_VARCHAR, 5, "héllo!", true, "", true},
+		{SQL_TYPE_VARCHAR, 5, "héllo!", false, "héllo", false},
+		{SQL_TYPE_VARCHAR, 0, "unbounded", true, "unbounded", false},
+		{SQL_TYPE_CHAR, 4, "ab", true, "ab  ", false},
+		{SQL_TYPE_CHAR, 4, "abcd  ", true, "abcd", false},
+		{SQL_TYPE_BINARY, 3, "x", true, "x\x00\x00", false},
+		{SQL_TYPE_BINARY, 2, "é!", true, "", true},
+		{SQL_TYPE_VARBINARY, 2, "é!", false, "é", false},
+	}
+	for _, test := range tests {
+		col := Column{DataType: test.dataType, Size: test.size}
+		got, err := col.FitString(test.input, test.strict)
+		if test.fails {
+			if err == nil {
+				t.Errorf("%s: FitString(%q) should fail", col.TypeString(), test.input)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("%s: FitString(%q) = %q, %v, want %q", col.TypeString(), test.input, got, err, test.want)
+		}
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"math/big"
 	"os"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/chaitanyasharma/DBs/go-db-lite/internal/ansi"
+	"github.com/chaitanyasharma/DBs/go-db-lite/internal/tree"
 )
 
 type Column struct {
@@ -18,7 +21,31 @@ type Column struct {
 	DataType     DataType
 	Nullable     bool
 	IsPrimaryKey bool
-	Size         int
+	Size         int      // declared length of CHAR, VARCHAR, BINARY and VARBINARY, 0 if unbounded
+	Precision    int      // total number of digits, DECIMAL only
+	Scale        int      // number of digits after the decimal point, DECIMAL only
+	Labels       []string // declared value list, ENUM and SET only
+	Default      string   // DEFAULT expression as SQL text, empty if none
+	Identity     Identity // filled from the table's AutoIncrement counter
+}
+
+// GetName returns the column name without the trailing null padding
+func (c *Column) GetName() string {
+	return strings.TrimRight(string(c.Name[:]), "\x00")
+}
+
+// TypeString returns the declared type of the column, e.g. DECIMAL(10,2)
+func (c *Column) TypeString() string {
+	if c.DataType == SQL_TYPE_DECIMAL {
+		return fmt.Sprintf("%s(%d,%d)", c.DataType.GetDataTypeString(), c.Precision, c.Scale)
+	}
+	if c.DataType == SQL_TYPE_ENUM || c.DataType == SQL_TYPE_SET {
+		return c.DataType.GetDataTypeString() + c.labelList()
+	}
+	if c.DataType.IsSized() && c.Size > 0 {
+		return fmt.Sprintf("%s(%d)", c.DataType.GetDataTypeString(), c.Size)
+	}
+	return c.DataType.GetDataTypeString()
 }
 
 func (c *Column) WriteTo(w io.Writer) (int64, error) {
@@ -31,7 +58,46 @@ func (c *Column) WriteTo(w io.Writer) (int64, error) {
 	if err := binary.Write(w, binary.LittleEndian, c.DataType); err != nil {
 		return written, err
 	}
-	return written, binary.Write(w, binary.LittleEndian, c.Nullable)
+	if err := binary.Write(w, binary.LittleEndian, c.Nullable); err != nil {
+		return written, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, c.IsPrimaryKey); err != nil {
+		return written, err
+	}
+	written += int64(binary.Size(c.DataType) + binary.Size(c.Nullable) + binary.Size(c.IsPrimaryKey))
+	// precision and scale
+	if err := binary.Write(w, binary.LittleEndian, [2]uint8{uint8(c.Precision), uint8(c.Scale)}); err != nil {
+		return written, err
+	}
+	written += 2
+	if err := binary.Write(w, binary.LittleEndian, uint32(c.Size)); err != nil {
+		return written, err
+	}
+	written += 4
+	// ENUM and SET value list
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(c.Labels))); err != nil {
+		return written, err
+	}
+	written += 2
+	for _, label := range c.Labels {
+		if err := binary.Write(w, binary.LittleEndian, uint16(len(label))); err != nil {
+			return written, err
+		}
+		n, err := io.WriteString(w, label)
+		written += int64(n) + 2
+		if err != nil {
+			return written, err
+		}
+	}
+	m, err := writeString(w, c.Default)
+	written += m
+	if err != nil {
+		return written, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, c.Identity); err != nil {
+		return written, err
+	}
+	return written + 1, nil
 }
 
 func (c *Column) ReadFrom(r io.Reader) (int64, error) {
@@ -45,21 +111,88 @@ func (c *Column) ReadFrom(r io.Reader) (int64, error) {
 		return read, err
 	}
 	read += int64(binary.Size(c.DataType))
-	return read, binary.Read(r, binary.LittleEndian, &c.Nullable)
+	if err := binary.Read(r, binary.LittleEndian, &c.Nullable); err != nil {
+		return read, err
+	}
+	read += int64(binary.Size(c.Nullable))
+	if err := binary.Read(r, binary.LittleEndian, &c.IsPrimaryKey); err != nil {
+		return read, err
+	}
+	read += int64(binary.Size(c.IsPrimaryKey))
+	var precisionScale [2]uint8
+	if err := binary.Read(r, binary.LittleEndian, &precisionScale); err != nil {
+		return read, err
+	}
+	c.Precision, c.Scale = int(precisionScale[0]), int(precisionScale[1])
+	read += 2
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return read, err
+	}
+	c.Size = int(size)
+	read += 4
+	var labelCount uint16
+	if err := binary.Read(r, binary.LittleEndian, &labelCount); err != nil {
+		return read, err
+	}
+	read += 2
+	c.Labels = nil
+	for i := 0; i < int(labelCount); i++ {
+		var length uint16
+		if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+			return read, err
+		}
+		label := make([]byte, length)
+		n, err := io.ReadFull(r, label)
+		read += int64(n) + 2
+		if err != nil {
+			return read, err
+		}
+		c.Labels = append(c.Labels, string(label))
+	}
+	def, n, err := readString(r)
+	c.Default = def
+	read += n
+	if err != nil {
+		return read, err
+	}
+	if err := binary.Read(r, binary.LittleEndian, &c.Identity); err != nil {
+		return read, err
+	}
+	return read + 1, nil
+}
+
+// writeString writes a string prefixed by its uint16 length
+func writeString(w io.Writer, s string) (int64, error) {
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(s))); err != nil {
+		return 0, err
+	}
+	n, err := io.WriteString(w, s)
+	return int64(n) + 2, err
+}
+
+// readString reads a string written by writeString
+func readString(r io.Reader) (string, int64, error) {
+	var length uint16
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return "", 0, err
+	}
+	s := make([]byte, length)
+	n, err := io.ReadFull(r, s)
+	return string(s), int64(n) + 2, err
 }
 
 type Row struct {
 	Values []byte // Store serialized values as a byte slice
+	ID     uint64 // identity of the row across its versions in an mvcc.Store; 0 for a new row, not persisted
 }
 
 func (r *Row) WriteTo(w io.Writer) (int64, error) {
-	var written int64
-	n, err := w.Write(r.Values)
-	written += int64(n)
-	if err != nil {
-		return written, err
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(r.Values))); err != nil {
+		return 0, err
 	}
-	return written, binary.Write(w, binary.LittleEndian, r.Values)
+	n, err := w.Write(r.Values)
+	return int64(n) + 4, err
 }
 
 func (r *Row) ReadFrom(reader io.Reader) (int64, error) {
@@ -68,17 +201,23 @@ func (r *Row) ReadFrom(reader io.Reader) (int64, error) {
 		return 0, err
 	}
 	r.Values = make([]byte, length)
-	_, err := io.ReadFull(reader, r.Values)
-	return 0, err
+	n, err := io.ReadFull(reader, r.Values)
+	return int64(n) + 4, err
 }
 
 type Table struct {
-	Name        [64]byte // Fixed-size field for name
-	Columns     []Column
-	Rows        []Row
-	ColumnCount int
-	RowCount    int
-	Metadata    map[string]string
+	Name          [64]byte // Fixed-size field for name
+	Columns       []Column
+	Rows          []Row
+	ColumnCount   int
+	RowCount      int
+	Metadata      map[string]string
+	PrimaryKey    []int // column indexes of the primary key, in key order
+	ForeignKeys   []ForeignKey
+	Checks        []CheckConstraint
+	AutoIncrement int64 // last value generated for the identity column
+
+	pkIndex *tree.UniqueIndex // built from the rows on first use
 }
 
 func (t *Table) AddColumn(name string, dataType DataType, nullable bool) {
@@ -96,6 +235,9 @@ func (t *Table) AddRow(values []interface{}) error {
 	if err != nil {
 		return fmt.Errorf("error serializing values: %v", err)
 	}
+	if err := t.checkPrimaryKey(values, len(t.Rows)); err != nil {
+		return err
+	}
 
 	t.Rows = append(t.Rows, Row{Values: serializedValues})
 	return nil
@@ -104,11 +246,26 @@ func (t *Table) AddRow(values []interface{}) error {
 func (t *Table) GetColumnNames() []string {
 	names := make([]string, len(t.Columns))
 	for i, col := range t.Columns {
-		names[i] = strings.TrimRight(string(col.Name[:]), "\x00")
+		names[i] = col.GetName()
 	}
 	return names
 }
 
+// GetName returns the table name without the trailing null padding
+func (t *Table) GetName() string {
+	return strings.TrimRight(string(t.Name[:]), "\x00")
+}
+
+// GetRowValues returns the deserialized values of the row at the given index
+func (t *Table) GetRowValues(index int) ([]interface{}, error) {
+	return deserializeValues(t.Rows[index].Values, t.Columns)
+}
+
+// GetColumnValue returns the value of a single column of the row at the given index
+func (t *Table) GetColumnValue(rowIndex, columnIndex int) (interface{}, error) {
+	return decodeColumn(t.Rows[rowIndex].Values, t.Columns, columnIndex)
+}
+
 func (t *Table) GetRow(index int) Row {
 	return t.Rows[index]
 }
@@ -131,9 +288,6 @@ func (t *Table) WriteTo(w io.Writer) (int64, error) {
 	} else {
 		written += int64(n)
 	}
-	if _, err := w.Write(t.Name[:]); err != nil {
-		return written, err
-	}
 
 	// Write number of columns
 	if err := binary.Write(w, binary.LittleEndian, uint32(len(t.Columns))); err != nil {
@@ -147,6 +301,44 @@ func (t *Table) WriteTo(w io.Writer) (int64, error) {
 		}
 	}
 
+	// Write the primary key column indexes
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(t.PrimaryKey))); err != nil {
+		return written, err
+	}
+	for _, column := range t.PrimaryKey {
+		if err := binary.Write(w, binary.LittleEndian, uint16(column)); err != nil {
+			return written, err
+		}
+	}
+
+	// Write the foreign keys
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(t.ForeignKeys))); err != nil {
+		return written, err
+	}
+	for _, fk := range t.ForeignKeys {
+		if _, err := fk.WriteTo(w); err != nil {
+			return written, err
+		}
+	}
+
+	// Write the check constraints
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(t.Checks))); err != nil {
+		return written, err
+	}
+	for _, check := range t.Checks {
+		if _, err := writeString(w, check.Name); err != nil {
+			return written, err
+		}
+		if _, err := writeString(w, check.Expr); err != nil {
+			return written, err
+		}
+	}
+
+	// Write the identity counter
+	if err := binary.Write(w, binary.LittleEndian, t.AutoIncrement); err != nil {
+		return written, err
+	}
+
 	// Write number of rows
 	if err := binary.Write(w, binary.LittleEndian, uint32(len(t.Rows))); err != nil {
 		return written, err
@@ -186,6 +378,69 @@ func (t *Table) ReadFrom(r io.Reader) (int64, error) {
 		}
 	}
 
+	// Read the primary key column indexes
+	var keyCount uint16
+	if err := binary.Read(r, binary.LittleEndian, &keyCount); err != nil {
+		return read, err
+	}
+	t.PrimaryKey = make([]int, keyCount)
+	for i := range t.PrimaryKey {
+		var column uint16
+		if err := binary.Read(r, binary.LittleEndian, &column); err != nil {
+			return read, err
+		}
+		if int(column) >= len(t.Columns) {
+			return read, fmt.Errorf("primary key column %d out of range", column)
+		}
+		t.PrimaryKey[i] = int(column)
+	}
+	read += int64(2 + 2*keyCount)
+	t.pkIndex = nil
+
+	// Read the foreign keys
+	var fkCount uint16
+	if err := binary.Read(r, binary.LittleEndian, &fkCount); err != nil {
+		return read, err
+	}
+	read += 2
+	t.ForeignKeys = make([]ForeignKey, fkCount)
+	for i := range t.ForeignKeys {
+		n, err := t.ForeignKeys[i].ReadFrom(r)
+		read += n
+		if err != nil {
+			return read, err
+		}
+		for _, column := range t.ForeignKeys[i].Columns {
+			if column >= len(t.Columns) {
+				return read, fmt.Errorf("foreign key column %d out of range", column)
+			}
+		}
+	}
+
+	// Read the check constraints
+	var checkCount uint16
+	if err := binary.Read(r, binary.LittleEndian, &checkCount); err != nil {
+		return read, err
+	}
+	read += 2
+	t.Checks = make([]CheckConstraint, checkCount)
+	for i := range t.Checks {
+		for _, field := range []*string{&t.Checks[i].Name, &t.Checks[i].Expr} {
+			s, n, err := readString(r)
+			read += n
+			if err != nil {
+				return read, err
+			}
+			*field = s
+		}
+	}
+
+	// Read the identity counter
+	if err := binary.Read(r, binary.LittleEndian, &t.AutoIncrement); err != nil {
+		return read, err
+	}
+	read += 8
+
 	// Read number of rows
 	var rowCount uint32
 	if err := binary.Read(r, binary.LittleEndian, &rowCount); err != nil {
@@ -204,130 +459,330 @@ func (t *Table) ReadFrom(r io.Reader) (int64, error) {
 	return read, nil
 }
 
+// Rows are encoded as
+//
+//	null bitmap   (n+7)/8 bytes, bit i is set when column i is NULL
+//	offset table  n little-endian uint32, the start of each column's slot within the row
+//	slots         one per column, laid out by the column type (see slotWidth)
+//
+// A slot ends where the next one starts, so variable-length values need no length prefix
+// and NULL slots are empty. A single column can be decoded by looking up its offset.
+
+// slotWidth returns the fixed width in bytes of values of the given type, or -1 for variable-length types
+func slotWidth(dataType DataType) int {
+	switch dataType {
+	case SQL_TYPE_BOOL:
+		return 1
+	case SQL_TYPE_ENUM:
+		return 2
+	case SQL_TYPE_VARCHAR, SQL_TYPE_CHAR, SQL_TYPE_TINYTEXT, SQL_TYPE_TEXT, SQL_TYPE_MEDIUMTEXT, SQL_TYPE_LONGTEXT,
+		SQL_TYPE_BINARY, SQL_TYPE_VARBINARY, SQL_TYPE_DECIMAL:
+		return -1
+	}
+	return 8
+}
+
 func serializeValues(values []interface{}, columns []Column) ([]byte, error) {
-	var buf bytes.Buffer
+	n := len(columns)
+	bitmap := (n + 7) / 8
+	header := make([]byte, bitmap+4*n)
+	var slots bytes.Buffer
 	for i, value := range values {
-		dataTypeStr := columns[i].DataType.GetDataTypeString()
-		buf.WriteString(dataTypeStr)
-		buf.WriteByte(0) // Null terminator for the string
-
+		binary.LittleEndian.PutUint32(header[bitmap+4*i:], uint32(len(header)+slots.Len()))
 		if value == nil {
-			buf.WriteByte(0xFF) // Indicator for null value
+			header[i/8] |= 1 << uint(i%8)
 			continue
 		}
+		if err := encodeValue(&slots, value, &columns[i]); err != nil {
+			return nil, err
+		}
+	}
+	return append(header, slots.Bytes()...), nil
+}
 
-		switch v := value.(type) {
+// encodeValue appends the slot of a non-null value to buf
+func encodeValue(buf *bytes.Buffer, value interface{}, col *Column) error {
+	var ok bool
+	switch col.DataType {
+	case SQL_TYPE_BIT, SQL_TYPE_TINYINT, SQL_TYPE_SMALLINT, SQL_TYPE_MEDIUMINT, SQL_TYPE_INT, SQL_TYPE_BIGINT,
+		SQL_TYPE_YEAR, SQL_TYPE_BLOB:
+		var v int64
+		switch i := value.(type) {
+		case int64:
+			v, ok = i, true
 		case int32:
-			binary.Write(&buf, binary.LittleEndian, v)
-		case string:
-			binary.Write(&buf, binary.LittleEndian, uint16(len(v)))
-			buf.WriteString(v)
-		case bool:
+			v, ok = int64(i), true
+		case int:
+			v, ok = int64(i), true
+		}
+		if ok {
+			binary.Write(buf, binary.LittleEndian, v)
+		}
+	case SQL_TYPE_FLOAT, SQL_TYPE_DOUBLE:
+		var v float64
+		switch f := value.(type) {
+		case float64:
+			v, ok = f, true
+		case float32:
+			v, ok = float64(f), true
+		}
+		if ok {
+			binary.Write(buf, binary.LittleEndian, math.Float64bits(v))
+		}
+	case SQL_TYPE_BOOL:
+		var v bool
+		if v, ok = value.(bool); ok {
 			if v {
 				buf.WriteByte(1)
 			} else {
 				buf.WriteByte(0)
 			}
-		case float32:
-			binary.Write(&buf, binary.LittleEndian, v)
-		default:
-			return nil, fmt.Errorf("unsupported type: %T", v)
 		}
+	case SQL_TYPE_DECIMAL:
+		var v Decimal
+		if v, ok = value.(Decimal); ok {
+			// scale, sign and the big-endian magnitude of the unscaled value
+			magnitude := v.Unscaled()
+			sign := uint8(0)
+			if magnitude.Sign() < 0 {
+				sign = 1
+				magnitude.Neg(magnitude)
+			}
+			buf.Write([]byte{uint8(v.Scale()), sign})
+			buf.Write(magnitude.Bytes())
+		}
+	case SQL_TYPE_DATE, SQL_TYPE_DATETIME, SQL_TYPE_TIMESTAMP:
+		var v DateTime
+		if v, ok = value.(DateTime); ok {
+			binary.Write(buf, binary.LittleEndian, v.UnixMicro())
+		}
+	case SQL_TYPE_TIME:
+		var v TimeOfDay
+		if v, ok = value.(TimeOfDay); ok {
+			binary.Write(buf, binary.LittleEndian, int64(v.Duration()/time.Microsecond))
+		}
+	case SQL_TYPE_ENUM, SQL_TYPE_SET:
+		var label string
+		if label, ok = value.(string); ok {
+			stored, err := col.encodeLabels(label)
+			if err != nil {
+				return err
+			}
+			if col.DataType == SQL_TYPE_ENUM {
+				binary.Write(buf, binary.LittleEndian, uint16(stored))
+			} else {
+				binary.Write(buf, binary.LittleEndian, stored)
+			}
+		}
+	case SQL_TYPE_VARCHAR, SQL_TYPE_CHAR, SQL_TYPE_TINYTEXT, SQL_TYPE_TEXT, SQL_TYPE_MEDIUMTEXT, SQL_TYPE_LONGTEXT,
+		SQL_TYPE_BINARY, SQL_TYPE_VARBINARY:
+		var v string
+		if v, ok = value.(string); ok {
+			buf.WriteString(v)
+		}
+	default:
+		return fmt.Errorf("unsupported data type: %s", col.DataType.GetDataTypeString())
 	}
-	return buf.Bytes(), nil
+	if !ok {
+		return fmt.Errorf("unsupported type for %s column %s: %T", col.DataType.GetDataTypeString(), col.GetName(), value)
+	}
+	return nil
 }
 
 func deserializeValues(serialized []byte, columns []Column) ([]interface{}, error) {
-	var values []interface{}
-	offset := 0
-	for _, col := range columns {
-		if offset >= len(serialized) {
-			return nil, fmt.Errorf("unexpected end of data for column %s", strings.TrimRight(string(col.Name[:]), "\x00"))
+	values := make([]interface{}, len(columns))
+	for i := range columns {
+		value, err := decodeColumn(serialized, columns, i)
+		if err != nil {
+			return nil, err
 		}
+		values[i] = value
+	}
+	return values, nil
+}
 
-		// Read data type string
-		end := bytes.IndexByte(serialized[offset:], 0)
-		if end == -1 {
-			return nil, fmt.Errorf("malformed data: no null terminator for data type string")
-		}
-		dataTypeStr := string(serialized[offset : offset+end])
-		offset += end + 1 // +1 for null terminator
+// decodeColumn decodes the value of column i of an encoded row without decoding the other columns
+func decodeColumn(serialized []byte, columns []Column, i int) (interface{}, error) {
+	n := len(columns)
+	bitmap := (n + 7) / 8
+	col := &columns[i]
+	if len(serialized) < bitmap+4*n {
+		return nil, fmt.Errorf("malformed row: header too short for %d columns", n)
+	}
+	if serialized[i/8]&(1<<uint(i%8)) != 0 {
+		return nil, nil
+	}
+	start := int(binary.LittleEndian.Uint32(serialized[bitmap+4*i:]))
+	end := len(serialized)
+	if i+1 < n {
+		end = int(binary.LittleEndian.Uint32(serialized[bitmap+4*(i+1):]))
+	}
+	if start < bitmap+4*n || start > end || end > len(serialized) {
+		return nil, fmt.Errorf("malformed row: bad offset for column %s", col.GetName())
+	}
+	slot := serialized[start:end]
+	if width := slotWidth(col.DataType); width != -1 && len(slot) != width {
+		return nil, fmt.Errorf("malformed row: column %s has %d bytes, want %d", col.GetName(), len(slot), width)
+	}
 
-		dataType := GetDataTypeFromString(dataTypeStr)
-		if dataType == SQL_TYPE_UNKNOWN {
-			return nil, fmt.Errorf("unknown data type: %s", dataTypeStr)
+	switch col.DataType {
+	case SQL_TYPE_BIT, SQL_TYPE_TINYINT, SQL_TYPE_SMALLINT, SQL_TYPE_MEDIUMINT, SQL_TYPE_INT, SQL_TYPE_BIGINT,
+		SQL_TYPE_YEAR, SQL_TYPE_BLOB:
+		return int64(binary.LittleEndian.Uint64(slot)), nil
+	case SQL_TYPE_DATE, SQL_TYPE_DATETIME, SQL_TYPE_TIMESTAMP:
+		return NewDateTimeFromMicros(int64(binary.LittleEndian.Uint64(slot)), col.DataType), nil
+	case SQL_TYPE_TIME:
+		micros := int64(binary.LittleEndian.Uint64(slot))
+		return TimeOfDay(time.Duration(micros) * time.Microsecond), nil
+	case SQL_TYPE_CHAR:
+		// CHAR is stored blank-padded; the padding is not part of the value
+		return strings.TrimRight(string(slot), " "), nil
+	case SQL_TYPE_VARCHAR, SQL_TYPE_TINYTEXT, SQL_TYPE_TEXT, SQL_TYPE_MEDIUMTEXT, SQL_TYPE_LONGTEXT,
+		SQL_TYPE_BINARY, SQL_TYPE_VARBINARY:
+		return string(slot), nil
+	case SQL_TYPE_BOOL:
+		return slot[0] != 0, nil
+	case SQL_TYPE_FLOAT, SQL_TYPE_DOUBLE:
+		return math.Float64frombits(binary.LittleEndian.Uint64(slot)), nil
+	case SQL_TYPE_DECIMAL:
+		if len(slot) < 2 {
+			return nil, fmt.Errorf("malformed row: column %s has %d bytes", col.GetName(), len(slot))
+		}
+		unscaled := new(big.Int).SetBytes(slot[2:])
+		if slot[1] == 1 {
+			unscaled.Neg(unscaled)
 		}
+		return NewDecimalFromBigInt(unscaled, int32(slot[0])), nil
+	case SQL_TYPE_ENUM:
+		return col.decodeLabels(uint64(binary.LittleEndian.Uint16(slot)))
+	case SQL_TYPE_SET:
+		return col.decodeLabels(binary.LittleEndian.Uint64(slot))
+	}
+	return nil, fmt.Errorf("unsupported data type: %s", col.DataType.GetDataTypeString())
+}
 
-		// Check for null value
-		if serialized[offset] == 0xFF {
-			values = append(values, nil)
-			offset++
-			continue
+// PrintTableMetadata prints the table metadata in a beautiful format: the columns with their
+// nullability, default and key, followed by the indexes and constraints of the table
+func (t *Table) PrintTableMetadata() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Column Name\tType\tNull\tKey\tDefault\tExtra\tComment")
+	fmt.Fprintln(w, "------------\t----\t----\t---\t-------\t-----\t-------")
+
+	for i, col := range t.Columns {
+		null := "NO"
+		if col.Nullable {
+			null = "YES"
+		}
+		defaultValue := col.Default
+		if defaultValue == "" {
+			defaultValue = "NULL"
 		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			col.GetName(),
+			col.TypeString(),
+			null,
+			t.columnKey(i),
+			defaultValue,
+			col.Identity.String(),
+			t.Metadata[ColumnCommentKey(col.GetName())])
+	}
+	w.Flush()
+	if comment := t.Metadata[MetadataComment]; comment != "" {
+		fmt.Printf("COMMENT %s\n", quoteLabel(comment))
+	}
+	if len(t.PrimaryKey) > 0 {
+		fmt.Printf("INDEX %s PRIMARY KEY (%s)\n", t.PrimaryKeyIndexName(), strings.Join(t.columnNames(t.PrimaryKey), ", "))
+	}
+	for i := range t.ForeignKeys {
+		fmt.Printf("CONSTRAINT %s %s\n", t.ForeignKeys[i].Name, t.ForeignKeys[i].String(t))
+	}
+	for _, check := range t.Checks {
+		fmt.Printf("CONSTRAINT %s CHECK (%s)\n", check.Name, check.Expr)
+	}
+}
 
-		switch dataType {
-		case SQL_TYPE_INT:
-			value := int32(binary.LittleEndian.Uint32(serialized[offset:]))
-			values = append(values, value)
-			offset += 4
-		case SQL_TYPE_VARCHAR:
-			length := int(binary.LittleEndian.Uint16(serialized[offset:]))
-			offset += 2
-			value := string(serialized[offset : offset+length])
-			values = append(values, value)
-			offset += length
-		case SQL_TYPE_BOOL:
-			value := serialized[offset] != 0
-			values = append(values, value)
-			offset++
-		case SQL_TYPE_FLOAT:
-			value := math.Float32frombits(binary.LittleEndian.Uint32(serialized[offset:]))
-			values = append(values, value)
-			offset += 4
-		default:
-			return nil, fmt.Errorf("unsupported data type: %s", dataTypeStr)
+// columnKey describes the key a column belongs to: PRI for the primary key, MUL for a
+// foreign key, whose values may repeat, and empty for none
+func (t *Table) columnKey(column int) string {
+	for _, c := range t.PrimaryKey {
+		if c == column {
+			return "PRI"
 		}
 	}
-	return values, nil
+	for _, fk := range t.ForeignKeys {
+		for _, c := range fk.Columns {
+			if c == column {
+				return "MUL"
+			}
+		}
+	}
+	return ""
 }
 
-// PrintTableMetadata prints the table metadata in a beautiful format
-func (t *Table) PrintTableMetadata() {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "Column Name\tType\tSize\tPrimary Key")
-	fmt.Fprintln(w, "------------\t----\t----\t-----------")
+// columnNames returns the names of the columns at the given indexes
+func (t *Table) columnNames(columns []int) []string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = t.Columns[column].GetName()
+	}
+	return names
+}
 
+// CreateTableStatement returns a CREATE TABLE statement that creates the table as it is now,
+// with its columns and constraints but without its rows
+func (t *Table) CreateTableStatement() string {
+	var defs []string
 	for _, col := range t.Columns {
-		primaryKey := "No"
-		if col.IsPrimaryKey {
-			primaryKey = "Yes"
+		def := col.GetName() + " " + col.TypeString()
+		if !col.Nullable {
+			def += " NOT NULL"
 		}
-		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n",
-			strings.TrimRight(string(col.Name[:]), "\x00"),
-			col.DataType.GetDataTypeString(),
-			col.Size,
-			primaryKey)
+		if col.Default != "" {
+			def += " DEFAULT " + col.Default
+		}
+		if col.Identity != IdentityNone {
+			def += " " + col.Identity.String()
+		}
+		defs = append(defs, def)
 	}
-	w.Flush()
+	if len(t.PrimaryKey) > 0 {
+		defs = append(defs, "PRIMARY KEY ("+strings.Join(t.columnNames(t.PrimaryKey), ", ")+")")
+	}
+	for i := range t.ForeignKeys {
+		defs = append(defs, "CONSTRAINT "+t.ForeignKeys[i].Name+" "+t.ForeignKeys[i].String(t))
+	}
+	for _, check := range t.Checks {
+		defs = append(defs, "CONSTRAINT "+check.Name+" CHECK ("+check.Expr+")")
+	}
+	return "CREATE TABLE " + t.GetName() + " (" + strings.Join(defs, ", ") + ")"
 }
 
 // PrintTable prints the table's contents (actual table, i.e. the column names, and then rows below them as opposed to the metadata)
 func (t *Table) PrintTable() {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight|tabwriter.Debug)
-
-	// Determine column widths
-	columnWidths := make([]int, len(t.Columns))
-	for i, col := range t.Columns {
-		columnWidths[i] = len(strings.TrimRight(string(col.Name[:]), "\x00"))
-	}
+	rows := make([][]interface{}, 0, len(t.Rows))
 	for _, row := range t.Rows {
 		values, err := deserializeValues(row.Values, t.Columns)
 		if err != nil {
 			fmt.Println(err)
 			continue
 		}
+		rows = append(rows, values)
+	}
+	printRows(t.GetColumnNames(), rows)
+}
+
+// printRows prints column names and rows of values as a grid
+func printRows(names []string, rows [][]interface{}) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight|tabwriter.Debug)
+
+	// Determine column widths
+	columnWidths := make([]int, len(names))
+	for i, name := range names {
+		columnWidths[i] = len(name)
+	}
+	for _, values := range rows {
 		for i, value := range values {
-			width := len(fmt.Sprintf("%v", value))
+			width := len(FormatValue(value))
 			if width > columnWidths[i] {
 				columnWidths[i] = width
 			}
@@ -335,8 +790,8 @@ func (t *Table) PrintTable() {
 	}
 
 	// Print column names
-	for i, col := range t.Columns {
-		fmt.Fprintf(w, "%s%s%s\t", ansi.BoldText, ansi.Cyan, padRight(strings.TrimRight(string(col.Name[:]), "\x00"), columnWidths[i]))
+	for i, name := range names {
+		fmt.Fprintf(w, "%s%s%s\t", ansi.BoldText, ansi.Cyan, padRight(name, columnWidths[i]))
 	}
 	fmt.Fprintln(w, ansi.Reset)
 
@@ -350,14 +805,9 @@ func (t *Table) PrintTable() {
 	fmt.Fprintln(w)
 
 	// Print rows
-	for _, row := range t.Rows {
-		values, err := deserializeValues(row.Values, t.Columns)
-		if err != nil {
-			fmt.Println(err)
-			continue
-		}
+	for _, values := range rows {
 		for i, value := range values {
-			fmt.Fprintf(w, "%s%s\t", ansi.Green, padRight(fmt.Sprintf("%v", value), columnWidths[i]))
+			fmt.Fprintf(w, "%s%s%s\t", ansi.RegText, ansi.Green, padRight(FormatValue(value), columnWidths[i]))
 		}
 		fmt.Fprintln(w, ansi.Reset)
 	}
@@ -0,0 +1,172 @@
+package types
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// Test the row encoding: NULLs live in the bitmap, so any byte is valid data, and single columns can be decoded
+func TestRowEncoding(t *testing.T) {
+	table := Table{}
+	table.CreateTable("mixed", nil)
+	table.AddColumn("id", SQL_TYPE_INT, false)
+	table.AddColumn("name", SQL_TYPE_VARCHAR, true)
+	table.AddColumn("flag", SQL_TYPE_BOOL, true)
+	table.AddColumn("price", SQL_TYPE_DECIMAL, true)
+	table.AddColumn("note", SQL_TYPE_TEXT, true)
+
+	price, _ := ParseDecimal("-12.50")
+	rows := [][]interface{}{
+		{int64(-1), "\xff\x00\xff", true, price, ""},
+		{int64(2), nil, nil, nil, nil},
+	}
+	for _, row := range rows {
+		if err := table.AddRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 1 bitmap byte + 5 offsets + int + 3 bytes + bool + decimal (scale, sign, 2 byte magnitude) + empty string
+	if got, want := len(table.Rows[0].Values), 1+5*4+8+3+1+4+0; got != want {
+		t.Errorf("row 0 is %d bytes, want %d", got, want)
+	}
+	if got, want := len(table.Rows[1].Values), 1+5*4+8; got != want {
+		t.Errorf("row 1 is %d bytes, want %d", got, want)
+	}
+
+	for i, row := range rows {
+		values, err := table.GetRowValues(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, want := range row {
+			if FormatValue(values[j]) != FormatValue(want) || (want == nil) != (values[j] == nil) {
+				t.Errorf("row %d column %d = %v, want %v", i, j, values[j], want)
+			}
+		}
+	}
+
+	name, err := table.GetColumnValue(0, 1)
+	if err != nil || name != "\xff\x00\xff" {
+		t.Errorf("GetColumnValue(0, 1) = %q, %v", name, err)
+	}
+	if note, err := table.GetColumnValue(0, 4); err != nil || note != "" {
+		t.Errorf("GetColumnValue(0, 4) = %v, %v; an empty string is not NULL", note, err)
+	}
+	if err := table.AddRow([]interface{}{"x", nil, nil, nil, nil}); err == nil {
+		t.Error("AddRow should reject a string for an INT column")
+	}
+}
+
+// Test that a composite primary key rejects duplicates, including after a write and read back
+func TestPrimaryKey(t *testing.T) {
+	table := Table{}
+	table.CreateTable("pairs", nil)
+	table.AddColumn("a", SQL_TYPE_INT, true)
+	table.AddColumn("b", SQL_TYPE_CHAR, true)
+	table.Columns[1].Size = 3
+	if err := table.SetPrimaryKey([]string{"B", "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if table.Columns[0].Nullable || table.Columns[1].Nullable {
+		t.Error("primary key columns should be NOT NULL")
+	}
+
+	for _, row := range [][]interface{}{{int64(1), "x  "}, {int64(2), "x  "}, {int64(1), "y  "}} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := table.AddRow([]interface{}{int64(1), "x"}); err == nil {
+		t.Error("duplicate key (x, 1) should be rejected")
+	}
+
+	var buf bytes.Buffer
+	if _, err := table.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var loaded Table
+	if _, err := loaded.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.PrimaryKeyString() != "PRIMARY KEY (b, a)" {
+		t.Errorf("loaded key = %s", loaded.PrimaryKeyString())
+	}
+	if err := loaded.AddRow([]interface{}{int64(2), "x  "}); err == nil {
+		t.Error("duplicate key (x, 2) should be rejected after reloading")
+	}
+	if err := loaded.AddRow([]interface{}{int64(2), nil}); err == nil {
+		t.Error("NULL key should be rejected")
+	}
+	if err := loaded.AddRow([]interface{}{int64(2), "y  "}); err != nil {
+		t.Error(err)
+	}
+}
+
+// Test that the metadata of the database and its tables is saved, and follows renamed and dropped columns
+func TestMetadataPersisted(t *testing.T) {
+	db := NewDatabase()
+	table := Table{}
+	table.CreateTable("acct", nil)
+	table.AddColumn("id", SQL_TYPE_INT, false)
+	table.AddColumn("bal", SQL_TYPE_INT, true)
+	table.AddColumn("note", SQL_TYPE_VARCHAR, true)
+	db.AddTable(table)
+	acct := db.FindTable("acct")
+	for _, err := range []error{
+		SetMetadata(&db.Metadata, MetadataOwner, "root"),
+		SetMetadata(&acct.Metadata, MetadataComment, "accounts"),
+		SetMetadata(&acct.Metadata, ColumnCommentKey("bal"), "balance"),
+		SetMetadata(&acct.Metadata, ColumnCommentKey("note"), "free text"),
+		db.RenameColumn(acct, 1, "balance"),
+		acct.DropColumn(2),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	clone, err := db.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !SameMetadata(db, clone) || clone.Metadata[MetadataOwner] != "root" {
+		t.Fatalf("metadata not saved: %v %v", clone.Metadata, clone.FindTable("acct").Metadata)
+	}
+	want := map[string]string{MetadataComment: "accounts", ColumnCommentKey("balance"): "balance"}
+	if got := clone.FindTable("acct").Metadata; !sameMap(got, want) {
+		t.Errorf("table metadata = %v, want %v", got, want)
+	}
+	if err := SetMetadata(&db.Metadata, MetadataOwner, ""); err != nil || len(db.Metadata) != 0 {
+		t.Errorf("an empty value did not remove the key: %v", db.Metadata)
+	}
+}
+
+// Test that database files carry their format version and that files of another format are
+// rejected rather than misread
+func TestFileFormatVersion(t *testing.T) {
+	path := t.TempDir() + "/test.db"
+	if err := NewDatabase().WriteToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	db := NewDatabase()
+	if err := db.ReadFromFile(path); err != nil || db.FileHeader.Version != FormatVersion {
+		t.Fatalf("reading a new file: version %d, %v", db.FileHeader.Version, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, change := range map[string]func([]byte) []byte{
+		"without a header":  func(b []byte) []byte { return b[8:] },
+		"of a next version": func(b []byte) []byte { b[4]++; return b },
+	} {
+		changed := change(append([]byte(nil), data...))
+		if err := os.WriteFile(path, changed, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := NewDatabase().ReadFromFile(path); err == nil {
+			t.Errorf("a file %s was read", name)
+		}
+	}
+}
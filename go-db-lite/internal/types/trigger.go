@@ -0,0 +1,209 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Trigger runs its body for every row an INSERT, UPDATE or DELETE changes in its table.
+// Like a procedure it is stored as text and parsed again when it runs.
+type Trigger struct {
+	Name   string
+	Table  string
+	Timing string // BEFORE or AFTER
+	Event  string // INSERT, UPDATE or DELETE
+	Body   string // BEGIN ... END, or a single statement
+}
+
+// String describes the trigger, e.g. CREATE TRIGGER audit AFTER DELETE ON accounts FOR EACH ROW ...
+func (tr *Trigger) String() string {
+	return "CREATE TRIGGER " + tr.Name + " " + tr.Timing + " " + tr.Event + " ON " + tr.Table + " FOR EACH ROW " + tr.Body
+}
+
+func (tr *Trigger) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for _, s := range []string{tr.Name, tr.Table, tr.Timing, tr.Event, tr.Body} {
+		n, err := writeString(w, s)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func (tr *Trigger) ReadFrom(r io.Reader) (int64, error) {
+	var read int64
+	for _, s := range []*string{&tr.Name, &tr.Table, &tr.Timing, &tr.Event, &tr.Body} {
+		value, n, err := readString(r)
+		read += n
+		if err != nil {
+			return read, err
+		}
+		*s = value
+	}
+	return read, nil
+}
+
+// TriggerExecutor runs the bodies of triggers. Like the RowValidator it lives in the engine,
+// which evaluates the statements of the body.
+type TriggerExecutor interface {
+	// RunTrigger runs a trigger for one row of its table. old is nil for INSERT and new is
+	// nil for DELETE; a BEFORE trigger may change the values of new before they are written.
+	RunTrigger(tr *Trigger, t *Table, old, new []interface{}) error
+}
+
+// FindTrigger returns the trigger with the given name, ignoring case, or nil
+func (db *Database) FindTrigger(name string) *Trigger {
+	for i := range db.Triggers {
+		if strings.EqualFold(db.Triggers[i].Name, name) {
+			return &db.Triggers[i]
+		}
+	}
+	return nil
+}
+
+// CreateTrigger adds a trigger on an existing table. Triggers of the same table, timing and
+// event run in the order they were created.
+func (db *Database) CreateTrigger(tr Trigger) error {
+	if len(tr.Body) > math.MaxUint16 {
+		return fmt.Errorf("the body of trigger %s is longer than %d bytes", tr.Name, math.MaxUint16)
+	}
+	if db.FindTrigger(tr.Name) != nil {
+		return fmt.Errorf("trigger already exists: %s", tr.Name)
+	}
+	t := db.FindTable(tr.Table)
+	if t == nil {
+		return fmt.Errorf("table not found: %s", tr.Table)
+	}
+	if db.FindView(tr.Table) != nil {
+		return fmt.Errorf("%s is a materialized view; triggers can only be created on tables", tr.Table)
+	}
+	tr.Table = t.GetName()
+	db.Triggers = append(db.Triggers, tr)
+	return nil
+}
+
+// DropTrigger removes a trigger
+func (db *Database) DropTrigger(name string) error {
+	for i := range db.Triggers {
+		if strings.EqualFold(db.Triggers[i].Name, name) {
+			db.Triggers = append(db.Triggers[:i], db.Triggers[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("trigger not found: %s", name)
+}
+
+// TableTriggers returns the triggers of a table
+func (db *Database) TableTriggers(table string) []Trigger {
+	var triggers []Trigger
+	for _, tr := range db.Triggers {
+		if strings.EqualFold(tr.Table, table) {
+			triggers = append(triggers, tr)
+		}
+	}
+	return triggers
+}
+
+// dropTableTriggers removes the triggers of a table that is dropped
+func (db *Database) dropTableTriggers(table string) {
+	kept := db.Triggers[:0]
+	for _, tr := range db.Triggers {
+		if !strings.EqualFold(tr.Table, table) {
+			kept = append(kept, tr)
+		}
+	}
+	db.Triggers = kept
+}
+
+// renameTableTriggers moves the triggers of a renamed table to its new name
+func (db *Database) renameTableTriggers(table, newName string) {
+	for i := range db.Triggers {
+		if strings.EqualFold(db.Triggers[i].Table, table) {
+			db.Triggers[i].Table = newName
+		}
+	}
+}
+
+// hasTriggers reports whether an event on the table fires any trigger
+func (db *Database) hasTriggers(t *Table, event string) bool {
+	for i := range db.Triggers {
+		if db.Triggers[i].Event == event && strings.EqualFold(db.Triggers[i].Table, t.GetName()) {
+			return true
+		}
+	}
+	return false
+}
+
+// fireTriggers runs the triggers of a table for the change of one row
+func (db *Database) fireTriggers(t *Table, timing, event string, old, new []interface{}) error {
+	for i := range db.Triggers {
+		tr := db.Triggers[i]
+		if tr.Timing != timing || tr.Event != event || !strings.EqualFold(tr.Table, t.GetName()) {
+			continue
+		}
+		if db.Executor == nil {
+			return fmt.Errorf("cannot run trigger %s", tr.Name)
+		}
+		if err := db.Executor.RunTrigger(&tr, t, old, new); err != nil {
+			return fmt.Errorf("trigger %s: %v", tr.Name, err)
+		}
+	}
+	return nil
+}
+
+// changingTables marks tables as being changed by the running statement until the returned
+// function is called. A trigger cannot write those tables: the rows the statement is
+// working through would move under it.
+func (db *Database) changingTables(tables ...*Table) func() {
+	n := len(db.changing)
+	for _, t := range tables {
+		db.changing = append(db.changing, t.GetName())
+	}
+	return func() { db.changing = db.changing[:n] }
+}
+
+// checkNotChanging fails for a table the running statement is changing
+func (db *Database) checkNotChanging(t *Table) error {
+	for _, name := range db.changing {
+		if strings.EqualFold(name, t.GetName()) {
+			return fmt.Errorf("table %s is being changed by the statement that fired the trigger", t.GetName())
+		}
+	}
+	return nil
+}
+
+// writeTriggers writes the triggers, which follow the procedures in the file
+func (db *Database) writeTriggers(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(db.Triggers))); err != nil {
+		return fmt.Errorf("error writing number of triggers: %v", err)
+	}
+	for _, tr := range db.Triggers {
+		if _, err := tr.WriteTo(w); err != nil {
+			return fmt.Errorf("error writing trigger: %v", err)
+		}
+	}
+	return nil
+}
+
+// readTriggers reads the triggers written by writeTriggers; files written before triggers
+// existed end before them
+func (db *Database) readTriggers(r io.Reader) error {
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err == io.EOF {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading number of triggers: %v", err)
+	}
+	db.Triggers = make([]Trigger, count)
+	for i := range db.Triggers {
+		if _, err := db.Triggers[i].ReadFrom(r); err != nil {
+			return fmt.Errorf("error reading trigger: %v", err)
+		}
+	}
+	return nil
+}
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // // InputBuffer represents the structure for an input buffer
 type InputBuffer struct {
 	Buffer       []byte
@@ -27,11 +29,16 @@ type Config struct {
 	HomeDir    string
 	InMemory   bool
 	DBFileName string
+	StrictMode bool // reject over-length values instead of truncating them
+
+	// BusyTimeout is how long to wait for a database file locked by another process
+	// before failing with "database is locked"
+	BusyTimeout time.Duration
 }
 
 // constructor
 func NewConfig(homeDir string, inMemory bool, dbFileName string) *Config {
-	return &Config{HomeDir: homeDir, InMemory: inMemory, DBFileName: dbFileName}
+	return &Config{HomeDir: homeDir, InMemory: inMemory, DBFileName: dbFileName, StrictMode: true, BusyTimeout: 5 * time.Second}
 }
 
 func (c *Config) GetDBFilePath() string {
@@ -0,0 +1,207 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// View is a named query. It is stored as the text of its SELECT statement and expanded
+// every time it is queried, so it always shows the current rows of the tables it reads.
+// A materialized view instead keeps the result of its query in a table of the same name.
+type View struct {
+	Name    string
+	Columns []string // names given to the columns of the query, empty to keep its own
+	Query   string   // the SELECT statement
+	Uses    []string // the tables and views the query reads
+
+	Materialized bool
+	Incremental  bool // materialized only: kept up to date as the rows it reads change
+}
+
+// String describes the view, e.g. CREATE VIEW cheap AS SELECT * FROM items WHERE price < 10
+func (v *View) String() string {
+	columns := ""
+	if len(v.Columns) > 0 {
+		columns = " (" + strings.Join(v.Columns, ", ") + ")"
+	}
+	kind := "VIEW "
+	if v.Materialized {
+		kind = "MATERIALIZED VIEW "
+	}
+	return "CREATE " + kind + v.Name + columns + " AS " + v.Query
+}
+
+// dependsOn reports whether the view reads the named table or view directly
+func (v *View) dependsOn(name string) bool {
+	for _, used := range v.Uses {
+		if strings.EqualFold(used, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *View) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	strs := append([]string{v.Name, v.Query}, v.Columns...)
+	strs = append(strs, v.Uses...)
+	counts := [2]uint16{uint16(len(v.Columns)), uint16(len(v.Uses))}
+	if err := binary.Write(w, binary.LittleEndian, counts); err != nil {
+		return written, err
+	}
+	written += int64(binary.Size(counts))
+	for _, s := range strs {
+		n, err := writeString(w, s)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func (v *View) ReadFrom(r io.Reader) (int64, error) {
+	var counts [2]uint16
+	if err := binary.Read(r, binary.LittleEndian, &counts); err != nil {
+		return 0, err
+	}
+	read := int64(binary.Size(counts))
+	strs := make([]string, 2+int(counts[0])+int(counts[1]))
+	for i := range strs {
+		s, n, err := readString(r)
+		read += n
+		if err != nil {
+			return read, err
+		}
+		strs[i] = s
+	}
+	v.Name, v.Query = strs[0], strs[1]
+	v.Columns = strs[2 : 2+counts[0]]
+	v.Uses = strs[2+counts[0]:]
+	return read, nil
+}
+
+// FindView returns the view with the given name, ignoring case, or nil
+func (db *Database) FindView(name string) *View {
+	for i := range db.Views {
+		if strings.EqualFold(db.Views[i].Name, name) {
+			return &db.Views[i]
+		}
+	}
+	return nil
+}
+
+// CreateView adds a view, or with replace redefines an existing one. The view must not
+// read itself, directly or through other views. The table of a materialized view is added
+// by the caller once the view is defined.
+func (db *Database) CreateView(v View, replace bool) error {
+	if db.FindTable(v.Name) != nil && !db.isMaterialized(v.Name) {
+		return fmt.Errorf("a table named %s already exists", v.Name)
+	}
+	if len(v.Query) > math.MaxUint16 {
+		return fmt.Errorf("the query of view %s is longer than %d bytes", v.Name, math.MaxUint16)
+	}
+	for _, used := range v.Uses {
+		if strings.EqualFold(used, v.Name) || db.viewReads(used, v.Name) {
+			return fmt.Errorf("view %s would read itself", v.Name)
+		}
+	}
+	if existing := db.FindView(v.Name); existing != nil {
+		if !replace {
+			return fmt.Errorf("view already exists: %s", v.Name)
+		}
+		if existing.Materialized {
+			return fmt.Errorf("%s is a materialized view and cannot be replaced", existing.Name)
+		}
+		*existing = v
+		return nil
+	}
+	db.Views = append(db.Views, v)
+	return nil
+}
+
+// viewReads reports whether the named view reads target, directly or through other views
+func (db *Database) viewReads(name, target string) bool {
+	v := db.FindView(name)
+	if v == nil {
+		return false
+	}
+	for _, used := range v.Uses {
+		if strings.EqualFold(used, target) || db.viewReads(used, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// DependentViews returns the names of the views reading the named table or view, directly
+// or through other views, those reading the others first
+func (db *Database) DependentViews(name string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		for i := range db.Views {
+			v := &db.Views[i]
+			if v.dependsOn(name) && !seen[strings.ToLower(v.Name)] {
+				seen[strings.ToLower(v.Name)] = true
+				visit(v.Name)
+				names = append(names, v.Name)
+			}
+		}
+	}
+	visit(name)
+	return names
+}
+
+// checkNoDependentViews fails if views read the named table or view
+func (db *Database) checkNoDependentViews(kind, name string) error {
+	if dependents := db.DependentViews(name); len(dependents) > 0 {
+		return fmt.Errorf("cannot drop %s %s, view %s depends on it (use CASCADE to drop the views too)", kind, name, dependents[len(dependents)-1])
+	}
+	return nil
+}
+
+// DropView removes a view, and the table of a materialized view. A view read by other views
+// cannot be dropped unless cascade is set, which drops them as well; the names of all views
+// dropped are returned.
+func (db *Database) DropView(name string, cascade bool) ([]string, error) {
+	v := db.FindView(name)
+	if v == nil {
+		return nil, fmt.Errorf("view not found: %s", name)
+	}
+	name = v.Name
+	var dropped []string
+	if cascade {
+		dropped = db.DependentViews(name)
+	} else if err := db.checkNoDependentViews("view", name); err != nil {
+		return nil, err
+	}
+	dropped = append(dropped, name)
+	kept := db.Views[:0]
+	for _, v := range db.Views {
+		drop := false
+		for _, d := range dropped {
+			drop = drop || strings.EqualFold(v.Name, d)
+		}
+		if !drop {
+			kept = append(kept, v)
+		} else if t := db.FindTable(v.Name); v.Materialized && t != nil {
+			db.removeTable(t)
+		}
+	}
+	db.Views = kept
+	return dropped, nil
+}
+
+// DropDependentViews removes the views reading the named table or view and returns their names
+func (db *Database) DropDependentViews(name string) []string {
+	dropped := db.DependentViews(name)
+	for _, d := range dropped {
+		db.DropView(d, true)
+	}
+	return dropped
+}